@@ -0,0 +1,359 @@
+// ABOUTME: REST API endpoint handlers for entries, search, tags, stats, and the feed
+// ABOUTME: Parses query filters and request bodies and writes JSON responses
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/feed"
+)
+
+// Result limits for the list and search endpoints.
+const (
+	defaultLimit = 50
+	maxLimit     = 1000
+)
+
+// entriesResponse is the body of list and search responses.
+type entriesResponse struct {
+	Entries []charm.Entry `json:"entries"`
+	Count   int           `json:"count"`
+}
+
+// createEntryRequest is the body of POST /api/entries.
+type createEntryRequest struct {
+	Message  string            `json:"message"`
+	Type     string            `json:"type,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// updateEntryRequest is the body of PATCH /api/entries/{id}. Fields left
+// out are unchanged.
+type updateEntryRequest struct {
+	Message *string   `json:"message,omitempty"`
+	Type    *string   `json:"type,omitempty"`
+	Tags    *[]string `json:"tags,omitempty"`
+}
+
+// statsResponse is the body of GET /api/stats.
+type statsResponse struct {
+	*charm.Stats
+	TopTags        []charm.Count `json:"top_tags"`
+	TopDirectories []charm.Count `json:"top_directories"`
+	LongestStreak  int           `json:"longest_streak"`
+}
+
+// handleListEntries implements GET /api/entries.
+func (s *Server) handleListEntries(w http.ResponseWriter, r *http.Request) {
+	s.writeEntries(w, r, "")
+}
+
+// handleSearch implements GET /api/search, a full-text search over
+// messages and metadata.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if text == "" {
+		writeFailure(w, invalidInput("q is required"))
+		return
+	}
+	s.writeEntries(w, r, text)
+}
+
+// writeEntries responds with the newest entries matching the request's
+// filters and text, which may be empty.
+func (s *Server) writeEntries(w http.ResponseWriter, r *http.Request, text string) {
+	q := r.URL.Query()
+	filter, err := entryFilter(q)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	filter.Text = text
+	limit, err := limitParam(q)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	entries, err := s.client.SearchEntries(filter, limit)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newEntriesResponse(entries))
+}
+
+// newEntriesResponse wraps entries for a list or search response, as an
+// empty list rather than null when there are none.
+func newEntriesResponse(entries []charm.Entry) entriesResponse {
+	if entries == nil {
+		entries = []charm.Entry{}
+	}
+	return entriesResponse{Entries: entries, Count: len(entries)}
+}
+
+// handleFeed implements GET /feed, the newest entries matching the same
+// filters as /api/entries as an Atom feed.
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := entryFilter(q)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	limit, err := limitParam(q)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	entries, err := s.client.SearchEntries(filter, limit)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+
+	// The self link repeats the filters but never the token
+	self := *r.URL
+	params := self.Query()
+	params.Del("token")
+	self.RawQuery = params.Encode()
+	self.Scheme = "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		self.Scheme = "https"
+	}
+	self.Host = r.Host
+
+	var buf bytes.Buffer
+	opts := feed.Options{Title: feed.DefaultTitle(filter.Tags), SelfURL: self.String()}
+	if err := feed.WriteAtom(&buf, entries, opts); err != nil {
+		writeFailure(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleGetEntry implements GET /api/entries/{id}.
+func (s *Server) handleGetEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.client.GetEntry(r.PathValue("id"))
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// handleCreateEntry implements POST /api/entries.
+func (s *Server) handleCreateEntry(w http.ResponseWriter, r *http.Request) {
+	var req createEntryRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeFailure(w, err)
+		return
+	}
+	entry, err := s.newEntry(req)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	id, err := s.client.CreateEntry(entry)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/entries/"+id)
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+// newEntry builds the entry for a create request: secrets redacted, the
+// config's default tags added, and this host and user recorded.
+func (s *Server) newEntry(req createEntryRequest) (charm.Entry, error) {
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		return charm.Entry{}, invalidInput("message cannot be empty")
+	}
+	if req.Type != "" && !charm.ValidEntryType(req.Type) {
+		return charm.Entry{}, invalidInput("invalid type %q (valid: %s)", req.Type, strings.Join(charm.EntryTypes, ", "))
+	}
+	message, _, err := s.redactor.Redact(message)
+	if err != nil {
+		return charm.Entry{}, err
+	}
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "unknown"
+	}
+	username := os.Getenv("USER")
+	if username == "" {
+		username = "unknown"
+	}
+	return charm.Entry{
+		Timestamp: time.Now(),
+		Message:   message,
+		Type:      req.Type,
+		Hostname:  hostname,
+		Username:  username,
+		Tags:      charm.MergeTags(req.Tags, s.defaultTags),
+		Source:    charm.SourceAPI,
+		Metadata:  req.Metadata,
+	}, nil
+}
+
+// handleUpdateEntry implements PATCH /api/entries/{id}.
+func (s *Server) handleUpdateEntry(w http.ResponseWriter, r *http.Request) {
+	var req updateEntryRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeFailure(w, err)
+		return
+	}
+	entry, err := s.client.GetEntry(r.PathValue("id"))
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	if err := s.applyUpdate(entry, req); err != nil {
+		writeFailure(w, err)
+		return
+	}
+	if err := s.client.UpdateEntry(*entry); err != nil {
+		writeFailure(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// applyUpdate changes entry as req says, redacting a new message.
+func (s *Server) applyUpdate(entry *charm.Entry, req updateEntryRequest) error {
+	if req.Message == nil && req.Type == nil && req.Tags == nil {
+		return invalidInput("nothing to change: give message, type, or tags")
+	}
+	if req.Message != nil {
+		message := strings.TrimSpace(*req.Message)
+		if message == "" {
+			return invalidInput("message cannot be empty")
+		}
+		message, _, err := s.redactor.Redact(message)
+		if err != nil {
+			return err
+		}
+		entry.Message = message
+	}
+	if req.Type != nil {
+		if *req.Type != "" && !charm.ValidEntryType(*req.Type) {
+			return invalidInput("invalid type %q (valid: %s)", *req.Type, strings.Join(charm.EntryTypes, ", "))
+		}
+		entry.Type = *req.Type
+	}
+	if req.Tags != nil {
+		entry.Tags = *req.Tags
+	}
+	return nil
+}
+
+// handleDeleteEntry implements DELETE /api/entries/{id}.
+func (s *Server) handleDeleteEntry(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := s.client.GetEntry(id); err != nil {
+		writeFailure(w, err)
+		return
+	}
+	if err := s.client.DeleteEntry(id); err != nil {
+		writeFailure(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleTags implements GET /api/tags: every tag with its entry count,
+// most used first.
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.client.Stats(nil)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]charm.Count{"tags": stats.TopTags(0)})
+}
+
+// handleStats implements GET /api/stats over the entries matching the
+// request's filters.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	filter, err := entryFilter(r.URL.Query())
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	stats, err := s.client.Stats(filter)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, statsResponse{
+		Stats:          stats,
+		TopTags:        stats.TopTags(0),
+		TopDirectories: stats.TopDirectories(10),
+		LongestStreak:  stats.LongestStreak(),
+	})
+}
+
+// decodeBody decodes the JSON request body into v, rejecting unknown
+// fields and oversized bodies.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return invalidInput("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// entryFilter reads the tag (repeatable), type, source, since, and until
+// query parameters.
+func entryFilter(q url.Values) (*charm.SearchFilter, error) {
+	filter := &charm.SearchFilter{
+		Tags:   q["tag"],
+		Type:   q.Get("type"),
+		Source: q.Get("source"),
+	}
+	if filter.Type != "" && !charm.ValidEntryType(filter.Type) {
+		return nil, invalidInput("invalid type %q (valid: %s)", filter.Type, strings.Join(charm.EntryTypes, ", "))
+	}
+	for _, param := range []struct {
+		name string
+		dest **time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value := q.Get(param.name)
+		if value == "" {
+			continue
+		}
+		t, err := dateparse.ParseLocal(value)
+		if err != nil {
+			return nil, invalidInput("invalid %s date %q", param.name, value)
+		}
+		*param.dest = &t
+	}
+	return filter, nil
+}
+
+// limitParam reads the limit query parameter, defaulting to defaultLimit
+// and capped at maxLimit.
+func limitParam(q url.Values) (int, error) {
+	value := q.Get("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, invalidInput("limit must be a positive number")
+	}
+	return min(limit, maxLimit), nil
+}
@@ -0,0 +1,107 @@
+// ABOUTME: Inbound webhook endpoint turning CI, GitHub, and monitoring payloads into entries
+// ABOUTME: Each hook has its own token and a mapping from the inbound_hooks config
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+// handleHook implements POST /hooks/{name}: it maps the payload with the
+// named hook and adds the entry, or skips it when the hook's when
+// condition says so.
+func (s *Server) handleHook(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	// The token is checked first, and unknown hooks get the same answer as
+	// wrong tokens, so callers can't probe which hooks exist
+	authorized := s.hookAuthorized(r, name)
+	hook, ok := s.hooks[name]
+	if !authorized || !ok {
+		writeError(w, http.StatusUnauthorized, "invalid hook token")
+		return
+	}
+
+	payload, err := readPayload(w, r)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	mapped, ok, err := hook.Map(payload)
+	if err != nil {
+		writeFailure(w, invalidInput("hook %s: %v", name, err))
+		return
+	}
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]bool{"skipped": true})
+		return
+	}
+
+	metadata := map[string]string{"hook": name}
+	for k, v := range mapped.Metadata {
+		metadata[k] = v
+	}
+	entry, err := s.newEntry(createEntryRequest{
+		Message:  mapped.Message,
+		Type:     mapped.Type,
+		Tags:     mapped.Tags,
+		Metadata: metadata,
+	})
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	entry.Source = charm.SourceWebhook
+	id, err := s.client.CreateEntry(entry)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/entries/"+id)
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+// hookAuthorized reports whether r carries the bearer token made for the
+// hook called name. A token for another hook, or an API token, won't do.
+func (s *Server) hookAuthorized(r *http.Request, name string) bool {
+	if s.hookTokens == nil {
+		return false
+	}
+	secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	tokenName, ok := s.hookTokens.Verify(strings.TrimSpace(secret))
+	return ok && tokenName == name
+}
+
+// readPayload decodes the request body as JSON, keeping numbers as
+// written. Form posts, which GitHub sends unless told otherwise, carry the
+// JSON in their payload field.
+func readPayload(w http.ResponseWriter, r *http.Request) (any, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		return nil, invalidInput("invalid request body: %v", err)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, invalidInput("invalid form body: %v", err)
+		}
+		body = []byte(form.Get("payload"))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var payload any
+	if err := dec.Decode(&payload); err != nil {
+		return nil, invalidInput("invalid JSON payload: %v", err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,81 @@
+// ABOUTME: Tests for the inbound webhook endpoint
+// ABOUTME: Checks per-hook tokens, payload decoding, and skipped payloads
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/chronicle/internal/config"
+)
+
+func TestHandleHook(t *testing.T) {
+	dir := t.TempDir()
+	apiTokens := NewTokenStore(filepath.Join(dir, "api_tokens.json"))
+	apiSecret, err := apiTokens.Create("ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hookTokens := NewTokenStore(filepath.Join(dir, "hook_tokens.json"))
+	ciSecret, err := hookTokens.Create("ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alertSecret, err := hookTokens.Create("alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks, err := config.CompileInboundHooks(map[string]config.InboundHook{
+		"ci":     {Message: "Build {{.status}}", When: `{{eq .status "failed"}}`},
+		"alerts": {Message: "$.title"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Payloads that pass every check are skipped, so the client isn't needed
+	s := &Server{tokens: apiTokens, hookTokens: hookTokens, hooks: hooks}
+	handler := s.Handler()
+
+	passed := `{"status": "passed"}`
+	tests := []struct {
+		name        string
+		target      string
+		auth        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"unknown hook", "/hooks/deploys", "Bearer " + ciSecret, "", passed, http.StatusUnauthorized},
+		{"no token", "/hooks/ci", "", "", passed, http.StatusUnauthorized},
+		{"API token", "/hooks/ci", "Bearer " + apiSecret, "", passed, http.StatusUnauthorized},
+		{"another hook's token", "/hooks/ci", "Bearer " + alertSecret, "", passed, http.StatusUnauthorized},
+		{"bearer token", "/hooks/ci", "Bearer " + ciSecret, "application/json", passed, http.StatusOK},
+		{"query token", "/hooks/ci?token=" + ciSecret, "", "", passed, http.StatusOK},
+		{"form payload", "/hooks/ci", "Bearer " + ciSecret, "application/x-www-form-urlencoded", "payload=" + url.QueryEscape(passed), http.StatusOK},
+		{"invalid JSON", "/hooks/ci", "Bearer " + ciSecret, "", "{", http.StatusBadRequest},
+		{"empty message", "/hooks/alerts", "Bearer " + alertSecret, "", `{"severity": "page"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusOK && !strings.Contains(rec.Body.String(), `"skipped":true`) {
+				t.Errorf("body = %s, want a skipped response", rec.Body)
+			}
+		})
+	}
+}
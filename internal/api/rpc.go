@@ -0,0 +1,345 @@
+// ABOUTME: JSON-RPC 2.0 over a Unix socket for local integrations such as editor plugins
+// ABOUTME: Offers add, list, and search with one request or batch per line and no tokens
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	// rpcServerError is any failure while carrying out a valid request
+	rpcServerError = -32000
+)
+
+// rpcRequest is a JSON-RPC 2.0 request. A request without an ID is a
+// notification and gets no response.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response, holding a result or an error.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error member of a response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcQueryParams are the params of list and search, the same filters as
+// GET /api/entries.
+type rpcQueryParams struct {
+	Text   string   `json:"text,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Type   string   `json:"type,omitempty"`
+	Source string   `json:"source,omitempty"`
+	Since  string   `json:"since,omitempty"`
+	Until  string   `json:"until,omitempty"`
+	Limit  int      `json:"limit,omitempty"`
+}
+
+// ServeSocket serves JSON-RPC on a Unix socket at path, readable only by
+// this user, until ctx is cancelled. A socket left by a server that's no
+// longer running is replaced.
+func (s *Server) ServeSocket(ctx context.Context, path string) error {
+	if err := removeStaleSocket(path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	ln, err := listenPrivate(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(path) }()
+
+	var mu sync.Mutex
+	conns := make(map[net.Conn]bool)
+	var wg sync.WaitGroup
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+		mu.Lock()
+		for conn := range conns {
+			_ = conn.Close()
+		}
+		mu.Unlock()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				wg.Wait()
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		conns[conn] = true
+		mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveRPC(conn)
+			mu.Lock()
+			delete(conns, conn)
+			mu.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+// listenPrivate listens on a Unix socket at path that only this user can
+// connect to. The socket is made inside a private directory and linked
+// into place once its mode is 0600, so nobody else can connect while the
+// umask's mode still applies. Linking fails if path already exists.
+func listenPrivate(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	tmp := filepath.Join(dir, "s")
+	ln, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// The socket's name changes, so closing the listener mustn't remove it
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmp, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	if err := os.Link(tmp, path); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// removeStaleSocket removes a socket at path that nothing is listening
+// on. It refuses to touch anything else.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("another server is already listening on %s", path)
+	}
+	return os.Remove(path)
+}
+
+// serveRPC answers requests on conn, one JSON request or batch per line,
+// until the client hangs up.
+func (s *Server) serveRPC(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBodyBytes)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if reply := s.handleRPCLine(line); reply != nil {
+			if err := enc.Encode(reply); err != nil {
+				return
+			}
+		}
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		_ = enc.Encode(rpcFailure(nil, rpcInvalidRequest, "request larger than "+strconv.Itoa(maxBodyBytes)+" bytes"))
+	}
+}
+
+// handleRPCLine answers one line, a request or a batch of them. It returns
+// nil when nothing should be sent back: a notification, or a batch of them.
+func (s *Server) handleRPCLine(line []byte) any {
+	if line[0] != '[' {
+		var req rpcRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			return rpcFailure(nil, rpcParseError, "parse error: "+err.Error())
+		}
+		if reply := s.handleRPC(req); reply != nil {
+			return reply
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(line, &batch); err != nil {
+		return rpcFailure(nil, rpcParseError, "parse error: "+err.Error())
+	}
+	if len(batch) == 0 {
+		return rpcFailure(nil, rpcInvalidRequest, "empty batch")
+	}
+	var replies []*rpcResponse
+	for _, raw := range batch {
+		var req rpcRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			replies = append(replies, rpcFailure(nil, rpcInvalidRequest, "invalid request: "+err.Error()))
+			continue
+		}
+		if reply := s.handleRPC(req); reply != nil {
+			replies = append(replies, reply)
+		}
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	return replies
+}
+
+// handleRPC carries out one request. It returns nil for notifications,
+// which get no response even when they fail.
+func (s *Server) handleRPC(req rpcRequest) *rpcResponse {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return rpcFailure(req.ID, rpcInvalidRequest, `invalid request: need "jsonrpc": "2.0" and a method`)
+	}
+	result, err := s.callRPC(req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return rpcFailure(req.ID, rpcErrorCode(err), err.Error())
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// rpcMethodError is a call to a method that doesn't exist.
+type rpcMethodError struct {
+	method string
+}
+
+func (e *rpcMethodError) Error() string {
+	return fmt.Sprintf("method %q not found (use add, list, or search)", e.method)
+}
+
+// callRPC runs method with params.
+func (s *Server) callRPC(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "add":
+		var req createEntryRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		entry, err := s.newEntry(req)
+		if err != nil {
+			return nil, err
+		}
+		id, err := s.client.CreateEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"id": id}, nil
+	case "list", "search":
+		var p rpcQueryParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		p.Text = strings.TrimSpace(p.Text)
+		if method == "search" && p.Text == "" {
+			return nil, invalidInput("text is required")
+		}
+		return s.queryRPC(p)
+	}
+	return nil, &rpcMethodError{method: method}
+}
+
+// queryRPC returns the newest entries matching p, validated the same way
+// as the REST API's query parameters.
+func (s *Server) queryRPC(p rpcQueryParams) (entriesResponse, error) {
+	q := url.Values{"tag": p.Tags}
+	for name, value := range map[string]string{"type": p.Type, "source": p.Source, "since": p.Since, "until": p.Until} {
+		if value != "" {
+			q.Set(name, value)
+		}
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	filter, err := entryFilter(q)
+	if err != nil {
+		return entriesResponse{}, err
+	}
+	filter.Text = p.Text
+	limit, err := limitParam(q)
+	if err != nil {
+		return entriesResponse{}, err
+	}
+	entries, err := s.client.SearchEntries(filter, limit)
+	if err != nil {
+		return entriesResponse{}, err
+	}
+	return newEntriesResponse(entries), nil
+}
+
+// decodeParams decodes params into v, rejecting unknown fields. Missing
+// params leave v empty.
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return invalidInput("invalid params: %v", err)
+	}
+	return nil
+}
+
+// rpcErrorCode picks the JSON-RPC error code for err.
+func rpcErrorCode(err error) int {
+	var method *rpcMethodError
+	var bad *badRequest
+	switch {
+	case errors.As(err, &method):
+		return rpcMethodNotFound
+	case errors.As(err, &bad):
+		return rpcInvalidParams
+	}
+	return rpcServerError
+}
+
+// rpcFailure is an error response to the request with id.
+func rpcFailure(id json.RawMessage, code int, msg string) *rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg}}
+}
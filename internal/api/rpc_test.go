@@ -0,0 +1,134 @@
+// ABOUTME: Tests for the JSON-RPC socket interface
+// ABOUTME: Checks request framing, error codes, batches, and the socket's lifecycle
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHandleRPCLine(t *testing.T) {
+	s := &Server{}
+	tests := []struct {
+		name string
+		line string
+		code int
+	}{
+		{"parse error", `{"jsonrpc": "2.0",`, rpcParseError},
+		{"missing version", `{"id": 1, "method": "list"}`, rpcInvalidRequest},
+		{"unknown method", `{"jsonrpc": "2.0", "id": 1, "method": "delete"}`, rpcMethodNotFound},
+		{"unknown param", `{"jsonrpc": "2.0", "id": 1, "method": "list", "params": {"tag": "x"}}`, rpcInvalidParams},
+		{"search without text", `{"jsonrpc": "2.0", "id": 1, "method": "search", "params": {"text": "  "}}`, rpcInvalidParams},
+		{"bad type", `{"jsonrpc": "2.0", "id": 1, "method": "list", "params": {"type": "idea"}}`, rpcInvalidParams},
+		{"bad limit", `{"jsonrpc": "2.0", "id": 1, "method": "list", "params": {"limit": -1}}`, rpcInvalidParams},
+		{"empty message", `{"jsonrpc": "2.0", "id": "a", "method": "add", "params": {"message": ""}}`, rpcInvalidParams},
+		{"empty batch", `[]`, rpcInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply, ok := s.handleRPCLine([]byte(tt.line)).(*rpcResponse)
+			if !ok || reply.Error == nil {
+				t.Fatalf("reply = %+v, want error %d", reply, tt.code)
+			}
+			if reply.Error.Code != tt.code {
+				t.Errorf("code = %d (%s), want %d", reply.Error.Code, reply.Error.Message, tt.code)
+			}
+		})
+	}
+}
+
+func TestHandleRPCLineNotificationsAndBatches(t *testing.T) {
+	s := &Server{}
+	if reply := s.handleRPCLine([]byte(`{"jsonrpc": "2.0", "method": "delete"}`)); reply != nil {
+		t.Errorf("notification got reply %+v", reply)
+	}
+	if reply := s.handleRPCLine([]byte(`[{"jsonrpc": "2.0", "method": "delete"}]`)); reply != nil {
+		t.Errorf("batch of notifications got reply %+v", reply)
+	}
+
+	batch := `[{"jsonrpc": "2.0", "id": 1, "method": "delete"}, {"jsonrpc": "2.0", "method": "delete"}, 42]`
+	replies, ok := s.handleRPCLine([]byte(batch)).([]*rpcResponse)
+	if !ok || len(replies) != 2 {
+		t.Fatalf("replies = %+v, want two", replies)
+	}
+	if string(replies[0].ID) != "1" || replies[0].Error.Code != rpcMethodNotFound {
+		t.Errorf("first reply = %+v", replies[0])
+	}
+	if string(replies[1].ID) != "null" || replies[1].Error.Code != rpcInvalidRequest {
+		t.Errorf("second reply = %+v", replies[1])
+	}
+}
+
+func TestServeSocket(t *testing.T) {
+	// Unix socket paths are limited to about 100 bytes, more than some
+	// temp dirs leave
+	dir, err := os.MkdirTemp("", "chr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "c.sock")
+
+	// A socket left behind by a crashed server is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	s := &Server{}
+	go func() { done <- s.ServeSocket(ctx, path) }()
+
+	var conn net.Conn
+	for i := 0; i < 100; i++ {
+		if conn, err = net.Dial("unix", path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("socket mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+	if names, _ := os.ReadDir(dir); len(names) != 1 {
+		t.Errorf("socket dir holds %v, want only the socket", names)
+	}
+	if err := s.ServeSocket(ctx, path); err == nil {
+		t.Error("a second server on the same socket should fail")
+	}
+
+	if _, err := conn.Write([]byte("{\"jsonrpc\": \"2.0\", \"id\": 7, \"method\": \"nope\"}\n\n")); err != nil {
+		t.Fatal(err)
+	}
+	var reply rpcResponse
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&reply); err != nil {
+		t.Fatalf("read reply: %v", err)
+	}
+	if string(reply.ID) != "7" || reply.Error == nil || reply.Error.Code != rpcMethodNotFound {
+		t.Errorf("reply = %+v", reply)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ServeSocket: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server didn't stop")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("socket not removed on shutdown")
+	}
+}
@@ -0,0 +1,210 @@
+// ABOUTME: REST API server exposing chronicle entries over HTTP as JSON
+// ABOUTME: Routes requests, checks bearer tokens, and maps errors to status codes
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/harper/chronicle/internal/metrics"
+)
+
+// maxBodyBytes caps request bodies.
+const maxBodyBytes = 1 << 20
+
+// Options configures a chronicle REST server.
+type Options struct {
+	// Tokens authorizes HTTP requests; every API call needs one of its
+	// tokens. A server without one can only serve its socket.
+	Tokens *TokenStore
+	// HookTokens authorizes posts to /hooks/<name>; each token is named
+	// after the one hook it may post to
+	HookTokens *TokenStore
+}
+
+// Server serves the chronicle REST API.
+type Server struct {
+	client *charm.Client
+	tokens *TokenStore
+	// hookTokens authorizes inbound hooks, one token per hook
+	hookTokens *TokenStore
+	// hooks map inbound webhook payloads to entries by hook name
+	hooks map[string]*config.CompiledInboundHook
+	// defaultTags are the global config's tags for every entry
+	defaultTags []string
+	// redactor scrubs or refuses secrets in messages; nil stores them as is
+	redactor *config.Redactor
+	// metrics serves /metrics for Prometheus
+	metrics *metrics.Collector
+}
+
+// NewServer creates a REST server for the current profile's journal.
+func NewServer(opts Options) (*Server, error) {
+	client, err := charm.GetClient()
+	if err != nil {
+		return nil, err
+	}
+	server := &Server{client: client, tokens: opts.Tokens, hookTokens: opts.HookTokens}
+	// Without a database path the size is reported as 0
+	dbPath, _ := charm.DBPath()
+	server.metrics = &metrics.Collector{Client: client, DBPath: dbPath}
+	if cfg := client.Config(); cfg != nil {
+		server.defaultTags = cfg.DefaultTags
+		server.redactor, err = config.NewRedactor(cfg.RedactSecrets, cfg.RedactPatterns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret redaction settings in %s: %w", charm.ConfigPath(), err)
+		}
+		server.hooks, err = config.CompileInboundHooks(cfg.InboundHooks)
+		if err != nil {
+			return nil, fmt.Errorf("invalid inbound_hooks in %s: %w", charm.ConfigPath(), err)
+		}
+	}
+	return server, nil
+}
+
+// Handler returns the API's routes, Atom feed, and Prometheus metrics
+// behind token authentication, inbound hooks behind their own tokens, and
+// the web UI, which asks for a token and calls the API itself.
+func (s *Server) Handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("GET /api/entries", s.handleListEntries)
+	api.HandleFunc("POST /api/entries", s.handleCreateEntry)
+	api.HandleFunc("GET /api/entries/{id}", s.handleGetEntry)
+	api.HandleFunc("PATCH /api/entries/{id}", s.handleUpdateEntry)
+	api.HandleFunc("DELETE /api/entries/{id}", s.handleDeleteEntry)
+	api.HandleFunc("GET /api/search", s.handleSearch)
+	api.HandleFunc("GET /api/tags", s.handleTags)
+	api.HandleFunc("GET /api/stats", s.handleStats)
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", cors(s.authenticate(api)))
+	mux.Handle("GET /feed", queryToken(s.authenticate(http.HandlerFunc(s.handleFeed))))
+	mux.Handle("GET /metrics", s.authenticate(s.metrics))
+	mux.Handle("POST /hooks/{name}", queryToken(http.HandlerFunc(s.handleHook)))
+	mux.Handle("/", webHandler())
+	return mux
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled, then
+// finishes in-flight requests before returning.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	if s.tokens == nil {
+		return fmt.Errorf("a token store is required to serve HTTP")
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// authenticate rejects requests without a valid "Authorization: Bearer"
+// token.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+		if _, ok := s.tokens.Verify(strings.TrimSpace(secret)); !ok {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// queryToken lets clients that can't set headers, such as feed readers,
+// pass their token as ?token= instead.
+func queryToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// cors lets browser extensions and pages call the API. Requests carry a
+// bearer token rather than cookies, so any origin may send them.
+func cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// badRequest is a problem with the request itself.
+type badRequest struct {
+	msg string
+}
+
+func (e *badRequest) Error() string {
+	return e.msg
+}
+
+// invalidInput reports a problem with the request parameters or body.
+func invalidInput(format string, args ...any) error {
+	return &badRequest{msg: fmt.Sprintf(format, args...)}
+}
+
+// statusFor picks the HTTP status for err.
+func statusFor(err error) int {
+	var bad *badRequest
+	var secretErr *config.SecretError
+	switch {
+	case errors.As(err, &bad):
+		return http.StatusBadRequest
+	case errors.As(err, &secretErr):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, charm.ErrReadOnly):
+		return http.StatusForbidden
+	case charm.IsNotFound(err):
+		return http.StatusNotFound
+	case charm.IsLocked(err):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// writeFailure writes err as a JSON error with a matching status.
+func writeFailure(w http.ResponseWriter, err error) {
+	writeError(w, statusFor(err), err.Error())
+}
+
+// writeError writes {"error": msg} with status.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// writeJSON writes v as the JSON response body with status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
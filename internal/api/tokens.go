@@ -0,0 +1,145 @@
+// ABOUTME: API tokens for the chronicle REST server
+// ABOUTME: Stores only SHA-256 hashes of tokens in a private file next to charm.json
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+// tokenPrefix starts every token, so leaked ones are easy to recognize.
+const tokenPrefix = "chron_"
+
+// Token is a named API token. The secret itself is shown once, when it's
+// created, and never stored.
+type Token struct {
+	Name    string    `json:"name"`
+	Hash    string    `json:"hash"`
+	Created time.Time `json:"created"`
+}
+
+// TokenStore keeps API tokens in a JSON file. It is read on every lookup,
+// so tokens revoked while the server runs stop working at once.
+type TokenStore struct {
+	path string
+}
+
+// NewTokenStore returns a store backed by the file at path.
+func NewTokenStore(path string) *TokenStore {
+	return &TokenStore{path: path}
+}
+
+// TokensPath is where the REST server's tokens are kept.
+func TokensPath() string {
+	return filepath.Join(charm.ConfigDir(), "api_tokens.json")
+}
+
+// HookTokensPath is where inbound hooks' tokens are kept, apart from API
+// tokens so a hook's token can't read the journal.
+func HookTokensPath() string {
+	return filepath.Join(charm.ConfigDir(), "hook_tokens.json")
+}
+
+// List returns the stored tokens sorted by name. A missing file has none.
+func (s *TokenStore) List() ([]Token, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tokens []Token
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Name < tokens[j].Name })
+	return tokens, nil
+}
+
+// Create adds a token called name and returns its secret.
+func (s *TokenStore) Create(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("token name cannot be empty")
+	}
+	tokens, err := s.List()
+	if err != nil {
+		return "", err
+	}
+	for _, t := range tokens {
+		if t.Name == name {
+			return "", fmt.Errorf("a token named %q already exists", name)
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	secret := tokenPrefix + hex.EncodeToString(raw)
+	tokens = append(tokens, Token{Name: name, Hash: hashToken(secret), Created: time.Now().UTC()})
+	return secret, s.save(tokens)
+}
+
+// Revoke removes the token called name.
+func (s *TokenStore) Revoke(name string) error {
+	tokens, err := s.List()
+	if err != nil {
+		return err
+	}
+	for i, t := range tokens {
+		if t.Name == name {
+			return s.save(append(tokens[:i], tokens[i+1:]...))
+		}
+	}
+	return fmt.Errorf("no token named %q", name)
+}
+
+// Verify returns the name of the token whose secret is secret.
+func (s *TokenStore) Verify(secret string) (string, bool) {
+	tokens, err := s.List()
+	if err != nil || secret == "" {
+		return "", false
+	}
+	hash := []byte(hashToken(secret))
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(t.Hash)) == 1 {
+			return t.Name, true
+		}
+	}
+	return "", false
+}
+
+// save writes tokens to the store's file, readable only by its owner.
+func (s *TokenStore) save(tokens []Token) error {
+	if tokens == nil {
+		tokens = []Token{}
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// hashToken returns the hex SHA-256 of a token secret.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,62 @@
+// ABOUTME: Tests for API token storage
+// ABOUTME: Validates creating, verifying, listing, and revoking tokens
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_tokens.json")
+	store := NewTokenStore(path)
+
+	if tokens, err := store.List(); err != nil || len(tokens) != 0 {
+		t.Fatalf("new store = %v, %v; want no tokens", tokens, err)
+	}
+	secret, err := store.Create("firefox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		t.Errorf("secret %q should start with %q", secret, tokenPrefix)
+	}
+	if _, err := store.Create("firefox"); err == nil {
+		t.Error("expected an error for a duplicate name")
+	}
+	if _, err := store.Create(" "); err == nil {
+		t.Error("expected an error for an empty name")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Error("token file holds the secret in plain text")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+
+	if name, ok := store.Verify(secret); !ok || name != "firefox" {
+		t.Errorf("Verify(secret) = %q, %v; want firefox", name, ok)
+	}
+	for _, bad := range []string{"", "chron_nope", secret + "x"} {
+		if _, ok := store.Verify(bad); ok {
+			t.Errorf("Verify(%q) succeeded", bad)
+		}
+	}
+
+	if err := store.Revoke("firefox"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Verify(secret); ok {
+		t.Error("revoked token still verifies")
+	}
+	if err := store.Revoke("firefox"); err == nil {
+		t.Error("expected an error revoking a missing token")
+	}
+}
@@ -0,0 +1,260 @@
+// chronicle web UI: a timeline over the REST API.
+"use strict";
+
+const PAGE = 50;
+const TOKEN_KEY = "chronicle-token";
+
+const state = {
+  token: localStorage.getItem(TOKEN_KEY) || "",
+  query: "",
+  tag: "",
+  limit: PAGE,
+};
+
+const $ = (id) => document.getElementById(id);
+
+// api calls the REST API and returns the parsed body, throwing the
+// server's error message on failure.
+async function api(method, path, body) {
+  const opts = { method, headers: { Authorization: "Bearer " + state.token } };
+  if (body !== undefined) {
+    opts.headers["Content-Type"] = "application/json";
+    opts.body = JSON.stringify(body);
+  }
+  const res = await fetch(path, opts);
+  if (res.status === 401) {
+    signOut("That token was rejected.");
+    throw new Error("unauthorized");
+  }
+  if (res.status === 204) {
+    return null;
+  }
+  const data = await res.json();
+  if (!res.ok) {
+    throw new Error(data.error || res.statusText);
+  }
+  return data;
+}
+
+function showError(err) {
+  $("error").textContent = err && err.message !== "unauthorized" ? err.message : "";
+}
+
+function parseTags(text) {
+  return text.split(",").map((t) => t.trim()).filter((t) => t !== "");
+}
+
+function dayLabel(date) {
+  return date.toLocaleDateString(undefined, { weekday: "long", year: "numeric", month: "long", day: "numeric" });
+}
+
+// loadEntries renders the timeline for the current search, tag, and limit.
+async function loadEntries() {
+  const params = new URLSearchParams({ limit: state.limit });
+  if (state.tag) {
+    params.append("tag", state.tag);
+  }
+  let path = "/api/entries?";
+  if (state.query) {
+    params.set("q", state.query);
+    path = "/api/search?";
+  }
+
+  const filters = [];
+  if (state.query) filters.push(`matching "${state.query}"`);
+  if (state.tag) filters.push(`tagged ${state.tag}`);
+  $("filter").hidden = filters.length === 0;
+  $("filter-label").textContent = "Entries " + filters.join(", ");
+
+  try {
+    const data = await api("GET", path + params);
+    renderTimeline(data.entries);
+    $("more").hidden = data.count < state.limit;
+    showError(null);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+function renderTimeline(entries) {
+  const timeline = $("timeline");
+  timeline.replaceChildren();
+  if (entries.length === 0) {
+    const empty = document.createElement("p");
+    empty.className = "empty";
+    empty.textContent = "No entries.";
+    timeline.append(empty);
+    return;
+  }
+  let day = "";
+  for (const entry of entries) {
+    const when = new Date(entry.timestamp);
+    const label = dayLabel(when);
+    if (label !== day) {
+      day = label;
+      const heading = document.createElement("h2");
+      heading.textContent = label;
+      timeline.append(heading);
+    }
+    timeline.append(renderEntry(entry, when));
+  }
+}
+
+function renderEntry(entry, when) {
+  const node = $("entry-template").content.firstElementChild.cloneNode(true);
+  node.querySelector("time").textContent = when.toLocaleTimeString(undefined, { hour: "2-digit", minute: "2-digit" });
+  node.querySelector("time").dateTime = entry.timestamp;
+  node.querySelector(".type").textContent = entry.type || "note";
+  node.querySelector(".type").dataset.type = entry.type || "note";
+  const tags = node.querySelector(".tags");
+  for (const tag of entry.tags || []) {
+    const link = document.createElement("a");
+    link.href = "#";
+    link.textContent = "#" + tag;
+    link.addEventListener("click", (e) => {
+      e.preventDefault();
+      filterByTag(tag);
+    });
+    tags.append(link);
+  }
+  node.querySelector(".message").textContent = entry.message;
+  node.querySelector(".edit").addEventListener("click", () => editEntry(node, entry));
+  node.querySelector(".delete").addEventListener("click", () => deleteEntry(entry));
+  return node;
+}
+
+function editEntry(node, entry) {
+  const form = $("edit-template").content.firstElementChild.cloneNode(true);
+  form.querySelector(".edit-message").value = entry.message;
+  form.querySelector(".edit-tags").value = (entry.tags || []).join(", ");
+  form.querySelector(".edit-type").value = entry.type || "";
+  form.querySelector(".cancel").addEventListener("click", () => form.replaceWith(node));
+  form.addEventListener("submit", async (e) => {
+    e.preventDefault();
+    try {
+      await api("PATCH", "/api/entries/" + encodeURIComponent(entry.id), {
+        message: form.querySelector(".edit-message").value,
+        tags: parseTags(form.querySelector(".edit-tags").value),
+        type: form.querySelector(".edit-type").value,
+      });
+      await Promise.all([loadEntries(), loadTags()]);
+    } catch (err) {
+      showError(err);
+    }
+  });
+  node.replaceWith(form);
+  form.querySelector(".edit-message").focus();
+}
+
+async function deleteEntry(entry) {
+  if (!confirm(`Delete "${entry.message}"?`)) {
+    return;
+  }
+  try {
+    await api("DELETE", "/api/entries/" + encodeURIComponent(entry.id));
+    await Promise.all([loadEntries(), loadTags()]);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+async function loadTags() {
+  try {
+    const data = await api("GET", "/api/tags");
+    const list = $("tags");
+    list.replaceChildren();
+    for (const { value, count } of data.tags) {
+      const item = document.createElement("li");
+      const link = document.createElement("a");
+      link.href = "#";
+      link.textContent = value;
+      link.className = value === state.tag ? "active" : "";
+      link.addEventListener("click", (e) => {
+        e.preventDefault();
+        filterByTag(value === state.tag ? "" : value);
+      });
+      const badge = document.createElement("span");
+      badge.className = "count";
+      badge.textContent = count;
+      item.append(link, badge);
+      list.append(item);
+    }
+  } catch (err) {
+    showError(err);
+  }
+}
+
+function filterByTag(tag) {
+  state.tag = tag;
+  state.limit = PAGE;
+  loadEntries();
+  loadTags();
+}
+
+function signOut(message) {
+  state.token = "";
+  localStorage.removeItem(TOKEN_KEY);
+  $("app").hidden = true;
+  $("signout").hidden = true;
+  $("login").hidden = false;
+  $("login-error").textContent = message || "";
+}
+
+function start() {
+  $("login").hidden = true;
+  $("app").hidden = false;
+  $("signout").hidden = false;
+  loadEntries();
+  loadTags();
+}
+
+$("token-form").addEventListener("submit", (e) => {
+  e.preventDefault();
+  state.token = $("token").value.trim();
+  localStorage.setItem(TOKEN_KEY, state.token);
+  $("token").value = "";
+  start();
+});
+
+$("signout").addEventListener("click", () => signOut());
+
+$("search").addEventListener("submit", (e) => {
+  e.preventDefault();
+  state.query = $("query").value.trim();
+  state.limit = PAGE;
+  loadEntries();
+});
+
+$("clear-filter").addEventListener("click", () => {
+  state.query = "";
+  $("query").value = "";
+  filterByTag("");
+});
+
+$("more").addEventListener("click", () => {
+  state.limit += PAGE;
+  loadEntries();
+});
+
+$("add").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const body = { message: $("add-message").value, tags: parseTags($("add-tags").value) };
+  if ($("add-type").value) {
+    body.type = $("add-type").value;
+  }
+  try {
+    await api("POST", "/api/entries", body);
+    $("add-message").value = "";
+    $("add-tags").value = "";
+    $("add-type").value = "";
+    await Promise.all([loadEntries(), loadTags()]);
+  } catch (err) {
+    showError(err);
+  }
+});
+
+if (state.token) {
+  start();
+} else {
+  signOut();
+}
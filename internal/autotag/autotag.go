@@ -0,0 +1,239 @@
+// ABOUTME: Asks an OpenAI-compatible model for an entry's tags and type as it's added
+// ABOUTME: Caches answers on disk and gives up after a hard timeout so adds never wait long
+package autotag
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+// maxCacheEntries bounds the cache file; the oldest answers go first.
+const maxCacheEntries = 500
+
+// maxTagLength drops tags too long to be a tag rather than a sentence.
+const maxTagLength = 32
+
+// systemPrompt tells the model what to answer and in what shape.
+const systemPrompt = `You tag entries in a developer's personal work log.
+Reply with only a JSON object: {"tags": [...], "type": "..."}.
+tags: up to %d short, lowercase, hyphenated topics for the entry, such as "deployment" or "code-review"; no generic tags like "work" or "log".
+type: "decision" for a choice made, "todo" for something still to do, "milestone" for a notable achievement, otherwise "note".`
+
+// Suggestion is the model's proposal for an entry.
+type Suggestion struct {
+	Tags []string `json:"tags,omitempty"`
+	Type string   `json:"type,omitempty"`
+}
+
+// Tagger asks a model for suggestions, remembering past answers in a
+// cache file so the same message is never sent twice.
+type Tagger struct {
+	cfg       *config.AutoTag
+	cachePath string
+	http      *http.Client
+
+	mu sync.Mutex
+}
+
+// cacheEntry is one remembered answer.
+type cacheEntry struct {
+	Suggestion
+	At time.Time `json:"at"`
+}
+
+// New returns a Tagger for cfg that caches answers in cachePath.
+func New(cfg *config.AutoTag, cachePath string) *Tagger {
+	return &Tagger{cfg: cfg, cachePath: cachePath, http: &http.Client{}}
+}
+
+// Suggest returns the model's tags and type for message, from the cache
+// if it was asked before. It gives up once the configured timeout or ctx
+// ends, whichever is sooner.
+func (t *Tagger) Suggest(ctx context.Context, message string) (Suggestion, error) {
+	key := t.cacheKey(message)
+	t.mu.Lock()
+	cached, ok := t.loadCache()[key]
+	t.mu.Unlock()
+	if ok {
+		return cached.Suggestion, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.cfg.Timeout())
+	defer cancel()
+	suggestion, err := t.ask(ctx, message)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return Suggestion{}, fmt.Errorf("no answer from %s within %s", t.cfg.Model, t.cfg.Timeout())
+		}
+		return Suggestion{}, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cache := t.loadCache()
+	cache[key] = cacheEntry{Suggestion: suggestion, At: time.Now()}
+	// A cache that can't be written only costs a repeat request later
+	_ = t.saveCache(cache)
+	return suggestion, nil
+}
+
+// cacheKey identifies message as asked of this endpoint and model.
+func (t *Tagger) cacheKey(message string) string {
+	sum := sha256.Sum256([]byte(t.cfg.URL + "\x00" + t.cfg.Model + "\x00" + message))
+	return hex.EncodeToString(sum[:])
+}
+
+// ask sends message to the chat completions endpoint.
+func (t *Tagger) ask(ctx context.Context, message string) (Suggestion, error) {
+	body, err := json.Marshal(map[string]any{
+		"model": t.cfg.Model,
+		"messages": []map[string]string{
+			{"role": "system", "content": fmt.Sprintf(systemPrompt, t.cfg.TagLimit())},
+			{"role": "user", "content": message},
+		},
+		"temperature": 0,
+	})
+	if err != nil {
+		return Suggestion{}, err
+	}
+	endpoint := strings.TrimRight(t.cfg.URL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Suggestion{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key := t.cfg.Secret(); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Suggestion{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Suggestion{}, fmt.Errorf("%s: %s", endpoint, resp.Status)
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &completion); err != nil || len(completion.Choices) == 0 {
+		return Suggestion{}, fmt.Errorf("%s: not a chat completion response", endpoint)
+	}
+	return parseSuggestion(completion.Choices[0].Message.Content, t.cfg.TagLimit())
+}
+
+// parseSuggestion reads the model's reply, tolerating prose or a code
+// fence around the JSON object, and cleans up what it proposed: tags are
+// lowercased and hyphenated, and an unknown type is dropped.
+func parseSuggestion(content string, limit int) (Suggestion, error) {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return Suggestion{}, fmt.Errorf("model reply has no JSON object: %q", content)
+	}
+	var raw struct {
+		Tags []string `json:"tags"`
+		Type string   `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &raw); err != nil {
+		return Suggestion{}, fmt.Errorf("model reply isn't valid JSON: %w", err)
+	}
+
+	var s Suggestion
+	for _, tag := range raw.Tags {
+		tag = strings.Join(strings.Fields(strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "#"))), "-")
+		if tag == "" || len(tag) > maxTagLength {
+			continue
+		}
+		if len(s.Tags) == limit {
+			break
+		}
+		s.Tags = charm.MergeTags(s.Tags, []string{tag})
+	}
+	if kind := strings.ToLower(strings.TrimSpace(raw.Type)); charm.ValidEntryType(kind) {
+		s.Type = kind
+	}
+	return s, nil
+}
+
+// Apply merges the rule matches and the model's suggestion into an
+// entry's tags and type. Tags the caller chose come first; the model's
+// type is used only when the caller chose none and it isn't a plain note.
+func Apply(tags []string, kind string, ruleTags []string, s Suggestion) ([]string, string) {
+	tags = charm.MergeTags(charm.MergeTags(tags, ruleTags), s.Tags)
+	if kind == "" && s.Type != charm.EntryTypeNote {
+		kind = s.Type
+	}
+	return tags, kind
+}
+
+// loadCache reads the cache file, treating a missing or damaged one as
+// empty. The caller holds t.mu.
+func (t *Tagger) loadCache() map[string]cacheEntry {
+	cache := make(map[string]cacheEntry)
+	data, err := os.ReadFile(t.cachePath)
+	if err == nil {
+		_ = json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// saveCache writes cache through a temp file, keeping only the newest
+// maxCacheEntries answers. The caller holds t.mu.
+func (t *Tagger) saveCache(cache map[string]cacheEntry) error {
+	if len(cache) > maxCacheEntries {
+		keys := make([]string, 0, len(cache))
+		for key := range cache {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return cache[keys[i]].At.After(cache[keys[j]].At) })
+		for _, key := range keys[maxCacheEntries:] {
+			delete(cache, key)
+		}
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.cachePath), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(t.cachePath), ".autotag-*.json")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), t.cachePath)
+}
@@ -0,0 +1,154 @@
+// ABOUTME: Tests for model-assisted auto-tagging
+// ABOUTME: Uses a fake chat completions server to check requests, parsing, caching, and timeouts
+package autotag
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/config"
+)
+
+// fakeModel serves chat completions that answer with reply, counting
+// requests and recording the last one.
+func fakeModel(t *testing.T, reply string, delay time.Duration) (*httptest.Server, *atomic.Int32, *http.Request) {
+	t.Helper()
+	var calls atomic.Int32
+	last := &http.Request{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		*last = *r.Clone(context.Background())
+		var body struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Model != "tiny" || len(body.Messages) != 2 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls, last
+}
+
+func TestSuggest(t *testing.T) {
+	srv, calls, last := fakeModel(t, "```json\n{\"tags\": [\"Deployment\", \"#api gateway\", \"deployment\", \"x\", \"extra\"], \"type\": \"Milestone\"}\n```", 0)
+	t.Setenv(config.AutoTagAPIKeyEnv, "sk-test")
+	cfg := &config.AutoTag{URL: srv.URL + "/v1/", Model: "tiny", MaxTags: 3}
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	tagger := New(cfg, cachePath)
+
+	got, err := tagger.Suggest(context.Background(), "shipped the API gateway to prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Suggestion{Tags: []string{"deployment", "api-gateway", "x"}, Type: "milestone"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest() = %+v, want %+v", got, want)
+	}
+	if last.URL.Path != "/v1/chat/completions" || last.Header.Get("Authorization") != "Bearer sk-test" {
+		t.Errorf("request to %s with auth %q", last.URL.Path, last.Header.Get("Authorization"))
+	}
+
+	// The same message is answered from the cache, even by a new tagger
+	got, err = New(cfg, cachePath).Suggest(context.Background(), "shipped the API gateway to prod")
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("cached Suggest() = %+v, %v", got, err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("model asked %d times, want 1", calls.Load())
+	}
+
+	// A different model doesn't share answers
+	other := *cfg
+	other.Model = "other"
+	if _, err := New(&other, cachePath).Suggest(context.Background(), "shipped the API gateway to prod"); err == nil {
+		t.Error("expected the fake server to reject another model")
+	}
+}
+
+func TestSuggestTimeout(t *testing.T) {
+	srv, _, _ := fakeModel(t, `{"tags": ["slow"]}`, 5*time.Second)
+	tagger := New(&config.AutoTag{URL: srv.URL, Model: "tiny", TimeoutMS: 50}, filepath.Join(t.TempDir(), "cache.json"))
+
+	start := time.Now()
+	_, err := tagger.Suggest(context.Background(), "anything")
+	if err == nil || !strings.Contains(err.Error(), "within 50ms") {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Suggest took %s despite the timeout", elapsed)
+	}
+}
+
+func TestParseSuggestion(t *testing.T) {
+	got, err := parseSuggestion(`Sure! {"tags": ["bug fix", "   ", "`+strings.Repeat("a", 40)+`"], "type": "idea"}`, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Suggestion{Tags: []string{"bug-fix"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSuggestion() = %+v, want %+v", got, want)
+	}
+	for _, reply := range []string{"no idea", `{"tags": "one"}`} {
+		if _, err := parseSuggestion(reply, 3); err == nil {
+			t.Errorf("parseSuggestion(%q) should fail", reply)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	s := Suggestion{Tags: []string{"deployment", "api"}, Type: "decision"}
+	tags, kind := Apply([]string{"work"}, "", []string{"deployment"}, s)
+	if want := []string{"work", "deployment", "api"}; !reflect.DeepEqual(tags, want) || kind != "decision" {
+		t.Errorf("Apply() = %v, %q", tags, kind)
+	}
+	// A type the caller chose wins, and a suggested note changes nothing
+	if _, kind := Apply(nil, "todo", nil, s); kind != "todo" {
+		t.Errorf("caller's type replaced by %q", kind)
+	}
+	if _, kind := Apply(nil, "", nil, Suggestion{Type: "note"}); kind != "" {
+		t.Errorf("suggested note set type %q", kind)
+	}
+}
+
+func TestCacheTrimmed(t *testing.T) {
+	tagger := New(&config.AutoTag{URL: "http://localhost", Model: "tiny"}, filepath.Join(t.TempDir(), "cache.json"))
+	cache := make(map[string]cacheEntry)
+	start := time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < maxCacheEntries+10; i++ {
+		cache[tagger.cacheKey(string(rune('a'+i%26))+strings.Repeat("x", i))] = cacheEntry{At: start.Add(time.Duration(i) * time.Minute)}
+	}
+	if err := tagger.saveCache(cache); err != nil {
+		t.Fatal(err)
+	}
+	loaded := tagger.loadCache()
+	if len(loaded) != maxCacheEntries {
+		t.Fatalf("cache holds %d answers, want %d", len(loaded), maxCacheEntries)
+	}
+	for _, entry := range loaded {
+		if entry.At.Before(start.Add(10 * time.Minute)) {
+			t.Errorf("kept an answer from %s, one of the oldest", entry.At)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// ABOUTME: Minimal CalDAV client that stores and removes calendar objects
+// ABOUTME: Uses plain HTTP PUT, DELETE, and REPORT with basic auth on one collection
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// requestTimeout bounds each request to the server.
+const requestTimeout = 30 * time.Second
+
+// Client reads and writes calendar objects in one CalDAV collection.
+type Client struct {
+	collection *url.URL
+	username   string
+	password   string
+	http       *http.Client
+}
+
+// NewClient returns a client for the collection at rawURL.
+func NewClient(rawURL, username, password string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid CalDAV URL %q", rawURL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &Client{collection: u, username: username, password: password, http: &http.Client{Timeout: requestTimeout}}, nil
+}
+
+// Put stores body as the calendar object name, replacing any earlier one.
+func (c *Client) Put(ctx context.Context, name string, body []byte) error {
+	resp, err := c.do(ctx, http.MethodPut, name, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("store %s: server returned %s", name, resp.Status)
+	}
+	return nil
+}
+
+// Delete removes the calendar object name. One that's already gone is not
+// an error.
+func (c *Client) Delete(ctx context.Context, name string) error {
+	resp, err := c.do(ctx, http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("remove %s: server returned %s", name, resp.Status)
+	}
+	return nil
+}
+
+// do sends one request for the object name in the collection.
+func (c *Client) do(ctx context.Context, method, name string, body []byte) (*http.Response, error) {
+	target := c.collection.JoinPath(name)
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	}
+	req.Header.Set("User-Agent", "chronicle")
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.http.Do(req)
+}
+
+// calendarQuery asks for the collection's events overlapping a time range,
+// with recurring ones expanded into their occurrences. The two %s are the
+// range's start and end.
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <C:calendar-data>
+      <C:expand start="%[1]s" end="%[2]s"/>
+    </C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="%[1]s" end="%[2]s"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>
+`
+
+// multistatus is the part of a REPORT response holding calendar data.
+type multistatus struct {
+	Responses []struct {
+		CalendarData []string `xml:"propstat>prop>calendar-data"`
+	} `xml:"response"`
+}
+
+// Events returns the calendar objects in the collection with events
+// between start and end, each as iCalendar text. The server expands
+// recurring events, so each occurrence is its own component.
+func (c *Client) Events(ctx context.Context, start, end time.Time) ([][]byte, error) {
+	query := fmt.Sprintf(calendarQuery, start.UTC().Format(icsTime), end.UTC().Format(icsTime))
+	req, err := http.NewRequestWithContext(ctx, "REPORT", c.collection.String(), strings.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+	req.Header.Set("User-Agent", "chronicle")
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("query %s: server returned %s", c.collection.Redacted(), resp.Status)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("decode calendar query: %w", err)
+	}
+	var objects [][]byte
+	for _, r := range ms.Responses {
+		for _, data := range r.CalendarData {
+			if strings.TrimSpace(data) != "" {
+				objects = append(objects, []byte(data))
+			}
+		}
+	}
+	return objects, nil
+}
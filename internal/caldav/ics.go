@@ -0,0 +1,123 @@
+// ABOUTME: iCalendar rendering of chronicle entries as VEVENT or VJOURNAL
+// ABOUTME: Escapes text values and folds long lines as RFC 5545 requires
+package caldav
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+// icsTime is the UTC date-time format of DTSTART and friends.
+const icsTime = "20060102T150405Z"
+
+// maxLine is the longest a content line may be, in octets, before folding.
+const maxLine = 75
+
+// Calendar renders entry as an iCalendar object holding one component of
+// kind (vevent or vjournal). Events start when the entry was logged and
+// last length, unless the entry records its own duration, as shell commands
+// do: then the event covers the command's run, ending when it was logged.
+// The output depends only on the entry, so unchanged entries render the
+// same bytes.
+func Calendar(entry charm.Entry, kind string, length time.Duration) []byte {
+	start, end := entry.Timestamp, entry.Timestamp.Add(length)
+	if d, err := time.ParseDuration(entry.Metadata["duration"]); err == nil && d >= time.Minute {
+		start, end = entry.Timestamp.Add(-d), entry.Timestamp
+	}
+	component := strings.ToUpper(kind)
+
+	var b strings.Builder
+	line := func(name, value string) {
+		writeLine(&b, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//chronicle//chronicle//EN")
+	line("BEGIN", component)
+	line("UID", entry.ID+"@chronicle")
+	line("DTSTAMP", entry.Timestamp.UTC().Format(icsTime))
+	line("DTSTART", start.UTC().Format(icsTime))
+	if kind == config.CalDAVEvent {
+		line("DTEND", end.UTC().Format(icsTime))
+		line("TRANSP", "TRANSPARENT")
+	}
+	line("SUMMARY", escapeText(summary(entry)))
+	line("DESCRIPTION", escapeText(description(entry)))
+	if len(entry.Tags) > 0 {
+		tags := make([]string, len(entry.Tags))
+		for i, tag := range entry.Tags {
+			tags[i] = escapeText(tag)
+		}
+		line("CATEGORIES", strings.Join(tags, ","))
+	}
+	line("END", component)
+	line("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// summary is the entry's first line, with its type in front of anything
+// but a note.
+func summary(entry charm.Entry) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(entry.Message), "\n")
+	if kind := entry.Kind(); kind != charm.EntryTypeNote {
+		return "[" + kind + "] " + first
+	}
+	return first
+}
+
+// description is the full message, then the entry's metadata and where it
+// was logged.
+func description(entry charm.Entry) string {
+	var b strings.Builder
+	b.WriteString(entry.Message)
+	var details []string
+	keys := make([]string, 0, len(entry.Metadata))
+	for key := range entry.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		details = append(details, fmt.Sprintf("%s: %s", key, entry.Metadata[key]))
+	}
+	if entry.Hostname != "" {
+		details = append(details, "host: "+entry.Hostname)
+	}
+	if entry.WorkingDirectory != "" {
+		details = append(details, "directory: "+entry.WorkingDirectory)
+	}
+	if len(details) > 0 {
+		b.WriteString("\n\n" + strings.Join(details, "\n"))
+	}
+	return b.String()
+}
+
+// escapeText escapes a TEXT value: backslashes, commas, semicolons, and
+// newlines.
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeLine writes a content line ending in CRLF, folded so no line is
+// longer than maxLine octets. Folds never split a UTF-8 character.
+func writeLine(b *strings.Builder, s string) {
+	limit := maxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts
+		limit = maxLine - 1
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Tests for iCalendar rendering
+// ABOUTME: Validates components, event times, escaping, and line folding
+package caldav
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+func TestCalendarEvent(t *testing.T) {
+	entry := charm.Entry{
+		ID:        "abc",
+		Timestamp: time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC),
+		Message:   "chose Postgres; SQLite was too small, sadly\nmore detail",
+		Type:      charm.EntryTypeDecision,
+		Tags:      []string{"db", "arch"},
+		Hostname:  "laptop",
+	}
+	got := string(Calendar(entry, config.CalDAVEvent, 15*time.Minute))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"BEGIN:VEVENT\r\n",
+		"UID:abc@chronicle\r\n",
+		"DTSTART:20260304T100000Z\r\n",
+		"DTEND:20260304T101500Z\r\n",
+		`SUMMARY:[decision] chose Postgres\; SQLite was too small\, sadly` + "\r\n",
+		"CATEGORIES:db,arch\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if !strings.Contains(strings.ReplaceAll(got, "\r\n ", ""), `\nmore detail\n\nhost: laptop`) {
+		t.Errorf("description missing details:\n%s", got)
+	}
+	if string(Calendar(entry, config.CalDAVEvent, 15*time.Minute)) != got {
+		t.Error("rendering the same entry twice gave different output")
+	}
+}
+
+func TestCalendarShellDuration(t *testing.T) {
+	entry := charm.Entry{
+		ID:        "abc",
+		Timestamp: time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC),
+		Message:   "make test",
+		Metadata:  map[string]string{"duration": "5m0s"},
+	}
+	got := string(Calendar(entry, config.CalDAVEvent, 15*time.Minute))
+	if !strings.Contains(got, "DTSTART:20260304T095500Z\r\n") || !strings.Contains(got, "DTEND:20260304T100000Z\r\n") {
+		t.Errorf("event should cover the command's run:\n%s", got)
+	}
+}
+
+func TestCalendarJournal(t *testing.T) {
+	entry := charm.Entry{ID: "abc", Timestamp: time.Now(), Message: "note"}
+	got := string(Calendar(entry, config.CalDAVJournal, 15*time.Minute))
+	if !strings.Contains(got, "BEGIN:VJOURNAL\r\n") || strings.Contains(got, "DTEND") {
+		t.Errorf("unexpected journal:\n%s", got)
+	}
+}
+
+func TestWriteLineFolds(t *testing.T) {
+	var b strings.Builder
+	writeLine(&b, "DESCRIPTION:"+strings.Repeat("é", 100))
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 3 {
+		t.Fatalf("expected folding, got %q", b.String())
+	}
+	var joined strings.Builder
+	for i, line := range lines {
+		if len(line) > maxLine {
+			t.Errorf("line %d is %d octets", i, len(line))
+		}
+		if i > 0 {
+			if !strings.HasPrefix(line, " ") {
+				t.Errorf("continuation line %d doesn't start with a space", i)
+			}
+			line = line[1:]
+		}
+		joined.WriteString(line)
+	}
+	if joined.String() != "DESCRIPTION:"+strings.Repeat("é", 100) {
+		t.Error("unfolding didn't restore the line")
+	}
+}
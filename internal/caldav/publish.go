@@ -0,0 +1,158 @@
+// ABOUTME: Incremental publishing of recent entries to a CalDAV calendar
+// ABOUTME: Remembers what was stored so only new, changed, or deleted entries are sent
+package caldav
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+// State records the calendar objects published so far, by entry ID.
+type State struct {
+	Objects map[string]Published `json:"objects"`
+}
+
+// Published is one entry's calendar object as last stored.
+type Published struct {
+	// Hash is the SHA-256 of the stored object
+	Hash string `json:"hash"`
+	// Timestamp is the entry's, to tell entries that aged out of the
+	// window from deleted ones
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Result counts what a publish did.
+type Result struct {
+	Stored    int `json:"stored"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// LoadState reads the state file at path; a missing file is an empty
+// state.
+func LoadState(path string) (*State, error) {
+	state := &State{Objects: make(map[string]Published)}
+	data, err := os.ReadFile(path) //nolint:gosec // Path is chronicle's own state file
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read caldav state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parse caldav state %s: %w", path, err)
+	}
+	if state.Objects == nil {
+		state.Objects = make(map[string]Published)
+	}
+	return state, nil
+}
+
+// Save atomically replaces the state file at path.
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write caldav state: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Publish brings the calendar in line with entries, the journal's entries
+// logged since since: new and changed ones are stored, and ones published
+// earlier that are no longer among them are removed. Entries older than
+// since are forgotten but left on the calendar. With dryRun nothing is
+// sent and state is unchanged. On error, state still records what was
+// done before it.
+func Publish(ctx context.Context, dav *Client, cfg *config.CalDAV, entries []charm.Entry, since time.Time, state *State, dryRun bool) (Result, error) {
+	var result Result
+	current := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		current[entry.ID] = true
+		body := Calendar(entry, cfg.ComponentName(), cfg.EventLength())
+		sum := sha256.Sum256(body)
+		hash := hex.EncodeToString(sum[:])
+		if state.Objects[entry.ID].Hash == hash {
+			result.Unchanged++
+			continue
+		}
+		if !dryRun {
+			if err := dav.Put(ctx, objectName(entry.ID), body); err != nil {
+				return result, err
+			}
+			state.Objects[entry.ID] = Published{Hash: hash, Timestamp: entry.Timestamp}
+		}
+		result.Stored++
+	}
+
+	for id, published := range state.Objects {
+		if current[id] {
+			continue
+		}
+		if published.Timestamp.Before(since) {
+			if !dryRun {
+				delete(state.Objects, id)
+			}
+			continue
+		}
+		if !dryRun {
+			if err := dav.Delete(ctx, objectName(id)); err != nil {
+				return result, err
+			}
+			delete(state.Objects, id)
+		}
+		result.Removed++
+	}
+	return result, nil
+}
+
+// PublishJournal publishes the client's entries from cfg's window to its
+// calendar, keeping state in the file at statePath.
+func PublishJournal(ctx context.Context, client *charm.Client, cfg *config.CalDAV, statePath string, dryRun bool) (Result, error) {
+	if err := cfg.Validate(); err != nil {
+		return Result{}, fmt.Errorf("invalid caldav settings: %w", err)
+	}
+	dav, err := NewClient(cfg.URL, cfg.Username, cfg.Secret())
+	if err != nil {
+		return Result{}, err
+	}
+	state, err := LoadState(statePath)
+	if err != nil {
+		return Result{}, err
+	}
+
+	since := time.Now().Add(-cfg.Window())
+	entries, err := client.SearchEntries(&charm.SearchFilter{Tags: cfg.Tags, Since: &since}, 0)
+	if err != nil {
+		return Result{}, fmt.Errorf("list entries: %w", err)
+	}
+
+	result, err := Publish(ctx, dav, cfg, entries, since, state, dryRun)
+	if !dryRun {
+		if saveErr := state.Save(statePath); err == nil {
+			err = saveErr
+		}
+	}
+	return result, err
+}
+
+// objectName is the calendar object holding entry id.
+func objectName(id string) string {
+	return id + ".ics"
+}
@@ -0,0 +1,183 @@
+// ABOUTME: Tests for incremental CalDAV publishing
+// ABOUTME: Runs publishes against a fake server and checks what is sent and remembered
+package caldav
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+// fakeServer is a CalDAV collection that records requests.
+type fakeServer struct {
+	mu       sync.Mutex
+	objects  map[string]string
+	requests []string
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		if _, ok := f.objects[r.URL.Path]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeServer) take() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := f.requests
+	f.requests = nil
+	return requests
+}
+
+func TestPublish(t *testing.T) {
+	fake := &fakeServer{objects: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	cfg := &config.CalDAV{URL: server.URL + "/cal/work", Username: "me", Password: "secret"}
+	dav, err := NewClient(cfg.URL, cfg.Username, cfg.Secret())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	since := now.Add(-cfg.Window())
+	entries := []charm.Entry{
+		{ID: "a", Timestamp: now.Add(-time.Hour), Message: "first"},
+		{ID: "b", Timestamp: now, Message: "second"},
+	}
+	state := &State{Objects: make(map[string]Published)}
+	ctx := context.Background()
+
+	result, err := Publish(ctx, dav, cfg, entries, since, state, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Stored: 2}) || len(fake.take()) != 2 || len(state.Objects) != 2 {
+		t.Fatalf("first publish: %+v, state %v", result, state.Objects)
+	}
+	if _, ok := fake.objects["/cal/work/a.ics"]; !ok {
+		t.Errorf("objects stored at %v", fake.objects)
+	}
+
+	// Nothing changed: nothing is sent
+	result, err = Publish(ctx, dav, cfg, entries, since, state, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Unchanged: 2}) || len(fake.take()) != 0 {
+		t.Errorf("unchanged publish: %+v", result)
+	}
+
+	// b is edited and a deleted
+	edited := []charm.Entry{{ID: "b", Timestamp: now, Message: "second, edited"}}
+	result, err = Publish(ctx, dav, cfg, edited, since, state, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := fake.take()
+	if result != (Result{Stored: 1, Removed: 1}) || len(requests) != 2 {
+		t.Errorf("edit publish: %+v, requests %v", result, requests)
+	}
+	if _, ok := state.Objects["a"]; ok {
+		t.Error("deleted entry still in state")
+	}
+
+	// An entry that aged out of the window is forgotten, not removed
+	state.Objects["old"] = Published{Hash: "x", Timestamp: since.Add(-time.Hour)}
+	result, err = Publish(ctx, dav, cfg, edited, since, state, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Removed != 0 || len(fake.take()) != 0 {
+		t.Errorf("aged-out entry was removed: %+v", result)
+	}
+	if _, ok := state.Objects["old"]; ok {
+		t.Error("aged-out entry still in state")
+	}
+}
+
+func TestPublishDryRun(t *testing.T) {
+	fake := &fakeServer{objects: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	cfg := &config.CalDAV{URL: server.URL, Username: "me", Password: "secret"}
+	dav, err := NewClient(cfg.URL, cfg.Username, cfg.Secret())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &State{Objects: map[string]Published{"gone": {Hash: "x", Timestamp: time.Now()}}}
+	entries := []charm.Entry{{ID: "a", Timestamp: time.Now(), Message: "new"}}
+	result, err := Publish(context.Background(), dav, cfg, entries, time.Now().Add(-time.Hour), state, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Stored: 1, Removed: 1}) || len(fake.take()) != 0 || len(state.Objects) != 1 {
+		t.Errorf("dry run: %+v, state %v", result, state.Objects)
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	fake := &fakeServer{objects: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	cfg := &config.CalDAV{URL: server.URL, Username: "me", Password: "wrong"}
+	dav, err := NewClient(cfg.URL, cfg.Username, cfg.Secret())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &State{Objects: make(map[string]Published)}
+	entries := []charm.Entry{{ID: "a", Timestamp: time.Now(), Message: "new"}}
+	if _, err := Publish(context.Background(), dav, cfg, entries, time.Now().Add(-time.Hour), state, false); err == nil {
+		t.Error("expected an error for rejected credentials")
+	}
+	if len(state.Objects) != 0 {
+		t.Error("failed store recorded in state")
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "caldav.json")
+	state, err := LoadState(path)
+	if err != nil || len(state.Objects) != 0 {
+		t.Fatalf("missing state: %v, %v", state, err)
+	}
+	stamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	state.Objects["a"] = Published{Hash: "h", Timestamp: stamp}
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Objects["a"]; got.Hash != "h" || !got.Timestamp.Equal(stamp) {
+		t.Errorf("loaded %+v", got)
+	}
+}
@@ -4,14 +4,19 @@
 package charm
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/charmbracelet/charm/client"
 	"github.com/charmbracelet/charm/kv"
 	charmproto "github.com/charmbracelet/charm/proto"
+	"github.com/harper/chronicle/internal/config"
 )
 
 const (
@@ -20,15 +25,35 @@ const (
 
 	// DBName is the KV database name for chronicle.
 	DBName = "chronicle"
+
+	// syncTimeout matches the timeout kv.KV.Sync applies on its own.
+	syncTimeout = 60 * time.Second
 )
 
+// ErrReadOnly is returned for writes on a device configured with read_only.
+var ErrReadOnly = errors.New("this device is read-only (read_only in charm.json); entries can't be added or changed here")
+
 // Client holds configuration for KV operations.
 // Unlike the previous implementation, it does NOT hold a persistent connection.
 // Each operation opens the database, performs the operation, and closes it.
 type Client struct {
-	dbName         string
-	autoSync       bool
-	staleThreshold time.Duration
+	dbName           string
+	autoSync         bool
+	normalizeTags    bool
+	homeRelativeDirs bool
+	dbDir            string
+	staleThreshold   time.Duration
+	offline          *offlineState
+	ignoredHosts     []string
+	hooks            *Hooks
+	webhooks         []Webhook
+	notifications    []config.Notification
+	profile          string
+
+	autoSyncPolicy     string
+	autoSyncInterval   time.Duration
+	autoSyncMaxPending int
+	readOnly           bool
 }
 
 // Option configures a Client.
@@ -48,6 +73,13 @@ func WithAutoSync(enabled bool) Option {
 	}
 }
 
+// WithTagNormalization enables or disables tag normalization on write.
+func WithTagNormalization(enabled bool) Option {
+	return func(c *Client) {
+		c.normalizeTags = enabled
+	}
+}
+
 // NewClient creates a new client with the given options.
 func NewClient(cfg *Config) (*Client, error) {
 	if cfg == nil {
@@ -65,14 +97,55 @@ func NewClient(cfg *Config) (*Client, error) {
 		}
 	}
 
+	profile := ResolveProfile(cfg.Profile)
+	if err := ValidateProfile(profile); err != nil {
+		return nil, err
+	}
+	if !ValidSyncPolicy(cfg.AutoSyncPolicy) {
+		return nil, fmt.Errorf("invalid auto_sync_policy %q (valid: %v)", cfg.AutoSyncPolicy, SyncPolicies)
+	}
+	if cfg.AutoSyncPolicy == SyncPolicyEveryN && cfg.AutoSyncMaxPending <= 0 {
+		return nil, fmt.Errorf("auto_sync_policy %q needs auto_sync_max_pending set", SyncPolicyEveryN)
+	}
+	if err := ValidateWebhooks(cfg.Webhooks); err != nil {
+		return nil, fmt.Errorf("invalid webhooks: %w", err)
+	}
+	if err := ValidateNotifications(cfg.Notifications); err != nil {
+		return nil, fmt.Errorf("invalid notifications: %w", err)
+	}
+
 	c := &Client{
-		dbName:         DBName,
-		autoSync:       cfg.AutoSync,
-		staleThreshold: cfg.StaleThreshold,
+		dbName:           DBNameForProfile(profile),
+		autoSync:         cfg.AutoSync,
+		normalizeTags:    cfg.NormalizeTags,
+		homeRelativeDirs: cfg.HomeRelativeDirs,
+		dbDir:            config.ResolveDBDir(cfg.DBPath),
+		staleThreshold:   cfg.StaleThreshold,
+		ignoredHosts:     cfg.IgnoredHosts,
+		hooks:            cfg.Hooks,
+		webhooks:         cfg.Webhooks,
+		notifications:    cfg.Notifications,
+		profile:          profile,
+		offline:          &offlineState{offline: markedOffline(DBNameForProfile(profile))},
+
+		autoSyncPolicy:     cfg.AutoSyncPolicy,
+		autoSyncInterval:   time.Duration(cfg.AutoSyncInterval) * time.Second,
+		autoSyncMaxPending: cfg.AutoSyncMaxPending,
+		readOnly:           cfg.ReadOnly,
 	}
 	return c, nil
 }
 
+// WithOptions returns a copy of the client with opts applied, leaving the
+// original (often the shared global client) untouched.
+func (c *Client) WithOptions(opts ...Option) *Client {
+	clone := *c
+	for _, opt := range opts {
+		opt(&clone)
+	}
+	return &clone
+}
+
 // Get retrieves a value by key (read-only, no lock contention).
 // Syncs first if data is stale (last sync > threshold).
 func (c *Client) Get(key []byte) ([]byte, error) {
@@ -87,33 +160,21 @@ func (c *Client) Get(key []byte) ([]byte, error) {
 		var err error
 		val, err = k.Get(key)
 		return err
-	})
+	}, c.kvOptions()...)
 	return val, err
 }
 
 // Set stores a value with the given key.
 func (c *Client) Set(key, value []byte) error {
-	return kv.Do(c.dbName, func(k *kv.KV) error {
-		if err := k.Set(key, value); err != nil {
-			return err
-		}
-		if c.autoSync {
-			return k.Sync()
-		}
-		return nil
+	return c.Do(func(k *kv.KV) error {
+		return k.Set(key, value)
 	})
 }
 
 // Delete removes a key.
 func (c *Client) Delete(key []byte) error {
-	return kv.Do(c.dbName, func(k *kv.KV) error {
-		if err := k.Delete(key); err != nil {
-			return err
-		}
-		if c.autoSync {
-			return k.Sync()
-		}
-		return nil
+	return c.Do(func(k *kv.KV) error {
+		return k.Delete(key)
 	})
 }
 
@@ -131,7 +192,7 @@ func (c *Client) Keys() ([][]byte, error) {
 		var err error
 		keys, err = k.Keys()
 		return err
-	})
+	}, c.kvOptions()...)
 	return keys, err
 }
 
@@ -145,29 +206,114 @@ func (c *Client) DoReadOnly(fn func(k *kv.KV) error) error {
 		fmt.Fprintf(os.Stderr, "warning: stale sync failed: %v\n", err)
 	}
 
-	return kv.DoReadOnly(c.dbName, fn)
+	return kv.DoReadOnly(c.dbName, fn, c.kvOptions()...)
 }
 
 // Do executes a function with write access to the database.
 // Use this for batch write operations.
+// Retries with backoff if another process holds the database lock.
+// With auto-sync on, the write is pushed afterwards as the auto-sync policy
+// allows; a failed push is only a warning since the change is already saved
+// locally.
 func (c *Client) Do(fn func(k *kv.KV) error) error {
-	return kv.Do(c.dbName, func(k *kv.KV) error {
-		if err := fn(k); err != nil {
-			return err
-		}
-		if c.autoSync {
-			return k.Sync()
-		}
-		return nil
-	})
+	return c.doWrite(writeChange, fn)
+}
+
+// doWrite is Do for a write of the given kind.
+func (c *Client) doWrite(kind writeKind, fn func(k *kv.KV) error) error {
+	if err := c.write(fn); err != nil {
+		return err
+	}
+	c.syncAfterWrite(kind)
+	return nil
+}
+
+// write runs fn with write access, retrying on lock contention, without syncing.
+// Read-only devices refuse every write. If the database stays locked, the
+// write is retried once if its recorded holder has died, and otherwise the
+// error names the chronicle process likely holding it.
+func (c *Client) write(fn func(k *kv.KV) error) error {
+	if c.readOnly {
+		return ErrReadOnly
+	}
+
+	path := holderPath(c.dbName)
+	attempt := func() error {
+		return withLockRetry(func() error {
+			return kv.Do(c.dbName, func(k *kv.KV) error {
+				release := markHolder(path)
+				defer release()
+				if err := c.flushOutbox(k); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: failed to add queued offline entries: %v\n", err)
+				}
+				return fn(k)
+			}, c.kvOptions()...)
+		})
+	}
+
+	err := attempt()
+	if !isLockContention(err) {
+		return err
+	}
+	return recoverFromDeadHolder(path, err, attempt)
 }
 
 // Sync triggers a manual sync with the charm server.
 // The charm library automatically records the sync timestamp.
+// A successful sync clears the offline state and flushes queued changes.
 func (c *Client) Sync() error {
-	return kv.Do(c.dbName, func(k *kv.KV) error {
-		return k.Sync()
-	})
+	err := c.syncOnce()
+	c.setOffline(err != nil)
+	return err
+}
+
+// syncOnce makes a single sync attempt, running any configured hooks.
+func (c *Client) syncOnce() error {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+	_, _, err := c.runSync(ctx, false)
+	return err
+}
+
+// runSync performs one sync wrapped in the configured hooks. Counters are
+// only collected when track is set or an on_remote_change hook needs them,
+// since reading them costs a database health check.
+func (c *Client) runSync(ctx context.Context, track bool) (before, after syncCounters, err error) {
+	hooks := c.hooks
+	if hooks == nil {
+		hooks = &Hooks{}
+	}
+	track = track || hooks.OnRemoteChange != ""
+
+	runHook(ctx, "pre_sync", hooks.PreSync)
+	if track {
+		before = c.counters()
+	}
+
+	started := time.Now()
+	err = kv.Do(c.dbName, func(k *kv.KV) error {
+		if !c.readOnly {
+			if err := c.flushOutbox(k); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to add queued offline entries: %v\n", err)
+			}
+		}
+		return k.SyncWithContext(ctx)
+	}, c.kvOptions()...)
+	if err == nil && track {
+		after = c.counters()
+	}
+	recordSyncSession(newSyncSession(started, before, after, track, err))
+
+	if err != nil {
+		runHook(ctx, "post_sync", hooks.PostSync, "CHRONICLE_SYNC_ERROR="+err.Error())
+		return before, after, err
+	}
+	runHook(ctx, "post_sync", hooks.PostSync)
+	if after.seq > before.seq {
+		pulled := strconv.FormatUint(after.seq-before.seq, 10)
+		runHook(ctx, "on_remote_change", hooks.OnRemoteChange, "CHRONICLE_PULLED="+pulled)
+	}
+	return before, after, nil
 }
 
 // LastSyncTime returns when the database was last synced.
@@ -176,10 +322,16 @@ func (c *Client) LastSyncTime() time.Time {
 	_ = kv.DoReadOnly(c.dbName, func(k *kv.KV) error {
 		lastSync = k.LastSyncTime()
 		return nil
-	})
+	}, c.kvOptions()...)
 	return lastSync
 }
 
+// StaleThreshold returns how old the last sync may be before reads sync
+// first, or zero if stale syncing is disabled.
+func (c *Client) StaleThreshold() time.Duration {
+	return c.staleThreshold
+}
+
 // IsStale returns true if the last sync was longer ago than the stale threshold.
 func (c *Client) IsStale() bool {
 	if c.staleThreshold == 0 {
@@ -189,7 +341,7 @@ func (c *Client) IsStale() bool {
 	_ = kv.DoReadOnly(c.dbName, func(k *kv.KV) error {
 		isStale = k.IsStale(c.staleThreshold)
 		return nil
-	})
+	}, c.kvOptions()...)
 	return isStale
 }
 
@@ -207,7 +359,7 @@ func (c *Client) SyncIfStale() error {
 func (c *Client) Reset() error {
 	return kv.Do(c.dbName, func(k *kv.KV) error {
 		return k.Reset()
-	})
+	}, c.kvOptions()...)
 }
 
 // ID returns the charm user ID for this device.
@@ -320,26 +472,76 @@ func GetCharmHost() string {
 // RepairDB attempts to repair a corrupted database without opening it.
 // This can be called even when the database is too corrupted to open normally.
 func RepairDB(force bool) (*kv.RepairResult, error) {
-	return kv.Repair(DBName, force)
+	return kv.Repair(configuredDBName(), force, dbDirOptions(configuredDBDir())...)
 }
 
 // ResetDBFromCloud resets the database without requiring an open client.
 // This deletes local data and re-syncs from cloud.
 func ResetDBFromCloud() error {
-	return kv.Reset(DBName)
+	return kv.Reset(configuredDBName(), dbDirOptions(configuredDBDir())...)
 }
 
 // Repair attempts to repair database corruption.
 func (c *Client) Repair(force bool) (*kv.RepairResult, error) {
-	return kv.Repair(DBName, force)
+	return kv.Repair(c.dbName, force, c.kvOptions()...)
 }
 
 // ResetDB resets the database to a clean state.
 func (c *Client) ResetDB() error {
-	return kv.Reset(DBName)
+	return kv.Reset(c.dbName, c.kvOptions()...)
 }
 
 // Wipe completely wipes all data including cloud backups.
 func (c *Client) Wipe() (*kv.WipeResult, error) {
-	return kv.Wipe(DBName)
+	return kv.Wipe(c.dbName, c.kvOptions()...)
+}
+
+// kvOptions returns the KV options for this client's database location.
+func (c *Client) kvOptions() []kv.Option {
+	return dbDirOptions(c.dbDir)
+}
+
+// dbDirOptions points the KV store at dir, or the Charm default if empty.
+func dbDirOptions(dir string) []kv.Option {
+	if dir == "" {
+		return nil
+	}
+	return []kv.Option{kv.WithPath(dir)}
+}
+
+// configuredDBDir resolves the database directory without a client.
+func configuredDBDir() string {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return config.ResolveDBDir("")
+	}
+	return config.ResolveDBDir(cfg.DBPath)
+}
+
+// DBPath returns the path of the local SQLite file backing the KV store.
+func DBPath() (string, error) {
+	dataDir := configuredDBDir()
+	if dataDir == "" {
+		cc, err := client.NewClientWithDefaults()
+		if err != nil {
+			return "", err
+		}
+		dataDir, err = cc.DataPath()
+		if err != nil {
+			return "", fmt.Errorf("failed to get data path: %w", err)
+		}
+	}
+	return filepath.Join(dataDir, "kv", configuredDBName()+".db"), nil
+}
+
+// DBSize returns the combined size in bytes of the database and its WAL file.
+// Missing files count as zero.
+func DBSize(path string) int64 {
+	var total int64
+	for _, p := range []string{path, path + "-wal"} {
+		if info, err := os.Stat(p); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
 }
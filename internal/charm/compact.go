@@ -0,0 +1,75 @@
+// ABOUTME: Compacts the local database through the client's locked write path
+// ABOUTME: Checkpoints the WAL, checks integrity, and vacuums without touching SQLite's lock files
+
+package charm
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/charmbracelet/charm/kv"
+	_ "modernc.org/sqlite" // SQLite driver, the same one the KV store uses
+)
+
+// CompactResult reports what Compact did.
+type CompactResult struct {
+	WalCheckpointed bool
+	IntegrityOK     bool
+	// Integrity is SQLite's report when the integrity check fails
+	Integrity string
+	Vacuumed  bool
+}
+
+// Compact checkpoints the WAL, checks integrity, and vacuums the database
+// while holding write access, retrying like any other write if another
+// chronicle process has it. Unlike RepairDB it never removes the -shm
+// file, so it's safe while serve, the MCP server, or the sync daemon has
+// the database open. A database that fails the integrity check isn't
+// vacuumed; repair it with sync repair instead.
+func (c *Client) Compact() (*CompactResult, error) {
+	path, err := DBPath()
+	if err != nil {
+		return nil, err
+	}
+	var result *CompactResult
+	err = c.write(func(*kv.KV) error {
+		result, err = compactDB(path)
+		return err
+	})
+	return result, err
+}
+
+// compactDB runs the compaction steps on the SQLite file at path over its
+// own connection.
+func compactDB(path string) (*CompactResult, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	// One connection, so the pragmas apply to every statement below
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA busy_timeout=5000"); err != nil {
+		return nil, fmt.Errorf("set busy timeout: %w", err)
+	}
+
+	result := &CompactResult{}
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return result, fmt.Errorf("checkpoint WAL: %w", err)
+	}
+	result.WalCheckpointed = true
+
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result.Integrity); err != nil {
+		return result, fmt.Errorf("check integrity: %w", err)
+	}
+	if result.Integrity != "ok" {
+		return result, nil
+	}
+	result.IntegrityOK = true
+
+	if _, err := db.Exec("VACUUM"); err != nil {
+		return result, fmt.Errorf("vacuum: %w", err)
+	}
+	result.Vacuumed = true
+	return result, nil
+}
@@ -0,0 +1,50 @@
+// ABOUTME: Tests for compacting the local database
+// ABOUTME: Checks that compaction vacuums a WAL database and leaves its -shm file alone
+package charm
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompactDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chronicle.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	db.SetMaxOpenConns(1)
+	for _, stmt := range []string{
+		"PRAGMA journal_mode=WAL",
+		"CREATE TABLE kv (k TEXT PRIMARY KEY, v BLOB)",
+		"WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 200) INSERT INTO kv SELECT 'key' || i, zeroblob(4096) FROM n",
+		"DELETE FROM kv",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	before := DBSize(path)
+
+	// The other connection stays open, as serve or the daemon's would
+	result, err := compactDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.WalCheckpointed || !result.IntegrityOK || !result.Vacuumed {
+		t.Errorf("result = %+v", result)
+	}
+	if after := DBSize(path); after >= before {
+		t.Errorf("size %d -> %d, want it smaller", before, after)
+	}
+	if _, err := os.Stat(path + "-shm"); err != nil {
+		t.Errorf("-shm file: %v, want it left in place", err)
+	}
+	var n int
+	if err := db.QueryRow("SELECT count(*) FROM kv").Scan(&n); err != nil || n != 0 {
+		t.Errorf("open connection after compaction: %d rows, %v", n, err)
+	}
+}
@@ -1,15 +1,17 @@
 // ABOUTME: Configuration for Charm KV backend
-// ABOUTME: Handles charm server settings and XDG config paths
+// ABOUTME: Handles charm server settings, XDG config paths, and CHRONICLE_* overrides
 
 package charm
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/charmbracelet/charm/kv"
+	"github.com/harper/chronicle/internal/config"
 )
 
 // Config holds charm sync configuration.
@@ -20,8 +22,184 @@ type Config struct {
 	// AutoSync enables automatic sync after writes (default: true)
 	AutoSync bool `json:"auto_sync"`
 
+	// AutoSyncPolicy picks which writes sync: every_write (default), on_add,
+	// every_n (once AutoSyncMaxPending changes are queued), or never
+	AutoSyncPolicy string `json:"auto_sync_policy,omitempty"`
+
+	// AutoSyncInterval syncs after a write at most once per this many seconds (default: 0, every write)
+	AutoSyncInterval int `json:"auto_sync_interval,omitempty"`
+
+	// AutoSyncMaxPending syncs early once this many writes are queued (default: 0, no limit)
+	AutoSyncMaxPending int `json:"auto_sync_max_pending,omitempty"`
+
+	// NormalizeTags trims, lowercases, and collapses whitespace in tags on write (default: true)
+	NormalizeTags bool `json:"normalize_tags"`
+
+	// GitProjects treats the nearest git repository as the project when no
+	// .chronicle file is found, using default project settings (default: false)
+	GitProjects bool `json:"git_projects,omitempty"`
+
+	// HomeRelativeDirs records working directories under $HOME as ~/... (default: false)
+	HomeRelativeDirs bool `json:"home_relative_dirs,omitempty"`
+
+	// Profile selects a separate journal with its own database (default: none).
+	// The CHRONICLE_PROFILE environment variable and --profile take precedence.
+	Profile string `json:"profile,omitempty"`
+
+	// DBPath overrides the directory holding the local database (default: Charm data dir).
+	// The CHRONICLE_DB environment variable takes precedence.
+	DBPath string `json:"db_path,omitempty"`
+
 	// StaleThreshold is the duration after which data is considered stale
 	StaleThreshold time.Duration `json:"stale_threshold,omitempty"`
+
+	// DeviceNames maps linked SSH key fingerprints to friendly device names
+	DeviceNames map[string]string `json:"device_names,omitempty"`
+
+	// IgnoredHosts hides entries written on these hostnames from lists, search, and stats
+	IgnoredHosts []string `json:"ignored_hosts,omitempty"`
+
+	// ProjectDatabases lists the project roots whose .chronicle may keep
+	// their entries in a database of their own
+	ProjectDatabases []string `json:"project_databases,omitempty"`
+
+	// DuplicateWindow is how many seconds back the MCP remember_this tool looks
+	// for an entry with the same message before adding another (default: 600).
+	// A negative value turns duplicate detection off
+	DuplicateWindow int `json:"duplicate_window,omitempty"`
+
+	// MCPRateLimit caps how many entries the MCP server adds per minute (default: 0, no limit)
+	MCPRateLimit int `json:"mcp_rate_limit,omitempty"`
+
+	// MCPCoalesceWindow merges an MCP add into the previous entry when it comes
+	// within this many seconds of it (default: 0, never merge)
+	MCPCoalesceWindow int `json:"mcp_coalesce_window,omitempty"`
+
+	// MCPRequireConfirmation makes the MCP edit_entry and delete_entry tools
+	// wait for the user's approval (default: false)
+	MCPRequireConfirmation bool `json:"mcp_require_confirmation,omitempty"`
+
+	// MCPReadOnly makes the MCP server offer only query tools (default: false).
+	// ReadOnly implies it
+	MCPReadOnly bool `json:"mcp_read_only,omitempty"`
+
+	// ReadOnly makes this device pull-only: syncing still brings in entries,
+	// but adds and edits are refused
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// TagRules add to the built-in keyword rules MCP remember_this uses to
+	// suggest tags; a project's .chronicle file can add more
+	TagRules []config.TagRule `json:"tag_rules,omitempty"`
+
+	// DefaultTags are added to every entry made on this machine, after the
+	// entry's own tags and its project's defaults
+	DefaultTags []string `json:"default_tags,omitempty"`
+
+	// PrivacyRules suppress or hash the working directory and hostname of
+	// entries created under matching paths
+	PrivacyRules []config.PrivacyRule `json:"privacy_rules,omitempty"`
+
+	// RedactSecrets is what happens to a message containing a secret such as
+	// an AWS key or bearer token: scrub (default), refuse, or off
+	RedactSecrets string `json:"redact_secrets,omitempty"`
+
+	// RedactPatterns are regular expressions for more secrets to redact
+	RedactPatterns []string `json:"redact_patterns,omitempty"`
+
+	// Defaults seeds flags per command when they aren't given, keyed by
+	// command ("list", "sync log") and then flag name
+	Defaults map[string]map[string]any `json:"defaults,omitempty"`
+
+	// Editor is the command for add --edit and config edit, e.g.
+	// "code --wait {{file}}"; $VISUAL or $EDITOR when empty
+	Editor string `json:"editor,omitempty"`
+
+	// ShellCapture picks which commands shell-integration logs
+	ShellCapture *config.ShellCapture `json:"shell_capture,omitempty"`
+
+	// Webhooks are notified when entries are created, updated, or deleted
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+
+	// Notifications post selected new entries to a team chat channel
+	Notifications []config.Notification `json:"notifications,omitempty"`
+
+	// CalDAV publishes entries to a calendar with caldav push and the
+	// sync daemon
+	CalDAV *config.CalDAV `json:"caldav,omitempty"`
+
+	// Vault mirrors entries into an Obsidian or Logseq vault's daily notes
+	// with vault sync and the sync daemon
+	Vault *config.Vault `json:"vault,omitempty"`
+
+	// DesktopNotifications picks events announced with a desktop
+	// notification, such as entries arriving from other devices
+	DesktopNotifications *config.DesktopNotifications `json:"desktop_notifications,omitempty"`
+
+	// AutoTag asks a language model for tags and a type when entries are
+	// added with add or MCP add_entry
+	AutoTag *config.AutoTag `json:"auto_tag,omitempty"`
+
+	// Transcription turns voice notes into entries with import audio
+	Transcription *config.Transcription `json:"transcription,omitempty"`
+
+	// TimeTracking maps tags to projects for chronicle export to Toggl
+	// and Timewarrior
+	TimeTracking *config.TimeTracking `json:"time_tracking,omitempty"`
+
+	// InboundHooks map payloads posted to serve's /hooks/<name>, such as CI
+	// or monitoring webhooks, to entries
+	InboundHooks map[string]config.InboundHook `json:"inbound_hooks,omitempty"`
+
+	// Hooks are shell commands run before and after sync
+	Hooks *Hooks `json:"hooks,omitempty"`
+
+	// OutputFormat is how list, search, and other commands print: table
+	// (default) or json. A command's --json flag takes precedence
+	OutputFormat string `json:"output_format,omitempty"`
+
+	// WeekStartsOn is the first day of "this week" and "last week" in
+	// summaries and stats; monday when empty
+	WeekStartsOn string `json:"week_starts_on,omitempty"`
+
+	// DateFormat is how dates print in tables and exports: iso (default),
+	// us, or eu
+	DateFormat string `json:"date_format,omitempty"`
+}
+
+// Output formats for OutputFormat.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// envSettings lists the CHRONICLE_* variables that override cfg's fields.
+func envSettings(cfg *Config) []config.EnvSetting {
+	return []config.EnvSetting{
+		{Env: "CHRONICLE_SYNC_SERVER", Set: config.EnvString(&cfg.CharmHost)},
+		{Env: "CHRONICLE_AUTO_SYNC", Set: config.EnvBool(&cfg.AutoSync)},
+		{Env: "CHRONICLE_AUTO_SYNC_POLICY", Set: config.EnvString(&cfg.AutoSyncPolicy)},
+		{Env: "CHRONICLE_AUTO_SYNC_INTERVAL", Set: config.EnvInt(&cfg.AutoSyncInterval)},
+		{Env: "CHRONICLE_AUTO_SYNC_MAX_PENDING", Set: config.EnvInt(&cfg.AutoSyncMaxPending)},
+		{Env: "CHRONICLE_NORMALIZE_TAGS", Set: config.EnvBool(&cfg.NormalizeTags)},
+		{Env: "CHRONICLE_GIT_PROJECTS", Set: config.EnvBool(&cfg.GitProjects)},
+		{Env: "CHRONICLE_HOME_RELATIVE_DIRS", Set: config.EnvBool(&cfg.HomeRelativeDirs)},
+		{Env: ProfileEnv, Set: config.EnvString(&cfg.Profile)},
+		{Env: config.DBPathEnv, Set: config.EnvString(&cfg.DBPath)},
+		{Env: "CHRONICLE_STALE_THRESHOLD", Set: config.EnvDuration(&cfg.StaleThreshold)},
+		{Env: "CHRONICLE_IGNORED_HOSTS", Set: config.EnvList(&cfg.IgnoredHosts)},
+		{Env: "CHRONICLE_DEFAULT_TAGS", Set: config.EnvList(&cfg.DefaultTags)},
+		{Env: "CHRONICLE_DUPLICATE_WINDOW", Set: config.EnvInt(&cfg.DuplicateWindow)},
+		{Env: "CHRONICLE_MCP_RATE_LIMIT", Set: config.EnvInt(&cfg.MCPRateLimit)},
+		{Env: "CHRONICLE_MCP_COALESCE_WINDOW", Set: config.EnvInt(&cfg.MCPCoalesceWindow)},
+		{Env: "CHRONICLE_MCP_REQUIRE_CONFIRMATION", Set: config.EnvBool(&cfg.MCPRequireConfirmation)},
+		{Env: "CHRONICLE_MCP_READ_ONLY", Set: config.EnvBool(&cfg.MCPReadOnly)},
+		{Env: "CHRONICLE_READ_ONLY", Set: config.EnvBool(&cfg.ReadOnly)},
+		{Env: "CHRONICLE_FORMAT", Set: config.EnvString(&cfg.OutputFormat)},
+		{Env: "CHRONICLE_EDITOR", Set: config.EnvString(&cfg.Editor)},
+		{Env: "CHRONICLE_REDACT_SECRETS", Set: config.EnvString(&cfg.RedactSecrets)},
+		{Env: "CHRONICLE_WEEK_STARTS_ON", Set: config.EnvString(&cfg.WeekStartsOn)},
+		{Env: "CHRONICLE_DATE_FORMAT", Set: config.EnvString(&cfg.DateFormat)},
+	}
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -29,6 +207,7 @@ func DefaultConfig() *Config {
 	return &Config{
 		CharmHost:      "charm.2389.dev",
 		AutoSync:       true,
+		NormalizeTags:  true,
 		StaleThreshold: kv.DefaultStaleThreshold,
 	}
 }
@@ -48,8 +227,52 @@ func ConfigPath() string {
 	return filepath.Join(ConfigDir(), "charm.json")
 }
 
-// LoadConfig loads configuration from disk, returns defaults if not found.
+// AllowsProjectDatabase reports whether the project at root is listed in
+// project_databases. Until it is, a checked-out repository's .chronicle
+// can't choose where entries are written.
+func (c *Config) AllowsProjectDatabase(root string) bool {
+	root = canonicalDir(root)
+	for _, dir := range c.ProjectDatabases {
+		if canonicalDir(config.ExpandHome(dir)) == root {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalDir makes dir absolute and resolves its symlinks where it can,
+// so two spellings of one directory compare equal.
+func canonicalDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+	return filepath.Clean(dir)
+}
+
+// LoadConfig loads configuration from disk (defaults if not found) with
+// CHRONICLE_* environment variables applied over it.
 func LoadConfig() (*Config, error) {
+	cfg, err := LoadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ApplyEnv(envSettings(cfg)); err != nil {
+		return nil, err
+	}
+	switch cfg.OutputFormat {
+	case "", OutputTable, OutputJSON:
+	default:
+		return nil, fmt.Errorf("invalid output_format %q (use %s or %s)", cfg.OutputFormat, OutputTable, OutputJSON)
+	}
+	return cfg, nil
+}
+
+// LoadConfigFile loads just the config file, without environment
+// overrides, for changing and saving it back.
+func LoadConfigFile() (*Config, error) {
 	cfg := DefaultConfig()
 
 	data, err := os.ReadFile(ConfigPath())
@@ -0,0 +1,47 @@
+// ABOUTME: Tests for config loading and CHRONICLE_* overrides
+// ABOUTME: Points XDG_CONFIG_HOME at a temp dir so the real config is untouched
+package charm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigEnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "chronicle"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"charm_host": "file.example.com", "auto_sync": true}`)
+	if err := os.WriteFile(ConfigPath(), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CHRONICLE_SYNC_SERVER", "env.example.com")
+	t.Setenv("CHRONICLE_AUTO_SYNC", "false")
+	t.Setenv("CHRONICLE_FORMAT", "json")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CharmHost != "env.example.com" || cfg.AutoSync || cfg.OutputFormat != OutputJSON {
+		t.Errorf("env not applied: host=%q auto_sync=%v format=%q", cfg.CharmHost, cfg.AutoSync, cfg.OutputFormat)
+	}
+
+	// Saving goes through the file alone so overrides aren't persisted
+	fileCfg, err := LoadConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileCfg.CharmHost != "file.example.com" || !fileCfg.AutoSync {
+		t.Errorf("file config picked up env: host=%q auto_sync=%v", fileCfg.CharmHost, fileCfg.AutoSync)
+	}
+
+	t.Setenv("CHRONICLE_FORMAT", "yaml")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("expected error for unknown CHRONICLE_FORMAT")
+	}
+}
@@ -0,0 +1,101 @@
+// ABOUTME: State shared between the background sync daemon and its status/stop commands
+// ABOUTME: Persists a PID/status file and computes jittered sync intervals
+
+package charm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DaemonStatus is written by `chronicle sync daemon` and read by its
+// status and stop subcommands.
+type DaemonStatus struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	Interval  string    `json:"interval"`
+	Jitter    string    `json:"jitter"`
+	LastSync  time.Time `json:"last_sync,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	Syncs     int       `json:"syncs"`
+}
+
+// DaemonStatusPath returns where the sync daemon keeps its status file.
+func DaemonStatusPath() string {
+	return stateFilePath("sync-daemon", ".json")
+}
+
+// DaemonLogPath returns where a sync daemon started by launchd writes its
+// output.
+func DaemonLogPath() string {
+	return stateFilePath("sync-daemon", ".log")
+}
+
+// WriteDaemonStatus atomically replaces the status file at path.
+func WriteDaemonStatus(path string, status *DaemonStatus) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create status dir: %w", err)
+	}
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal status: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write status: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadDaemonStatus reads the status file at path. It returns (nil, nil) if
+// no daemon has written one.
+func ReadDaemonStatus(path string) (*DaemonStatus, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read status: %w", err)
+	}
+	var status DaemonStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("parse status: %w", err)
+	}
+	return &status, nil
+}
+
+// Running reports whether the process that wrote the status is still alive.
+func (s *DaemonStatus) Running() bool {
+	return s != nil && processAlive(s.PID)
+}
+
+// Stop asks the daemon that wrote the status to exit.
+func (s *DaemonStatus) Stop() error {
+	return stopProcess(s.PID)
+}
+
+// NextSyncDelay returns interval plus a random extra delay of up to jitter,
+// so several devices don't all hit the server at the same moment.
+func NextSyncDelay(interval, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int64N(int64(jitter)+1))
+}
+
+// DBModTime returns the latest modification time of the database and its
+// WAL file. Local writes bump it, which the daemon uses to push early.
+func DBModTime(path string) time.Time {
+	var latest time.Time
+	for _, p := range []string{path, path + "-wal"} {
+		if info, err := os.Stat(p); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
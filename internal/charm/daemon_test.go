@@ -0,0 +1,56 @@
+// ABOUTME: Tests for sync daemon status file and interval helpers
+// ABOUTME: Uses temp dirs and the current process as a live PID
+package charm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDaemonStatusRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "sync-daemon.json")
+
+	missing, err := ReadDaemonStatus(path)
+	if err != nil || missing != nil {
+		t.Fatalf("expected no status before write, got %+v, %v", missing, err)
+	}
+	if missing.Running() {
+		t.Error("nil status should not be running")
+	}
+
+	want := &DaemonStatus{
+		PID:       os.Getpid(),
+		StartedAt: time.Date(2025, 11, 29, 14, 30, 0, 0, time.UTC),
+		Interval:  "5m0s",
+		LastError: "boom",
+		Syncs:     3,
+	}
+	if err := WriteDaemonStatus(path, want); err != nil {
+		t.Fatalf("WriteDaemonStatus: %v", err)
+	}
+
+	got, err := ReadDaemonStatus(path)
+	if err != nil {
+		t.Fatalf("ReadDaemonStatus: %v", err)
+	}
+	if got.PID != want.PID || !got.StartedAt.Equal(want.StartedAt) || got.Syncs != 3 || got.LastError != "boom" {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if !got.Running() {
+		t.Error("expected current process to be reported as running")
+	}
+}
+
+func TestNextSyncDelay(t *testing.T) {
+	if d := NextSyncDelay(time.Minute, 0); d != time.Minute {
+		t.Errorf("no jitter: got %v, want 1m", d)
+	}
+	for i := 0; i < 100; i++ {
+		d := NextSyncDelay(time.Minute, 10*time.Second)
+		if d < time.Minute || d > time.Minute+10*time.Second {
+			t.Fatalf("delay %v outside [1m, 1m10s]", d)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// ABOUTME: Per-day index keys so date-range queries fetch only matching entries
+// ABOUTME: Keys look like day:<YYYY-MM-DD>:<id>; searches fall back to a full scan when incomplete
+
+package charm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+// DayIndexPrefix is the key prefix for the per-day entry index. Like entry
+// keys, index keys are stored unencrypted, so they reveal which UTC day each
+// entry was written but nothing of its contents.
+const DayIndexPrefix = "day:"
+
+// dayIndexDateLen is the length of the YYYY-MM-DD part of an index key.
+const dayIndexDateLen = len(time.DateOnly)
+
+// dayKey returns the index key for an entry written at ts.
+func dayKey(ts time.Time, id string) []byte {
+	return []byte(DayIndexPrefix + ts.UTC().Format(time.DateOnly) + ":" + id)
+}
+
+// parseDayKey splits an index key into its day and entry ID.
+func parseDayKey(key string) (day, id string, ok bool) {
+	rest, found := strings.CutPrefix(key, DayIndexPrefix)
+	if !found || len(rest) <= dayIndexDateLen+1 || rest[dayIndexDateLen] != ':' {
+		return "", "", false
+	}
+	return rest[:dayIndexDateLen], rest[dayIndexDateLen+1:], true
+}
+
+// putEntry stores an entry along with its day index key.
+func (c *Client) putEntry(k *kv.KV, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	if err := k.Set(entryKey(entry.ID), data); err != nil {
+		return err
+	}
+	return k.Set(dayKey(entry.Timestamp, entry.ID), []byte{})
+}
+
+// replaceEntry stores an updated entry, moving its index key if the
+// timestamp changed to another day.
+func (c *Client) replaceEntry(k *kv.KV, entry *Entry) error {
+	if data, err := k.Get(entryKey(entry.ID)); err == nil {
+		var prev Entry
+		if json.Unmarshal(data, &prev) == nil && string(dayKey(prev.Timestamp, prev.ID)) != string(dayKey(entry.Timestamp, entry.ID)) {
+			if err := k.Delete(dayKey(prev.Timestamp, prev.ID)); err != nil {
+				return err
+			}
+		}
+	}
+	return c.putEntry(k, entry)
+}
+
+// removeEntry deletes an entry and its day index key.
+func removeEntry(k *kv.KV, id string) error {
+	if data, err := k.Get(entryKey(id)); err == nil {
+		var prev Entry
+		if json.Unmarshal(data, &prev) == nil {
+			if err := k.Delete(dayKey(prev.Timestamp, id)); err != nil {
+				return err
+			}
+		}
+	}
+	return k.Delete(entryKey(id))
+}
+
+// entryKeysBetween picks the entry keys that need fetching for a query
+// limited to since and until (either may be nil). The index is only trusted
+// when every entry has exactly one index key; entries synced from older
+// builds have none, and then every entry key is returned.
+func entryKeysBetween(keys [][]byte, since, until *time.Time) [][]byte {
+	var entryKeys [][]byte
+	for _, key := range keys {
+		if strings.HasPrefix(string(key), EntryPrefix) {
+			entryKeys = append(entryKeys, key)
+		}
+	}
+	if since == nil && until == nil {
+		return entryKeys
+	}
+
+	days := make(map[string]string)
+	for _, key := range keys {
+		day, id, ok := parseDayKey(string(key))
+		if !ok {
+			continue
+		}
+		if _, dup := days[id]; dup {
+			return entryKeys
+		}
+		days[id] = day
+	}
+
+	var from, to string
+	if since != nil {
+		from = since.UTC().Format(time.DateOnly)
+	}
+	if until != nil {
+		to = until.UTC().Format(time.DateOnly)
+	}
+
+	var matched [][]byte
+	for _, key := range entryKeys {
+		day, ok := days[strings.TrimPrefix(string(key), EntryPrefix)]
+		if !ok {
+			return entryKeys
+		}
+		if (from != "" && day < from) || (to != "" && day > to) {
+			continue
+		}
+		matched = append(matched, key)
+	}
+	return matched
+}
+
+// RebuildDayIndex rewrites the day index from the stored entries, adding
+// missing keys and removing stale ones. Returns how many keys changed.
+func (c *Client) RebuildDayIndex() (int, error) {
+	changed := 0
+	err := c.write(func(k *kv.KV) error {
+		keys, err := k.Keys()
+		if err != nil {
+			return fmt.Errorf("get keys: %w", err)
+		}
+
+		indexed := make(map[string]bool)
+		for _, key := range keys {
+			if _, _, ok := parseDayKey(string(key)); ok {
+				indexed[string(key)] = true
+			}
+		}
+
+		want := make(map[string]bool)
+		if err := eachEntry(k, func(entry *Entry) error {
+			key := string(dayKey(entry.Timestamp, entry.ID))
+			want[key] = true
+			if indexed[key] {
+				return nil
+			}
+			changed++
+			return k.Set([]byte(key), []byte{})
+		}); err != nil {
+			return err
+		}
+
+		for key := range indexed {
+			if want[key] {
+				continue
+			}
+			if err := k.Delete([]byte(key)); err != nil {
+				return err
+			}
+			changed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("rebuild day index: %w", err)
+	}
+	if changed > 0 {
+		c.syncAfterWrite(writeChange)
+	}
+	return changed, nil
+}
@@ -0,0 +1,68 @@
+// ABOUTME: Tests for the per-day entry index
+// ABOUTME: Covers key parsing and choosing which entries a date range must fetch
+package charm
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDayKeyRoundTrip(t *testing.T) {
+	ts := time.Date(2025, 11, 29, 23, 30, 0, 0, time.FixedZone("PST", -8*3600))
+	key := string(dayKey(ts, "abc:def"))
+	if key != "day:2025-11-30:abc:def" {
+		t.Errorf("dayKey() = %q, want the UTC day", key)
+	}
+
+	day, id, ok := parseDayKey(key)
+	if !ok || day != "2025-11-30" || id != "abc:def" {
+		t.Errorf("parseDayKey(%q) = %q, %q, %v", key, day, id, ok)
+	}
+	for _, bad := range []string{"entry:x", "day:2025-11-30", "day:2025-11-30x", "day:2025-11-30:"} {
+		if _, _, ok := parseDayKey(bad); ok {
+			t.Errorf("parseDayKey(%q) ok, want not ok", bad)
+		}
+	}
+}
+
+func TestEntryKeysBetween(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2025, 11, n, 12, 0, 0, 0, time.UTC) }
+	indexed := [][]byte{
+		entryKey("a"), dayKey(day(1), "a"),
+		entryKey("b"), dayKey(day(2), "b"),
+		entryKey("c"), dayKey(day(3), "c"),
+		[]byte("project:p1"),
+	}
+	ids := func(keys [][]byte) string {
+		var out []string
+		for _, k := range keys {
+			out = append(out, strings.TrimPrefix(string(k), EntryPrefix))
+		}
+		sort.Strings(out)
+		return strings.Join(out, ",")
+	}
+	since, until := day(2), day(2).Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		keys         [][]byte
+		since, until *time.Time
+		want         string
+	}{
+		{"no range", indexed, nil, nil, "a,b,c"},
+		{"since", indexed, &since, nil, "b,c"},
+		{"until", indexed, nil, &until, "a,b"},
+		{"since and until", indexed, &since, &until, "b"},
+		{"unindexed entry falls back", append(indexed, entryKey("d")), &since, &until, "a,b,c,d"},
+		{"duplicate index falls back", append(indexed, dayKey(day(9), "a")), &since, &until, "a,b,c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(entryKeysBetween(tt.keys, tt.since, tt.until)); got != tt.want {
+				t.Errorf("entryKeysBetween() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// ABOUTME: Device management for the Charm account that syncs chronicle
+// ABOUTME: Each linked SSH key is a device; local names live in the config
+
+package charm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/charm/client"
+	charmproto "github.com/charmbracelet/charm/proto"
+)
+
+// minFingerprintPrefix is the shortest fingerprint prefix accepted as a device reference.
+const minFingerprintPrefix = 6
+
+// Device is one SSH key linked to the Charm account.
+type Device struct {
+	Index       int        `json:"index"`
+	Name        string     `json:"name,omitempty"`
+	Fingerprint string     `json:"fingerprint"`
+	Key         string     `json:"key"`
+	LinkedAt    *time.Time `json:"linked_at,omitempty"`
+	Current     bool       `json:"current"`
+}
+
+// Devices lists the devices linked to this Charm account. The server
+// doesn't track when a key was last used, so only the link time is known.
+func (c *Client) Devices() ([]Device, error) {
+	cc, err := client.NewClientWithDefaults()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := cc.AuthorizedKeysWithMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("list linked keys: %w", err)
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return buildDevices(keys, cfg.DeviceNames), nil
+}
+
+// RevokeDevice unlinks a device's key from the Charm account.
+func (c *Client) RevokeDevice(device *Device) error {
+	cc, err := client.NewClientWithDefaults()
+	if err != nil {
+		return err
+	}
+	if err := cc.UnlinkAuthorizedKey(device.Key); err != nil {
+		return fmt.Errorf("unlink key: %w", err)
+	}
+
+	// Forget the local name along with the key
+	cfg, err := LoadConfigFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.DeviceNames[device.Fingerprint]; ok {
+		delete(cfg.DeviceNames, device.Fingerprint)
+		return SaveConfig(cfg)
+	}
+	return nil
+}
+
+// RenameDevice sets the local name shown for a device. An empty name clears it.
+func (c *Client) RenameDevice(device *Device, name string) error {
+	cfg, err := LoadConfigFile()
+	if err != nil {
+		return err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		delete(cfg.DeviceNames, device.Fingerprint)
+	} else {
+		if cfg.DeviceNames == nil {
+			cfg.DeviceNames = make(map[string]string)
+		}
+		cfg.DeviceNames[device.Fingerprint] = name
+	}
+	return SaveConfig(cfg)
+}
+
+// buildDevices turns the server's key list into numbered devices.
+func buildDevices(keys *charmproto.Keys, names map[string]string) []Device {
+	if keys == nil {
+		return nil
+	}
+	devices := make([]Device, 0, len(keys.Keys))
+	for i, key := range keys.Keys {
+		sha := key.Sha()
+		devices = append(devices, Device{
+			Index:       i + 1,
+			Name:        names[sha],
+			Fingerprint: sha,
+			Key:         key.Key,
+			LinkedAt:    key.CreatedAt,
+			Current:     i == keys.ActiveKey,
+		})
+	}
+	return devices
+}
+
+// FindDevice resolves ref as a list index, a device name (case-insensitive),
+// or a fingerprint prefix.
+func FindDevice(devices []Device, ref string) (*Device, error) {
+	ref = strings.TrimSpace(ref)
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(devices) {
+			return nil, fmt.Errorf("no device #%d (have %d)", n, len(devices))
+		}
+		return &devices[n-1], nil
+	}
+
+	for i := range devices {
+		if devices[i].Name != "" && strings.EqualFold(devices[i].Name, ref) {
+			return &devices[i], nil
+		}
+	}
+
+	if len(ref) < minFingerprintPrefix {
+		return nil, fmt.Errorf("no device named %q", ref)
+	}
+	var match *Device
+	for i := range devices {
+		if strings.HasPrefix(devices[i].Fingerprint, strings.ToLower(ref)) {
+			if match != nil {
+				return nil, fmt.Errorf("fingerprint prefix %q matches more than one device", ref)
+			}
+			match = &devices[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no device matching %q", ref)
+	}
+	return match, nil
+}
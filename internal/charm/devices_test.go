@@ -0,0 +1,67 @@
+// ABOUTME: Unit tests for linked device listing and lookup
+// ABOUTME: Builds key lists in memory without a Charm server
+package charm
+
+import (
+	"testing"
+
+	charmproto "github.com/charmbracelet/charm/proto"
+)
+
+func testDevices() []Device {
+	keys := &charmproto.Keys{
+		ActiveKey: 1,
+		Keys: []*charmproto.PublicKey{
+			{Key: "ssh-ed25519 AAAA-work"},
+			{Key: "ssh-ed25519 AAAA-laptop"},
+		},
+	}
+	work := keys.Keys[0].Sha()
+	return buildDevices(keys, map[string]string{work: "Work Desktop"})
+}
+
+func TestBuildDevices(t *testing.T) {
+	devices := testDevices()
+	if len(devices) != 2 {
+		t.Fatalf("got %d devices, want 2", len(devices))
+	}
+	if devices[0].Index != 1 || devices[0].Name != "Work Desktop" || devices[0].Current {
+		t.Errorf("unexpected first device: %+v", devices[0])
+	}
+	if !devices[1].Current || devices[1].Name != "" {
+		t.Errorf("expected second device to be current and unnamed: %+v", devices[1])
+	}
+}
+
+func TestFindDevice(t *testing.T) {
+	devices := testDevices()
+
+	tests := []struct {
+		ref     string
+		want    int
+		wantErr bool
+	}{
+		{ref: "2", want: 2},
+		{ref: "work desktop", want: 1},
+		{ref: devices[1].Fingerprint[:8], want: 2},
+		{ref: "3", wantErr: true},
+		{ref: "abc", wantErr: true},
+		{ref: "ffffffffff", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := FindDevice(devices, tt.ref)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("FindDevice(%q): expected error", tt.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FindDevice(%q): %v", tt.ref, err)
+			continue
+		}
+		if got.Index != tt.want {
+			t.Errorf("FindDevice(%q) = #%d, want #%d", tt.ref, got.Index, tt.want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// ABOUTME: Validation of the global charm.json for chronicle config doctor
+// ABOUTME: Reports unknown keys, bad values, and conflicting settings with line numbers
+
+package charm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/harper/chronicle/internal/config"
+)
+
+// CheckConfig validates the config file at ConfigPath. Environment
+// overrides are not applied. A missing file has no problems.
+func CheckConfig() ([]config.Diagnostic, error) {
+	path := ConfigPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return checkConfigData(path, data), nil
+}
+
+// checkConfigData validates data, the contents of the config file at path.
+func checkConfigData(path string, data []byte) []config.Diagnostic {
+	var diags []config.Diagnostic
+	report := func(key, format string, args ...any) {
+		diags = append(diags, config.Diagnostic{File: path, Line: config.KeyLine(data, key), Message: fmt.Sprintf(format, args...)})
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return []config.Diagnostic{jsonDiagnostic(path, data, err)}
+	}
+	cfg := DefaultConfig()
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return []config.Diagnostic{jsonDiagnostic(path, data, err)}
+	}
+
+	for _, key := range unknownKeys(raw, reflect.TypeOf(Config{})) {
+		if note, ok := retiredKeys[key]; ok {
+			report(key, "%s", note)
+			continue
+		}
+		report(key, "unknown key %q", key)
+	}
+	for _, section := range []struct {
+		name string
+		t    reflect.Type
+	}{
+		{"hooks", reflect.TypeOf(Hooks{})},
+		{"shell_capture", reflect.TypeOf(config.ShellCapture{})},
+		{"caldav", reflect.TypeOf(config.CalDAV{})},
+		{"vault", reflect.TypeOf(config.Vault{})},
+		{"desktop_notifications", reflect.TypeOf(config.DesktopNotifications{})},
+		{"auto_tag", reflect.TypeOf(config.AutoTag{})},
+		{"transcription", reflect.TypeOf(config.Transcription{})},
+		{"time_tracking", reflect.TypeOf(config.TimeTracking{})},
+	} {
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(raw[section.name], &fields) != nil {
+			continue
+		}
+		for _, key := range unknownKeys(fields, section.t) {
+			report(key, "unknown key %q in %s", key, section.name)
+		}
+	}
+	for _, list := range []struct {
+		name string
+		t    reflect.Type
+	}{
+		{"tag_rules", reflect.TypeOf(config.TagRule{})},
+		{"privacy_rules", reflect.TypeOf(config.PrivacyRule{})},
+		{"webhooks", reflect.TypeOf(Webhook{})},
+		{"notifications", reflect.TypeOf(config.Notification{})},
+	} {
+		var rules []map[string]json.RawMessage
+		if json.Unmarshal(raw[list.name], &rules) != nil {
+			continue
+		}
+		for i, rule := range rules {
+			for _, key := range unknownKeys(rule, list.t) {
+				report(key, "unknown key %q in %s[%d]", key, list.name, i)
+			}
+		}
+	}
+	var inbound map[string]map[string]json.RawMessage
+	if json.Unmarshal(raw["inbound_hooks"], &inbound) == nil {
+		for name, hook := range inbound {
+			for _, key := range unknownKeys(hook, reflect.TypeOf(config.InboundHook{})) {
+				report(key, "unknown key %q in inbound_hooks.%s", key, name)
+			}
+		}
+	}
+
+	if !ValidSyncPolicy(cfg.AutoSyncPolicy) {
+		report("auto_sync_policy", "auto_sync_policy %q is not one of %s", cfg.AutoSyncPolicy, strings.Join(SyncPolicies, ", "))
+	}
+	if cfg.AutoSyncPolicy == SyncPolicyEveryN && cfg.AutoSyncMaxPending <= 0 {
+		report("auto_sync_policy", "auto_sync_policy %s needs auto_sync_max_pending set", SyncPolicyEveryN)
+	}
+	if !cfg.AutoSync && cfg.AutoSyncPolicy != "" && cfg.AutoSyncPolicy != SyncPolicyNever {
+		report("auto_sync_policy", "auto_sync_policy is ignored because auto_sync is false")
+	}
+	switch cfg.OutputFormat {
+	case "", OutputTable, OutputJSON:
+	default:
+		report("output_format", "output_format %q is not one of %s, %s", cfg.OutputFormat, OutputTable, OutputJSON)
+	}
+	if _, err := config.ParseWeekday(cfg.WeekStartsOn); err != nil {
+		report("week_starts_on", "%v", err)
+	}
+	if _, err := config.DateLayout(cfg.DateFormat); err != nil {
+		report("date_format", "%v", err)
+	}
+	if err := ValidateProfile(cfg.Profile); err != nil {
+		report("profile", "%v", err)
+	}
+	if cfg.MCPReadOnly && cfg.MCPRequireConfirmation {
+		report("mcp_require_confirmation", "mcp_require_confirmation has no effect because mcp_read_only leaves out the tools it confirms")
+	}
+	if _, err := config.CompileTagRules(cfg.TagRules); err != nil {
+		report("tag_rules", "%v", err)
+	}
+	if _, err := config.CompilePrivacyRules(cfg.PrivacyRules); err != nil {
+		report("privacy_rules", "%v", err)
+	}
+	if err := ValidateWebhooks(cfg.Webhooks); err != nil {
+		report("webhooks", "%v", err)
+	}
+	if err := ValidateNotifications(cfg.Notifications); err != nil {
+		report("notifications", "%v", err)
+	}
+	if _, err := config.CompileInboundHooks(cfg.InboundHooks); err != nil {
+		report("inbound_hooks", "invalid inbound_hooks: %v", err)
+	}
+	if _, err := config.CompileShellCapture(cfg.ShellCapture); err != nil {
+		report("shell_capture", "invalid shell_capture: %v", err)
+	}
+	if cfg.CalDAV != nil {
+		if err := cfg.CalDAV.Validate(); err != nil {
+			report("caldav", "invalid caldav: %v", err)
+		}
+	}
+	if cfg.Vault != nil {
+		if err := cfg.Vault.Validate(); err != nil {
+			report("vault", "invalid vault: %v", err)
+		}
+	}
+	if cfg.DesktopNotifications != nil {
+		for _, t := range cfg.DesktopNotifications.Types {
+			if !ValidEntryType(t) {
+				report("desktop_notifications", "desktop_notifications: unknown entry type %q (valid: %v)", t, EntryTypes)
+			}
+		}
+	}
+	if cfg.AutoTag != nil {
+		if err := cfg.AutoTag.Validate(); err != nil {
+			report("auto_tag", "invalid auto_tag: %v", err)
+		}
+	}
+	if cfg.Transcription != nil {
+		if err := cfg.Transcription.Validate(); err != nil {
+			report("transcription", "invalid transcription: %v", err)
+		}
+	}
+	if cfg.TimeTracking != nil {
+		if err := cfg.TimeTracking.Validate(); err != nil {
+			report("time_tracking", "invalid time_tracking: %v", err)
+		}
+	}
+	if _, err := config.NewRedactor(cfg.RedactSecrets, nil); err != nil {
+		report("redact_secrets", "%v", err)
+	}
+	if _, err := config.NewRedactor("", cfg.RedactPatterns); err != nil {
+		report("redact_patterns", "%v", err)
+	}
+	return diags
+}
+
+// jsonDiagnostic describes a JSON decoding error, at its line if known.
+func jsonDiagnostic(path string, data []byte, err error) config.Diagnostic {
+	d := config.Diagnostic{File: path, Message: err.Error()}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		d.Line = config.OffsetLine(data, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		d.Line = config.OffsetLine(data, typeErr.Offset)
+		d.Message = fmt.Sprintf("%s should be %s, not %s", typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	return d
+}
+
+// retiredKeys are keys older versions wrote into charm.json themselves,
+// with a note on where that state went.
+var retiredKeys = map[string]string{
+	"offline": `"offline" is no longer read; the offline state lives in $XDG_STATE_HOME/chronicle, so the key can be removed`,
+}
+
+// unknownKeys returns the keys of raw that aren't json fields of t, sorted.
+func unknownKeys(raw map[string]json.RawMessage, t reflect.Type) []string {
+	known := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		known[name] = true
+	}
+	var unknown []string
+	for key := range raw {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
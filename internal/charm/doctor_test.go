@@ -0,0 +1,56 @@
+// ABOUTME: Tests for charm.json validation
+// ABOUTME: Checks unknown keys, bad values, conflicts, and reported lines
+package charm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckConfigData(t *testing.T) {
+	tests := []struct {
+		name, content string
+		line          int
+		want          string
+	}{
+		{"unknown key", "{\n  \"auto_sync\": true,\n  \"charm_hots\": \"x\"\n}", 3, `unknown key "charm_hots"`},
+		{"retired offline key", "{\n  \"offline\": true\n}", 2, "$XDG_STATE_HOME/chronicle"},
+		{"unknown hook", "{\n  \"hooks\": {\n    \"pre-sync\": \"true\"\n  }\n}", 3, "in hooks"},
+		{"bad policy", "{\n  \"auto_sync_policy\": \"sometimes\"\n}", 2, `auto_sync_policy "sometimes"`},
+		{"every_n without max", "{\"auto_sync_policy\": \"every_n\"}", 1, "auto_sync_max_pending"},
+		{"policy with auto_sync off", "{\"auto_sync\": false, \"auto_sync_policy\": \"on_add\"}", 1, "auto_sync is false"},
+		{"bad output format", "{\n\"output_format\": \"yaml\"\n}", 2, "output_format"},
+		{"bad week start", "{\n\"week_starts_on\": \"funday\"\n}", 2, "week_starts_on"},
+		{"bad date format", "{\n\"date_format\": \"yyyy\"\n}", 2, "date_format"},
+		{"bad shell capture", "{\n\"shell_capture\": {\"threshold\": 5, \"deny\": [\"(\"]}\n}", 2, "deny pattern"},
+		{"bad webhook", "{\n\"webhooks\": [{\"url\": \"example.com\"}]\n}", 2, "webhooks[0]"},
+		{"bad notification", "{\n\"notifications\": [{\"service\": \"slack\", \"url\": \"https://hooks.slack.com/x\", \"types\": [\"deploy\"]}]\n}", 2, "unknown entry type"},
+		{"bad caldav", "{\n\"caldav\": {\"url\": \"https://dav.example.com/cal/\", \"component\": \"vtodo\"}\n}", 2, "component \"vtodo\""},
+		{"bad vault", "{\n\"vault\": {\"path\": \"~/notes\", \"format\": \"roam\"}\n}", 2, "format \"roam\""},
+		{"bad desktop notification type", "{\n\"desktop_notifications\": {\"sync\": true, \"types\": [\"idea\"]}\n}", 2, "unknown entry type \"idea\""},
+		{"unknown desktop notification key", "{\"desktop_notifications\": {\n\"snyc\": true}}", 2, `unknown key "snyc" in desktop_notifications`},
+		{"auto tag without model", "{\n\"auto_tag\": {\"url\": \"http://localhost:11434/v1\"}\n}", 2, "invalid auto_tag: model is required"},
+		{"unknown auto tag key", "{\"auto_tag\": {\"url\": \"http://localhost:11434/v1\", \"model\": \"llama3.2\",\n\"timeout\": 2}}", 2, `unknown key "timeout" in auto_tag`},
+		{"bad transcription language", "{\"transcription\": {\"url\": \"https://api.openai.com/v1\",\n\"language\": \"english\"}}", 1, "two-letter"},
+		{"unknown time tracking key", "{\"time_tracking\": {\n\"project\": {}}}", 2, `unknown key "project" in time_tracking`},
+		{"bad inbound hook path", "{\n\"inbound_hooks\": {\"ci\": {\"message\": \"$.build[\"}}}", 2, "invalid inbound_hooks: hook ci: message"},
+		{"unknown inbound hook key", "{\"inbound_hooks\": {\"ci\": {\"message\": \"$.title\",\n\"tag\": \"ci\"}}}", 2, `unknown key "tag" in inbound_hooks.ci`},
+		{"wrong type", "{\n  \"auto_sync\": \"yes\"\n}", 2, "auto_sync should be bool"},
+		{"syntax error", "{\n  \"auto_sync\": true,\n}", 3, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := checkConfigData("charm.json", []byte(tt.content))
+			if len(diags) != 1 {
+				t.Fatalf("got %v, want one diagnostic", diags)
+			}
+			if diags[0].Line != tt.line || !strings.Contains(diags[0].Message, tt.want) {
+				t.Errorf("got %s, want line %d containing %q", diags[0], tt.line, tt.want)
+			}
+		})
+	}
+
+	if diags := checkConfigData("charm.json", []byte(`{"auto_sync": true, "hooks": {"post_sync": "true"}}`)); len(diags) != 0 {
+		t.Errorf("valid config: got %v, want none", diags)
+	}
+}
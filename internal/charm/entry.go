@@ -4,23 +4,127 @@ package charm
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/charm/kv"
 	"github.com/google/uuid"
+	"github.com/harper/chronicle/internal/config"
 )
 
+// Entry types classify what kind of record an entry is.
+const (
+	EntryTypeNote      = "note"
+	EntryTypeDecision  = "decision"
+	EntryTypeTodo      = "todo"
+	EntryTypeMilestone = "milestone"
+)
+
+// EntryTypes lists every valid entry type.
+var EntryTypes = []string{EntryTypeNote, EntryTypeDecision, EntryTypeTodo, EntryTypeMilestone}
+
 // Entry represents a chronicle log entry.
 type Entry struct {
 	ID               string    `json:"id"`
 	Timestamp        time.Time `json:"timestamp"`
 	Message          string    `json:"message"`
+	Type             string    `json:"type,omitempty"`
 	Hostname         string    `json:"hostname"`
 	Username         string    `json:"username"`
 	WorkingDirectory string    `json:"working_directory"`
+	ProjectID        string    `json:"project_id,omitempty"`
 	Tags             []string  `json:"tags"`
+	Source           string    `json:"source,omitempty"`
+	Pinned           bool      `json:"pinned,omitempty"`
+	Recurrence       string    `json:"recurrence,omitempty"`
+	Checksum         string    `json:"checksum,omitempty"`
+
+	// Metadata holds structured fields for typed entries, such as a
+	// decision's rationale. Text search matches its values too.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Kind returns the entry type, treating entries written before types existed as notes.
+func (e *Entry) Kind() string {
+	if e.Type == "" {
+		return EntryTypeNote
+	}
+	return e.Type
+}
+
+// ValidEntryType reports whether t is a known entry type.
+func ValidEntryType(t string) bool {
+	for _, known := range EntryTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Entry sources record how an entry was created.
+const (
+	SourceCLI     = "cli"
+	SourceMCP     = "mcp"
+	SourceImport  = "import"
+	SourceGitHook = "git-hook"
+	SourceAPI     = "api"
+	SourceShell   = "shell"
+	SourceWebhook = "webhook"
+)
+
+// Sources lists every valid entry source.
+var Sources = []string{SourceCLI, SourceMCP, SourceImport, SourceGitHook, SourceAPI, SourceShell, SourceWebhook}
+
+// ValidSource reports whether s is empty (unknown) or a known source.
+func ValidSource(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, known := range Sources {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Recurrence rules for pinned standing-context entries.
+const (
+	RecurrenceDaily    = "daily"
+	RecurrenceWeekdays = "weekdays"
+	RecurrenceWeekly   = "weekly"
+	RecurrenceMonthly  = "monthly"
+)
+
+// Recurrences lists every valid recurrence rule.
+var Recurrences = []string{RecurrenceDaily, RecurrenceWeekdays, RecurrenceWeekly, RecurrenceMonthly}
+
+// ValidRecurrence reports whether r is empty or a known recurrence rule.
+func ValidRecurrence(r string) bool {
+	if r == "" {
+		return true
+	}
+	for _, known := range Recurrences {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePinning checks the pinned/recurrence fields are consistent.
+func validatePinning(entry *Entry) error {
+	if !ValidRecurrence(entry.Recurrence) {
+		return fmt.Errorf("invalid recurrence %q (valid: %v)", entry.Recurrence, Recurrences)
+	}
+	if entry.Recurrence != "" && !entry.Pinned {
+		return fmt.Errorf("recurrence requires a pinned entry")
+	}
+	return nil
 }
 
 // entryKey returns the KV key for an entry.
@@ -30,6 +134,82 @@ func entryKey(id string) []byte {
 
 // CreateEntry creates a new entry and returns its ID.
 func (c *Client) CreateEntry(entry Entry) (string, error) {
+	if err := c.prepareEntry(&entry); err != nil {
+		return "", err
+	}
+
+	_, err := c.storeOrQueue([]Entry{entry}, func() error {
+		return c.doWrite(writeAdd, func(k *kv.KV) error {
+			return c.putEntry(k, &entry)
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("create entry: %w", err)
+	}
+
+	countCreated(entry)
+	c.notifyWebhooks(EventCreated, &entry)
+	c.notifyChat(&entry)
+	return entry.ID, nil
+}
+
+// createBatchSize is how many entries CreateEntries writes per connection,
+// so a large import doesn't hold the database lock for its whole duration.
+const createBatchSize = 500
+
+// CreateEntries stores many entries in chunks with at most one sync at the
+// end, which keeps bulk imports fast. All entries are validated before
+// anything is written. Returns the IDs in input order.
+func (c *Client) CreateEntries(entries []Entry) ([]string, error) {
+	prepared := make([]Entry, len(entries))
+	for i, entry := range entries {
+		if err := c.prepareEntry(&entry); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		prepared[i] = entry
+	}
+
+	if len(prepared) == 0 {
+		return []string{}, nil
+	}
+
+	ids := make([]string, 0, len(prepared))
+	for start := 0; start < len(prepared); start += createBatchSize {
+		chunk := prepared[start:min(start+createBatchSize, len(prepared))]
+		// If the server is unreachable, everything not yet stored is queued
+		queued, err := c.storeOrQueue(prepared[start:], func() error {
+			return c.write(func(k *kv.KV) error {
+				for i := range chunk {
+					if err := c.putEntry(k, &chunk[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create entries (%d of %d stored): %w", len(ids), len(prepared), err)
+		}
+		if queued {
+			for _, entry := range prepared[start:] {
+				ids = append(ids, entry.ID)
+			}
+			countCreated(prepared...)
+			return ids, nil
+		}
+		for _, entry := range chunk {
+			ids = append(ids, entry.ID)
+		}
+	}
+
+	countCreated(prepared...)
+	c.syncAfterWrite(writeAdd)
+	return ids, nil
+}
+
+// prepareEntry fills in defaults for a new entry, validates its type,
+// normalizes its tags if enabled, and canonicalizes its working directory.
+func (c *Client) prepareEntry(entry *Entry) error {
 	// Generate UUID if not provided
 	if entry.ID == "" {
 		entry.ID = uuid.New().String()
@@ -40,19 +220,40 @@ func (c *Client) CreateEntry(entry Entry) (string, error) {
 		entry.Timestamp = time.Now()
 	}
 
-	key := entryKey(entry.ID)
-	if err := c.SetJSON(key, entry); err != nil {
-		return "", fmt.Errorf("create entry: %w", err)
+	// Default type if not provided
+	if entry.Type == "" {
+		entry.Type = EntryTypeNote
+	} else if !ValidEntryType(entry.Type) {
+		return fmt.Errorf("invalid entry type %q", entry.Type)
+	}
+	if !ValidSource(entry.Source) {
+		return fmt.Errorf("invalid entry source %q", entry.Source)
+	}
+	if err := validatePinning(entry); err != nil {
+		return err
 	}
 
-	return entry.ID, nil
+	if c.normalizeTags {
+		entry.Tags = NormalizeTags(entry.Tags)
+	}
+	entry.WorkingDirectory = config.CanonicalDir(entry.WorkingDirectory, c.homeRelativeDirs)
+	entry.Checksum = entry.ComputeChecksum()
+	return nil
+}
+
+// IsNotFound reports whether err means a requested entry or key doesn't exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, kv.ErrMissingKey)
 }
 
 // GetEntry retrieves an entry by ID.
 func (c *Client) GetEntry(id string) (*Entry, error) {
-	key := entryKey(id)
+	data, err := c.Get(entryKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("get entry: %w", err)
+	}
 	var entry Entry
-	if err := c.GetJSON(key, &entry); err != nil {
+	if err := json.Unmarshal(data, &entry); err != nil {
 		return nil, fmt.Errorf("get entry: %w", err)
 	}
 	return &entry, nil
@@ -63,78 +264,144 @@ func (c *Client) UpdateEntry(entry Entry) error {
 	if entry.ID == "" {
 		return fmt.Errorf("entry ID required")
 	}
-	key := entryKey(entry.ID)
-	if err := c.SetJSON(key, entry); err != nil {
+	if entry.Type != "" && !ValidEntryType(entry.Type) {
+		return fmt.Errorf("invalid entry type %q", entry.Type)
+	}
+	if err := validatePinning(&entry); err != nil {
+		return err
+	}
+	if c.normalizeTags {
+		entry.Tags = NormalizeTags(entry.Tags)
+	}
+	entry.Checksum = entry.ComputeChecksum()
+	err := c.Do(func(k *kv.KV) error {
+		return c.replaceEntry(k, &entry)
+	})
+	if err != nil {
 		return fmt.Errorf("update entry: %w", err)
 	}
+	c.notifyWebhooks(EventUpdated, &entry)
 	return nil
 }
 
-// DeleteEntry removes an entry by ID.
+// DeleteEntry removes an entry by ID along with any links touching it.
 func (c *Client) DeleteEntry(id string) error {
-	key := entryKey(id)
-	if err := c.Delete(key); err != nil {
+	// Webhooks are sent the entry as it was
+	var deleted *Entry
+	if len(c.webhooks) > 0 {
+		deleted, _ = c.GetEntry(id)
+	}
+	err := c.Do(func(k *kv.KV) error {
+		if err := removeEntry(k, id); err != nil {
+			return err
+		}
+		return deleteLinksFor(k, id)
+	})
+	if err != nil {
 		return fmt.Errorf("delete entry: %w", err)
 	}
+	if deleted != nil {
+		c.notifyWebhooks(EventDeleted, deleted)
+	}
 	return nil
 }
 
+// SetPinned pins or unpins an entry. Unpinning clears any recurrence rule.
+func (c *Client) SetPinned(id string, pinned bool, recurrence string) error {
+	entry, err := c.GetEntry(id)
+	if err != nil {
+		return err
+	}
+	entry.Pinned = pinned
+	entry.Recurrence = recurrence
+	if !pinned {
+		entry.Recurrence = ""
+	}
+	return c.UpdateEntry(*entry)
+}
+
+// PinnedEntries returns all pinned entries, newest first.
+func (c *Client) PinnedEntries() ([]Entry, error) {
+	return c.SearchEntries(&SearchFilter{PinnedOnly: true}, 0)
+}
+
 // ListEntries returns entries, ordered by timestamp descending.
 func (c *Client) ListEntries(limit int) ([]Entry, error) {
 	return c.SearchEntries(nil, limit)
 }
 
-// SearchFilter defines search criteria.
-type SearchFilter struct {
-	Text  string
-	Tags  []string
-	Since *time.Time
-	Until *time.Time
+// ListEntriesPage returns up to limit entries strictly older than the cursor
+// (afterTimestamp, afterID), newest first. Entries with equal timestamps are
+// ordered by ID descending so pages never skip or repeat entries. A zero
+// afterTimestamp starts from the newest entry.
+func (c *Client) ListEntriesPage(limit int, afterTimestamp time.Time, afterID string) ([]Entry, error) {
+	return c.SearchEntriesPage(nil, limit, afterTimestamp, afterID)
 }
 
-// SearchEntries returns entries matching the filter.
-func (c *Client) SearchEntries(filter *SearchFilter, limit int) ([]Entry, error) {
+// SearchEntriesPage is ListEntriesPage for entries matching filter (nil for all).
+func (c *Client) SearchEntriesPage(filter *SearchFilter, limit int, afterTimestamp time.Time, afterID string) ([]Entry, error) {
 	var entries []Entry
 
-	// Use DoReadOnly for batch read operation
-	err := c.DoReadOnly(func(k *kv.KV) error {
-		// Get all keys from the KV store
-		keys, err := k.Keys()
-		if err != nil {
-			return fmt.Errorf("get keys: %w", err)
+	err := c.IterateEntries(filter, func(entry *Entry) error {
+		entries = append(entries, *entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pageEntries(entries, limit, afterTimestamp, afterID), nil
+}
+
+// pageEntries applies the keyset cursor, ordering, and limit to entries.
+func pageEntries(entries []Entry, limit int, afterTimestamp time.Time, afterID string) []Entry {
+	page := entries[:0]
+	for i := range entries {
+		if afterTimestamp.IsZero() || entryBefore(&entries[i], afterTimestamp, afterID) {
+			page = append(page, entries[i])
 		}
+	}
 
-		// Filter keys by entry prefix and fetch matching entries
-		prefix := []byte(EntryPrefix)
-		for _, key := range keys {
-			// Skip keys that don't have the entry prefix
-			if !strings.HasPrefix(string(key), string(prefix)) {
-				continue
-			}
+	sort.Slice(page, func(i, j int) bool {
+		return entryBefore(&page[j], page[i].Timestamp, page[i].ID)
+	})
+	if limit > 0 && len(page) > limit {
+		page = page[:limit]
+	}
+	return page
+}
 
-			// Fetch the entry value
-			val, err := k.Get(key)
-			if err != nil {
-				// Skip entries that can't be fetched
-				continue
-			}
+// entryBefore reports whether entry sorts after the (ts, id) cursor in
+// newest-first order.
+func entryBefore(entry *Entry, ts time.Time, id string) bool {
+	if !entry.Timestamp.Equal(ts) {
+		return entry.Timestamp.Before(ts)
+	}
+	return entry.ID < id
+}
 
-			// Unmarshal the entry
-			var entry Entry
-			if err := json.Unmarshal(val, &entry); err != nil {
-				// Skip invalid entries (corrupted data)
-				continue
-			}
+// SearchFilter defines search criteria.
+type SearchFilter struct {
+	Text       string
+	Tags       []string
+	Type       string
+	ProjectID  string
+	Source     string
+	PinnedOnly bool
+	Since      *time.Time
+	Until      *time.Time
 
-			// Apply filter
-			if matchesFilter(&entry, filter) {
-				entries = append(entries, entry)
-			}
-		}
+	// Directory limits results to entries recorded in it or below it
+	Directory string
+}
+
+// SearchEntries returns entries matching the filter.
+func (c *Client) SearchEntries(filter *SearchFilter, limit int) ([]Entry, error) {
+	var entries []Entry
 
+	err := c.IterateEntries(filter, func(entry *Entry) error {
+		entries = append(entries, *entry)
 		return nil
 	})
-
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +417,122 @@ func (c *Client) SearchEntries(filter *SearchFilter, limit int) ([]Entry, error)
 	return entries, nil
 }
 
+// IterateEntries calls fn for each visible entry matching filter (nil for
+// all), in no particular order, without collecting them. Memory use stays
+// constant however large the journal is. Returning an error from fn stops
+// the iteration and is returned as-is. fn runs inside a read-only
+// connection, so it must not write to the database.
+func (c *Client) IterateEntries(filter *SearchFilter, fn func(entry *Entry) error) error {
+	var since, until *time.Time
+	if filter != nil {
+		since, until = filter.Since, filter.Until
+	}
+	return c.DoReadOnly(func(k *kv.KV) error {
+		return c.eachVisibleEntry(k, since, until, func(entry *Entry) error {
+			if !matchesFilter(entry, filter) {
+				return nil
+			}
+			return fn(entry)
+		})
+	})
+}
+
+// CountEntries returns how many visible entries match filter (nil for all).
+// Without a filter or ignored hosts only the keys are counted, so no entry
+// is fetched or decoded.
+func (c *Client) CountEntries(filter *SearchFilter) (int, error) {
+	if filter == nil && len(c.ignoredHosts) == 0 {
+		count := 0
+		err := c.DoReadOnly(func(k *kv.KV) error {
+			keys, err := k.Keys()
+			if err != nil {
+				return fmt.Errorf("get keys: %w", err)
+			}
+			for _, key := range keys {
+				if strings.HasPrefix(string(key), EntryPrefix) {
+					count++
+				}
+			}
+			return nil
+		})
+		return count, err
+	}
+
+	count := 0
+	err := c.IterateEntries(filter, func(*Entry) error {
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// eachEntry calls fn for every decodable entry in an open KV store.
+// Entries are streamed one at a time rather than collected. An error from
+// fn stops the iteration.
+func eachEntry(k *kv.KV, fn func(entry *Entry) error) error {
+	return eachEntryBetween(k, nil, nil, fn)
+}
+
+// eachEntryBetween is eachEntry for a query limited to since and until
+// (either may be nil). The day index narrows which entries are fetched, but
+// fn may still see entries outside the range and must filter them itself.
+func eachEntryBetween(k *kv.KV, since, until *time.Time, fn func(entry *Entry) error) error {
+	keys, err := k.Keys()
+	if err != nil {
+		return fmt.Errorf("get keys: %w", err)
+	}
+
+	for _, key := range entryKeysBetween(keys, since, until) {
+		val, err := k.Get(key)
+		if err != nil {
+			// Skip entries that can't be fetched
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(val, &entry); err != nil {
+			// Skip invalid entries (corrupted data)
+			continue
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachVisibleEntry is eachEntryBetween minus entries written on hosts listed
+// in the ignored_hosts config, so one machine's noise can be hidden on another.
+func (c *Client) eachVisibleEntry(k *kv.KV, since, until *time.Time, fn func(entry *Entry) error) error {
+	return eachEntryBetween(k, since, until, func(entry *Entry) error {
+		if c.hostIgnored(entry.Hostname) {
+			return nil
+		}
+		return fn(entry)
+	})
+}
+
+// hostIgnored reports whether entries from hostname are hidden.
+func (c *Client) hostIgnored(hostname string) bool {
+	for _, h := range c.ignoredHosts {
+		if strings.EqualFold(h, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// metadataContains reports whether any metadata value contains text,
+// which must already be lowercase.
+func metadataContains(metadata map[string]string, text string) bool {
+	for _, value := range metadata {
+		if strings.Contains(strings.ToLower(value), text) {
+			return true
+		}
+	}
+	return false
+}
+
 // matchesFilter checks if an entry matches the search filter.
 func matchesFilter(entry *Entry, filter *SearchFilter) bool {
 	if filter == nil {
@@ -159,8 +542,7 @@ func matchesFilter(entry *Entry, filter *SearchFilter) bool {
 	// Text search (case-insensitive substring match)
 	if filter.Text != "" {
 		text := strings.ToLower(filter.Text)
-		message := strings.ToLower(entry.Message)
-		if !strings.Contains(message, text) {
+		if !strings.Contains(strings.ToLower(entry.Message), text) && !metadataContains(entry.Metadata, text) {
 			return false
 		}
 	}
@@ -172,6 +554,31 @@ func matchesFilter(entry *Entry, filter *SearchFilter) bool {
 		}
 	}
 
+	// Type filter
+	if filter.Type != "" && entry.Kind() != filter.Type {
+		return false
+	}
+
+	// Project filter
+	if filter.ProjectID != "" && entry.ProjectID != filter.ProjectID {
+		return false
+	}
+
+	// Source filter
+	if filter.Source != "" && entry.Source != filter.Source {
+		return false
+	}
+
+	// Pinned filter
+	if filter.PinnedOnly && !entry.Pinned {
+		return false
+	}
+
+	// Directory filter
+	if filter.Directory != "" && !config.DirWithin(entry.WorkingDirectory, filter.Directory) {
+		return false
+	}
+
 	// Date range filter
 	if filter.Since != nil && entry.Timestamp.Before(*filter.Since) {
 		return false
@@ -197,6 +604,22 @@ func hasAnyTag(entryTags, filterTags []string) bool {
 	return false
 }
 
+// MergeTags appends extra tags that are not already present, preserving order.
+func MergeTags(tags, extra []string) []string {
+	seen := make(map[string]bool, len(tags)+len(extra))
+	merged := make([]string, 0, len(tags)+len(extra))
+	for _, list := range [][]string{tags, extra} {
+		for _, t := range list {
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
 // sortEntriesByTimestamp sorts entries by timestamp descending (most recent first).
 func sortEntriesByTimestamp(entries []Entry) {
 	// Simple insertion sort (entries are usually already mostly sorted)
@@ -0,0 +1,295 @@
+// ABOUTME: Unit tests for entry types and search filtering
+// ABOUTME: Covers type validation and filter matching without a KV store
+package charm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidEntryType(t *testing.T) {
+	tests := []struct {
+		kind  string
+		valid bool
+	}{
+		{"note", true},
+		{"decision", true},
+		{"todo", true},
+		{"milestone", true},
+		{"", false},
+		{"Decision", false},
+		{"idea", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			if got := ValidEntryType(tt.kind); got != tt.valid {
+				t.Errorf("ValidEntryType(%q) = %v, want %v", tt.kind, got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestMatchesFilterType(t *testing.T) {
+	ts := time.Date(2025, 11, 29, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		entry  Entry
+		filter *SearchFilter
+		want   bool
+	}{
+		{"nil filter matches", Entry{Type: "todo", Timestamp: ts}, nil, true},
+		{"empty type filter matches any type", Entry{Type: "todo", Timestamp: ts}, &SearchFilter{}, true},
+		{"same type matches", Entry{Type: "decision", Timestamp: ts}, &SearchFilter{Type: "decision"}, true},
+		{"different type does not match", Entry{Type: "todo", Timestamp: ts}, &SearchFilter{Type: "decision"}, false},
+		{"untyped entry matches note", Entry{Timestamp: ts}, &SearchFilter{Type: "note"}, true},
+		{"untyped entry does not match decision", Entry{Timestamp: ts}, &SearchFilter{Type: "decision"}, false},
+		{
+			"type combines with text",
+			Entry{Type: "milestone", Message: "shipped v2", Timestamp: ts},
+			&SearchFilter{Type: "milestone", Text: "v3"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesFilter(&tt.entry, tt.filter); got != tt.want {
+				t.Errorf("matchesFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// clientCase is a client call that must fail on its input alone.
+type clientCase struct {
+	name string
+	call func(c *Client) error
+	// want, if set, must appear in the error
+	want string
+}
+
+// assertClientRejects runs each case in its own subtest. Input is checked
+// before the KV store is opened, so a bare client is enough.
+func assertClientRejects(t *testing.T, cases []clientCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Client{dbName: "chronicle-invalid-input-test", normalizeTags: true}
+			err := tc.call(c)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestEntryWritesRejectInvalidType(t *testing.T) {
+	assertClientRejects(t, []clientCase{
+		{"create", func(c *Client) error {
+			_, err := c.CreateEntry(Entry{Message: "test", Type: "idea"})
+			return err
+		}, "invalid entry type"},
+		{"update", func(c *Client) error {
+			return c.UpdateEntry(Entry{ID: "abc", Message: "test", Type: "idea"})
+		}, "invalid entry type"},
+	})
+}
+
+func TestMergeTags(t *testing.T) {
+	got := MergeTags([]string{"work", "go"}, []string{"go", "oss"})
+	want := []string{"work", "go", "oss"}
+	if len(got) != len(want) {
+		t.Fatalf("MergeTags() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("MergeTags()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMatchesFilterProject(t *testing.T) {
+	entry := Entry{ProjectID: "p1"}
+	if !matchesFilter(&entry, &SearchFilter{ProjectID: "p1"}) {
+		t.Error("expected entry to match its own project")
+	}
+	if matchesFilter(&entry, &SearchFilter{ProjectID: "p2"}) {
+		t.Error("expected entry not to match another project")
+	}
+	if matchesFilter(&Entry{}, &SearchFilter{ProjectID: "p1"}) {
+		t.Error("expected entry without project not to match a project filter")
+	}
+}
+
+func TestMatchesFilterTextSearchesMetadata(t *testing.T) {
+	entry := Entry{Message: "use sqlite", Metadata: map[string]string{"rationale": "Zero ops overhead"}}
+	if !matchesFilter(&entry, &SearchFilter{Text: "ops overhead"}) {
+		t.Error("expected text search to match a metadata value")
+	}
+	if matchesFilter(&entry, &SearchFilter{Text: "rationale"}) {
+		t.Error("expected text search not to match a metadata key")
+	}
+}
+
+func TestMatchesFilterDirectory(t *testing.T) {
+	entry := Entry{WorkingDirectory: "/src/chronicle/internal"}
+	if !matchesFilter(&entry, &SearchFilter{Directory: "/src/chronicle"}) {
+		t.Error("expected entry below the directory to match")
+	}
+	if matchesFilter(&entry, &SearchFilter{Directory: "/src/other"}) {
+		t.Error("expected entry outside the directory not to match")
+	}
+}
+
+func TestCreateEntriesValidatesBeforeWriting(t *testing.T) {
+	// An invalid entry anywhere in the batch fails before the KV store is opened
+	c := &Client{dbName: "chronicle-invalid-batch-test"}
+
+	_, err := c.CreateEntries([]Entry{
+		{Message: "ok"},
+		{Message: "bad", Type: "idea"},
+	})
+	if err == nil {
+		t.Fatal("expected error for invalid type in batch")
+	}
+	if !strings.Contains(err.Error(), "entry 1") {
+		t.Errorf("expected error to name the failing entry, got: %v", err)
+	}
+}
+
+func TestCreateEntriesEmpty(t *testing.T) {
+	c := &Client{dbName: "chronicle-empty-batch-test"}
+
+	ids, err := c.CreateEntries(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("expected no IDs, got %v", ids)
+	}
+}
+
+func TestPageEntries(t *testing.T) {
+	ts := time.Date(2025, 11, 29, 14, 30, 0, 0, time.UTC)
+	all := func() []Entry {
+		return []Entry{
+			{ID: "a", Timestamp: ts},
+			{ID: "d", Timestamp: ts.Add(-time.Hour)},
+			{ID: "c", Timestamp: ts},
+			{ID: "b", Timestamp: ts},
+			{ID: "e", Timestamp: ts.Add(time.Hour)},
+		}
+	}
+
+	// Walk the pages and confirm every entry appears once, newest first
+	var got []string
+	var cursorTS time.Time
+	var cursorID string
+	for {
+		page := pageEntries(all(), 2, cursorTS, cursorID)
+		if len(page) == 0 {
+			break
+		}
+		for _, e := range page {
+			got = append(got, e.ID)
+		}
+		last := page[len(page)-1]
+		cursorTS, cursorID = last.Timestamp, last.ID
+	}
+
+	want := "e,c,b,a,d"
+	if strings.Join(got, ",") != want {
+		t.Errorf("paged order = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestValidatePinning(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   Entry
+		wantErr bool
+	}{
+		{"unpinned", Entry{}, false},
+		{"pinned", Entry{Pinned: true}, false},
+		{"pinned weekly", Entry{Pinned: true, Recurrence: RecurrenceWeekly}, false},
+		{"unknown recurrence", Entry{Pinned: true, Recurrence: "hourly"}, true},
+		{"recurrence without pin", Entry{Recurrence: RecurrenceDaily}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validatePinning(&tt.entry); (err != nil) != tt.wantErr {
+				t.Errorf("validatePinning() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchesFilterPinned(t *testing.T) {
+	filter := &SearchFilter{PinnedOnly: true}
+	if !matchesFilter(&Entry{Pinned: true}, filter) {
+		t.Error("expected pinned entry to match")
+	}
+	if matchesFilter(&Entry{}, filter) {
+		t.Error("expected unpinned entry not to match")
+	}
+}
+
+func TestSourceValidationAndFilter(t *testing.T) {
+	if !ValidSource("") || !ValidSource(SourceMCP) || ValidSource("email") {
+		t.Error("unexpected ValidSource results")
+	}
+
+	c := &Client{dbName: "chronicle-invalid-source-test"}
+	if _, err := c.CreateEntry(Entry{Message: "x", Source: "email"}); err == nil {
+		t.Error("expected error for invalid source")
+	}
+
+	filter := &SearchFilter{Source: SourceMCP}
+	if !matchesFilter(&Entry{Source: SourceMCP}, filter) {
+		t.Error("expected mcp entry to match mcp source filter")
+	}
+	if matchesFilter(&Entry{Source: SourceCLI}, filter) {
+		t.Error("expected cli entry not to match mcp source filter")
+	}
+}
+
+func TestHostIgnored(t *testing.T) {
+	c := &Client{ignoredHosts: []string{"work-desktop"}}
+	if !c.hostIgnored("Work-Desktop") {
+		t.Error("expected ignored host to match case-insensitively")
+	}
+	if c.hostIgnored("laptop") {
+		t.Error("expected other hosts to stay visible")
+	}
+	if (&Client{}).hostIgnored("laptop") {
+		t.Error("expected nothing ignored by default")
+	}
+}
+
+func TestWithOptionsLeavesOriginal(t *testing.T) {
+	c := &Client{dbName: "chronicle", autoSync: true}
+	quiet := c.WithOptions(WithAutoSync(false))
+	if quiet.autoSync || !c.autoSync {
+		t.Errorf("autoSync: copy = %v, original = %v; want false, true", quiet.autoSync, c.autoSync)
+	}
+	if quiet.dbName != c.dbName {
+		t.Error("expected other settings to be copied")
+	}
+}
+
+func TestReadOnlyClientRefusesWrites(t *testing.T) {
+	c := &Client{dbName: "chronicle-read-only-test", readOnly: true}
+	if _, err := c.CreateEntry(Entry{Message: "hello"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("CreateEntry error = %v, want ErrReadOnly", err)
+	}
+	if _, err := c.CreateEntries([]Entry{{Message: "hello"}}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("CreateEntries error = %v, want ErrReadOnly", err)
+	}
+}
@@ -0,0 +1,30 @@
+// ABOUTME: Full JSONL export of every entry in the KV store
+// ABOUTME: Output round-trips through `chronicle import legacy`
+
+package charm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+// ExportEntries writes every entry as one JSON object per line and returns
+// how many were written. Unlike listing, entries from ignored hosts are
+// included, so the export is a complete copy of the store.
+func (c *Client) ExportEntries(w io.Writer) (int, error) {
+	enc := json.NewEncoder(w)
+	count := 0
+	err := c.DoReadOnly(func(k *kv.KV) error {
+		return eachEntry(k, func(entry *Entry) error {
+			if err := enc.Encode(entry); err != nil {
+				return fmt.Errorf("write entry %s: %w", entry.ID, err)
+			}
+			count++
+			return nil
+		})
+	})
+	return count, err
+}
@@ -0,0 +1,169 @@
+// ABOUTME: Consistency check over stored entries and the day index
+// ABOUTME: Finds unparseable or incomplete entries and can quarantine them under corrupt:
+
+package charm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+// CorruptPrefix is the key prefix damaged entries are moved under by
+// Fsck, so they stop being skipped silently but aren't lost either.
+const CorruptPrefix = "corrupt:"
+
+// FsckProblem describes one damaged entry.
+type FsckProblem struct {
+	ID      string `json:"id"`
+	Problem string `json:"problem"`
+	// Quarantined is set when the entry was moved under CorruptPrefix
+	Quarantined bool `json:"quarantined,omitempty"`
+}
+
+// FsckResult summarizes a consistency check of the stored entries.
+type FsckResult struct {
+	Checked int           `json:"checked"`
+	Damaged []FsckProblem `json:"damaged,omitempty"`
+	// IndexMissing lists entries without a day index key
+	IndexMissing []string `json:"index_missing,omitempty"`
+	// IndexStale lists day index keys that point at no entry or the wrong day
+	IndexStale []string `json:"index_stale,omitempty"`
+	// Fixed is set when the problems found were repaired
+	Fixed bool `json:"fixed,omitempty"`
+}
+
+// OK reports whether nothing was wrong.
+func (r *FsckResult) OK() bool {
+	return len(r.Damaged) == 0 && len(r.IndexMissing) == 0 && len(r.IndexStale) == 0
+}
+
+// Fsck checks every stored entry and the day index. With fix set, entries
+// that don't parse or lack required fields are moved under CorruptPrefix
+// and the index is repaired. Entries that can't be read at all are only
+// reported, since their value can't be preserved.
+func (c *Client) Fsck(fix bool) (*FsckResult, error) {
+	result := &FsckResult{Fixed: fix}
+	check := func(k *kv.KV) error {
+		return fsck(k, result, fix)
+	}
+
+	var err error
+	if fix {
+		err = c.write(check)
+	} else {
+		err = c.DoReadOnly(check)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fsck: %w", err)
+	}
+	if fix && !result.OK() {
+		c.syncAfterWrite(writeChange)
+	}
+	return result, nil
+}
+
+// fsck fills in result from an open store, repairing as it goes if fix is set.
+func fsck(k *kv.KV, result *FsckResult, fix bool) error {
+	keys, err := k.Keys()
+	if err != nil {
+		return fmt.Errorf("get keys: %w", err)
+	}
+
+	indexed := make(map[string]bool)
+	for _, key := range keys {
+		if _, _, ok := parseDayKey(string(key)); ok {
+			indexed[string(key)] = true
+		}
+	}
+
+	want := make(map[string]bool)
+	for _, key := range keys {
+		id, ok := strings.CutPrefix(string(key), EntryPrefix)
+		if !ok {
+			continue
+		}
+		result.Checked++
+
+		val, err := k.Get(key)
+		if err != nil {
+			result.Damaged = append(result.Damaged, FsckProblem{ID: id, Problem: fmt.Sprintf("unreadable: %v", err)})
+			continue
+		}
+
+		var entry Entry
+		problem := ""
+		if err := json.Unmarshal(val, &entry); err != nil {
+			problem = fmt.Sprintf("unparseable: %v", err)
+		} else {
+			problem = entryProblem(id, &entry)
+		}
+		if problem != "" {
+			damaged := FsckProblem{ID: id, Problem: problem}
+			if fix {
+				if err := quarantineEntry(k, id, val); err != nil {
+					return err
+				}
+				damaged.Quarantined = true
+			}
+			result.Damaged = append(result.Damaged, damaged)
+			continue
+		}
+
+		dk := string(dayKey(entry.Timestamp, id))
+		want[dk] = true
+		if indexed[dk] {
+			continue
+		}
+		result.IndexMissing = append(result.IndexMissing, id)
+		if fix {
+			if err := k.Set([]byte(dk), []byte{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for key := range indexed {
+		if want[key] {
+			continue
+		}
+		result.IndexStale = append(result.IndexStale, key)
+		if fix {
+			if err := k.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+	}
+	sort.Strings(result.IndexStale)
+	return nil
+}
+
+// entryProblem returns why a decoded entry is unusable, or "" if it's fine.
+func entryProblem(id string, entry *Entry) string {
+	switch {
+	case entry.ID == "":
+		return "missing id"
+	case entry.ID != id:
+		return fmt.Sprintf("id %q does not match its key", entry.ID)
+	case entry.Timestamp.IsZero():
+		return "missing timestamp"
+	case strings.TrimSpace(entry.Message) == "":
+		return "missing message"
+	}
+	return ""
+}
+
+// quarantineEntry moves a damaged entry's raw value under CorruptPrefix.
+// Its day index key, if any, is left for fsck to remove as stale.
+func quarantineEntry(k *kv.KV, id string, raw []byte) error {
+	if err := k.Set([]byte(CorruptPrefix+id), raw); err != nil {
+		return fmt.Errorf("quarantine %s: %w", id, err)
+	}
+	if err := k.Delete(entryKey(id)); err != nil {
+		return fmt.Errorf("quarantine %s: %w", id, err)
+	}
+	return nil
+}
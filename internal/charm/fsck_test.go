@@ -0,0 +1,74 @@
+// ABOUTME: Tests for the entry and day index consistency check
+// ABOUTME: Covers required-field checks and a quarantine pass against a real KV store
+package charm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+func TestEntryProblem(t *testing.T) {
+	ts := time.Date(2025, 11, 29, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry Entry
+		want  string
+	}{
+		{"valid", Entry{ID: "a", Timestamp: ts, Message: "hi"}, ""},
+		{"missing id", Entry{Timestamp: ts, Message: "hi"}, "missing id"},
+		{"wrong id", Entry{ID: "b", Timestamp: ts, Message: "hi"}, `id "b" does not match its key`},
+		{"missing timestamp", Entry{ID: "a", Message: "hi"}, "missing timestamp"},
+		{"blank message", Entry{ID: "a", Timestamp: ts, Message: "  "}, "missing message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entryProblem("a", &tt.entry); got != tt.want {
+				t.Errorf("entryProblem() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFsckQuarantinesDamagedEntries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	tmpDir := t.TempDir()
+	t.Setenv("CHARM_DATA_DIR", tmpDir)
+
+	const dbName = "chronicle-fsck-test"
+	initKV, err := kv.OpenWithDefaults(dbName, kv.WithPath(tmpDir))
+	if err != nil {
+		t.Skipf("skipping fsck test - charm KV not available: %v", err)
+	}
+	_ = initKV.Close()
+
+	c := &Client{dbName: dbName, dbDir: tmpDir}
+	if _, err := c.CreateEntry(Entry{Message: "fine"}); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+	err = c.Do(func(k *kv.KV) error {
+		if err := k.Set(entryKey("broken"), []byte("{not json")); err != nil {
+			return err
+		}
+		return k.Set(dayKey(time.Now(), "gone"), []byte{})
+	})
+	if err != nil {
+		t.Fatalf("seed damage: %v", err)
+	}
+
+	result, err := c.Fsck(true)
+	if err != nil {
+		t.Fatalf("Fsck: %v", err)
+	}
+	if len(result.Damaged) != 1 || !result.Damaged[0].Quarantined || len(result.IndexStale) != 1 {
+		t.Errorf("Fsck(fix) = %+v, want one quarantined entry and one stale index key", result)
+	}
+
+	result, err = c.Fsck(false)
+	if err != nil || !result.OK() || result.Checked != 1 {
+		t.Errorf("Fsck after fix = %+v (err %v), want one clean entry", result, err)
+	}
+}
@@ -0,0 +1,109 @@
+// ABOUTME: Records which chronicle process is writing so lock errors can name it
+// ABOUTME: A marker whose process has died means its lock is gone, so the write is retried
+
+package charm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// lockHolder describes the process currently inside a write connection.
+type lockHolder struct {
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	Since   time.Time `json:"since"`
+}
+
+// holderPath returns where the current writer of dbName is recorded.
+func holderPath(dbName string) string {
+	return stateFilePathFor(dbName, "db-holder", ".json")
+}
+
+// markHolder records this process as the writer. It is called with the
+// write connection open, so this process holds the lock. The returned
+// release removes the marker if it is still ours.
+func markHolder(path string) (release func()) {
+	h := lockHolder{PID: os.Getpid(), Command: commandLine(), Since: time.Now()}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return func() {}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return func() {}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return func() {}
+	}
+	return func() {
+		if cur := readHolder(path); cur != nil && cur.PID == os.Getpid() {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// readHolder returns the recorded writer, or nil if there is none or its
+// process has exited.
+func readHolder(path string) *lockHolder {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var h lockHolder
+	if err := json.Unmarshal(data, &h); err != nil || !processAlive(h.PID) {
+		return nil
+	}
+	return &h
+}
+
+// holderDied reports whether the recorded writer is another process that
+// has since exited.
+func holderDied(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var h lockHolder
+	if err := json.Unmarshal(data, &h); err != nil || h.PID <= 0 {
+		return false
+	}
+	return h.PID != os.Getpid() && !processAlive(h.PID)
+}
+
+// recoverFromDeadHolder handles a write that stayed locked. The marker is
+// not the lock, but SQLite releases a process's locks when it exits, so a
+// dead recorded holder means the contention came from a crashed writer and
+// is over: its marker is removed and the write retried once. Otherwise, or
+// if the retry is locked too, the error gets the holder hint.
+func recoverFromDeadHolder(path string, err error, retry func() error) error {
+	if holderDied(path) {
+		_ = os.Remove(path)
+		if err = retry(); !isLockContention(err) {
+			return err
+		}
+	}
+	return describeLockHolder(path, err)
+}
+
+// describeLockHolder adds a best-effort hint naming the writing process to
+// a lock contention error. Without a live marker the error is unchanged.
+func describeLockHolder(path string, err error) error {
+	h := readHolder(path)
+	if h == nil || h.PID == os.Getpid() {
+		return err
+	}
+	return fmt.Errorf("%w; held by PID %d (%s)", err, h.PID, h.Command)
+}
+
+// commandLine names this process the way a user would recognise it.
+func commandLine() string {
+	args := append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...)
+	if len(args) > 3 {
+		args = args[:3]
+	}
+	return strings.Join(args, " ")
+}
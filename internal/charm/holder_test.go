@@ -0,0 +1,95 @@
+// ABOUTME: Tests for the database writer marker used in lock errors
+// ABOUTME: Uses this process as a live holder and an exited child as a dead one
+package charm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeHolder puts a marker for pid at path.
+func writeHolder(t *testing.T, path string, pid int) {
+	t.Helper()
+	data, err := json.Marshal(lockHolder{PID: pid, Command: "chronicle mcp", Since: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// deadPID returns the PID of a process that has already exited.
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot run helper process: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestMarkHolderReleasesOwnMarker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db-holder.json")
+
+	release := markHolder(path)
+	if h := readHolder(path); h == nil || h.PID != os.Getpid() {
+		t.Fatalf("expected this process recorded as holder, got %+v", h)
+	}
+	release()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected marker removed on release")
+	}
+}
+
+func TestDescribeLockHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db-holder.json")
+	lockErr := errors.New("database is locked")
+
+	// A live holder (the parent of this test process) is named in the error
+	writeHolder(t, path, os.Getppid())
+	err := describeLockHolder(path, lockErr)
+	want := fmt.Sprintf("held by PID %d (chronicle mcp)", os.Getppid())
+	if !strings.Contains(err.Error(), want) || !errors.Is(err, lockErr) {
+		t.Errorf("err = %v; want it to contain %q", err, want)
+	}
+
+	// A marker left by a crashed process gives no hint
+	writeHolder(t, path, deadPID(t))
+	if err := describeLockHolder(path, lockErr); err != lockErr {
+		t.Errorf("err = %v; want the lock error unchanged", err)
+	}
+}
+
+func TestRecoverFromDeadHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db-holder.json")
+	lockErr := errors.New("database is locked")
+	retries := 0
+	retry := func() error {
+		retries++
+		return nil
+	}
+
+	// A crashed holder's lock is gone, so the write is retried
+	writeHolder(t, path, deadPID(t))
+	if err := recoverFromDeadHolder(path, lockErr, retry); err != nil || retries != 1 {
+		t.Errorf("err = %v, retries = %d; want nil after one retry", err, retries)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the dead holder's marker removed")
+	}
+
+	// A live holder is only named
+	retries = 0
+	writeHolder(t, path, os.Getppid())
+	if err := recoverFromDeadHolder(path, lockErr, retry); !errors.Is(err, lockErr) || retries != 0 {
+		t.Errorf("err = %v, retries = %d; want the lock error without a retry", err, retries)
+	}
+}
@@ -0,0 +1,47 @@
+// ABOUTME: User-configured shell hooks run around sync
+// ABOUTME: pre_sync, post_sync, and on_remote_change; failures only warn
+
+package charm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// hookTimeout bounds how long a single hook command may run.
+const hookTimeout = 30 * time.Second
+
+// Hooks are shell commands run around each sync.
+type Hooks struct {
+	// PreSync runs before every sync.
+	PreSync string `json:"pre_sync,omitempty"`
+
+	// PostSync runs after every sync; CHRONICLE_SYNC_ERROR is set if it failed.
+	PostSync string `json:"post_sync,omitempty"`
+
+	// OnRemoteChange runs after a sync that pulled changes; CHRONICLE_PULLED
+	// holds how many.
+	OnRemoteChange string `json:"on_remote_change,omitempty"`
+}
+
+// runHook runs command through the shell with CHRONICLE_HOOK and env set.
+// Output goes to stderr so it never mixes with JSON or MCP output on stdout.
+// A failing hook is reported and otherwise ignored.
+func runHook(ctx context.Context, name, command string, env ...string) {
+	if command == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(append(os.Environ(), "CHRONICLE_HOOK="+name), env...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s hook failed: %v\n", name, err)
+	}
+}
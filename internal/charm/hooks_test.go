@@ -0,0 +1,32 @@
+// ABOUTME: Tests for sync hook execution
+// ABOUTME: Runs small shell commands that write into a temp dir
+package charm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunHookPassesEnv(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hook.out")
+	runHook(context.Background(), "on_remote_change",
+		`printf '%s %s' "$CHRONICLE_HOOK" "$CHRONICLE_PULLED" > "$OUT"`,
+		"CHRONICLE_PULLED=3", "OUT="+out)
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "on_remote_change 3" {
+		t.Errorf("hook saw %q, want %q", got, "on_remote_change 3")
+	}
+}
+
+func TestRunHookIgnoresEmptyAndFailing(t *testing.T) {
+	// Neither should panic or block; failures are only warnings
+	runHook(context.Background(), "pre_sync", "")
+	runHook(context.Background(), "pre_sync", "exit 1")
+}
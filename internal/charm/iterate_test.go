@@ -0,0 +1,62 @@
+// ABOUTME: Integration test for streaming entry iteration and export
+// ABOUTME: Skips when a Charm KV store can't be opened in this environment
+
+package charm
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+func TestIterateEntriesStopsEarly(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	tmpDir := t.TempDir()
+	t.Setenv("CHARM_DATA_DIR", tmpDir)
+
+	const dbName = "chronicle-iterate-test"
+	initKV, err := kv.OpenWithDefaults(dbName, kv.WithPath(tmpDir))
+	if err != nil {
+		t.Skipf("skipping iterate test - charm KV not available: %v", err)
+	}
+	_ = initKV.Close()
+
+	c := &Client{dbName: dbName, dbDir: tmpDir}
+	if _, err := c.CreateEntries([]Entry{
+		{Message: "one", Tags: []string{"a"}},
+		{Message: "two", Tags: []string{"b"}},
+		{Message: "three", Tags: []string{"a"}},
+	}); err != nil {
+		t.Fatalf("CreateEntries: %v", err)
+	}
+
+	matched := 0
+	err = c.IterateEntries(&SearchFilter{Tags: []string{"a"}}, func(entry *Entry) error {
+		matched++
+		return nil
+	})
+	if err != nil || matched != 2 {
+		t.Errorf("matched %d entries (err %v), want 2", matched, err)
+	}
+
+	var buf bytes.Buffer
+	exported, err := c.ExportEntries(&buf)
+	if err != nil || exported != 3 || strings.Count(buf.String(), "\n") != 3 {
+		t.Errorf("exported %d entries (err %v), want 3 JSON lines", exported, err)
+	}
+
+	stop := errors.New("stop")
+	seen := 0
+	err = c.IterateEntries(nil, func(entry *Entry) error {
+		seen++
+		return stop
+	})
+	if !errors.Is(err, stop) || seen != 1 {
+		t.Errorf("seen %d entries with err %v, want 1 and the stop error", seen, err)
+	}
+}
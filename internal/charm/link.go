@@ -0,0 +1,139 @@
+// ABOUTME: Cross-entry links for relating chronicle entries to each other
+// ABOUTME: Uses type-prefixed keys (link:from:to) so links sync like entries
+package charm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+const (
+	// LinkPrefix is the key prefix for entry links.
+	LinkPrefix = "link:"
+
+	// RelationReferences is the default relation for --ref links.
+	RelationReferences = "references"
+)
+
+// Link is a directed relation from one entry to another.
+type Link struct {
+	FromID    string    `json:"from_id"`
+	ToID      string    `json:"to_id"`
+	Relation  string    `json:"relation"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// linkKey returns the KV key for a link.
+func linkKey(fromID, toID string) []byte {
+	return []byte(LinkPrefix + fromID + ":" + toID)
+}
+
+// AddLink creates or replaces the link from one entry to another.
+func (c *Client) AddLink(fromID, toID, relation string) error {
+	if fromID == "" || toID == "" {
+		return fmt.Errorf("link requires both entry IDs")
+	}
+	if fromID == toID {
+		return fmt.Errorf("cannot link an entry to itself")
+	}
+	if relation == "" {
+		relation = RelationReferences
+	}
+
+	link := Link{
+		FromID:    fromID,
+		ToID:      toID,
+		Relation:  relation,
+		CreatedAt: time.Now(),
+	}
+	if err := c.SetJSON(linkKey(fromID, toID), link); err != nil {
+		return fmt.Errorf("add link: %w", err)
+	}
+	return nil
+}
+
+// RemoveLink deletes the link from one entry to another.
+func (c *Client) RemoveLink(fromID, toID string) error {
+	if err := c.Delete(linkKey(fromID, toID)); err != nil {
+		return fmt.Errorf("remove link: %w", err)
+	}
+	return nil
+}
+
+// OutboundLinks returns links from the given entry to others.
+func (c *Client) OutboundLinks(id string) ([]Link, error) {
+	return c.findLinks(func(l Link) bool { return l.FromID == id })
+}
+
+// InboundLinks returns links from other entries to the given entry.
+func (c *Client) InboundLinks(id string) ([]Link, error) {
+	return c.findLinks(func(l Link) bool { return l.ToID == id })
+}
+
+// findLinks returns every link accepted by match.
+func (c *Client) findLinks(match func(Link) bool) ([]Link, error) {
+	var links []Link
+
+	err := c.DoReadOnly(func(k *kv.KV) error {
+		all, err := readLinks(k)
+		if err != nil {
+			return err
+		}
+		for _, l := range all {
+			if match(l) {
+				links = append(links, l)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// readLinks loads every link record from an open KV store.
+func readLinks(k *kv.KV) ([]Link, error) {
+	keys, err := k.Keys()
+	if err != nil {
+		return nil, fmt.Errorf("get keys: %w", err)
+	}
+
+	var links []Link
+	for _, key := range keys {
+		if !strings.HasPrefix(string(key), LinkPrefix) {
+			continue
+		}
+		val, err := k.Get(key)
+		if err != nil {
+			continue
+		}
+		var link Link
+		if err := json.Unmarshal(val, &link); err != nil {
+			// Skip invalid links (corrupted data)
+			continue
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// deleteLinksFor removes every link that touches the given entry.
+func deleteLinksFor(k *kv.KV, id string) error {
+	links, err := readLinks(k)
+	if err != nil {
+		return err
+	}
+	for _, l := range links {
+		if l.FromID == id || l.ToID == id {
+			if err := k.Delete(linkKey(l.FromID, l.ToID)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+// ABOUTME: Unit tests for cross-entry links
+// ABOUTME: Covers link validation without a KV store
+package charm
+
+import "testing"
+
+func TestAddLinkRejectsInvalidLinks(t *testing.T) {
+	assertClientRejects(t, []clientCase{
+		{"empty from ID", func(c *Client) error { return c.AddLink("", "b", "") }, ""},
+		{"empty to ID", func(c *Client) error { return c.AddLink("a", "", "") }, ""},
+		{"self-link", func(c *Client) error { return c.AddLink("a", "a", "") }, ""},
+	})
+}
@@ -0,0 +1,21 @@
+// ABOUTME: Unix file locking for the offline outbox
+// ABOUTME: Uses flock so a process appending and one replaying take turns
+
+//go:build !windows
+
+package charm
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile blocks until it holds an exclusive lock on f.
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+// unlockFile releases the lock taken by lockFile.
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
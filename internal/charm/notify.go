@@ -0,0 +1,134 @@
+// ABOUTME: Chat notifications posting selected new entries to team channels
+// ABOUTME: Dispatches to per-service formatters; configured globally or per project
+
+package charm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/harper/chronicle/internal/config"
+)
+
+// chatFormatters turn an entry into the JSON body of a service's incoming
+// webhook. Supporting another service means adding it to
+// config.NotifyServices and registering its formatter here.
+var chatFormatters = map[string]func(entry *Entry) any{
+	config.NotifySlack:   slackMessage,
+	config.NotifyDiscord: discordMessage,
+}
+
+// ValidateNotifications checks every notification's service, URL, and
+// entry types, naming the first bad one.
+func ValidateNotifications(notifications []config.Notification) error {
+	if err := config.ValidateNotifications(notifications); err != nil {
+		return err
+	}
+	for i, n := range notifications {
+		for _, t := range n.Types {
+			if !ValidEntryType(t) {
+				return fmt.Errorf("notifications[%d]: unknown entry type %q (valid: %v)", i, t, EntryTypes)
+			}
+		}
+	}
+	return nil
+}
+
+// notifyChat posts a new entry to each notification that wants it, from the
+// global config and the .chronicle file of the entry's project. Failures
+// are reported and otherwise ignored, since the entry has been stored.
+func (c *Client) notifyChat(entry *Entry) {
+	for _, n := range append(c.notifications, projectNotifications(entry.WorkingDirectory)...) {
+		if !n.Wants(c.profile, entry.Kind(), entry.Tags) {
+			continue
+		}
+		if err := postNotification(context.Background(), n, entry); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s notification failed: %v\n", n.Service, err)
+		}
+	}
+}
+
+// projectNotifications returns the notifications set by the .chronicle file
+// of the project holding dir, if any.
+func projectNotifications(dir string) []config.Notification {
+	if dir == "" {
+		return nil
+	}
+	root, err := config.FindProjectRoot(config.ExpandHome(dir))
+	if err != nil || root == "" {
+		return nil
+	}
+	projectCfg, err := config.LoadProjectConfig(filepath.Join(root, ".chronicle"))
+	if err != nil || len(projectCfg.Notifications) == 0 {
+		return nil
+	}
+	if err := ValidateNotifications(projectCfg.Notifications); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ignoring notifications in %s: %v\n", filepath.Join(root, ".chronicle"), err)
+		return nil
+	}
+	return projectCfg.Notifications
+}
+
+// postNotification sends entry to n's incoming webhook, formatted for its
+// service.
+func postNotification(ctx context.Context, n config.Notification, entry *Entry) error {
+	format, ok := chatFormatters[n.Service]
+	if !ok {
+		return fmt.Errorf("unsupported service %q", n.Service)
+	}
+	body, err := json.Marshal(format(entry))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "chronicle")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	return nil
+}
+
+// entryContext lists an entry's tags, host, and directory for the line
+// under its message, with tags written by tag.
+func entryContext(entry *Entry, tag func(string) string) []string {
+	var context []string
+	if len(entry.Tags) > 0 {
+		tags := make([]string, len(entry.Tags))
+		for i, t := range entry.Tags {
+			tags[i] = tag(t)
+		}
+		context = append(context, strings.Join(tags, " "))
+	}
+	if entry.Hostname != "" {
+		context = append(context, entry.Hostname)
+	}
+	if entry.WorkingDirectory != "" {
+		context = append(context, entry.WorkingDirectory)
+	}
+	return context
+}
+
+// kindTitle returns an entry's type capitalized, as in "Milestone".
+func kindTitle(entry *Entry) string {
+	kind := entry.Kind()
+	return strings.ToUpper(kind[:1]) + kind[1:]
+}
@@ -0,0 +1,66 @@
+// ABOUTME: Discord formatting for chat notifications
+// ABOUTME: Builds embed messages for Discord channel webhooks
+
+package charm
+
+import (
+	"strings"
+	"time"
+)
+
+// discordStyle gives each entry type's emoji and embed color in Discord.
+var discordStyle = map[string]struct {
+	emoji string
+	color int
+}{
+	EntryTypeNote:      {"📝", 0x656d76},
+	EntryTypeDecision:  {"⚖️", 0x8250df},
+	EntryTypeTodo:      {"☑️", 0x9a6700},
+	EntryTypeMilestone: {"🏆", 0x1a7f37},
+}
+
+// discordPayload is a Discord webhook message. AllowedMentions is left
+// empty so an entry mentioning @everyone doesn't ping the channel.
+type discordPayload struct {
+	Username        string                 `json:"username"`
+	Embeds          []discordEmbed         `json:"embeds"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Color       int            `json:"color"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// discordMessage formats entry for Discord: an embed titled with the type,
+// holding the message, with the tags, host, and directory in its footer.
+func discordMessage(entry *Entry) any {
+	style := discordStyle[entry.Kind()]
+	embed := discordEmbed{
+		Title:       style.emoji + " " + kindTitle(entry),
+		Description: entry.Message,
+		Color:       style.color,
+	}
+	if !entry.Timestamp.IsZero() {
+		embed.Timestamp = entry.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if context := entryContext(entry, func(tag string) string { return "#" + tag }); len(context) > 0 {
+		embed.Footer = &discordFooter{Text: strings.Join(context, " · ")}
+	}
+	return discordPayload{
+		Username:        "chronicle",
+		Embeds:          []discordEmbed{embed},
+		AllowedMentions: discordAllowedMentions{Parse: []string{}},
+	}
+}
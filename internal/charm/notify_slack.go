@@ -0,0 +1,61 @@
+// ABOUTME: Slack formatting for chat notifications
+// ABOUTME: Builds Block Kit messages for Slack incoming webhooks
+
+package charm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// slackEmoji marks each entry type in Slack messages.
+var slackEmoji = map[string]string{
+	EntryTypeNote:      ":memo:",
+	EntryTypeDecision:  ":scales:",
+	EntryTypeTodo:      ":ballot_box_with_check:",
+	EntryTypeMilestone: ":trophy:",
+}
+
+// slackPayload is a Slack incoming webhook message: Text is the plain
+// fallback shown in notifications, Blocks the formatted message.
+type slackPayload struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackMessage formats entry for Slack: the type and message, then a line
+// with the tags, host, and directory.
+func slackMessage(entry *Entry) any {
+	heading := fmt.Sprintf("%s *%s*", slackEmoji[entry.Kind()], kindTitle(entry))
+	msg := slackPayload{
+		Text: fmt.Sprintf("[%s] %s", entry.Kind(), entry.Message),
+		Blocks: []slackBlock{{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: heading + "\n" + slackEscape(entry.Message)},
+		}},
+	}
+	context := entryContext(entry, func(tag string) string { return "`#" + tag + "`" })
+	if len(context) > 0 {
+		msg.Blocks = append(msg.Blocks, slackBlock{
+			Type:     "context",
+			Elements: []slackText{{Type: "mrkdwn", Text: slackEscape(strings.Join(context, " · "))}},
+		})
+	}
+	return msg
+}
+
+// slackEscape escapes the characters Slack's mrkdwn treats as markup.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,146 @@
+// ABOUTME: Tests for chat notifications
+// ABOUTME: Validates entry selection, Slack and Discord formatting, and delivery errors
+package charm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/config"
+)
+
+func TestNotificationWants(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       config.Notification
+		profile string
+		kind    string
+		tags    []string
+		want    bool
+	}{
+		{"no filters", config.Notification{}, "", EntryTypeNote, nil, true},
+		{"tag matches", config.Notification{Tags: []string{"deployment"}}, "", EntryTypeNote, []string{"work", "deployment"}, true},
+		{"tag missing", config.Notification{Tags: []string{"deployment"}}, "", EntryTypeNote, []string{"work"}, false},
+		{"type matches", config.Notification{Tags: []string{"deployment"}, Types: []string{EntryTypeDecision}}, "", EntryTypeDecision, nil, true},
+		{"default profile", config.Notification{Profiles: []string{"default"}}, "", EntryTypeNote, nil, true},
+		{"other profile", config.Notification{Profiles: []string{"work"}}, "personal", EntryTypeNote, nil, false},
+	}
+	for _, tt := range tests {
+		if got := tt.n.Wants(tt.profile, tt.kind, tt.tags); got != tt.want {
+			t.Errorf("%s: Wants = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPostNotification(t *testing.T) {
+	var got slackPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	entry := &Entry{
+		Message:          "shipped <v2> & friends",
+		Type:             EntryTypeMilestone,
+		Tags:             []string{"deployment"},
+		Hostname:         "laptop",
+		WorkingDirectory: "~/code/app",
+	}
+	n := config.Notification{Service: config.NotifySlack, URL: server.URL}
+	if err := postNotification(context.Background(), n, entry); err != nil {
+		t.Fatal(err)
+	}
+	if got.Text != "[milestone] shipped <v2> & friends" {
+		t.Errorf("fallback text = %q", got.Text)
+	}
+	if len(got.Blocks) != 2 {
+		t.Fatalf("got %d blocks, want 2", len(got.Blocks))
+	}
+	section := got.Blocks[0].Text.Text
+	if !strings.Contains(section, ":trophy: *Milestone*") || !strings.Contains(section, "shipped &lt;v2&gt; &amp; friends") {
+		t.Errorf("section = %q", section)
+	}
+	context := got.Blocks[1].Elements[0].Text
+	if context != "`#deployment` · laptop · ~/code/app" {
+		t.Errorf("context = %q", context)
+	}
+}
+
+func TestPostNotificationDiscord(t *testing.T) {
+	var got discordPayload
+	var raw map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+		_ = json.Unmarshal(body, &raw)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	entry := &Entry{
+		Message:   "chose Postgres over SQLite @everyone",
+		Type:      EntryTypeDecision,
+		Tags:      []string{"db"},
+		Hostname:  "laptop",
+		Timestamp: time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC),
+	}
+	n := config.Notification{Service: config.NotifyDiscord, URL: server.URL}
+	if err := postNotification(context.Background(), n, entry); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Embeds) != 1 {
+		t.Fatalf("got %d embeds, want 1", len(got.Embeds))
+	}
+	embed := got.Embeds[0]
+	if embed.Title != "⚖️ Decision" || embed.Description != entry.Message || embed.Color != 0x8250df {
+		t.Errorf("unexpected embed %+v", embed)
+	}
+	if embed.Timestamp != "2026-03-04T10:00:00Z" || embed.Footer == nil || embed.Footer.Text != "#db · laptop" {
+		t.Errorf("unexpected embed details %+v", embed)
+	}
+	mentions, ok := raw["allowed_mentions"].(map[string]any)
+	if !ok || mentions["parse"] == nil {
+		t.Errorf("allowed_mentions.parse must be an empty list to suppress pings, got %v", raw["allowed_mentions"])
+	}
+}
+
+func TestChatFormattersCoverServices(t *testing.T) {
+	for _, service := range config.NotifyServices {
+		if _, ok := chatFormatters[service]; !ok {
+			t.Errorf("no formatter registered for %q", service)
+		}
+	}
+}
+
+func TestPostNotificationError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+	n := config.Notification{Service: config.NotifySlack, URL: server.URL}
+	if err := postNotification(context.Background(), n, &Entry{Message: "hi"}); err == nil {
+		t.Error("expected an error for a 403 response")
+	}
+}
+
+func TestValidateNotifications(t *testing.T) {
+	good := config.Notification{Service: config.NotifySlack, URL: "https://hooks.slack.com/services/T/B/X"}
+	if err := ValidateNotifications([]config.Notification{good}); err != nil {
+		t.Errorf("valid notification rejected: %v", err)
+	}
+	for name, n := range map[string]config.Notification{
+		"service": {Service: "pager", URL: good.URL},
+		"url":     {Service: config.NotifySlack, URL: "hooks.slack.com"},
+		"type":    {Service: config.NotifySlack, URL: good.URL, Types: []string{"deploy"}},
+	} {
+		if err := ValidateNotifications([]config.Notification{good, n}); err == nil || !strings.Contains(err.Error(), "notifications[1]") {
+			t.Errorf("bad %s: got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// ABOUTME: Bounded sync retries and offline tracking for auto-sync after writes
+// ABOUTME: A failed push never fails the write; queued changes flush on the next good sync
+
+package charm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// syncRetryAttempts is how many times auto-sync is tried after a write.
+	syncRetryAttempts = 3
+
+	// syncRetryBaseDelay is the first backoff delay; it doubles per attempt.
+	syncRetryBaseDelay = 500 * time.Millisecond
+)
+
+// withSyncRetry runs fn up to attempts times with exponential backoff,
+// returning the last error if every attempt fails.
+func withSyncRetry(attempts int, fn func() error) error {
+	var err error
+	delay := syncRetryBaseDelay
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			sleep(delay)
+			delay *= 2
+		}
+	}
+	return err
+}
+
+// Auto-sync policies, chosen with auto_sync_policy in the config.
+const (
+	SyncPolicyEveryWrite = "every_write"
+	SyncPolicyOnAdd      = "on_add"
+	SyncPolicyEveryN     = "every_n"
+	SyncPolicyNever      = "never"
+)
+
+// SyncPolicies lists the valid auto-sync policies.
+var SyncPolicies = []string{SyncPolicyEveryWrite, SyncPolicyOnAdd, SyncPolicyEveryN, SyncPolicyNever}
+
+// ValidSyncPolicy reports whether p is a known policy. Empty means every_write.
+func ValidSyncPolicy(p string) bool {
+	if p == "" {
+		return true
+	}
+	for _, known := range SyncPolicies {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// writeKind tells the auto-sync policy what a write did.
+type writeKind int
+
+const (
+	// writeChange edits or removes existing data
+	writeChange writeKind = iota
+	// writeAdd adds new entries
+	writeAdd
+)
+
+// syncAfterWrite pushes a write that is already stored locally, if auto-sync
+// and its policy call for it. Failures are reported as a warning and mark
+// the client offline; the Charm KV keeps the change queued and pushes it on
+// the next successful sync. While offline, only one attempt is made so
+// writes stay fast.
+func (c *Client) syncAfterWrite(kind writeKind) {
+	if !c.autoSyncWanted(kind) {
+		return
+	}
+	attempts := syncRetryAttempts
+	if c.Offline() {
+		attempts = 1
+	}
+	err := withSyncRetry(attempts, c.syncOnce)
+	c.setOffline(err != nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: sync failed, change saved locally and will sync on next connection: %v\n", err)
+	}
+}
+
+// autoSyncWanted applies the auto-sync policy to a write of the given kind.
+// Writes that don't sync stay queued and go out with the next sync of any kind.
+func (c *Client) autoSyncWanted(kind writeKind) bool {
+	if !c.autoSync {
+		return false
+	}
+	switch c.autoSyncPolicy {
+	case SyncPolicyNever:
+		return false
+	case SyncPolicyOnAdd:
+		if kind != writeAdd {
+			return false
+		}
+	case SyncPolicyEveryN:
+		return c.autoSyncMaxPending > 0 && c.PendingOps() >= int64(c.autoSyncMaxPending)
+	}
+	return autoSyncDue(c.autoSyncInterval, c.LastSyncTime, c.PendingOps, c.autoSyncMaxPending)
+}
+
+// autoSyncDue decides whether a write should sync now. With no interval set
+// every write syncs. Otherwise writes within interval of the last sync stay
+// queued unless maxPending queued writes have built up. Skipped writes go out
+// with the next sync of any kind. lastSync and pending are only called when
+// needed since each opens the database.
+func autoSyncDue(interval time.Duration, lastSync func() time.Time, pending func() int64, maxPending int) bool {
+	if interval <= 0 || time.Since(lastSync()) >= interval {
+		return true
+	}
+	return maxPending > 0 && pending() >= int64(maxPending)
+}
+
+// PendingOps returns how many local writes are waiting to be pushed.
+func (c *Client) PendingOps() int64 {
+	return c.counters().pending
+}
+
+// offlineState is whether syncs are failing, shared by a client and its
+// WithOptions copies. The REST API and RPC server call one client from
+// concurrent handlers, so it's guarded by a mutex that also keeps the
+// marker file in step.
+type offlineState struct {
+	mu      sync.Mutex
+	offline bool
+}
+
+// Offline reports whether the last sync attempt failed.
+func (c *Client) Offline() bool {
+	c.offline.mu.Lock()
+	defer c.offline.mu.Unlock()
+	return c.offline.offline
+}
+
+// ReadOnly reports whether this device refuses local writes.
+func (c *Client) ReadOnly() bool {
+	return c.readOnly
+}
+
+// offlinePath returns the marker whose presence means dbName's last sync
+// failed.
+func offlinePath(dbName string) string {
+	return stateFilePathFor(dbName, "offline", "")
+}
+
+// markedOffline reports whether the last sync of dbName, by any chronicle
+// process, failed.
+func markedOffline(dbName string) bool {
+	_, err := os.Stat(offlinePath(dbName))
+	return err == nil
+}
+
+// setOffline records the offline state in the client and the state dir,
+// so the next process starts knowing it. The marker only changes when the
+// state does.
+func (c *Client) setOffline(offline bool) {
+	c.offline.mu.Lock()
+	defer c.offline.mu.Unlock()
+	if c.offline.offline == offline {
+		return
+	}
+	c.offline.offline = offline
+
+	path := offlinePath(c.dbName)
+	var err error
+	if offline {
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+			err = os.WriteFile(path, []byte(time.Now().Format(time.RFC3339)+"\n"), 0600)
+		}
+	} else if err = os.Remove(path); os.IsNotExist(err) {
+		err = nil
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save offline state: %v\n", err)
+	}
+}
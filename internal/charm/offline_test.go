@@ -0,0 +1,154 @@
+// ABOUTME: Unit tests for auto-sync retries and offline tracking
+// ABOUTME: Stubs sleep and points the state dir at a temp dir
+package charm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithSyncRetry(t *testing.T) {
+	sleeps := stubSleep(t)
+
+	calls := 0
+	err := withSyncRetry(3, func() error {
+		calls++
+		if calls < 2 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 || *sleeps != 1 {
+		t.Errorf("err = %v, calls = %d, sleeps = %d; want nil, 2, 1", err, calls, *sleeps)
+	}
+
+	calls, *sleeps = 0, 0
+	err = withSyncRetry(3, func() error {
+		calls++
+		return errors.New("connection refused")
+	})
+	if err == nil || calls != 3 || *sleeps != 2 {
+		t.Errorf("err = %v, calls = %d, sleeps = %d; want error, 3, 2", err, calls, *sleeps)
+	}
+}
+
+func TestSetOfflinePersists(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	c := &Client{dbName: "offline-test", offline: &offlineState{}}
+	c.setOffline(true)
+	if !c.Offline() {
+		t.Fatal("expected client to be offline")
+	}
+	if !markedOffline(c.dbName) {
+		t.Error("expected offline state saved for the next process")
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "chronicle")); !os.IsNotExist(err) {
+		t.Error("expected the config dir left alone")
+	}
+
+	c.setOffline(false)
+	if markedOffline(c.dbName) {
+		t.Error("expected offline state cleared after reconnect")
+	}
+}
+
+func TestSetOfflineConcurrent(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	// API and RPC handlers share one client; run with -race to check
+	c := &Client{dbName: "offline-race-test", offline: &offlineState{}}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.setOffline(i%2 == 0)
+			_ = c.Offline()
+		}()
+	}
+	wg.Wait()
+
+	c.setOffline(!c.Offline())
+	if markedOffline(c.dbName) != c.Offline() {
+		t.Error("expected the marker to follow the last state")
+	}
+}
+
+func TestAutoSyncDue(t *testing.T) {
+	recent := func() time.Time { return time.Now().Add(-5 * time.Second) }
+	pendingCalls := 0
+	pending := func(n int64) func() int64 {
+		return func() int64 { pendingCalls++; return n }
+	}
+
+	if !autoSyncDue(0, recent, pending(0), 0) {
+		t.Error("expected every write to sync without an interval")
+	}
+	if autoSyncDue(time.Minute, recent, pending(3), 0) {
+		t.Error("expected write within the interval to be deferred")
+	}
+	if pendingCalls != 0 {
+		t.Error("pending count should not be read without a max")
+	}
+	if !autoSyncDue(time.Minute, recent, pending(10), 10) {
+		t.Error("expected sync once max pending is reached")
+	}
+	if !autoSyncDue(time.Second, recent, pending(0), 0) {
+		t.Error("expected sync once the interval has passed")
+	}
+}
+
+func TestAutoSyncWantedPolicy(t *testing.T) {
+	// None of these cases needs the database: no interval means every
+	// permitted write syncs, and every_n without a limit never does
+	tests := []struct {
+		name     string
+		client   Client
+		kind     writeKind
+		wantSync bool
+	}{
+		{"auto sync off", Client{autoSync: false}, writeAdd, false},
+		{"default add", Client{autoSync: true}, writeAdd, true},
+		{"default change", Client{autoSync: true}, writeChange, true},
+		{"every_write change", Client{autoSync: true, autoSyncPolicy: SyncPolicyEveryWrite}, writeChange, true},
+		{"on_add add", Client{autoSync: true, autoSyncPolicy: SyncPolicyOnAdd}, writeAdd, true},
+		{"on_add change", Client{autoSync: true, autoSyncPolicy: SyncPolicyOnAdd}, writeChange, false},
+		{"never", Client{autoSync: true, autoSyncPolicy: SyncPolicyNever}, writeAdd, false},
+		{"every_n without limit", Client{autoSync: true, autoSyncPolicy: SyncPolicyEveryN}, writeAdd, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.client.autoSyncWanted(tt.kind); got != tt.wantSync {
+				t.Errorf("autoSyncWanted() = %v, want %v", got, tt.wantSync)
+			}
+		})
+	}
+}
+
+func TestNewClientValidatesSyncPolicy(t *testing.T) {
+	t.Setenv(ProfileEnv, "")
+	cfg := DefaultConfig()
+	cfg.CharmHost = ""
+
+	cfg.AutoSyncPolicy = "sometimes"
+	if _, err := NewClient(cfg); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+
+	cfg.AutoSyncPolicy = SyncPolicyEveryN
+	if _, err := NewClient(cfg); err == nil {
+		t.Error("expected error for every_n without auto_sync_max_pending")
+	}
+
+	cfg.AutoSyncMaxPending = 10
+	if _, err := NewClient(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
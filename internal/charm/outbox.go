@@ -0,0 +1,149 @@
+// ABOUTME: Local outbox for entries added while the Charm server is unreachable
+// ABOUTME: Opening the KV store needs the server, so offline adds queue here and replay later
+
+package charm
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/charm/kv"
+	charmproto "github.com/charmbracelet/charm/proto"
+)
+
+// isOfflineError reports whether err means the Charm server couldn't be
+// reached. Opening the KV store authenticates first, so being offline fails
+// before any local read or write happens.
+func isOfflineError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var authErr charmproto.ErrAuthFailed
+	var netErr net.Error
+	return errors.As(err, &authErr) || errors.As(err, &netErr)
+}
+
+// IsOffline reports whether err means the Charm server couldn't be reached.
+func IsOffline(err error) bool {
+	return isOfflineError(err)
+}
+
+// outboxPath returns where offline entries for dbName are queued.
+func outboxPath(dbName string) string {
+	return stateFilePathFor(dbName, "outbox", ".jsonl")
+}
+
+// withOutboxLock runs fn holding an exclusive lock on the outbox, so a
+// process appending can't race one that is replaying.
+func withOutboxLock(path string, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create outbox dir: %w", err)
+	}
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("open outbox lock: %w", err)
+	}
+	defer func() { _ = lock.Close() }()
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("lock outbox: %w", err)
+	}
+	defer func() { _ = unlockFile(lock) }()
+	return fn()
+}
+
+// queueOffline appends prepared entries to the outbox.
+func queueOffline(path string, entries []Entry) error {
+	return withOutboxLock(path, func() error {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("open outbox: %w", err)
+		}
+		enc := json.NewEncoder(f)
+		for _, entry := range entries {
+			if err := enc.Encode(entry); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("queue entry %s: %w", entry.ID, err)
+			}
+		}
+		return f.Close()
+	})
+}
+
+// readOutbox returns the queued entries. A missing outbox is empty.
+func readOutbox(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open outbox: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	return entries, nil
+}
+
+// flushOutbox writes queued entries into an open store and empties the
+// outbox. Entries keep the IDs they were given offline, so replaying twice
+// is harmless.
+func (c *Client) flushOutbox(k *kv.KV) error {
+	path := outboxPath(c.dbName)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	return withOutboxLock(path, func() error {
+		entries, err := readOutbox(path)
+		if err != nil {
+			return err
+		}
+		for i := range entries {
+			if err := c.putEntry(k, &entries[i]); err != nil {
+				return fmt.Errorf("replay entry %s: %w", entries[i].ID, err)
+			}
+		}
+		return os.Remove(path)
+	})
+}
+
+// OutboxCount returns how many entries are waiting for the server.
+func (c *Client) OutboxCount() int {
+	entries, err := readOutbox(outboxPath(c.dbName))
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
+
+// storeOrQueue runs store and, if it failed because the server is
+// unreachable, queues entries in the outbox instead. queued reports which
+// happened.
+func (c *Client) storeOrQueue(entries []Entry, store func() error) (queued bool, err error) {
+	err = store()
+	if !isOfflineError(err) {
+		return false, err
+	}
+	if qerr := queueOffline(outboxPath(c.dbName), entries); qerr != nil {
+		return false, fmt.Errorf("%w (and queueing offline failed: %v)", err, qerr)
+	}
+	c.setOffline(true)
+	fmt.Fprintf(os.Stderr, "warning: Charm server unreachable, queued %d entries to add when back online\n", len(entries))
+	return true, nil
+}
@@ -0,0 +1,84 @@
+// ABOUTME: Tests for the offline outbox used when the Charm server is unreachable
+// ABOUTME: Covers offline error detection, queue round trips, and fallback on store failure
+package charm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+
+	charmproto "github.com/charmbracelet/charm/proto"
+)
+
+func TestIsOfflineError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", errors.New("disk full"), false},
+		{"auth failed", fmt.Errorf("open kv: %w", charmproto.ErrAuthFailed{Err: errors.New("dial tcp: refused")}), true},
+		{"network", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("refused")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOfflineError(tt.err); got != tt.want {
+				t.Errorf("isOfflineError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutboxRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+
+	entries, err := readOutbox(path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("readOutbox(missing) = %v, %v; want empty", entries, err)
+	}
+
+	if err := queueOffline(path, []Entry{{ID: "a", Message: "first"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := queueOffline(path, []Entry{{ID: "b", Message: "second"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err = readOutbox(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ID != "a" || entries[1].Message != "second" {
+		t.Errorf("readOutbox() = %+v, want entries a and b in order", entries)
+	}
+}
+
+func TestStoreOrQueue(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	c := &Client{dbName: "chronicle-outbox-test", offline: &offlineState{}}
+
+	queued, err := c.storeOrQueue([]Entry{{ID: "x"}}, func() error { return errors.New("disk full") })
+	if queued || err == nil {
+		t.Errorf("plain failure: queued = %v, err = %v; want false and an error", queued, err)
+	}
+	if c.OutboxCount() != 0 {
+		t.Error("expected nothing queued for a plain failure")
+	}
+
+	offline := charmproto.ErrAuthFailed{Err: errors.New("dial tcp: refused")}
+	queued, err = c.storeOrQueue([]Entry{{ID: "x"}, {ID: "y"}}, func() error { return offline })
+	if !queued || err != nil {
+		t.Fatalf("offline failure: queued = %v, err = %v; want true and nil", queued, err)
+	}
+	if got := c.OutboxCount(); got != 2 {
+		t.Errorf("OutboxCount() = %d, want 2", got)
+	}
+	if !c.Offline() {
+		t.Error("expected client to be marked offline")
+	}
+}
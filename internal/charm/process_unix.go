@@ -0,0 +1,25 @@
+// ABOUTME: Unix process checks for the sync daemon's status and stop commands
+// ABOUTME: Probes processes with signal 0 and stops them with SIGTERM
+
+//go:build !windows
+
+package charm
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether a process with pid exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// stopProcess sends pid SIGTERM, letting it finish what it's doing.
+func stopProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
@@ -0,0 +1,43 @@
+// ABOUTME: Windows process checks for the sync daemon's status and stop commands
+// ABOUTME: Windows has no signals, so a process is checked by its exit code and stopped by killing it
+
+//go:build windows
+
+package charm
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code Windows reports for a running process.
+const stillActive = 259
+
+// processAlive reports whether a process with pid exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Another user's process can't be opened but is still there
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer func() { _ = windows.CloseHandle(h) }()
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// stopProcess ends pid. Windows can't deliver SIGTERM, so the daemon is
+// killed; sync writes are transactional, so nothing is left half done.
+func stopProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
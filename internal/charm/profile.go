@@ -0,0 +1,118 @@
+// ABOUTME: Profiles keep separate journals (e.g. work and personal) in one Charm account
+// ABOUTME: Each profile gets its own KV database name and local state files
+
+package charm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/harper/chronicle/internal/config"
+)
+
+// ProfileEnv selects the profile from the environment, overriding the config.
+const ProfileEnv = "CHRONICLE_PROFILE"
+
+// validProfile matches profile names that are safe in database and file names.
+var validProfile = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ResolveProfile returns the active profile: CHRONICLE_PROFILE if set,
+// else the configured one. Empty means the default profile.
+func ResolveProfile(configured string) string {
+	if env := os.Getenv(ProfileEnv); env != "" {
+		return env
+	}
+	return configured
+}
+
+// ValidateProfile checks that a profile name can be used as a database name.
+func ValidateProfile(profile string) error {
+	if profile == "" || validProfile.MatchString(profile) {
+		return nil
+	}
+	return fmt.Errorf("invalid profile %q: use lowercase letters, digits, '-' and '_'", profile)
+}
+
+// DBNameForProfile returns the KV database name for a profile. The default
+// profile keeps the original name so existing journals are untouched.
+func DBNameForProfile(profile string) string {
+	if profile == "" || profile == "default" {
+		return DBName
+	}
+	return DBName + "-" + profile
+}
+
+// configuredDBName resolves the database name without a client.
+func configuredDBName() string {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return DBNameForProfile(ResolveProfile(""))
+	}
+	return DBNameForProfile(ResolveProfile(cfg.Profile))
+}
+
+// stateFilePath returns a local state file under the chronicle state dir.
+// Non-default profiles get their own copy, e.g. sync-log-work.jsonl.
+func stateFilePath(name, ext string) string {
+	return stateFilePathFor(configuredDBName(), name, ext)
+}
+
+// CalDAVStatePath returns where caldav push remembers what it published.
+func CalDAVStatePath() string {
+	return stateFilePath("caldav", ".json")
+}
+
+// TogglStatePath returns where export --push remembers the time entries
+// it created in Toggl.
+func TogglStatePath() string {
+	return stateFilePath("toggl", ".json")
+}
+
+// AutoTagCachePath returns where auto-tagging remembers the model's
+// answers.
+func AutoTagCachePath() string {
+	return stateFilePath("autotag-cache", ".json")
+}
+
+// SocketPath returns where serve --socket listens by default.
+func SocketPath() string {
+	return stateFilePath("chronicle", ".sock")
+}
+
+// AttachmentsDir returns where files linked to entries, such as
+// screenshots, are kept. They live beside the database, one directory per
+// profile, and aren't synced.
+func AttachmentsDir() string {
+	name := "attachments" + strings.TrimPrefix(configuredDBName(), DBName)
+	return filepath.Join(config.GetDataHome(), "chronicle", name)
+}
+
+// stateFilePathFor is stateFilePath for a known database name. A file left
+// in the data dir by older builds is moved over the first time it's needed.
+func stateFilePathFor(dbName, name, ext string) string {
+	file := name + strings.TrimPrefix(dbName, DBName) + ext
+	path := filepath.Join(config.GetStateHome(), "chronicle", file)
+	migrateStateFile(filepath.Join(config.GetDataHome(), "chronicle", file), path)
+	return path
+}
+
+// migrateStateFile moves oldPath to path unless path already exists.
+// Failures leave the old file where it is.
+func migrateStateFile(oldPath, path string) {
+	if oldPath == path {
+		return
+	}
+	if _, err := os.Stat(path); err == nil {
+		return
+	}
+	if _, err := os.Stat(oldPath); err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = os.Rename(oldPath, path)
+}
@@ -0,0 +1,78 @@
+// ABOUTME: Tests for profile resolution and per-profile database names
+// ABOUTME: Uses t.Setenv to exercise the CHRONICLE_PROFILE override
+package charm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDBNameForProfile(t *testing.T) {
+	tests := map[string]string{
+		"":        "chronicle",
+		"default": "chronicle",
+		"work":    "chronicle-work",
+	}
+	for profile, want := range tests {
+		if got := DBNameForProfile(profile); got != want {
+			t.Errorf("DBNameForProfile(%q) = %q, want %q", profile, got, want)
+		}
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	t.Setenv(ProfileEnv, "")
+	if got := ResolveProfile("personal"); got != "personal" {
+		t.Errorf("got %q, want configured profile", got)
+	}
+	t.Setenv(ProfileEnv, "work")
+	if got := ResolveProfile("personal"); got != "work" {
+		t.Errorf("got %q, want env override", got)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	for _, p := range []string{"", "work", "side-project_2"} {
+		if err := ValidateProfile(p); err != nil {
+			t.Errorf("ValidateProfile(%q): %v", p, err)
+		}
+	}
+	for _, p := range []string{"Work", "../evil", "a b", "-x"} {
+		if err := ValidateProfile(p); err == nil {
+			t.Errorf("ValidateProfile(%q): expected error", p)
+		}
+	}
+}
+
+func TestNewClientRejectsInvalidProfile(t *testing.T) {
+	t.Setenv(ProfileEnv, "Not Valid")
+	if _, err := NewClient(DefaultConfig()); err == nil {
+		t.Error("expected invalid profile to be rejected")
+	}
+}
+
+func TestStateFilePathMigrates(t *testing.T) {
+	dataHome, stateHome := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	old := filepath.Join(dataHome, "chronicle", "sync-log-work.jsonl")
+	if err := os.MkdirAll(filepath.Dir(old), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(old, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path := stateFilePathFor(DBNameForProfile("work"), "sync-log", ".jsonl")
+	if want := filepath.Join(stateHome, "chronicle", "sync-log-work.jsonl"); path != want {
+		t.Fatalf("got %s, want %s", path, want)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("state file not moved: %v", err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("old state file still present: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+// ABOUTME: Project model and lookups for associating entries with projects
+// ABOUTME: Uses type-prefixed keys (project:uuid) keyed by detected project root
+package charm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+	"github.com/google/uuid"
+)
+
+// ProjectPrefix is the key prefix for chronicle projects.
+const ProjectPrefix = "project:"
+
+// Project represents a directory tree that entries can be associated with.
+type Project struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	RootPath    string    `json:"root_path"`
+	DefaultTags []string  `json:"default_tags,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// projectKey returns the KV key for a project.
+func projectKey(id string) []byte {
+	return []byte(ProjectPrefix + id)
+}
+
+// ListProjects returns all projects sorted by name.
+func (c *Client) ListProjects() ([]Project, error) {
+	var projects []Project
+
+	err := c.DoReadOnly(func(k *kv.KV) error {
+		var err error
+		projects, err = readProjects(k)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+	return projects, nil
+}
+
+// GetProject retrieves a project by ID.
+func (c *Client) GetProject(id string) (*Project, error) {
+	var project Project
+	if err := c.GetJSON(projectKey(id), &project); err != nil {
+		return nil, fmt.Errorf("get project: %w", err)
+	}
+	return &project, nil
+}
+
+// FindProjectByName returns the project with the given name (case-insensitive).
+// Returns nil if no project matches.
+func (c *Client) FindProjectByName(name string) (*Project, error) {
+	projects, err := c.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	for i := range projects {
+		if strings.EqualFold(projects[i].Name, name) {
+			return &projects[i], nil
+		}
+	}
+	return nil, nil //nolint:nilnil // nil project means not found
+}
+
+// FindProjectByRoot returns the project rooted at root without creating it.
+// Returns nil if there is none.
+func (c *Client) FindProjectByRoot(root string) (*Project, error) {
+	projects, err := c.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	root = filepath.Clean(root)
+	for i := range projects {
+		if projects[i].RootPath == root {
+			return &projects[i], nil
+		}
+	}
+	return nil, nil //nolint:nilnil // nil project means not found
+}
+
+// EnsureProject returns the project rooted at root, creating it if needed.
+// New projects are named after the root directory.
+func (c *Client) EnsureProject(root string) (*Project, error) {
+	if root == "" {
+		return nil, errors.New("project root required")
+	}
+	root = filepath.Clean(root)
+
+	var project *Project
+	err := c.Do(func(k *kv.KV) error {
+		projects, err := readProjects(k)
+		if err != nil {
+			return err
+		}
+		for i := range projects {
+			if projects[i].RootPath == root {
+				project = &projects[i]
+				return nil
+			}
+		}
+
+		project = &Project{
+			ID:        uuid.New().String(),
+			Name:      filepath.Base(root),
+			RootPath:  root,
+			CreatedAt: time.Now(),
+		}
+		data, err := json.Marshal(project)
+		if err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+		return k.Set(projectKey(project.ID), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ensure project: %w", err)
+	}
+	return project, nil
+}
+
+// UpdateProject updates an existing project.
+func (c *Client) UpdateProject(project Project) error {
+	if project.ID == "" {
+		return fmt.Errorf("project ID required")
+	}
+	if err := c.SetJSON(projectKey(project.ID), project); err != nil {
+		return fmt.Errorf("update project: %w", err)
+	}
+	return nil
+}
+
+// readProjects loads every project record from an open KV store.
+func readProjects(k *kv.KV) ([]Project, error) {
+	keys, err := k.Keys()
+	if err != nil {
+		return nil, fmt.Errorf("get keys: %w", err)
+	}
+
+	var projects []Project
+	for _, key := range keys {
+		if !strings.HasPrefix(string(key), ProjectPrefix) {
+			continue
+		}
+		val, err := k.Get(key)
+		if err != nil {
+			continue
+		}
+		var project Project
+		if err := json.Unmarshal(val, &project); err != nil {
+			// Skip invalid projects (corrupted data)
+			continue
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
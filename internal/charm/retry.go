@@ -0,0 +1,62 @@
+// ABOUTME: Bounded retry with jitter for writes that hit database lock contention
+// ABOUTME: Lets the CLI and MCP server write concurrently without spurious failures
+
+package charm
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+const (
+	// lockRetryAttempts is how many times a write is tried before giving up.
+	lockRetryAttempts = 5
+
+	// lockRetryBaseDelay is the first backoff delay; it doubles per attempt.
+	lockRetryBaseDelay = 50 * time.Millisecond
+)
+
+// sleep is replaced in tests to avoid real delays.
+var sleep = time.Sleep
+
+// isLockContention reports whether err means another process holds the database.
+// SQLite reports "database is locked" (SQLITE_BUSY) once busy_timeout expires.
+func isLockContention(err error) bool {
+	if err == nil {
+		return false
+	}
+	if kv.IsLocked(err) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "SQLITE_BUSY")
+}
+
+// IsLocked reports whether err means the database was held by another process.
+func IsLocked(err error) bool {
+	return isLockContention(err)
+}
+
+// withLockRetry runs fn, retrying with exponential backoff and jitter while
+// the database is locked by another process. Other errors are returned as-is.
+func withLockRetry(fn func() error) error {
+	var err error
+	delay := lockRetryBaseDelay
+	for attempt := 1; attempt <= lockRetryAttempts; attempt++ {
+		err = fn()
+		if !isLockContention(err) {
+			return err
+		}
+		if attempt < lockRetryAttempts {
+			jitter := time.Duration(rand.Int64N(int64(delay)))
+			sleep(delay + jitter)
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("database still locked after %d attempts; another chronicle process "+
+		"(such as the MCP server) may be writing, try again shortly: %w", lockRetryAttempts, err)
+}
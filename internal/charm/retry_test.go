@@ -0,0 +1,74 @@
+// ABOUTME: Unit tests for lock contention retry
+// ABOUTME: Uses a stubbed sleep so tests run without real delays
+package charm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+func stubSleep(t *testing.T) *int {
+	t.Helper()
+	sleeps := 0
+	orig := sleep
+	sleep = func(time.Duration) { sleeps++ }
+	t.Cleanup(func() { sleep = orig })
+	return &sleeps
+}
+
+func TestWithLockRetrySucceedsAfterContention(t *testing.T) {
+	sleeps := stubSleep(t)
+
+	calls := 0
+	err := withLockRetry(func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("database is locked (5) (SQLITE_BUSY)")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 || *sleeps != 2 {
+		t.Errorf("calls = %d, sleeps = %d; want 3 and 2", calls, *sleeps)
+	}
+}
+
+func TestWithLockRetryGivesUp(t *testing.T) {
+	stubSleep(t)
+
+	calls := 0
+	lockErr := &kv.ErrDatabaseLocked{Path: "/tmp/db", Err: errors.New("busy")}
+	err := withLockRetry(func() error {
+		calls++
+		return lockErr
+	})
+	if calls != lockRetryAttempts {
+		t.Errorf("calls = %d, want %d", calls, lockRetryAttempts)
+	}
+	if !errors.Is(err, lockErr) {
+		t.Errorf("expected wrapped lock error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "still locked") {
+		t.Errorf("expected contention message, got: %v", err)
+	}
+}
+
+func TestWithLockRetryPassesThroughOtherErrors(t *testing.T) {
+	sleeps := stubSleep(t)
+
+	want := errors.New("disk full")
+	calls := 0
+	err := withLockRetry(func() error {
+		calls++
+		return want
+	})
+	if !errors.Is(err, want) || calls != 1 || *sleeps != 0 {
+		t.Errorf("err = %v, calls = %d, sleeps = %d; want passthrough with no retry", err, calls, *sleeps)
+	}
+}
@@ -0,0 +1,273 @@
+// ABOUTME: Similarity search over entries using BM25 term scoring
+// ABOUTME: Finds past entries that share rare words with a query, for "you've seen this before"
+
+package charm
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 tuning constants, at their usual defaults.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// similarityStopwords are words too common to say two entries are related.
+var similarityStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "from": true, "had": true,
+	"has": true, "have": true, "i": true, "in": true, "into": true, "is": true,
+	"it": true, "its": true, "me": true, "my": true, "of": true, "on": true,
+	"or": true, "so": true, "that": true, "the": true, "this": true, "to": true,
+	"was": true, "we": true, "were": true, "with": true,
+}
+
+// Similar is an entry found by SimilarEntries and how closely it matched.
+type Similar struct {
+	Entry Entry   `json:"entry"`
+	Score float64 `json:"score"`
+}
+
+// similarDoc is one candidate entry's term counts.
+type similarDoc struct {
+	entry  Entry
+	terms  map[string]int
+	length int
+}
+
+// similarIndex collects candidate entries and ranks them against a query.
+type similarIndex struct {
+	docs        []similarDoc
+	docFreq     map[string]int
+	totalLength int
+}
+
+// add indexes entry as a candidate.
+func (x *similarIndex) add(entry *Entry) {
+	if x.docFreq == nil {
+		x.docFreq = make(map[string]int)
+	}
+	terms := make(map[string]int)
+	length := 0
+	for _, term := range similarityTerms(entryText(entry)) {
+		terms[term]++
+		length++
+	}
+	for term := range terms {
+		x.docFreq[term]++
+	}
+	x.totalLength += length
+	x.docs = append(x.docs, similarDoc{entry: *entry, terms: terms, length: length})
+}
+
+// rank scores every candidate against query with BM25 and returns up to
+// limit (0 for all) with a positive score, best first, newer first on ties.
+func (x *similarIndex) rank(query string, limit int) []Similar {
+	if len(x.docs) == 0 {
+		return nil
+	}
+	// Each distinct query term counts once, so repeating a word in the
+	// query doesn't outweigh the others
+	unique := make(map[string]bool)
+	for _, term := range similarityTerms(query) {
+		unique[term] = true
+	}
+
+	n := float64(len(x.docs))
+	avgLength := float64(x.totalLength) / n
+	var results []Similar
+	for _, doc := range x.docs {
+		score := 0.0
+		for term := range unique {
+			tf := float64(doc.terms[term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(x.docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*float64(doc.length)/avgLength
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+		if score > 0 {
+			results = append(results, Similar{Entry: doc.entry, Score: score})
+		}
+	}
+
+	return bestFirst(results, limit)
+}
+
+// rankPrefix is rank for text still being typed: every query word must
+// start some word of an entry, and counts each word it starts. Unlike
+// rank, single characters and a lone stopword still match, since they may
+// be the start of a longer word.
+func (x *similarIndex) rankPrefix(query string, limit int) []Similar {
+	var prefixes []string
+	seen := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[word] {
+			seen[word] = true
+			prefixes = append(prefixes, word)
+		}
+	}
+	if len(x.docs) == 0 || len(prefixes) == 0 {
+		return nil
+	}
+
+	// How often each prefix starts a word of each doc
+	tfs := make([][]int, len(x.docs))
+	docFreq := make([]int, len(prefixes))
+	for i, doc := range x.docs {
+		tfs[i] = make([]int, len(prefixes))
+		for term, count := range doc.terms {
+			for j, prefix := range prefixes {
+				if strings.HasPrefix(term, prefix) {
+					tfs[i][j] += count
+				}
+			}
+		}
+		for j, tf := range tfs[i] {
+			if tf > 0 {
+				docFreq[j]++
+			}
+		}
+	}
+
+	n := float64(len(x.docs))
+	avgLength := math.Max(float64(x.totalLength)/n, 1)
+	var results []Similar
+docs:
+	for i, doc := range x.docs {
+		score := 0.0
+		for j, count := range tfs[i] {
+			if count == 0 {
+				continue docs
+			}
+			tf, df := float64(count), float64(docFreq[j])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*float64(doc.length)/avgLength
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+		results = append(results, Similar{Entry: doc.entry, Score: score})
+	}
+	return bestFirst(results, limit)
+}
+
+// bestFirst sorts results by score, newer first on ties, and keeps up to
+// limit (0 for all).
+func bestFirst(results []Similar, limit int) []Similar {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Entry.Timestamp.After(results[j].Entry.Timestamp)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// SimilarEntries returns up to limit visible entries matching filter (nil
+// for all) that are most similar to query, best first, scored with BM25
+// over their messages, tags, and metadata. The entry excludeID, if set, is
+// left out. Entries sharing no terms with query are never returned.
+func (c *Client) SimilarEntries(filter *SearchFilter, query, excludeID string, limit int) ([]Similar, error) {
+	if len(similarityTerms(query)) == 0 {
+		return nil, nil
+	}
+	var index similarIndex
+	err := c.IterateEntries(filter, func(entry *Entry) error {
+		if entry.ID != excludeID {
+			index.add(entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return index.rank(query, limit), nil
+}
+
+// QueryEntries returns up to limit visible entries matching filter whose
+// words start with every word of query, best first, for search boxes that
+// query on each keystroke. An empty query returns the newest entries, each
+// with a zero score.
+func (c *Client) QueryEntries(filter *SearchFilter, query string, limit int) ([]Similar, error) {
+	if strings.TrimSpace(query) == "" {
+		entries, err := c.SearchEntries(filter, limit)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]Similar, len(entries))
+		for i, entry := range entries {
+			results[i] = Similar{Entry: entry}
+		}
+		return results, nil
+	}
+	var index similarIndex
+	err := c.IterateEntries(filter, func(entry *Entry) error {
+		index.add(entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return index.rankPrefix(query, limit), nil
+}
+
+// ContainsAllTerms reports whether entry contains every word of query
+// that similarity compares, in any order. A query without such words
+// matches nothing.
+func ContainsAllTerms(entry *Entry, query string) bool {
+	terms := similarityTerms(query)
+	if len(terms) == 0 {
+		return false
+	}
+	have := make(map[string]bool)
+	for _, term := range similarityTerms(entryText(entry)) {
+		have[term] = true
+	}
+	for _, term := range terms {
+		if !have[term] {
+			return false
+		}
+	}
+	return true
+}
+
+// SimilarityQuery returns the text SimilarEntries should search with to
+// find entries like entry.
+func SimilarityQuery(entry *Entry) string {
+	return entryText(entry)
+}
+
+// entryText is the text of entry that similarity compares: its message,
+// tags, and metadata values.
+func entryText(entry *Entry) string {
+	parts := append([]string{entry.Message}, entry.Tags...)
+	for _, value := range entry.Metadata {
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// similarityTerms splits text into lowercase words, dropping stopwords and
+// single characters.
+func similarityTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := words[:0]
+	for _, word := range words {
+		if len([]rune(word)) > 1 && !similarityStopwords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
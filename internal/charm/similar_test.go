@@ -0,0 +1,98 @@
+// ABOUTME: Tests for BM25 similarity ranking over entries
+// ABOUTME: Uses an in-memory index so no database is needed
+
+package charm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSimilarityTerms(t *testing.T) {
+	got := similarityTerms("Fixed the OOM in worker-pool, v2 (again)!")
+	want := []string{"fixed", "oom", "worker", "pool", "v2", "again"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("similarityTerms() = %v, want %v", got, want)
+	}
+}
+
+func TestSimilarIndexRank(t *testing.T) {
+	at := func(d int) time.Time { return time.Date(2025, 3, d, 9, 0, 0, 0, time.UTC) }
+	var index similarIndex
+	for _, entry := range []Entry{
+		{ID: "oom", Timestamp: at(3), Message: "worker OOM fixed by capping the batch size", Tags: []string{"bug"}},
+		{ID: "standup", Timestamp: at(4), Message: "standup with the team", Tags: []string{"meeting"}},
+		{ID: "bug", Timestamp: at(5), Message: "login bug fixed", Tags: []string{"bug"}},
+		{ID: "decision", Timestamp: at(6), Message: "chose sqlite", Metadata: map[string]string{"rationale": "worker memory stays low"}},
+		{ID: "other", Timestamp: at(7), Message: "lunch"},
+	} {
+		index.add(&entry)
+	}
+
+	got := index.rank("worker crashed with OOM again", 0)
+	ids := make([]string, len(got))
+	for i, s := range got {
+		ids[i] = s.Entry.ID
+	}
+	// The OOM entry shares two rare words; the decision shares one via metadata
+	if want := []string{"oom", "decision"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("rank() = %v, want %v", ids, want)
+	}
+	if got[0].Score <= got[1].Score {
+		t.Errorf("scores %v, want best first", got)
+	}
+
+	if got := index.rank("worker", 1); len(got) != 1 {
+		t.Errorf("rank with limit 1 returned %d results", len(got))
+	}
+	if got := index.rank("the and of", 0); got != nil {
+		t.Errorf("stopword-only query returned %v, want nothing", got)
+	}
+}
+
+func TestSimilarIndexRankPrefix(t *testing.T) {
+	at := func(d int) time.Time { return time.Date(2025, 3, d, 9, 0, 0, 0, time.UTC) }
+	var index similarIndex
+	for _, entry := range []Entry{
+		{ID: "deploy", Timestamp: at(3), Message: "deployed the API to staging", Tags: []string{"ops"}},
+		{ID: "deploys", Timestamp: at(4), Message: "deploy deploy deploy, rollback of the deployment"},
+		{ID: "design", Timestamp: at(5), Message: "design review for the API"},
+		{ID: "lunch", Timestamp: at(6), Message: "lunch"},
+	} {
+		index.add(&entry)
+	}
+
+	ids := func(results []Similar) []string {
+		out := make([]string, len(results))
+		for i, s := range results {
+			out[i] = s.Entry.ID
+		}
+		return out
+	}
+	// An unfinished word matches every word it starts, more matches first
+	if got, want := ids(index.rankPrefix("depl", 0)), []string{"deploys", "deploy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rankPrefix(depl) = %v, want %v", got, want)
+	}
+	// Every word must match
+	if got, want := ids(index.rankPrefix("api sta", 0)), []string{"deploy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rankPrefix(api sta) = %v, want %v", got, want)
+	}
+	// A single letter still narrows, and the limit applies
+	if got := ids(index.rankPrefix("D", 2)); len(got) != 2 || got[0] != "deploys" {
+		t.Errorf("rankPrefix(D, 2) = %v, want two results led by deploys", got)
+	}
+	if got := index.rankPrefix(" , ", 0); got != nil {
+		t.Errorf("rankPrefix without words = %v, want nothing", got)
+	}
+}
+
+func TestContainsAllTerms(t *testing.T) {
+	entry := &Entry{Message: "Rotated the staging TLS certificates", Tags: []string{"ops"}}
+	if !ContainsAllTerms(entry, "certificates staging") || !ContainsAllTerms(entry, "ops TLS") {
+		t.Error("expected a match when every word is present in any order")
+	}
+	if ContainsAllTerms(entry, "production certificates") || ContainsAllTerms(entry, "the") {
+		t.Error("expected no match with a missing word or only stopwords")
+	}
+}
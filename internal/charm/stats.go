@@ -0,0 +1,244 @@
+// ABOUTME: Aggregation queries over entries for stats and reports
+// ABOUTME: Streams entries once and keeps only counters, never the full entry set
+
+package charm
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// dayLayout is the key format for per-day counts.
+const dayLayout = "2006-01-02"
+
+// created counts the entries this process has stored, by source.
+var created = struct {
+	sync.Mutex
+	bySource map[string]int
+}{bySource: make(map[string]int)}
+
+// countCreated adds newly stored entries to the per-process counts.
+func countCreated(entries ...Entry) {
+	created.Lock()
+	defer created.Unlock()
+	for _, entry := range entries {
+		created.bySource[entry.Source]++
+	}
+}
+
+// CreatedBySource returns how many entries this process has stored since it
+// started, by source. Entries without a source are counted under "".
+func CreatedBySource() map[string]int {
+	created.Lock()
+	defer created.Unlock()
+	counts := make(map[string]int, len(created.bySource))
+	for source, n := range created.bySource {
+		counts[source] = n
+	}
+	return counts
+}
+
+// Count pairs a value (tag, directory, ...) with how many entries had it.
+type Count struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Stats holds aggregate counts over a set of entries.
+// Days and hours are in local time.
+type Stats struct {
+	Total         int            `json:"total"`
+	EntriesPerDay map[string]int `json:"entries_per_day"`
+	HourHistogram [24]int        `json:"hour_histogram"`
+	Sources       map[string]int `json:"sources"`
+	Tags          map[string]int `json:"-"`
+	Directories   map[string]int `json:"-"`
+}
+
+// newStats returns an empty Stats ready for accumulation.
+func newStats() *Stats {
+	return &Stats{
+		EntriesPerDay: make(map[string]int),
+		Sources:       make(map[string]int),
+		Tags:          make(map[string]int),
+		Directories:   make(map[string]int),
+	}
+}
+
+// add folds one entry into the counters.
+func (s *Stats) add(entry *Entry) {
+	ts := entry.Timestamp.Local()
+	s.Total++
+	s.EntriesPerDay[ts.Format(dayLayout)]++
+	s.HourHistogram[ts.Hour()]++
+	if entry.Source != "" {
+		s.Sources[entry.Source]++
+	}
+	for _, tag := range entry.Tags {
+		s.Tags[tag]++
+	}
+	if entry.WorkingDirectory != "" {
+		s.Directories[entry.WorkingDirectory]++
+	}
+}
+
+// Stats aggregates every entry matching filter (nil for all entries).
+func (c *Client) Stats(filter *SearchFilter) (*Stats, error) {
+	stats := newStats()
+	err := c.IterateEntries(filter, func(entry *Entry) error {
+		stats.add(entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// TopTags returns the n most used tags, most frequent first.
+// n <= 0 returns all tags.
+func (s *Stats) TopTags(n int) []Count {
+	return topCounts(s.Tags, n)
+}
+
+// TopDirectories returns the n most common working directories.
+// n <= 0 returns all directories.
+func (s *Stats) TopDirectories(n int) []Count {
+	return topCounts(s.Directories, n)
+}
+
+// LongestStreak returns the most consecutive days that each had an entry.
+func (s *Stats) LongestStreak() int {
+	days := make([]time.Time, 0, len(s.EntriesPerDay))
+	for day := range s.EntriesPerDay {
+		t, err := time.ParseInLocation(dayLayout, day, time.Local)
+		if err != nil {
+			continue
+		}
+		days = append(days, t)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	longest, current := 0, 0
+	for i, day := range days {
+		if i > 0 && days[i-1].AddDate(0, 0, 1).Equal(day) {
+			current++
+		} else {
+			current = 1
+		}
+		if current > longest {
+			longest = current
+		}
+	}
+	return longest
+}
+
+// topCounts sorts counts descending (ties alphabetical) and keeps the first n.
+func topCounts(m map[string]int, n int) []Count {
+	counts := make([]Count, 0, len(m))
+	for value, count := range m {
+		counts = append(counts, Count{Value: value, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+	if n > 0 && len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// TagUsage summarizes one tag over a window and the equally long window
+// before it. Examples holds the tag's most recent entries in the window.
+type TagUsage struct {
+	Tag      string  `json:"tag"`
+	Count    int     `json:"count"`
+	Previous int     `json:"previous"`
+	Examples []Entry `json:"examples,omitempty"`
+}
+
+// tagUsageCounter accumulates TagUsage for a window starting at since.
+type tagUsageCounter struct {
+	since    time.Time
+	examples int
+	usage    map[string]*TagUsage
+}
+
+// add counts an entry toward the current window if it is at or after since,
+// otherwise toward the previous one.
+func (t *tagUsageCounter) add(entry *Entry) {
+	current := !entry.Timestamp.Before(t.since)
+	for _, tag := range entry.Tags {
+		u, ok := t.usage[tag]
+		if !ok {
+			u = &TagUsage{Tag: tag}
+			t.usage[tag] = u
+		}
+		if !current {
+			u.Previous++
+			continue
+		}
+		u.Count++
+		if t.examples <= 0 {
+			continue
+		}
+		// Keep the newest examples, newest first
+		i := sort.Search(len(u.Examples), func(i int) bool {
+			return u.Examples[i].Timestamp.Before(entry.Timestamp)
+		})
+		if i >= t.examples {
+			continue
+		}
+		u.Examples = append(u.Examples, Entry{})
+		copy(u.Examples[i+1:], u.Examples[i:])
+		u.Examples[i] = *entry
+		if len(u.Examples) > t.examples {
+			u.Examples = u.Examples[:t.examples]
+		}
+	}
+}
+
+// sorted returns tags used in the current window, most used first (ties
+// alphabetical), followed by tags only used in the previous one.
+func (t *tagUsageCounter) sorted() []TagUsage {
+	usage := make([]TagUsage, 0, len(t.usage))
+	for _, u := range t.usage {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Count != usage[j].Count {
+			return usage[i].Count > usage[j].Count
+		}
+		if usage[i].Previous != usage[j].Previous {
+			return usage[i].Previous > usage[j].Previous
+		}
+		return usage[i].Tag < usage[j].Tag
+	})
+	return usage
+}
+
+// TagUsageBetween counts tags on entries matching filter (nil for all) from
+// since to until, comparing each with the same-length window just before
+// since. The filter's own dates are ignored. Up to examples recent entries
+// are kept per tag.
+func (c *Client) TagUsageBetween(filter *SearchFilter, since, until time.Time, examples int) ([]TagUsage, error) {
+	counter := &tagUsageCounter{since: since, examples: examples, usage: make(map[string]*TagUsage)}
+	previous := since.Add(-until.Sub(since))
+	window := SearchFilter{}
+	if filter != nil {
+		window = *filter
+	}
+	window.Since, window.Until = &previous, &until
+	err := c.IterateEntries(&window, func(entry *Entry) error {
+		counter.add(entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counter.sorted(), nil
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Unit tests for entry aggregation
+// ABOUTME: Builds Stats from in-memory entries without a KV store
+package charm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsAggregation(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2025, 11, d, hour, 0, 0, 0, time.Local)
+	}
+	entries := []Entry{
+		{Timestamp: day(1, 9), Tags: []string{"work", "go"}, WorkingDirectory: "/src/a"},
+		{Timestamp: day(2, 9), Tags: []string{"work"}, WorkingDirectory: "/src/a"},
+		{Timestamp: day(2, 14), Tags: []string{"oss"}, WorkingDirectory: "/src/b"},
+		{Timestamp: day(3, 22)},
+		{Timestamp: day(10, 9), Tags: []string{"go"}},
+	}
+
+	stats := newStats()
+	for i := range entries {
+		stats.add(&entries[i])
+	}
+
+	if stats.Total != 5 {
+		t.Errorf("Total = %d, want 5", stats.Total)
+	}
+	if got := stats.EntriesPerDay["2025-11-02"]; got != 2 {
+		t.Errorf("EntriesPerDay[2025-11-02] = %d, want 2", got)
+	}
+	if stats.HourHistogram[9] != 3 || stats.HourHistogram[22] != 1 {
+		t.Errorf("HourHistogram = %v", stats.HourHistogram)
+	}
+	if got := stats.LongestStreak(); got != 3 {
+		t.Errorf("LongestStreak() = %d, want 3", got)
+	}
+
+	tags := stats.TopTags(2)
+	if len(tags) != 2 || tags[0] != (Count{"go", 2}) || tags[1] != (Count{"work", 2}) {
+		t.Errorf("TopTags(2) = %v", tags)
+	}
+	dirs := stats.TopDirectories(0)
+	if len(dirs) != 2 || dirs[0] != (Count{"/src/a", 2}) {
+		t.Errorf("TopDirectories(0) = %v", dirs)
+	}
+}
+
+func TestLongestStreakEmpty(t *testing.T) {
+	if got := newStats().LongestStreak(); got != 0 {
+		t.Errorf("LongestStreak() = %d, want 0", got)
+	}
+}
+
+func TestTagUsageCounter(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 11, d, 12, 0, 0, 0, time.UTC) }
+	counter := &tagUsageCounter{since: day(10), examples: 2, usage: make(map[string]*TagUsage)}
+
+	for _, e := range []Entry{
+		{ID: "old-go", Timestamp: day(5), Tags: []string{"go"}},
+		{ID: "old-ops", Timestamp: day(6), Tags: []string{"ops"}},
+		{ID: "go1", Timestamp: day(11), Tags: []string{"go", "sync"}},
+		{ID: "go3", Timestamp: day(13), Tags: []string{"go"}},
+		{ID: "go2", Timestamp: day(12), Tags: []string{"go"}},
+	} {
+		counter.add(&e)
+	}
+
+	usage := counter.sorted()
+	if len(usage) != 3 {
+		t.Fatalf("got %d tags, want 3: %+v", len(usage), usage)
+	}
+
+	goUsage := usage[0]
+	if goUsage.Tag != "go" || goUsage.Count != 3 || goUsage.Previous != 1 {
+		t.Errorf("go usage = %+v, want 3 now and 1 before", goUsage)
+	}
+	if len(goUsage.Examples) != 2 || goUsage.Examples[0].ID != "go3" || goUsage.Examples[1].ID != "go2" {
+		t.Errorf("go examples = %+v, want go3 then go2", goUsage.Examples)
+	}
+	if usage[1].Tag != "sync" || usage[2].Tag != "ops" || usage[2].Count != 0 || usage[2].Previous != 1 {
+		t.Errorf("order = %s, %s; want sync then the previous-only ops", usage[1].Tag, usage[2].Tag)
+	}
+}
+
+func TestCreatedBySource(t *testing.T) {
+	before := CreatedBySource()
+	countCreated(Entry{Source: SourceAPI}, Entry{Source: SourceAPI}, Entry{})
+	after := CreatedBySource()
+	if after[SourceAPI]-before[SourceAPI] != 2 || after[""]-before[""] != 1 {
+		t.Errorf("counts went from %v to %v", before, after)
+	}
+
+	// The returned map is a copy
+	after[SourceAPI] = -1
+	if CreatedBySource()[SourceAPI] == -1 {
+		t.Error("CreatedBySource exposed its internal map")
+	}
+}
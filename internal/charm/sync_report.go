@@ -0,0 +1,58 @@
+// ABOUTME: Manual sync that reports what was pushed and pulled
+// ABOUTME: Compares pending ops and local sequence before and after the sync
+
+package charm
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+// SyncReport summarizes one manual sync.
+type SyncReport struct {
+	Pushed   int64         `json:"pushed"`
+	Pulled   uint64        `json:"pulled"`
+	Pending  int64         `json:"pending"`
+	Duration time.Duration `json:"duration"`
+}
+
+// syncCounters is the part of a database health check a sync report needs.
+type syncCounters struct {
+	pending int64
+	seq     uint64
+}
+
+// counters reads pending ops and the local sequence without a write lock.
+func (c *Client) counters() syncCounters {
+	result, err := kv.DoctorDB(c.dbName, c.kvOptions()...)
+	if err != nil || result == nil {
+		return syncCounters{}
+	}
+	return syncCounters{pending: result.PendingOpsCount, seq: result.LocalSeq}
+}
+
+// SyncWithReport syncs once and reports how many queued writes were pushed
+// and how many changes were pulled. Cancelling ctx aborts the sync.
+func (c *Client) SyncWithReport(ctx context.Context) (*SyncReport, error) {
+	start := time.Now()
+	before, after, err := c.runSync(ctx, true)
+	c.setOffline(err != nil)
+	if err != nil {
+		return nil, err
+	}
+	return newSyncReport(before, after, time.Since(start)), nil
+}
+
+// newSyncReport derives pushed and pulled counts from two snapshots.
+func newSyncReport(before, after syncCounters, elapsed time.Duration) *SyncReport {
+	report := &SyncReport{Pending: after.pending, Duration: elapsed}
+	if before.pending > after.pending {
+		report.Pushed = before.pending - after.pending
+	}
+	if after.seq > before.seq {
+		report.Pulled = after.seq - before.seq
+	}
+	return report
+}
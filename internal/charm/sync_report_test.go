@@ -0,0 +1,18 @@
+// ABOUTME: Unit tests for manual sync reports
+// ABOUTME: Checks pushed/pulled counts derived from before/after snapshots
+package charm
+
+import "testing"
+
+func TestNewSyncReport(t *testing.T) {
+	report := newSyncReport(syncCounters{pending: 5, seq: 10}, syncCounters{pending: 0, seq: 14}, 0)
+	if report.Pushed != 5 || report.Pulled != 4 || report.Pending != 0 {
+		t.Errorf("got %+v, want pushed 5, pulled 4, pending 0", report)
+	}
+
+	// Counters that went the wrong way (concurrent writes) never go negative
+	report = newSyncReport(syncCounters{pending: 1, seq: 10}, syncCounters{pending: 2, seq: 10}, 0)
+	if report.Pushed != 0 || report.Pulled != 0 || report.Pending != 2 {
+		t.Errorf("got %+v, want zero pushed and pulled", report)
+	}
+}
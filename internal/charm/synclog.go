@@ -0,0 +1,142 @@
+// ABOUTME: History of recent sync sessions for `chronicle sync log`
+// ABOUTME: Appends one JSON line per sync and keeps only the newest sessions
+
+package charm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxSyncLogSessions is how many sessions the sync log keeps.
+const maxSyncLogSessions = 500
+
+// SyncSession records one sync. Pushed and Pulled are nil when the sync
+// wasn't measured (auto-sync skips the extra health check to stay fast).
+type SyncSession struct {
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Pushed   *int64        `json:"pushed,omitempty"`
+	Pulled   *uint64       `json:"pulled,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// SyncLogPath returns where sync sessions are recorded.
+func SyncLogPath() string {
+	return stateFilePath("sync-log", ".jsonl")
+}
+
+// newSyncSession builds a session record, with counts only if measured.
+func newSyncSession(started time.Time, before, after syncCounters, measured bool, err error) SyncSession {
+	session := SyncSession{Started: started, Duration: time.Since(started)}
+	if err != nil {
+		session.Error = err.Error()
+		return session
+	}
+	if measured {
+		report := newSyncReport(before, after, session.Duration)
+		session.Pushed = &report.Pushed
+		session.Pulled = &report.Pulled
+	}
+	return session
+}
+
+// recordSyncSession appends a session to the log, warning on failure.
+func recordSyncSession(session SyncSession) {
+	if err := appendSyncSession(SyncLogPath(), session); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record sync session: %v\n", err)
+	}
+}
+
+// appendSyncSession adds session to the log at path, dropping the oldest
+// sessions beyond maxSyncLogSessions.
+func appendSyncSession(path string, session SyncSession) error {
+	sessions, err := ReadSyncLog(path, 0)
+	if err != nil {
+		return err
+	}
+	sessions = append(sessions, session)
+	if len(sessions) > maxSyncLogSessions {
+		sessions = sessions[len(sessions)-maxSyncLogSessions:]
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range sessions {
+		if err := enc.Encode(s); err != nil {
+			return fmt.Errorf("encode session: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create log dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write log: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadSyncLog returns the last n sessions, oldest first (n <= 0 for all).
+// A missing log is empty. Lines that fail to decode are skipped.
+func ReadSyncLog(path string, n int) ([]SyncSession, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open sync log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var sessions []SyncSession
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s SyncSession
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read sync log: %w", err)
+	}
+
+	if n > 0 && len(sessions) > n {
+		sessions = sessions[len(sessions)-n:]
+	}
+	return sessions, nil
+}
+
+// lastSuccessfulSync returns when the newest error-free session in the log
+// at path finished, or the zero time if there is none.
+func lastSuccessfulSync(path string) time.Time {
+	sessions, err := ReadSyncLog(path, 0)
+	if err != nil {
+		return time.Time{}
+	}
+	for i := len(sessions) - 1; i >= 0; i-- {
+		if sessions[i].Error == "" {
+			return sessions[i].Started.Add(sessions[i].Duration)
+		}
+	}
+	return time.Time{}
+}
+
+// LastSuccessfulSync returns when this device last synced without error, or
+// the zero time if it never has. The sync log answers without opening the
+// database, which fails while offline; the KV store's own record covers
+// syncs from before the log existed.
+func (c *Client) LastSuccessfulSync() time.Time {
+	if last := lastSuccessfulSync(SyncLogPath()); !last.IsZero() {
+		return last
+	}
+	return c.LastSyncTime()
+}
@@ -0,0 +1,86 @@
+// ABOUTME: Tests for the sync session log
+// ABOUTME: Writes logs to temp dirs and checks trimming and measured counts
+package charm
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSyncLogAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync-log.jsonl")
+
+	empty, err := ReadSyncLog(path, 10)
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("expected empty log, got %v, %v", empty, err)
+	}
+
+	start := time.Now().Add(-time.Second)
+	measured := newSyncSession(start, syncCounters{pending: 2, seq: 5}, syncCounters{seq: 8}, true, nil)
+	failed := newSyncSession(start, syncCounters{}, syncCounters{}, false, errors.New("offline"))
+	for _, s := range []SyncSession{measured, failed} {
+		if err := appendSyncSession(path, s); err != nil {
+			t.Fatalf("appendSyncSession: %v", err)
+		}
+	}
+
+	sessions, err := ReadSyncLog(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(sessions))
+	}
+	if sessions[0].Pushed == nil || *sessions[0].Pushed != 2 || *sessions[0].Pulled != 3 {
+		t.Errorf("unexpected measured session: %+v", sessions[0])
+	}
+	if sessions[1].Error != "offline" || sessions[1].Pushed != nil {
+		t.Errorf("unexpected failed session: %+v", sessions[1])
+	}
+
+	last, err := ReadSyncLog(path, 1)
+	if err != nil || len(last) != 1 || last[0].Error != "offline" {
+		t.Errorf("expected only the newest session, got %+v, %v", last, err)
+	}
+}
+
+func TestSyncLogTrimsOldSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync-log.jsonl")
+	for i := 0; i < maxSyncLogSessions+5; i++ {
+		if err := appendSyncSession(path, SyncSession{Duration: time.Duration(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sessions, err := ReadSyncLog(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != maxSyncLogSessions || sessions[0].Duration != 5 {
+		t.Errorf("got %d sessions starting at %v; want %d starting at 5", len(sessions), sessions[0].Duration, maxSyncLogSessions)
+	}
+}
+
+func TestLastSuccessfulSync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync-log.jsonl")
+	if got := lastSuccessfulSync(path); !got.IsZero() {
+		t.Errorf("lastSuccessfulSync(missing) = %v, want zero", got)
+	}
+
+	ok := time.Date(2025, 11, 29, 14, 0, 0, 0, time.UTC)
+	sessions := []SyncSession{
+		{Started: ok.Add(-time.Hour), Duration: time.Second},
+		{Started: ok, Duration: 2 * time.Second},
+		{Started: ok.Add(time.Hour), Duration: time.Second, Error: "connection refused"},
+	}
+	for _, s := range sessions {
+		if err := appendSyncSession(path, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, want := lastSuccessfulSync(path), ok.Add(2*time.Second); !got.Equal(want) {
+		t.Errorf("lastSuccessfulSync() = %v, want %v (the failed sync is ignored)", got, want)
+	}
+}
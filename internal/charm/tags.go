@@ -0,0 +1,211 @@
+// ABOUTME: Tag normalization policy and bulk tag operations for chronicle entries
+// ABOUTME: Normalization keeps "Deploy" and "deploy " from being distinct tags; bulk ops edit many entries
+
+package charm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/charm/kv"
+)
+
+// NormalizeTag trims a tag, collapses internal whitespace to single spaces,
+// and lowercases it.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// NormalizeTags normalizes each tag, dropping empty and duplicate results
+// while preserving order.
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if n := NormalizeTag(t); n != "" {
+			normalized = append(normalized, n)
+		}
+	}
+	return MergeTags(normalized, nil)
+}
+
+// NormalizeAllTags rewrites every stored entry whose tags change under
+// NormalizeTags. Returns the number of entries updated.
+func (c *Client) NormalizeAllTags() (int, error) {
+	updated, err := c.updateEntries(nil, func(entry *Entry) bool {
+		tags := NormalizeTags(entry.Tags)
+		if equalTags(tags, entry.Tags) {
+			return false
+		}
+		entry.Tags = tags
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("normalize tags: %w", err)
+	}
+	return updated, nil
+}
+
+// AddTagToEntries adds tag to each of the given entries in one write.
+// Returns the number of entries that did not already have the tag.
+func (c *Client) AddTagToEntries(ids []string, tag string) (int, error) {
+	tag = c.prepareTag(tag)
+	if tag == "" {
+		return 0, errors.New("tag required")
+	}
+	updated, err := c.updateEntries(ids, func(entry *Entry) bool {
+		if hasTag(entry.Tags, tag) {
+			return false
+		}
+		entry.Tags = append(entry.Tags, tag)
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("add tag: %w", err)
+	}
+	return updated, nil
+}
+
+// RemoveTagFromEntries removes tag from each of the given entries in one write.
+// Returns the number of entries that had the tag.
+func (c *Client) RemoveTagFromEntries(ids []string, tag string) (int, error) {
+	tag = c.prepareTag(tag)
+	updated, err := c.updateEntries(ids, func(entry *Entry) bool {
+		return removeTag(entry, tag)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("remove tag: %w", err)
+	}
+	return updated, nil
+}
+
+// RenameTag replaces oldTag with newTag on every entry. If an entry already
+// has newTag the two are merged. Returns the number of entries changed.
+func (c *Client) RenameTag(oldTag, newTag string) (int, error) {
+	oldTag, newTag = c.prepareTag(oldTag), c.prepareTag(newTag)
+	if oldTag == "" || newTag == "" {
+		return 0, errors.New("both tags required")
+	}
+	updated, err := c.updateEntries(nil, func(entry *Entry) bool {
+		if !removeTag(entry, oldTag) {
+			return false
+		}
+		if !hasTag(entry.Tags, newTag) {
+			entry.Tags = append(entry.Tags, newTag)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("rename tag: %w", err)
+	}
+	return updated, nil
+}
+
+// prepareTag applies the client's normalization policy to a single tag.
+func (c *Client) prepareTag(tag string) string {
+	if c.normalizeTags {
+		return NormalizeTag(tag)
+	}
+	return strings.TrimSpace(tag)
+}
+
+// updateEntries applies fn to the given entries (nil ids means every entry)
+// inside a single write, saving those for which fn reports a change.
+// Returns the number of entries saved.
+func (c *Client) updateEntries(ids []string, fn func(entry *Entry) bool) (int, error) {
+	updated := 0
+
+	err := c.Do(func(k *kv.KV) error {
+		var keys [][]byte
+		if ids == nil {
+			all, err := k.Keys()
+			if err != nil {
+				return fmt.Errorf("get keys: %w", err)
+			}
+			for _, key := range all {
+				if strings.HasPrefix(string(key), EntryPrefix) {
+					keys = append(keys, key)
+				}
+			}
+		} else {
+			for _, id := range ids {
+				keys = append(keys, entryKey(id))
+			}
+		}
+
+		for _, key := range keys {
+			val, err := k.Get(key)
+			if err != nil {
+				if ids != nil {
+					return fmt.Errorf("entry %s: %w", strings.TrimPrefix(string(key), EntryPrefix), err)
+				}
+				continue
+			}
+			var entry Entry
+			if err := json.Unmarshal(val, &entry); err != nil {
+				// Skip invalid entries (corrupted data)
+				continue
+			}
+
+			if !fn(&entry) {
+				continue
+			}
+			entry.Checksum = entry.ComputeChecksum()
+
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if err := k.Set(key, data); err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// hasTag reports whether tags contains tag exactly.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// removeTag drops every occurrence of tag from the entry.
+// Returns true if any were removed.
+func removeTag(entry *Entry, tag string) bool {
+	kept := entry.Tags[:0]
+	for _, t := range entry.Tags {
+		if t != tag {
+			kept = append(kept, t)
+		}
+	}
+	removed := len(kept) != len(entry.Tags)
+	entry.Tags = kept
+	return removed
+}
+
+// equalTags reports whether two tag lists are identical, in order.
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
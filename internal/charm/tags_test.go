@@ -0,0 +1,61 @@
+// ABOUTME: Unit tests for tag normalization
+// ABOUTME: Covers trimming, case folding, whitespace collapse, and dedupe
+package charm
+
+import "testing"
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"deploy", "deploy"},
+		{"Deploy", "deploy"},
+		{"deploy ", "deploy"},
+		{"  Code   Review ", "code review"},
+		{"\tgo\n", "go"},
+		{"   ", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTag(tt.in); got != tt.want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{"Deploy", "deploy ", " ", "Work", "go"})
+	want := []string{"deploy", "work", "go"}
+	if !equalTags(got, want) {
+		t.Errorf("NormalizeTags() = %v, want %v", got, want)
+	}
+
+	if NormalizeTags(nil) != nil {
+		t.Error("NormalizeTags(nil) should stay nil")
+	}
+}
+
+func TestRemoveTag(t *testing.T) {
+	entry := Entry{Tags: []string{"work", "go", "work"}}
+	if !removeTag(&entry, "work") {
+		t.Error("expected removeTag to report a removal")
+	}
+	if !equalTags(entry.Tags, []string{"go"}) {
+		t.Errorf("Tags = %v, want [go]", entry.Tags)
+	}
+	if removeTag(&entry, "missing") {
+		t.Error("expected no removal for a missing tag")
+	}
+}
+
+func TestBulkTagOpsRejectEmptyTags(t *testing.T) {
+	assertClientRejects(t, []clientCase{
+		{"add empty tag", func(c *Client) error {
+			_, err := c.AddTagToEntries([]string{"a"}, "   ")
+			return err
+		}, ""},
+		{"rename to empty tag", func(c *Client) error {
+			_, err := c.RenameTag("work", " ")
+			return err
+		}, ""},
+	})
+}
@@ -0,0 +1,58 @@
+// ABOUTME: Unit tests for entry checksums
+// ABOUTME: Covers stability, tamper detection, and verify accounting
+package charm
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestComputeChecksumStableAcrossRoundTrip(t *testing.T) {
+	entry := Entry{
+		ID:        "abc",
+		Timestamp: time.Date(2025, 11, 29, 14, 30, 0, 123, time.UTC),
+		Message:   "deployed v2",
+		Type:      EntryTypeMilestone,
+		Tags:      []string{"work"},
+	}
+	entry.Checksum = entry.ComputeChecksum()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Entry
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ComputeChecksum() != entry.Checksum {
+		t.Error("checksum changed after JSON round trip")
+	}
+}
+
+func TestVerifyResultAdd(t *testing.T) {
+	good := Entry{ID: "good", Message: "hello"}
+	good.Checksum = good.ComputeChecksum()
+
+	tampered := Entry{ID: "bad", Message: "hello"}
+	tampered.Checksum = tampered.ComputeChecksum()
+	tampered.Message = "goodbye"
+
+	legacy := Entry{ID: "old", Message: "before checksums"}
+
+	r := &VerifyResult{}
+	r.add(&good)
+	r.add(&tampered)
+	r.add(&legacy)
+
+	if r.Checked != 2 || r.Unchecked != 1 {
+		t.Errorf("Checked = %d, Unchecked = %d; want 2 and 1", r.Checked, r.Unchecked)
+	}
+	if len(r.Mismatched) != 1 || r.Mismatched[0] != "bad" {
+		t.Errorf("Mismatched = %v, want [bad]", r.Mismatched)
+	}
+	if r.OK() {
+		t.Error("expected OK() to be false with a mismatch")
+	}
+}
@@ -0,0 +1,161 @@
+// ABOUTME: Outgoing webhooks POSTed when entries are created, updated, or deleted
+// ABOUTME: Payloads are JSON signed with HMAC-SHA256; failures only warn
+
+package charm
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// webhookTimeout bounds how long a single delivery may take.
+const webhookTimeout = 5 * time.Second
+
+// Entry events reported to webhooks.
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// EntryEvents lists every entry event.
+var EntryEvents = []string{EventCreated, EventUpdated, EventDeleted}
+
+// Webhook is an endpoint notified of entry changes.
+type Webhook struct {
+	// URL receives a POST for each matching event
+	URL string `json:"url"`
+
+	// Secret, when set, signs each payload: the X-Chronicle-Signature
+	// header is "sha256=" and the hex HMAC-SHA256 of the body
+	Secret string `json:"secret,omitempty"`
+
+	// Events limits deliveries to created, updated, or deleted; all when
+	// empty
+	Events []string `json:"events,omitempty"`
+
+	// Tags limits deliveries to entries with at least one of these tags;
+	// all entries when empty
+	Tags []string `json:"tags,omitempty"`
+}
+
+// WebhookPayload is the JSON body of a webhook delivery.
+type WebhookPayload struct {
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+	Entry     Entry     `json:"entry"`
+}
+
+// Validate checks the webhook's URL and events.
+func (w Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q must be an http or https URL", w.URL)
+	}
+	for _, event := range w.Events {
+		if !contains(EntryEvents, event) {
+			return fmt.Errorf("unknown event %q (valid: %v)", event, EntryEvents)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the webhook wants event for entry.
+func (w Webhook) Matches(event string, entry *Entry) bool {
+	if len(w.Events) > 0 && !contains(w.Events, event) {
+		return false
+	}
+	if len(w.Tags) == 0 {
+		return true
+	}
+	for _, tag := range w.Tags {
+		if hasTag(entry.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateWebhooks checks every webhook, naming the first bad one.
+func ValidateWebhooks(webhooks []Webhook) error {
+	for i, w := range webhooks {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("webhooks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// notifyWebhooks delivers event for entry to each matching webhook. A
+// failed delivery is reported and otherwise ignored, since the change has
+// already been stored.
+func (c *Client) notifyWebhooks(event string, entry *Entry) {
+	for _, w := range c.webhooks {
+		if !w.Matches(event, entry) {
+			continue
+		}
+		if err := deliverWebhook(context.Background(), w, event, entry); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: webhook %s failed: %v\n", w.URL, err)
+		}
+	}
+}
+
+// deliverWebhook POSTs the event's payload to w.
+func deliverWebhook(ctx context.Context, w Webhook, event string, entry *Entry) error {
+	body, err := json.Marshal(WebhookPayload{Event: event, Timestamp: time.Now().UTC(), Entry: *entry})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "chronicle")
+	req.Header.Set("X-Chronicle-Event", event)
+	req.Header.Set("X-Chronicle-Delivery", uuid.New().String())
+	if w.Secret != "" {
+		req.Header.Set("X-Chronicle-Signature", SignWebhook(w.Secret, body))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhook returns the X-Chronicle-Signature header for body.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// contains reports whether list holds s.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+// ABOUTME: Tests for outgoing webhooks
+// ABOUTME: Validates payload signing, event and tag filters, and delivery errors
+package charm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeliverWebhook(t *testing.T) {
+	var got WebhookPayload
+	var signature, event string
+	var verified bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get("X-Chronicle-Signature")
+		event = r.Header.Get("X-Chronicle-Event")
+		verified = signature == SignWebhook("s3cret", body)
+		_ = json.Unmarshal(body, &got)
+	}))
+	defer server.Close()
+
+	entry := &Entry{ID: "abc", Message: "shipped", Tags: []string{"deploy"}}
+	hook := Webhook{URL: server.URL, Secret: "s3cret"}
+	if err := deliverWebhook(context.Background(), hook, EventCreated, entry); err != nil {
+		t.Fatal(err)
+	}
+	if !verified {
+		t.Errorf("signature %q doesn't match the body", signature)
+	}
+	if event != EventCreated || got.Event != EventCreated || got.Entry.ID != "abc" || got.Timestamp.IsZero() {
+		t.Errorf("unexpected delivery: event header %q, payload %+v", event, got)
+	}
+
+	// Without a secret nothing is signed
+	signature = "unset"
+	if err := deliverWebhook(context.Background(), Webhook{URL: server.URL}, EventDeleted, entry); err != nil {
+		t.Fatal(err)
+	}
+	if signature != "" {
+		t.Errorf("unsigned delivery sent signature %q", signature)
+	}
+}
+
+func TestDeliverWebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	if err := deliverWebhook(context.Background(), Webhook{URL: server.URL}, EventCreated, &Entry{}); err == nil {
+		t.Error("expected an error for a 500 response")
+	}
+}
+
+func TestWebhookMatches(t *testing.T) {
+	entry := &Entry{Tags: []string{"deploy", "work"}}
+	tests := []struct {
+		name  string
+		hook  Webhook
+		event string
+		want  bool
+	}{
+		{"no filters", Webhook{}, EventUpdated, true},
+		{"event listed", Webhook{Events: []string{EventCreated}}, EventCreated, true},
+		{"event not listed", Webhook{Events: []string{EventCreated}}, EventDeleted, false},
+		{"tag matches", Webhook{Tags: []string{"release", "deploy"}}, EventCreated, true},
+		{"tag missing", Webhook{Tags: []string{"release"}}, EventCreated, false},
+	}
+	for _, tt := range tests {
+		if got := tt.hook.Matches(tt.event, entry); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	if err := ValidateWebhooks([]Webhook{{URL: "https://example.com/hook", Events: []string{EventDeleted}}}); err != nil {
+		t.Errorf("valid webhook rejected: %v", err)
+	}
+	for _, bad := range []Webhook{
+		{URL: ""},
+		{URL: "ftp://example.com"},
+		{URL: "https://example.com", Events: []string{"archived"}},
+	} {
+		if err := ValidateWebhooks([]Webhook{bad}); err == nil {
+			t.Errorf("ValidateWebhooks(%+v): expected error", bad)
+		}
+	}
+}
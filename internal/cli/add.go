@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/harper/chronicle/internal/charm"
@@ -19,90 +21,292 @@ const (
 )
 
 var (
-	tags []string
+	tags         []string
+	entryType    string
+	refs         []string
+	refRelation  string
+	addEdit      bool
+	addNoRedact  bool
+	addNoAutoTag bool
+	addDuration  time.Duration
 )
 
 var addCmd = &cobra.Command{
 	Use:     "add [message]",
 	Aliases: []string{"a"},
 	Short:   "Add a log entry",
-	Args:    cobra.ExactArgs(1),
+	Long: `Add a log entry. With --edit the message is written in your editor
+(the editor config option, else $VISUAL or $EDITOR), starting from the
+message argument if one is given.
+
+With auto_tag configured, tags from matching tag rules and a language
+model's suggested tags and type are added too; --no-auto-tag skips them.
+
+--duration records how long the work took, ending when it's logged, for
+chronicle export to time trackers.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if addEdit {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		message := args[0]
+		var message string
+		if len(args) > 0 {
+			message = args[0]
+		}
+		if addEdit {
+			edited, err := editText(message)
+			if err != nil {
+				return err
+			}
+			message = edited
+		}
 
 		// Validate message is not empty
 		if message == "" {
 			return fmt.Errorf("message cannot be empty")
 		}
 
-		// Get Charm client
-		client, err := charm.GetClient()
-		if err != nil {
-			return fmt.Errorf("failed to connect to Charm: %w", err)
+		if err := validateEntryType(entryType); err != nil {
+			return err
+		}
+		var metadata map[string]string
+		if addDuration < 0 {
+			return fmt.Errorf("--duration must not be negative")
+		} else if addDuration > 0 {
+			metadata = map[string]string{"duration": addDuration.String()}
 		}
 
-		// Get metadata
-		hostname, err := os.Hostname()
+		id, err := addEntry(newEntry{
+			Message:  message,
+			Type:     entryType,
+			Tags:     tags,
+			Refs:     refs,
+			Relation: refRelation,
+			Source:   charm.SourceCLI,
+			NoRedact: addNoRedact,
+			AutoTag:  !addNoAutoTag,
+			Metadata: metadata,
+		})
 		if err != nil {
-			hostname = unknownValue
+			return err
 		}
-		username := os.Getenv("USER")
-		if username == "" {
-			username = unknownValue
+		fmt.Printf("Entry created (ID: %s)\n", id)
+		return nil
+	},
+}
+
+// newEntry is what a caller chooses about an entry; addEntry fills in the
+// host, user, directory, and project.
+type newEntry struct {
+	Message  string
+	Type     string
+	Tags     []string
+	Refs     []string
+	Relation string
+	Source   string
+	Metadata map[string]string
+	// NoRedact stores the message even if it looks like it holds a secret
+	NoRedact bool
+	// AutoTag adds tags and a type suggested by auto_tag, when configured
+	AutoTag bool
+}
+
+// addEntry stores e with the privacy rules, secret redaction, project
+// tags, and default tags applied, links its refs, and appends it to the
+// project log. It returns the new entry's ID.
+func addEntry(e newEntry) (string, error) {
+	// Get Charm client
+	client, err := charm.GetClient()
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to Charm: %w", err)
+	}
+
+	// Referenced entries must exist before we link to them
+	for _, ref := range e.Refs {
+		if _, err := client.GetEntry(ref); err != nil {
+			return "", fmt.Errorf("referenced entry %s not found: %w", ref, err)
 		}
-		workingDir, err := os.Getwd()
-		if err != nil {
-			workingDir = unknownValue
+	}
+
+	cfg := client.Config()
+	if cfg == nil {
+		cfg = charm.DefaultConfig()
+	}
+	privacyRules, err := config.CompilePrivacyRules(cfg.PrivacyRules)
+	if err != nil {
+		return "", fmt.Errorf("invalid privacy_rules in %s: %w", charm.ConfigPath(), err)
+	}
+	message, err := redactMessage(cfg, e.Message, e.NoRedact)
+	if err != nil {
+		return "", err
+	}
+
+	// Get metadata
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = unknownValue
+	}
+	username := os.Getenv("USER")
+	if username == "" {
+		username = unknownValue
+	}
+	workingDir, err := os.Getwd()
+	if err != nil {
+		workingDir = unknownValue
+	}
+
+	// Associate the entry with the detected project, if any
+	projectRoot, err := findProjectRoot(workingDir)
+	if err != nil {
+		projectRoot = ""
+	}
+
+	if e.AutoTag {
+		e.Tags, e.Type = autoTagEntry(cfg, message, projectRoot, e.Tags, e.Type)
+	}
+
+	// Create entry (set timestamp now for project logging)
+	now := time.Now()
+	entry := charm.Entry{
+		Timestamp:        now,
+		Message:          message,
+		Type:             e.Type,
+		Hostname:         hostname,
+		Username:         username,
+		WorkingDirectory: workingDir,
+		Source:           e.Source,
+		Tags:             e.Tags,
+		Metadata:         e.Metadata,
+	}
+	entry.WorkingDirectory, entry.Hostname = config.ApplyPrivacy(privacyRules, workingDir, hostname)
+
+	if projectRoot != "" {
+		if projectCfg, err := config.LoadProjectConfig(filepath.Join(projectRoot, ".chronicle")); err == nil {
+			entry.Tags = projectCfg.ApplyTags(entry.Tags)
+		}
+		// A project record stores its root, so private directories get none
+		if entry.WorkingDirectory == workingDir {
+			project, err := client.EnsureProject(projectRoot)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to resolve project: %v\n", err)
+			} else {
+				entry.ProjectID = project.ID
+				entry.Tags = charm.MergeTags(entry.Tags, project.DefaultTags)
+			}
 		}
+	}
+	entry.Tags = charm.MergeTags(entry.Tags, cfg.DefaultTags)
+
+	id, err := client.CreateEntry(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to create entry: %w", err)
+	}
 
-		// Create entry (set timestamp now for project logging)
-		now := time.Now()
-		entry := charm.Entry{
-			Timestamp:        now,
-			Message:          message,
-			Hostname:         hostname,
-			Username:         username,
-			WorkingDirectory: workingDir,
-			Tags:             tags,
+	for _, ref := range e.Refs {
+		if err := client.AddLink(id, ref, e.Relation); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to link to %s: %v\n", ref, err)
 		}
+	}
 
-		id, err := client.CreateEntry(entry)
-		if err != nil {
-			return fmt.Errorf("failed to create entry: %w", err)
+	if projectRoot != "" {
+		entry.ID = id
+		writeProjectLog(projectRoot, entry)
+	}
+	return id, nil
+}
+
+// redactMessage scrubs secrets from message, or refuses it, as
+// redact_secrets says. With noRedact (--no-redact) the message is kept as
+// is, with a warning if it holds anything that looks like a secret.
+func redactMessage(cfg *charm.Config, message string, noRedact bool) (string, error) {
+	redactor, err := config.NewRedactor(cfg.RedactSecrets, cfg.RedactPatterns)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret redaction settings in %s: %w", charm.ConfigPath(), err)
+	}
+	clean, kinds, err := redactor.Redact(message)
+	if noRedact {
+		if len(kinds) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: storing a message that looks like it contains a secret (%s) because of --no-redact\n", strings.Join(kinds, ", "))
 		}
+		return message, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w; remove it or pass --no-redact to store it anyway", err)
+	}
+	if len(kinds) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: redacted a secret (%s) from the message\n", strings.Join(kinds, ", "))
+	}
+	return clean, nil
+}
 
-		fmt.Printf("Entry created (ID: %s)\n", id)
+// writeProjectLog appends the entry to the project log if local logging is enabled.
+// Failures are reported as warnings since the entry is already stored.
+func writeProjectLog(projectRoot string, entry charm.Entry) {
+	chroniclePath := filepath.Join(projectRoot, ".chronicle")
+	projectCfg, err := config.LoadProjectConfig(chroniclePath)
+	if err != nil || !projectCfg.LocalLogging {
+		return
+	}
 
-		// Check for project logging
-		projectRoot, err := config.FindProjectRoot(workingDir)
-		if err == nil && projectRoot != "" {
-			chroniclePath := filepath.Join(projectRoot, ".chronicle")
-			projectCfg, err := config.LoadProjectConfig(chroniclePath)
-			if err == nil && projectCfg.LocalLogging {
-				logDir := filepath.Join(projectRoot, projectCfg.LogDir)
-				// Convert charm.Entry to logging.Entry for project logging
-				logEntry := logging.Entry{
-					ID:               id,
-					Timestamp:        now,
-					Message:          entry.Message,
-					Hostname:         entry.Hostname,
-					Username:         entry.Username,
-					WorkingDirectory: entry.WorkingDirectory,
-					Tags:             entry.Tags,
-				}
-				if err := logging.WriteProjectLog(logDir, projectCfg.LogFormat, logEntry); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to write project log: %v\n", err)
-				} else {
-					fmt.Printf("Project log updated: %s\n", logDir)
-				}
-			}
+	logDir := projectCfg.LogPath(projectRoot)
+	logEntry := toLogEntry(entry)
+	tmpl, err := projectLogTemplate(projectRoot, projectCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write project log: %v\n", err)
+		return
+	}
+	if err := logging.WriteProjectLog(logDir, projectCfg.LogFormat, projectCfg.LogRotation, tmpl, logEntry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write project log: %v\n", err)
+		return
+	}
+	fmt.Printf("Project log updated: %s\n", logDir)
+
+	if projectCfg.LogLatest {
+		if err := logging.UpdateLatest(logDir, projectCfg.LogFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	if projectCfg.LogIndex {
+		if err := logging.WriteIndex(logDir, tmpl == nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to update log index: %v\n", err)
 		}
+	}
+}
 
-		return nil
-	},
+// toLogEntry converts a stored entry to the form project logs write.
+func toLogEntry(entry charm.Entry) logging.Entry {
+	return logging.Entry{
+		ID:               entry.ID,
+		Timestamp:        entry.Timestamp,
+		Message:          entry.Message,
+		Type:             entry.Type,
+		Hostname:         entry.Hostname,
+		Username:         entry.Username,
+		WorkingDirectory: entry.WorkingDirectory,
+		Tags:             entry.Tags,
+	}
+}
+
+// projectLogTemplate parses the project's markdown entry template, or
+// returns nil to use the built-in layout.
+func projectLogTemplate(projectRoot string, projectCfg *config.ProjectConfig) (*template.Template, error) {
+	text, err := projectCfg.EntryTemplate(projectRoot)
+	if err != nil || text == "" {
+		return nil, err
+	}
+	return logging.ParseEntryTemplate(text)
 }
 
 func init() {
 	addCmd.Flags().StringArrayVarP(&tags, "tag", "t", []string{}, "Add tags to entry")
+	addCmd.Flags().StringArrayVar(&refs, "ref", []string{}, "Link this entry to an existing entry ID")
+	addCmd.Flags().StringVar(&refRelation, "relation", charm.RelationReferences, "Relation used for --ref links")
+	addCmd.Flags().StringVar(&entryType, "type", "", "Entry type (note, decision, todo, milestone)")
+	addCmd.Flags().BoolVarP(&addEdit, "edit", "e", false, "Write the message in your editor")
+	addCmd.Flags().BoolVar(&addNoRedact, "no-redact", false, "Store the message even if it looks like it contains a secret")
+	addCmd.Flags().BoolVar(&addNoAutoTag, "no-auto-tag", false, "Skip auto_tag suggestions for this entry")
+	addCmd.Flags().DurationVarP(&addDuration, "duration", "d", 0, "How long the work took, ending now (e.g. 90m, 1h30m)")
 	rootCmd.AddCommand(addCmd)
 }
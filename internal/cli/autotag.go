@@ -0,0 +1,53 @@
+// ABOUTME: Auto-tagging for add: tag rule matches merged with a language model's suggestions
+// ABOUTME: Only runs with auto_tag configured, and never fails an add
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/harper/chronicle/internal/autotag"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+// autoTagEntry merges the tags of every tag rule matching message (the
+// built-in ones, the config's, and the project's at projectRoot) and the
+// auto_tag model's suggestions into tags and kind. Without auto_tag it
+// changes nothing. Problems, such as the model timing out, are warnings:
+// the entry is added with whatever was suggested.
+func autoTagEntry(cfg *charm.Config, message, projectRoot string, tags []string, kind string) ([]string, string) {
+	if cfg.AutoTag == nil {
+		return tags, kind
+	}
+	if err := cfg.AutoTag.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: auto-tagging skipped, invalid auto_tag in %s: %v\n", charm.ConfigPath(), err)
+		return tags, kind
+	}
+
+	rules := append(append([]config.TagRule{}, config.DefaultTagRules...), cfg.TagRules...)
+	if projectRoot != "" {
+		if projectCfg, err := config.LoadProjectConfig(filepath.Join(projectRoot, ".chronicle")); err == nil {
+			rules = append(rules, projectCfg.TagRules...)
+		}
+	}
+	var ruleTags []string
+	if compiled, err := config.CompileTagRules(rules); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tag rules skipped: %v\n", err)
+	} else {
+		for _, rule := range compiled {
+			if rule.Matches(message) {
+				ruleTags = charm.MergeTags(ruleTags, rule.Tags)
+			}
+		}
+	}
+
+	tagger := autotag.New(cfg.AutoTag, charm.AutoTagCachePath())
+	suggestion, err := tagger.Suggest(context.Background(), message)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: auto-tagging skipped: %v\n", err)
+	}
+	return autotag.Apply(tags, kind, ruleTags, suggestion)
+}
@@ -0,0 +1,62 @@
+// ABOUTME: CalDAV command publishing recent entries to a calendar
+// ABOUTME: Lets Nextcloud or Fastmail calendars show the journal beside meetings
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/caldav"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var caldavDryRun bool
+
+var caldavCmd = &cobra.Command{
+	Use:   "caldav",
+	Short: "Publish entries to a CalDAV calendar",
+}
+
+var caldavPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Publish recent entries to the configured calendar",
+	Long: `Publish the last caldav.days days of entries (30 by default) to the
+calendar at caldav.url, one event per entry, so calendar apps show what you
+worked on next to your meetings. Only new and changed entries are sent, and
+entries deleted from the journal are removed from the calendar.
+
+The sync daemon runs this after every sync when caldav is configured; run
+it yourself, or from cron, otherwise. Set the password with caldav.password
+or CHRONICLE_CALDAV_PASSWORD.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+		cfg := client.Config()
+		if cfg == nil || cfg.CalDAV == nil {
+			return fmt.Errorf("no caldav settings in %s", charm.ConfigPath())
+		}
+
+		result, err := caldav.PublishJournal(cmd.Context(), client, cfg.CalDAV, charm.CalDAVStatePath(), caldavDryRun)
+		if err != nil {
+			return fmt.Errorf("caldav push failed (%d stored, %d removed before the error): %w", result.Stored, result.Removed, err)
+		}
+		verb := "Published"
+		if caldavDryRun {
+			verb = "Would publish"
+		}
+		color.Green("%s %d entries, removed %d, %d unchanged", verb, result.Stored, result.Removed, result.Unchanged)
+		return nil
+	},
+}
+
+func init() {
+	caldavPushCmd.Flags().BoolVar(&caldavDryRun, "dry-run", false, "Show what would change without contacting the server")
+	caldavCmd.AddCommand(caldavPushCmd)
+	rootCmd.AddCommand(caldavCmd)
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Config command for the global charm.json settings
+// ABOUTME: config edit opens it in the user's editor; config doctor checks it and .chronicle files
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage chronicle configuration",
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the global config in your editor",
+	Long: `Open ~/.config/chronicle/charm.json in your editor (the editor config
+option, else $VISUAL or $EDITOR), creating it if needed. The file is
+checked after the editor exits.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := charm.ConfigPath()
+		if !charm.ConfigExists() {
+			if err := charm.SaveConfig(charm.DefaultConfig()); err != nil {
+				return fmt.Errorf("failed to create %s: %w", path, err)
+			}
+		}
+
+		if err := openEditor(path); err != nil {
+			return err
+		}
+
+		if _, err := charm.LoadConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s has problems: %v\n", path, err)
+		}
+		return nil
+	},
+}
+
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor [.chronicle file...]",
+	Short: "Check config files for mistakes",
+	Long: `Check the global config and the current project's .chronicle files (plus
+any .chronicle files given) for unknown keys, invalid values such as an
+unknown log_format, and settings that conflict or have no effect. Problems
+are printed as file:line: message, and the command fails if there are any.
+Environment variable overrides are not checked.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths := args
+		if cwd, err := os.Getwd(); err == nil {
+			if found, err := config.FindProjectConfigs(cwd); err == nil {
+				paths = append(found, paths...)
+			}
+		}
+
+		diags, err := charm.CheckConfig()
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", charm.ConfigPath(), err)
+		}
+		if data, err := os.ReadFile(charm.ConfigPath()); err == nil {
+			var cfg charm.Config
+			if json.Unmarshal(data, &cfg) == nil {
+				diags = append(diags, checkCommandDefaults(charm.ConfigPath(), data, cfg.Defaults)...)
+			}
+		}
+		var checked []string
+		if charm.ConfigExists() {
+			checked = append(checked, charm.ConfigPath())
+		}
+		for _, path := range paths {
+			found, err := config.CheckProjectConfig(path)
+			if err != nil {
+				return fmt.Errorf("failed to check %s: %w", path, err)
+			}
+			diags = append(diags, found...)
+			checked = append(checked, path)
+		}
+
+		for _, d := range diags {
+			fmt.Println(d)
+		}
+		if len(diags) > 0 {
+			return fmt.Errorf("found %d problem(s)", len(diags))
+		}
+		for _, path := range checked {
+			fmt.Printf("%s: ok\n", path)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configDoctorCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,82 @@
+// ABOUTME: Count command for how many entries match a filter
+// ABOUTME: Counts keys directly when no filter is given
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	countTags    []string
+	countType    string
+	countProject string
+	countSince   string
+	countUntil   string
+)
+
+var countCmd = &cobra.Command{
+	Use:   "count [text]",
+	Short: "Count entries",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateEntryType(countType); err != nil {
+			return err
+		}
+
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		projectID, err := resolveProjectID(client, countProject)
+		if err != nil {
+			return err
+		}
+
+		filter := &charm.SearchFilter{
+			Tags:      countTags,
+			Type:      countType,
+			ProjectID: projectID,
+		}
+		if len(args) > 0 {
+			filter.Text = args[0]
+		}
+		if filter.Since, err = parseDateFlag("since", countSince); err != nil {
+			return err
+		}
+		if filter.Until, err = parseDateFlag("until", countUntil); err != nil {
+			return err
+		}
+
+		// An empty filter lets the client count keys without decoding entries
+		if isEmptyFilter(filter) {
+			filter = nil
+		}
+
+		count, err := client.CountEntries(filter)
+		if err != nil {
+			return fmt.Errorf("failed to count entries: %w", err)
+		}
+		fmt.Println(count)
+		return nil
+	},
+}
+
+// isEmptyFilter reports whether filter would match every entry.
+func isEmptyFilter(f *charm.SearchFilter) bool {
+	return f.Text == "" && len(f.Tags) == 0 && f.Type == "" && f.ProjectID == "" &&
+		f.Source == "" && !f.PinnedOnly && f.Since == nil && f.Until == nil
+}
+
+func init() {
+	countCmd.Flags().StringArrayVarP(&countTags, "tag", "t", []string{}, "Filter by tags")
+	countCmd.Flags().StringVar(&countType, "type", "", "Filter by entry type (note, decision, todo, milestone)")
+	countCmd.Flags().StringVarP(&countProject, "project", "p", "", "Filter by project name")
+	countCmd.Flags().StringVar(&countSince, "since", "", "Start date (natural language or ISO)")
+	countCmd.Flags().StringVar(&countUntil, "until", "", "End date (natural language or ISO)")
+	rootCmd.AddCommand(countCmd)
+}
@@ -0,0 +1,18 @@
+// ABOUTME: Tests for the count command helpers
+// ABOUTME: Checks when a filter can be skipped for a key-only count
+package cli
+
+import (
+	"testing"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func TestIsEmptyFilter(t *testing.T) {
+	if !isEmptyFilter(&charm.SearchFilter{Tags: []string{}}) {
+		t.Error("expected filter with no criteria to be empty")
+	}
+	if isEmptyFilter(&charm.SearchFilter{Type: "todo"}) {
+		t.Error("expected type filter to be non-empty")
+	}
+}
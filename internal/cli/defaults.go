@@ -0,0 +1,79 @@
+// ABOUTME: Per-command flag defaults from the config's defaults section
+// ABOUTME: Seeds flags the user didn't pass, e.g. defaults.list.limit
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// commandKey names cmd in the defaults section: its path below the root
+// command, e.g. "list" or "sync log".
+func commandKey(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
+
+// applyCommandDefaults sets each flag configured for cmd under defaults
+// that wasn't given on the command line. List values set repeatable flags
+// such as --tag once per item.
+func applyCommandDefaults(cmd *cobra.Command) error {
+	cfg, err := charm.LoadConfig()
+	if err != nil {
+		return nil
+	}
+	defaults := cfg.Defaults[commandKey(cmd)]
+	for _, name := range sortedKeys(defaults) {
+		value := defaults[name]
+		f := cmd.Flags().Lookup(name)
+		if f == nil {
+			return fmt.Errorf("unknown flag %q in defaults.%s of %s", name, commandKey(cmd), charm.ConfigPath())
+		}
+		if f.Changed {
+			continue
+		}
+		values := []any{value}
+		if list, ok := value.([]any); ok {
+			values = list
+		}
+		for _, v := range values {
+			if err := cmd.Flags().Set(name, fmt.Sprint(v)); err != nil {
+				return fmt.Errorf("invalid defaults.%s.%s in %s: %w", commandKey(cmd), name, charm.ConfigPath(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkCommandDefaults reports defaults entries in the config file at path,
+// whose contents are data, naming a command or flag that doesn't exist.
+func checkCommandDefaults(path string, data []byte, defaults map[string]map[string]any) []config.Diagnostic {
+	var diags []config.Diagnostic
+	for _, key := range sortedKeys(defaults) {
+		cmd, rest, err := rootCmd.Find(strings.Fields(key))
+		if err != nil || len(rest) > 0 || cmd == rootCmd {
+			diags = append(diags, config.Diagnostic{File: path, Line: config.KeyLine(data, key), Message: fmt.Sprintf("defaults for unknown command %q", key)})
+			continue
+		}
+		for _, name := range sortedKeys(defaults[key]) {
+			if cmd.Flags().Lookup(name) == nil && cmd.InheritedFlags().Lookup(name) == nil {
+				diags = append(diags, config.Diagnostic{File: path, Line: config.KeyLine(data, name), Message: fmt.Sprintf("%s has no --%s flag", cmd.CommandPath(), name)})
+			}
+		}
+	}
+	return diags
+}
+
+// sortedKeys returns m's keys in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+// ABOUTME: Tests for per-command flag defaults from config
+// ABOUTME: Uses a throwaway command tree and a temp XDG_CONFIG_HOME
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+func TestApplyCommandDefaults(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "chronicle"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := `{"defaults": {"sync log": {"limit": 50, "tag": ["a", "b"]}, "other": {"nope": 1}}}`
+	if err := os.WriteFile(charm.ConfigPath(), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	newTree := func() (*cobra.Command, *int, *[]string) {
+		var limit int
+		var tags []string
+		root := &cobra.Command{Use: "chronicle"}
+		sync := &cobra.Command{Use: "sync"}
+		log := &cobra.Command{Use: "log", Run: func(*cobra.Command, []string) {}}
+		log.Flags().IntVar(&limit, "limit", 20, "")
+		log.Flags().StringArrayVar(&tags, "tag", nil, "")
+		sync.AddCommand(log)
+		root.AddCommand(sync)
+		return log, &limit, &tags
+	}
+
+	cmd, limit, tags := newTree()
+	if err := applyCommandDefaults(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if *limit != 50 || strings.Join(*tags, ",") != "a,b" {
+		t.Errorf("got limit %d tags %v, want 50 and [a b]", *limit, *tags)
+	}
+
+	cmd, limit, _ = newTree()
+	if err := cmd.Flags().Parse([]string{"--limit", "5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyCommandDefaults(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if *limit != 5 {
+		t.Errorf("got limit %d, want the flag given to win", *limit)
+	}
+}
@@ -0,0 +1,88 @@
+// ABOUTME: Opens the user's editor for composing entries and editing config
+// ABOUTME: Uses the editor config option, then $VISUAL, $EDITOR, and vi
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+// editorFilePlaceholder marks where the file goes in an editor command,
+// e.g. "code --wait {{file}}". Without it the file is appended.
+const editorFilePlaceholder = "{{file}}"
+
+// resolveEditor returns the editor command: configured if set, else
+// $VISUAL, else $EDITOR, else vi.
+func resolveEditor(configured string) string {
+	for _, editor := range []string{configured, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if editor = strings.TrimSpace(editor); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// editorCommandLine returns the shell command that opens path in editor.
+func editorCommandLine(editor, path string) string {
+	quoted := shellQuote(path)
+	if strings.Contains(editor, editorFilePlaceholder) {
+		return strings.ReplaceAll(editor, editorFilePlaceholder, quoted)
+	}
+	return editor + " " + quoted
+}
+
+// shellQuote single-quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// openEditor runs the configured editor on path and waits for it to exit.
+func openEditor(path string) error {
+	var configured string
+	if cfg, err := charm.LoadConfig(); err == nil {
+		configured = cfg.Editor
+	}
+	editor := resolveEditor(configured)
+
+	cmd := exec.Command("sh", "-c", editorCommandLine(editor, path))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
+
+// editText opens initial in the editor and returns what was saved, with
+// surrounding whitespace trimmed.
+func editText(initial string) (string, error) {
+	f, err := os.CreateTemp("", "chronicle-*.md")
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	defer func() { _ = os.Remove(path) }()
+
+	if _, err := f.WriteString(initial); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	if err := openEditor(path); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,43 @@
+// ABOUTME: Tests for editor resolution and command lines
+// ABOUTME: Validates the fallback order and {{file}} placeholder handling
+package cli
+
+import "testing"
+
+func TestResolveEditor(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := resolveEditor(""); got != "vi" {
+		t.Errorf("got %q, want vi", got)
+	}
+
+	t.Setenv("EDITOR", "nano")
+	if got := resolveEditor(""); got != "nano" {
+		t.Errorf("got %q, want $EDITOR", got)
+	}
+
+	t.Setenv("VISUAL", "emacs")
+	if got := resolveEditor(""); got != "emacs" {
+		t.Errorf("got %q, want $VISUAL over $EDITOR", got)
+	}
+
+	if got := resolveEditor("code --wait"); got != "code --wait" {
+		t.Errorf("got %q, want configured editor", got)
+	}
+}
+
+func TestEditorCommandLine(t *testing.T) {
+	tests := []struct {
+		editor, path, want string
+	}{
+		{"vim", "/tmp/a.md", "vim '/tmp/a.md'"},
+		{"code --wait {{file}}", "/tmp/a.md", "code --wait '/tmp/a.md'"},
+		{"subl -w {{file}}:1", "/tmp/a.md", "subl -w '/tmp/a.md':1"},
+		{"vim", "/tmp/it's.md", `vim '/tmp/it'\''s.md'`},
+	}
+	for _, tt := range tests {
+		if got := editorCommandLine(tt.editor, tt.path); got != tt.want {
+			t.Errorf("editorCommandLine(%q, %q) = %q, want %q", tt.editor, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// ABOUTME: Export command turning entries with a duration into time-tracking data
+// ABOUTME: Writes Toggl Track CSV or Timewarrior JSON, or pushes straight to Toggl's API
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/harper/chronicle/internal/timetrack"
+	"github.com/spf13/cobra"
+)
+
+// Export formats.
+const (
+	exportToggl = "toggl"
+	exportTimew = "timew"
+)
+
+var (
+	exportFormat string
+	exportSince  string
+	exportUntil  string
+	exportTags   []string
+	exportOutput string
+	exportPush   bool
+	exportDryRun bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export --format toggl|timew",
+	Short: "Export time spent to Toggl Track or Timewarrior",
+	Long: `Export entries that record how long they took (a duration, such as from
+'add --duration', shell capture, or imported meetings) as time tracking,
+so billable hours can come straight from the journal. Each entry covers
+the time up to when it was logged.
+
+Entries go to the project of their first tag listed in time_tracking.projects
+in charm.json; entries without one have no project.
+
+  toggl  CSV for Toggl Track's import (time_tracking.email names the
+         account), or with --push, time entries created through Toggl's
+         API in time_tracking.toggl_workspace_id. --push remembers what it
+         created, so pushing again only sends new entries.
+  timew  JSON for 'timew import'; the project becomes the first tag.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportFormat != exportToggl && exportFormat != exportTimew {
+			return fmt.Errorf("invalid --format %q (valid: %s, %s)", exportFormat, exportToggl, exportTimew)
+		}
+		if exportPush && exportFormat != exportToggl {
+			return fmt.Errorf("--push only works with --format %s", exportToggl)
+		}
+		if exportDryRun && !exportPush {
+			return fmt.Errorf("--dry-run only applies to --push")
+		}
+
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+		var tracking *config.TimeTracking
+		if cfg := client.Config(); cfg != nil && cfg.TimeTracking != nil {
+			tracking = cfg.TimeTracking
+			if err := tracking.Validate(); err != nil {
+				return fmt.Errorf("invalid time_tracking in %s: %w", charm.ConfigPath(), err)
+			}
+		}
+
+		filter := &charm.SearchFilter{Tags: exportTags}
+		if filter.Since, err = parseDateFlag("since", exportSince); err != nil {
+			return err
+		}
+		if filter.Until, err = parseDateFlag("until", exportUntil); err != nil {
+			return err
+		}
+		entries, err := client.SearchEntries(filter, 0)
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+		intervals := timetrack.Intervals(entries, tracking)
+
+		if exportPush {
+			return pushToggl(cmd, tracking, intervals)
+		}
+
+		var w io.Writer = os.Stdout
+		if exportOutput != "" {
+			f, err := os.Create(exportOutput) //nolint:gosec // User-chosen output file
+			if err != nil {
+				return err
+			}
+			defer func() { _ = f.Close() }()
+			w = f
+		}
+		switch exportFormat {
+		case exportToggl:
+			email, billable := "", false
+			if tracking != nil {
+				email, billable = tracking.Email, tracking.Billable
+			}
+			if email == "" {
+				fmt.Fprintln(os.Stderr, "Warning: time_tracking.email isn't set; Toggl's import needs the Email column filled in")
+			}
+			err = timetrack.WriteTogglCSV(w, intervals, email, billable)
+		case exportTimew:
+			err = timetrack.WriteTimew(w, intervals)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		if exportOutput != "" {
+			fmt.Printf("Exported %d entries to %s\n", len(intervals), exportOutput)
+		}
+		return nil
+	},
+}
+
+// pushToggl creates Toggl time entries for intervals not pushed before.
+func pushToggl(cmd *cobra.Command, tracking *config.TimeTracking, intervals []timetrack.Interval) error {
+	if tracking == nil || tracking.TogglWorkspaceID == 0 {
+		return fmt.Errorf("--push needs time_tracking.toggl_workspace_id in %s", charm.ConfigPath())
+	}
+	token := tracking.Secret()
+	if token == "" {
+		return fmt.Errorf("--push needs a Toggl API token in time_tracking.toggl_api_token or %s", config.TogglAPITokenEnv)
+	}
+	state, err := timetrack.LoadPushState(charm.TogglStatePath())
+	if err != nil {
+		return err
+	}
+
+	toggl := timetrack.NewToggl(token, tracking.TogglWorkspaceID)
+	result, err := timetrack.Push(cmd.Context(), toggl, intervals, tracking.Billable, state, exportDryRun)
+	if !exportDryRun && result.Created > 0 {
+		if saveErr := state.Save(charm.TogglStatePath()); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("toggl push failed (%d created before the error): %w", result.Created, err)
+	}
+	verb := "Created"
+	if exportDryRun {
+		verb = "Would create"
+	}
+	color.Green("%s %d Toggl time entries, %d already pushed", verb, result.Created, result.Skipped)
+	return nil
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "Export format: toggl or timew")
+	exportCmd.Flags().StringVar(&exportSince, "since", "", "Start date (natural language or ISO)")
+	exportCmd.Flags().StringVar(&exportUntil, "until", "", "End date (natural language or ISO)")
+	exportCmd.Flags().StringArrayVarP(&exportTags, "tag", "t", []string{}, "Only entries with these tags")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write to this file instead of stdout")
+	exportCmd.Flags().BoolVar(&exportPush, "push", false, "Create the time entries through Toggl's API")
+	exportCmd.Flags().BoolVar(&exportDryRun, "dry-run", false, "With --push, show what would be created without creating it")
+	_ = exportCmd.MarkFlagRequired("format")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Feed command writing selected entries as an Atom feed
+// ABOUTME: For following the journal, or a filtered share of it, in a feed reader
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/feed"
+	"github.com/spf13/cobra"
+)
+
+var (
+	feedOutput  string
+	feedTags    []string
+	feedType    string
+	feedLimit   int
+	feedTitle   string
+	feedSelfURL string
+)
+
+var feedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "Write entries as an Atom feed",
+	Long: `Write the newest entries as an Atom feed, to stdout or to --output. Filter
+with --tag and --type to share part of the journal, for example entries
+tagged public, and publish the file wherever your feed reader can fetch it.
+
+Feeds include each entry's message, type, tags, author, and metadata, but
+not the host or directory it was logged from.
+
+  chronicle feed --output ~/public/feed.xml --tag public
+  chronicle feed --type milestone --limit 20`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateEntryType(feedType); err != nil {
+			return err
+		}
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+		entries, err := client.SearchEntries(&charm.SearchFilter{Tags: feedTags, Type: feedType}, feedLimit)
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+
+		title := feedTitle
+		if title == "" {
+			title = feed.DefaultTitle(feedTags)
+		}
+		var buf bytes.Buffer
+		if err := feed.WriteAtom(&buf, entries, feed.Options{Title: title, SelfURL: feedSelfURL}); err != nil {
+			return err
+		}
+		if feedOutput == "" {
+			_, err := os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		if err := writeFeedFile(feedOutput, buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to save feed: %w", err)
+		}
+		color.Green("Wrote %d entries to %s", len(entries), feedOutput)
+		return nil
+	},
+}
+
+// writeFeedFile replaces path with data in one step, so a web server never
+// serves a half-written feed. The file is world-readable for that server.
+func writeFeedFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".chronicle-feed-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil { //nolint:gosec // Feeds are published for others to read
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func init() {
+	feedCmd.Flags().StringVarP(&feedOutput, "output", "o", "", "Write the feed to this file instead of stdout")
+	feedCmd.Flags().StringArrayVarP(&feedTags, "tag", "t", []string{}, "Only include entries with one of these tags")
+	feedCmd.Flags().StringVar(&feedType, "type", "", "Only include entries of this type")
+	feedCmd.Flags().IntVarP(&feedLimit, "limit", "n", 50, "Maximum number of entries")
+	feedCmd.Flags().StringVar(&feedTitle, "title", "", "Feed title (default: chronicle, plus any tags)")
+	feedCmd.Flags().StringVar(&feedSelfURL, "url", "", "URL the feed will be published at")
+	rootCmd.AddCommand(feedCmd)
+}
@@ -0,0 +1,121 @@
+// ABOUTME: Shared table rendering for list and search output
+// ABOUTME: Adds per-type icons and colors so entry kinds stand out, and picks table or JSON output
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// jsonOutput reports whether cmd should print JSON: its --json flag if
+// given, else output_format (or CHRONICLE_FORMAT) from the config.
+func jsonOutput(cmd *cobra.Command, flag bool) bool {
+	if cmd.Flags().Changed("json") {
+		return flag
+	}
+	cfg, err := charm.LoadConfig()
+	return err == nil && cfg.OutputFormat == charm.OutputJSON
+}
+
+// timestampLayout is how tables and show print entry times: the config's
+// date_format followed by the time of day. Invalid settings fall back to iso.
+func timestampLayout() string {
+	date := time.DateOnly
+	if cfg, err := charm.LoadConfig(); err == nil {
+		if layout, err := config.DateLayout(cfg.DateFormat); err == nil {
+			date = layout
+		}
+	}
+	return date + " 15:04:05"
+}
+
+// entryTypeStyles maps entry types to their icon and color.
+var entryTypeStyles = map[string]struct {
+	icon  string
+	color *color.Color
+}{
+	charm.EntryTypeNote:      {"•", color.New(color.FgWhite)},
+	charm.EntryTypeDecision:  {"◆", color.New(color.FgMagenta)},
+	charm.EntryTypeTodo:      {"☐", color.New(color.FgYellow)},
+	charm.EntryTypeMilestone: {"★", color.New(color.FgGreen)},
+}
+
+// entryTypeLabel renders an entry type as an uncolored icon and label.
+func entryTypeLabel(kind string) string {
+	style, ok := entryTypeStyles[kind]
+	if !ok {
+		return kind
+	}
+	return style.icon + " " + kind
+}
+
+// colorEntryType wraps a type label in the type's color.
+// Colors are dropped automatically when NO_COLOR is set or stdout is not a TTY.
+func colorEntryType(kind, label string) string {
+	style, ok := entryTypeStyles[kind]
+	if !ok {
+		return label
+	}
+	return style.color.Sprint(label)
+}
+
+// validateEntryType returns an error listing the valid types if kind is unknown.
+func validateEntryType(kind string) error {
+	if kind == "" || charm.ValidEntryType(kind) {
+		return nil
+	}
+	return fmt.Errorf("invalid type %q (valid: %v)", kind, charm.EntryTypes)
+}
+
+// printEntriesTable writes entries as an aligned table.
+// Columns are aligned on the plain text first and colored afterwards, so
+// ANSI escape codes never count toward column widths.
+func printEntriesTable(w io.Writer, entries []charm.Entry) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "ID\tTimestamp\tType\tTags\tMessage")
+	_, _ = fmt.Fprintln(tw, "--\t---------\t----\t----\t-------")
+	layout := timestampLayout()
+	for _, entry := range entries {
+		tagsStr := ""
+		if len(entry.Tags) > 0 {
+			tagsStr = fmt.Sprintf("%v", entry.Tags)
+		}
+		timestamp := entry.Timestamp.Format(layout)
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", entry.ID, timestamp, entryTypeLabel(entry.Kind()), tagsStr, tableCell(entry.Message))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	// The first two lines are the header and separator, and tableCell keeps
+	// every entry to one line after them.
+	for i, entry := range entries {
+		line := lines[i+2]
+		label := entryTypeLabel(entry.Kind())
+		lines[i+2] = strings.Replace(line, label, colorEntryType(entry.Kind(), label), 1)
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, ""))
+	return err
+}
+
+// cellBreaks are the characters that would end a table row or cell early.
+var cellBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// tableCell flattens text, such as a message written with add --edit, onto
+// one line for a table cell.
+func tableCell(text string) string {
+	return cellBreaks.Replace(strings.TrimSpace(text))
+}
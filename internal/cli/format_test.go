@@ -0,0 +1,111 @@
+// ABOUTME: Unit tests for shared table rendering
+// ABOUTME: Verifies column alignment with and without color output
+package cli
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func tableTestEntries() []charm.Entry {
+	ts := time.Date(2025, 11, 29, 14, 30, 0, 0, time.UTC)
+	return []charm.Entry{
+		{ID: "a1", Timestamp: ts, Type: "milestone", Tags: []string{"release"}, Message: "shipped v2"},
+		{ID: "b2", Timestamp: ts, Tags: []string{"work"}, Message: "legacy entry"},
+		{ID: "c3", Timestamp: ts, Type: "todo", Message: "write docs"},
+		{ID: "d4", Timestamp: ts, Type: "decision", Message: "use sqlite\n\nfewer moving parts"},
+	}
+}
+
+func TestPrintEntriesTable(t *testing.T) {
+	original := color.NoColor
+	defer func() { color.NoColor = original }()
+
+	t.Run("plain output has no escape codes and aligned columns", func(t *testing.T) {
+		color.NoColor = true
+
+		var out bytes.Buffer
+		if err := printEntriesTable(&out, tableTestEntries()); err != nil {
+			t.Fatalf("printEntriesTable failed: %v", err)
+		}
+
+		if strings.Contains(out.String(), "\x1b") {
+			t.Errorf("expected no ANSI escape codes, got: %q", out.String())
+		}
+
+		lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+		if len(lines) != 6 {
+			t.Fatalf("expected 6 lines, got %d: %q", len(lines), out.String())
+		}
+
+		// Message column starts at the same rune offset on every line
+		header := []rune(lines[0])
+		want := strings.Index(string(header), "Message")
+		wantRunes := len([]rune(string(header)[:want]))
+		messages := []string{"shipped v2", "legacy entry", "write docs", "use sqlite  fewer moving parts"}
+		for i, msg := range messages {
+			line := lines[i+2]
+			idx := strings.Index(line, msg)
+			if idx < 0 {
+				t.Fatalf("line %q missing message %q", line, msg)
+			}
+			if got := len([]rune(line[:idx])); got != wantRunes {
+				t.Errorf("message %q starts at column %d, header at %d", msg, got, wantRunes)
+			}
+		}
+
+		if !strings.Contains(lines[3], "• note") {
+			t.Errorf("expected untyped entry to render as note, got: %q", lines[3])
+		}
+	})
+
+	t.Run("colored output aligns the same once escapes are removed", func(t *testing.T) {
+		color.NoColor = true
+		var plain bytes.Buffer
+		if err := printEntriesTable(&plain, tableTestEntries()); err != nil {
+			t.Fatalf("printEntriesTable failed: %v", err)
+		}
+
+		color.NoColor = false
+		var colored bytes.Buffer
+		if err := printEntriesTable(&colored, tableTestEntries()); err != nil {
+			t.Fatalf("printEntriesTable failed: %v", err)
+		}
+
+		if !strings.Contains(colored.String(), "\x1b[") {
+			t.Fatal("expected colored output to contain escape codes")
+		}
+		if got := ansiPattern.ReplaceAllString(colored.String(), ""); got != plain.String() {
+			t.Errorf("colored output misaligned:\ngot:\n%s\nwant:\n%s", got, plain.String())
+		}
+		// The multi-line entry's label is colored on its own row
+		last := strings.Split(strings.TrimRight(colored.String(), "\n"), "\n")[5]
+		if !strings.Contains(last, "d4") || !strings.Contains(last, "\x1b[") {
+			t.Errorf("last row %q should be d4's, colored", last)
+		}
+	})
+}
+
+func TestValidateEntryType(t *testing.T) {
+	if err := validateEntryType(""); err != nil {
+		t.Errorf("expected empty type to be allowed, got: %v", err)
+	}
+	if err := validateEntryType("decision"); err != nil {
+		t.Errorf("expected decision to be valid, got: %v", err)
+	}
+	err := validateEntryType("idea")
+	if err == nil {
+		t.Fatal("expected error for unknown type")
+	}
+	if !strings.Contains(err.Error(), "milestone") {
+		t.Errorf("expected error to list valid types, got: %v", err)
+	}
+}
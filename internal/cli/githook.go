@@ -0,0 +1,245 @@
+// ABOUTME: Githook command, which installs git hooks that log commits to chronicle
+// ABOUTME: Hooks call the hidden githook run subcommand with the details git passes them
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+// Hooks githook install can write.
+const (
+	hookPostCommit   = "post-commit"
+	hookPostMerge    = "post-merge"
+	hookPostCheckout = "post-checkout"
+)
+
+// hookMarker identifies hook scripts chronicle wrote, so install can update
+// them and uninstall can remove them without touching anyone else's hooks.
+const hookMarker = "# Installed by chronicle githook install"
+
+var (
+	githookPostMerge    bool
+	githookPostCheckout bool
+	githookForce        bool
+)
+
+var githookCmd = &cobra.Command{
+	Use:   "githook",
+	Short: "Log commits automatically with git hooks",
+}
+
+var githookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install git hooks in the current repository",
+	Long: `Install a post-commit hook in the current repository that logs each commit's
+subject and branch, tagged git and the repository name. --post-merge and
+--post-checkout also log merges and branch switches.
+
+The hooks run chronicle in the background and never fail a git command.
+An existing hook that chronicle didn't write is left alone unless --force
+is given.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hooks := []string{hookPostCommit}
+		if githookPostMerge {
+			hooks = append(hooks, hookPostMerge)
+		}
+		if githookPostCheckout {
+			hooks = append(hooks, hookPostCheckout)
+		}
+
+		dir, err := gitHooksDir()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		for _, hook := range hooks {
+			path := filepath.Join(dir, hook)
+			if err := installHook(path, hookScript(hookCommand(), hook), githookForce); err != nil {
+				return err
+			}
+			fmt.Printf("Installed %s\n", path)
+		}
+		return nil
+	},
+}
+
+var githookUninstallCmd = &cobra.Command{
+	Use:          "uninstall",
+	Short:        "Remove chronicle's git hooks from the current repository",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := gitHooksDir()
+		if err != nil {
+			return err
+		}
+		removed := 0
+		for _, hook := range []string{hookPostCommit, hookPostMerge, hookPostCheckout} {
+			path := filepath.Join(dir, hook)
+			if !isChronicleHook(path) {
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			fmt.Printf("Removed %s\n", path)
+			removed++
+		}
+		if removed == 0 {
+			fmt.Println("No chronicle hooks installed")
+		}
+		return nil
+	},
+}
+
+var githookRunCmd = &cobra.Command{
+	Use:    "run <hook> [git hook args...]",
+	Short:  "Log the event for a git hook (called by the installed hooks)",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		message, err := hookMessage(args[0], args[1:])
+		if err != nil || message == "" {
+			return err
+		}
+		top, err := gitOutput("rev-parse", "--show-toplevel")
+		if err != nil {
+			return err
+		}
+		_, err = addEntry(newEntry{
+			Message: message,
+			Tags:    []string{"git", filepath.Base(top)},
+			Source:  charm.SourceGitHook,
+		})
+		return err
+	},
+}
+
+// hookMessage describes the event behind a git hook, or returns "" when
+// there's nothing worth logging, such as the checkout git clone does.
+func hookMessage(hook string, args []string) (string, error) {
+	switch hook {
+	case hookPostCommit:
+		subject, err := gitOutput("log", "-1", "--format=%s")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Committed on %s: %s", currentBranch(), subject), nil
+	case hookPostMerge:
+		subject, err := gitOutput("log", "-1", "--format=%s")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Merged into %s: %s", currentBranch(), subject), nil
+	case hookPostCheckout:
+		// Arguments are the previous HEAD, the new HEAD, and 1 for a
+		// branch checkout (0 for checking out files)
+		if len(args) < 3 || args[2] != "1" || strings.Trim(args[0], "0") == "" {
+			return "", nil
+		}
+		return fmt.Sprintf("Switched to %s", currentBranch()), nil
+	}
+	return "", fmt.Errorf("unknown hook %q (use %s, %s, or %s)", hook, hookPostCommit, hookPostMerge, hookPostCheckout)
+}
+
+// currentBranch names the checked-out branch, or the short commit hash
+// when HEAD is detached.
+func currentBranch() string {
+	if branch, err := gitOutput("symbolic-ref", "--short", "-q", "HEAD"); err == nil && branch != "" {
+		return branch
+	}
+	if hash, err := gitOutput("rev-parse", "--short", "HEAD"); err == nil {
+		return "detached HEAD " + hash
+	}
+	return unknownValue
+}
+
+// gitHooksDir returns the current repository's hooks directory, honoring
+// core.hooksPath.
+func gitHooksDir() (string, error) {
+	dir, err := gitOutput("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	return filepath.Abs(dir)
+}
+
+// gitOutput runs git in the current directory and returns its trimmed
+// standard output.
+func gitOutput(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// hookCommand is how hooks invoke chronicle: by name when it's on the
+// PATH, so upgrades and moves keep working, else by this binary's path.
+func hookCommand() string {
+	if _, err := exec.LookPath("chronicle"); err == nil {
+		return "chronicle"
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "chronicle"
+	}
+	return shellQuote(exe)
+}
+
+// hookScript is the shell script installed as hook. chronicle runs in the
+// background with its output discarded so git is never slowed down or
+// failed by it.
+func hookScript(command, hook string) string {
+	return fmt.Sprintf("#!/bin/sh\n%s\n%s githook run %s \"$@\" >/dev/null 2>&1 &\n", hookMarker, command, hook)
+}
+
+// installHook writes script to path. A hook chronicle didn't write is
+// only replaced when force is set.
+func installHook(path, script string, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force && !isChronicleHook(path) {
+		return fmt.Errorf("%s already exists; pass --force to replace it", path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	// WriteFile keeps an existing file's mode
+	return os.Chmod(path, 0755)
+}
+
+// isChronicleHook reports whether the hook at path was written by chronicle.
+func isChronicleHook(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && bytes.Contains(data, []byte(hookMarker))
+}
+
+func init() {
+	githookInstallCmd.Flags().BoolVar(&githookPostMerge, "post-merge", false, "Also log merges and pulls")
+	githookInstallCmd.Flags().BoolVar(&githookPostCheckout, "post-checkout", false, "Also log branch switches")
+	githookInstallCmd.Flags().BoolVar(&githookForce, "force", false, "Replace existing hooks chronicle didn't write")
+	githookCmd.AddCommand(githookInstallCmd)
+	githookCmd.AddCommand(githookUninstallCmd)
+	githookCmd.AddCommand(githookRunCmd)
+	rootCmd.AddCommand(githookCmd)
+}
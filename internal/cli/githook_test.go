@@ -0,0 +1,70 @@
+// ABOUTME: Tests for the githook command
+// ABOUTME: Validates hook scripts, installing over existing hooks, and checkout filtering
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHookScript(t *testing.T) {
+	script := hookScript("chronicle", hookPostCommit)
+	if !strings.HasPrefix(script, "#!/bin/sh\n") {
+		t.Errorf("script should start with a shebang: %q", script)
+	}
+	if !strings.Contains(script, hookMarker) || !strings.Contains(script, `chronicle githook run post-commit "$@"`) {
+		t.Errorf("unexpected script: %q", script)
+	}
+}
+
+func TestInstallHook(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, hookPostCommit)
+	script := hookScript("chronicle", hookPostCommit)
+
+	if err := installHook(path, script, false); err != nil {
+		t.Fatalf("install into empty dir: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Fatalf("hook should be executable: %v", err)
+	}
+	// Reinstalling over our own hook is fine
+	if err := installHook(path, script, false); err != nil {
+		t.Errorf("reinstall: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nmake lint\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := installHook(path, script, false); err == nil {
+		t.Error("expected an error replacing someone else's hook")
+	}
+	if isChronicleHook(path) {
+		t.Error("foreign hook reported as chronicle's")
+	}
+	if err := installHook(path, script, true); err != nil {
+		t.Errorf("install with force: %v", err)
+	}
+	if !isChronicleHook(path) {
+		t.Error("hook not replaced with force")
+	}
+}
+
+func TestHookMessageSkipsUninterestingCheckouts(t *testing.T) {
+	const prev, next = "1111111111111111111111111111111111111111", "2222222222222222222222222222222222222222"
+	for name, args := range map[string][]string{
+		"file checkout": {prev, next, "0"},
+		"clone":         {"0000000000000000000000000000000000000000", next, "1"},
+		"no args":       nil,
+	} {
+		msg, err := hookMessage(hookPostCheckout, args)
+		if err != nil || msg != "" {
+			t.Errorf("%s: got %q, %v; want nothing logged", name, msg, err)
+		}
+	}
+	if _, err := hookMessage("pre-push", nil); err == nil {
+		t.Error("expected an error for an unknown hook")
+	}
+}
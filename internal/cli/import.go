@@ -0,0 +1,140 @@
+// ABOUTME: Import command for bringing entries in from other sources
+// ABOUTME: Supports legacy chronicle backups and other journaling tools' formats
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importDryRun bool
+	importNoSync bool
+	importFormat string
+)
+
+// importReaders reads a file in each format import --format accepts.
+var importReaders = map[string]func(path string) ([]charm.Entry, error){
+	"dayone": importer.ReadDayOneFile,
+	"ics":    importer.ReadICSFile,
+	"jrnl":   importer.ReadJrnlFile,
+	"legacy": importer.ReadLegacyFile,
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import --format <format> <file|url>",
+	Short: "Import entries from other sources",
+	Long: `Import entries from other sources, keeping their original timestamps.
+
+Formats:
+  dayone  - A Day One JSON export (the zip, or one journal's JSON file).
+            Tags, stars (as pins), and location metadata are kept; entries
+            from journals other than "Journal" are tagged with its name.
+  ics     - Past events from an .ics file, a calendar feed URL (https or
+            webcal, such as Google Calendar's secret iCal address), or a
+            CalDAV calendar URL. Each becomes an entry tagged meeting, dated
+            when it ended, with its length as duration metadata. A username
+            in the URL is sent with the password from
+            CHRONICLE_CALDAV_PASSWORD.
+  jrnl    - A jrnl journal file, or the output of 'jrnl --export json'.
+            @tags become tags and starred entries are pinned.
+  legacy  - Old chronicle SQLite backups or JSON/JSONL KV exports
+            (also available as 'chronicle import legacy <file>')
+
+Voice notes are imported with 'chronicle import audio <file|dir>', which
+transcribes them (see 'chronicle import audio --help').
+
+Imported entries get IDs derived from their contents, so importing the
+same file twice does not create duplicates.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		read, ok := importReaders[importFormat]
+		if !ok {
+			if importFormat == "" {
+				return fmt.Errorf("--format is required (one of %s)", importFormatNames())
+			}
+			return fmt.Errorf("unknown format %q (one of %s)", importFormat, importFormatNames())
+		}
+		entries, err := read(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+		return importEntries(entries)
+	},
+}
+
+// importFormatNames lists the formats import --format accepts.
+func importFormatNames() string {
+	names := make([]string, 0, len(importReaders))
+	for name := range importReaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+var importLegacyCmd = &cobra.Command{
+	Use:   "legacy <file>",
+	Short: "Import an old chronicle backup or export",
+	Long: `Import history from an old chronicle install.
+
+Accepts SQLite database backups (both the original integer-ID schema and the
+later UUID schema) and JSON or JSON-lines exports of the KV store. Integer
+IDs are mapped to stable UUIDs, so importing the same file twice does not
+create duplicates.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := importer.ReadLegacyFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+		return importEntries(entries)
+	},
+}
+
+// importEntries stores entries in one batch, or just reports them with --dry-run.
+func importEntries(entries []charm.Entry) error {
+	if importDryRun {
+		fmt.Printf("Would import %d entries\n", len(entries))
+		return nil
+	}
+
+	client, err := charm.GetClient()
+	if err != nil {
+		return fmt.Errorf("failed to connect to Charm: %w", err)
+	}
+
+	if importNoSync {
+		client = client.WithOptions(charm.WithAutoSync(false))
+	}
+
+	for i := range entries {
+		if entries[i].Source == "" {
+			entries[i].Source = charm.SourceImport
+		}
+	}
+
+	ids, err := client.CreateEntries(entries)
+	if err != nil {
+		return fmt.Errorf("failed to import entries: %w", err)
+	}
+
+	fmt.Printf("Imported %d entries\n", len(ids))
+	if importNoSync {
+		fmt.Println("Sync skipped; run 'chronicle sync now' to push them.")
+	}
+	return nil
+}
+
+func init() {
+	importCmd.PersistentFlags().BoolVar(&importDryRun, "dry-run", false, "Parse and count entries without storing them")
+	importCmd.Flags().StringVarP(&importFormat, "format", "f", "", "Format of the file: "+importFormatNames())
+	importCmd.PersistentFlags().BoolVar(&importNoSync, "no-sync", false, "Store entries locally without syncing afterwards")
+	importCmd.AddCommand(importLegacyCmd)
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,236 @@
+// ABOUTME: Import audio command for turning voice notes into entries
+// ABOUTME: Transcribes recordings, keeps the audio as an attachment, and can watch a folder for new ones
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/harper/chronicle/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+// audioKey is the metadata key holding a voice note's stored recording.
+const audioKey = "audio"
+
+// audioSettle is how long a recording must sit unchanged before --watch
+// imports it, so one still being written or synced into the folder isn't
+// read half-finished.
+const audioSettle = 10 * time.Second
+
+var (
+	importAudioWatch    bool
+	importAudioInterval time.Duration
+)
+
+var importAudioCmd = &cobra.Command{
+	Use:   "audio <file|dir>...",
+	Short: "Import voice notes as transcribed entries",
+	Long: `Transcribe voice notes and add each as an entry tagged voice, dated when
+the recording was made. A directory imports every recording in it. The
+recording is copied into the attachments directory and linked in the entry's
+"audio" metadata; like screenshots, it stays on this machine.
+
+Transcription uses the transcription section of charm.json: any
+OpenAI-compatible /audio/transcriptions endpoint, such as OpenAI's Whisper
+or a local Whisper server. Recordings already imported are skipped without
+transcribing them again.
+
+With --watch the directories are checked every --interval for new
+recordings, for a folder your phone's voice memos sync into.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := charm.LoadConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Transcription == nil {
+			return fmt.Errorf("transcription isn't configured; add a transcription section with a url to %s", charm.ConfigPath())
+		}
+		if err := cfg.Transcription.Validate(); err != nil {
+			return fmt.Errorf("invalid transcription in %s: %w", charm.ConfigPath(), err)
+		}
+
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+		if importNoSync {
+			client = client.WithOptions(charm.WithAutoSync(false))
+		}
+		imp := &audioImporter{client: client, cfg: cfg.Transcription, dryRun: importDryRun, failed: make(map[string]bool)}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if importAudioWatch {
+			return imp.watch(ctx, args, importAudioInterval)
+		}
+
+		files, err := audioFiles(args)
+		if err != nil {
+			return err
+		}
+		failures := 0
+		for _, path := range files {
+			if err := imp.importFile(ctx, path); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to import %s: %v\n", path, err)
+				failures++
+			}
+		}
+		if failures > 0 {
+			return fmt.Errorf("%d of %d voice notes failed", failures, len(files))
+		}
+		if importNoSync && !importDryRun {
+			fmt.Println("Sync skipped; run 'chronicle sync now' to push them.")
+		}
+		return nil
+	},
+}
+
+// audioImporter imports recordings into a journal, remembering those that
+// failed so --watch doesn't retry them on every pass.
+type audioImporter struct {
+	client *charm.Client
+	cfg    *config.Transcription
+	dryRun bool
+	failed map[string]bool
+}
+
+// importFile transcribes the recording at path into an entry, unless it
+// was imported before.
+func (imp *audioImporter) importFile(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // User-chosen import file
+	if err != nil {
+		return err
+	}
+	if existing, err := imp.client.GetEntry(importer.AudioEntryID(data)); err == nil && existing != nil {
+		fmt.Printf("Skipped %s, already imported (ID: %s)\n", path, existing.ID)
+		return nil
+	}
+	if imp.dryRun {
+		fmt.Printf("Would transcribe %s\n", path)
+		return nil
+	}
+
+	entry, err := importer.ReadAudio(ctx, imp.cfg, path)
+	if err != nil {
+		return err
+	}
+	stored, err := newAttachmentPath(charm.AttachmentsDir(), entry.Timestamp, strings.ToLower(filepath.Ext(path)))
+	if err != nil {
+		return err
+	}
+	if err := copyAttachment(path, stored); err != nil {
+		return fmt.Errorf("failed to store recording: %w", err)
+	}
+	entry.Metadata[audioKey] = stored
+	ids, err := imp.client.CreateEntries([]charm.Entry{entry})
+	if err != nil {
+		_ = os.Remove(stored)
+		return fmt.Errorf("failed to create entry: %w", err)
+	}
+	fmt.Printf("Imported %s (ID: %s)\n", path, ids[0])
+	return nil
+}
+
+// watch imports recordings as they appear in dirs, checking every
+// interval, until ctx is cancelled. Recordings already there are imported
+// on the first pass.
+func (imp *audioImporter) watch(ctx context.Context, dirs []string, interval time.Duration) error {
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("--watch needs directories: %s is not one", dir)
+		}
+	}
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	fmt.Printf("Watching %s for voice notes (Ctrl+C to stop)\n", strings.Join(dirs, ", "))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		files, err := audioFiles(dirs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		for _, path := range settledAudio(files, time.Now()) {
+			if imp.failed[path] {
+				continue
+			}
+			if err := imp.importFile(ctx, path); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Fprintf(os.Stderr, "Failed to import %s: %v (skipped until restart)\n", path, err)
+				imp.failed[path] = true
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// audioFiles expands paths into recordings: files as given, and the
+// recordings directly inside directories, sorted by name. A file that
+// isn't a recording is an error.
+func audioFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if !importer.IsAudioFile(path) {
+				return nil, fmt.Errorf("%s is not a supported recording (flac, m4a, mp3, mp4, mpeg, mpga, oga, ogg, wav, or webm)", path)
+			}
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var found []string
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") && importer.IsAudioFile(entry.Name()) {
+				found = append(found, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// settledAudio returns the files last written at least audioSettle
+// before now.
+func settledAudio(files []string, now time.Time) []string {
+	var settled []string
+	for _, path := range files {
+		if info, err := os.Stat(path); err == nil && now.Sub(info.ModTime()) >= audioSettle {
+			settled = append(settled, path)
+		}
+	}
+	return settled
+}
+
+func init() {
+	importAudioCmd.Flags().BoolVar(&importAudioWatch, "watch", false, "Keep watching the directories for new recordings")
+	importAudioCmd.Flags().DurationVar(&importAudioInterval, "interval", 30*time.Second, "How often --watch checks for new recordings")
+	importCmd.AddCommand(importAudioCmd)
+}
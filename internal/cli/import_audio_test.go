@@ -0,0 +1,54 @@
+// ABOUTME: Tests for finding recordings to import as voice notes
+// ABOUTME: Checks directory expansion and the settle delay used by --watch
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAudioFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.m4a", "a.mp3", "notes.txt", ".partial.m4a"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.wav"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := audioFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(dir, "a.mp3"), filepath.Join(dir, "b.m4a")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("audioFiles() = %v, want %v", got, want)
+	}
+	if _, err := audioFiles([]string{filepath.Join(dir, "notes.txt")}); err == nil {
+		t.Error("expected an error naming a file that isn't a recording")
+	}
+	if _, err := audioFiles([]string{filepath.Join(dir, "missing.mp3")}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestSettledAudio(t *testing.T) {
+	dir := t.TempDir()
+	old, fresh := filepath.Join(dir, "old.m4a"), filepath.Join(dir, "fresh.m4a")
+	for _, path := range []string{old, fresh} {
+		if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	if err := os.Chtimes(old, now.Add(-time.Minute), now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if got := settledAudio([]string{old, fresh}, now); !reflect.DeepEqual(got, []string{old}) {
+		t.Errorf("settledAudio() = %v, want only the old recording", got)
+	}
+}
@@ -0,0 +1,153 @@
+// ABOUTME: Init command, an interactive first-run setup wizard
+// ABOUTME: Asks about sync, storage, and default tags, then writes the global config
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up chronicle interactively",
+	Long: `Walk through first-time setup: whether entries sync through Charm or stay
+on this machine, where the database lives, and tags to add to every entry.
+Answers are written to ~/.config/chronicle/charm.json; press Enter to keep
+the value shown in brackets. Optionally prints MCP configuration for
+assistants.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInit(cmd.InOrStdin(), cmd.OutOrStdout())
+	},
+}
+
+// Sync choices offered by chronicle init.
+const (
+	initSyncCharm = "1"
+	initSyncLocal = "2"
+)
+
+// runInit asks the setup questions on in and out and saves the answers
+// over the existing config file.
+func runInit(in io.Reader, out io.Writer) error {
+	cfg, err := charm.LoadConfigFile()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", charm.ConfigPath(), err)
+	}
+	p := &prompter{in: bufio.NewReader(in), out: out}
+
+	fmt.Fprintln(out, "Welcome to chronicle! Press Enter to keep the value in brackets.")
+
+	fmt.Fprintln(out, "\nHow should entries sync?")
+	fmt.Fprintln(out, "  1) Through Charm, across all your linked devices")
+	fmt.Fprintln(out, "  2) Local only, until you run 'chronicle sync now'")
+	current := initSyncCharm
+	if !cfg.AutoSync {
+		current = initSyncLocal
+	}
+	for {
+		choice := p.ask("Choice", current)
+		if choice == initSyncCharm || choice == initSyncLocal {
+			current = choice
+			break
+		}
+		fmt.Fprintln(out, "Please enter 1 or 2.")
+	}
+	if current == initSyncCharm {
+		cfg.AutoSync = true
+		cfg.CharmHost = p.ask("Charm server", cfg.CharmHost)
+	} else {
+		cfg.AutoSync = false
+		cfg.AutoSyncPolicy = ""
+	}
+
+	dbPath := cfg.DBPath
+	if dbPath == "" {
+		dbPath = "default"
+	}
+	cfg.DBPath = p.ask("\nDatabase directory", dbPath)
+	if cfg.DBPath == "default" {
+		cfg.DBPath = ""
+	}
+
+	tags := p.ask("\nTags for every entry, comma-separated (e.g. work-laptop)", strings.Join(cfg.DefaultTags, ", "))
+	cfg.DefaultTags = nil
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			cfg.DefaultTags = append(cfg.DefaultTags, tag)
+		}
+	}
+
+	showMCP := p.confirm("\nShow MCP setup for AI assistants?")
+
+	if err := charm.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to write %s: %w", charm.ConfigPath(), err)
+	}
+	fmt.Fprintf(out, "\nSaved %s\n", charm.ConfigPath())
+
+	if showMCP {
+		printMCPSetup(out)
+	}
+	if cfg.AutoSync {
+		fmt.Fprintln(out, "\nNext, run 'chronicle sync link' to connect this device to your Charm account.")
+	}
+	fmt.Fprintln(out, "Try it out: chronicle \"set up chronicle\"")
+	return nil
+}
+
+// printMCPSetup prints configuration snippets that register the chronicle
+// MCP server with common clients.
+func printMCPSetup(out io.Writer) {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "chronicle"
+	}
+	fmt.Fprintln(out, "\nClaude Code:")
+	fmt.Fprintf(out, "  claude mcp add chronicle -- %s mcp\n", exe)
+	fmt.Fprintln(out, "\nClaude Desktop (claude_desktop_config.json):")
+	fmt.Fprintf(out, `  {
+    "mcpServers": {
+      "chronicle": {
+        "command": %q,
+        "args": ["mcp"]
+      }
+    }
+  }
+`, exe)
+}
+
+// prompter reads answers to setup questions.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints question and returns the trimmed answer, or def if it's blank.
+func (p *prompter) ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	answer, _ := p.in.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// confirm asks a yes/no question that defaults to no.
+func (p *prompter) confirm(question string) bool {
+	answer := strings.ToLower(p.ask(question+" [y/N]", ""))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
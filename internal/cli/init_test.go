@@ -0,0 +1,47 @@
+// ABOUTME: Tests for the chronicle init setup wizard
+// ABOUTME: Feeds scripted answers and checks the config written
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func TestRunInit(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	t.Run("local only with tags", func(t *testing.T) {
+		var out bytes.Buffer
+		answers := "3\n2\n~/journal\nwork-laptop, ops\ny\n"
+		if err := runInit(strings.NewReader(answers), &out); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := charm.LoadConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.AutoSync || cfg.DBPath != "~/journal" || strings.Join(cfg.DefaultTags, ",") != "work-laptop,ops" {
+			t.Errorf("unexpected config: auto_sync=%v db_path=%q tags=%v", cfg.AutoSync, cfg.DBPath, cfg.DefaultTags)
+		}
+		if !strings.Contains(out.String(), "Please enter 1 or 2") || !strings.Contains(out.String(), "mcpServers") {
+			t.Errorf("expected a retry prompt and MCP setup, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("blank answers keep the current config", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := runInit(strings.NewReader("\n\n\n\n"), &out); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := charm.LoadConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.AutoSync || cfg.DBPath != "~/journal" || len(cfg.DefaultTags) != 2 {
+			t.Errorf("blank answers changed the config: %+v", cfg)
+		}
+	})
+}
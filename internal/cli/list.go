@@ -5,6 +5,7 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/harper/chronicle/internal/charm"
 	"github.com/spf13/cobra"
@@ -12,6 +13,8 @@ import (
 
 var (
 	listLimit      int
+	listType       string
+	listProject    string
 	listJSONOutput bool
 )
 
@@ -19,44 +22,73 @@ var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List recent entries",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateEntryType(listType); err != nil {
+			return err
+		}
+
 		// Get Charm client
 		client, err := charm.GetClient()
 		if err != nil {
 			return fmt.Errorf("failed to connect to Charm: %w", err)
 		}
 
+		projectID, err := resolveProjectID(client, listProject)
+		if err != nil {
+			return err
+		}
+
 		// List entries
-		entries, err := client.ListEntries(listLimit)
+		filter := &charm.SearchFilter{Type: listType, ProjectID: projectID}
+		all, err := client.SearchEntries(filter, 0)
 		if err != nil {
 			return fmt.Errorf("failed to list entries: %w", err)
 		}
 
-		if listJSONOutput {
-			data, err := json.MarshalIndent(entries, "", "  ")
+		// Pinned entries always come first, outside the limit
+		pinned, entries := splitPinned(all, listLimit)
+
+		if jsonOutput(cmd, listJSONOutput) {
+			data, err := json.MarshalIndent(append(pinned, entries...), "", "  ")
 			if err != nil {
 				return fmt.Errorf("failed to marshal JSON: %w", err)
 			}
 			fmt.Println(string(data))
-		} else {
-			// Print table
-			fmt.Println("ID\tTimestamp\t\t\tTags\t\tMessage")
-			fmt.Println("--\t---------\t\t\t----\t\t-------")
-			for _, entry := range entries {
-				tagsStr := ""
-				if len(entry.Tags) > 0 {
-					tagsStr = fmt.Sprintf("%v", entry.Tags)
-				}
-				timestamp := entry.Timestamp.Format("2006-01-02 15:04:05")
-				fmt.Printf("%s\t%s\t%s\t%s\n", entry.ID, timestamp, tagsStr, entry.Message)
+			return nil
+		}
+
+		if len(pinned) > 0 {
+			fmt.Println("Pinned:")
+			if err := printEntriesTable(os.Stdout, pinned); err != nil {
+				return fmt.Errorf("failed to print entries: %w", err)
 			}
+			fmt.Println()
+		}
+		if err := printEntriesTable(os.Stdout, entries); err != nil {
+			return fmt.Errorf("failed to print entries: %w", err)
 		}
 
 		return nil
 	},
 }
 
+// splitPinned separates pinned entries from the rest, keeping at most limit
+// of the unpinned entries. Order is preserved within each group.
+func splitPinned(entries []charm.Entry, limit int) (pinned, rest []charm.Entry) {
+	for _, e := range entries {
+		switch {
+		case e.Pinned:
+			pinned = append(pinned, e)
+		case limit <= 0 || len(rest) < limit:
+			rest = append(rest, e)
+		}
+	}
+	return pinned, rest
+}
+
 func init() {
 	listCmd.Flags().IntVarP(&listLimit, "limit", "n", 20, "Number of entries to show")
+	listCmd.Flags().StringVar(&listType, "type", "", "Filter by entry type (note, decision, todo, milestone)")
+	listCmd.Flags().StringVarP(&listProject, "project", "p", "", "Filter by project name")
 	listCmd.Flags().BoolVar(&listJSONOutput, "json", false, "Output as JSON")
 	rootCmd.AddCommand(listCmd)
 }
@@ -0,0 +1,32 @@
+// ABOUTME: Tests for list command helpers
+// ABOUTME: Covers pinned entries being separated from the limited list
+package cli
+
+import (
+	"testing"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func TestSplitPinned(t *testing.T) {
+	entries := []charm.Entry{
+		{ID: "1"},
+		{ID: "2", Pinned: true},
+		{ID: "3"},
+		{ID: "4"},
+		{ID: "5", Pinned: true},
+	}
+
+	pinned, rest := splitPinned(entries, 2)
+	if len(pinned) != 2 || pinned[0].ID != "2" || pinned[1].ID != "5" {
+		t.Errorf("pinned = %v, want [2 5]", pinned)
+	}
+	if len(rest) != 2 || rest[0].ID != "1" || rest[1].ID != "3" {
+		t.Errorf("rest = %v, want [1 3]", rest)
+	}
+
+	_, all := splitPinned(entries, 0)
+	if len(all) != 3 {
+		t.Errorf("limit 0 should keep every unpinned entry, got %d", len(all))
+	}
+}
@@ -0,0 +1,155 @@
+// ABOUTME: Logs command for maintaining a project's local log directory
+// ABOUTME: Regenerates the INDEX.md listing every log file and backfills logs from the database
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/harper/chronicle/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backfillSince     string
+	backfillOverwrite bool
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Maintain the current project's local log files",
+	Long: `Work with the log files a project writes when its .chronicle file sets
+local_logging = true.
+
+Commands:
+  index     - Regenerate INDEX.md in the log directory
+  backfill  - Write log files for entries recorded before local logging was on`,
+}
+
+var logsIndexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Regenerate the project log index",
+	Long: `Write INDEX.md into the project's log directory, listing each log file
+with its period, entry count, and most used tags.
+
+Set log_index = true in .chronicle to keep the index updated on every
+chronicle add.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectRoot, projectCfg, err := currentProject()
+		if err != nil {
+			return err
+		}
+		tmpl, err := projectLogTemplate(projectRoot, projectCfg)
+		if err != nil {
+			return err
+		}
+
+		logDir := projectCfg.LogPath(projectRoot)
+		if err := logging.WriteIndex(logDir, tmpl == nil); err != nil {
+			return err
+		}
+		color.Green("Wrote %s", filepath.Join(logDir, logging.IndexFileName))
+		return nil
+	},
+}
+
+var logsBackfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "Write project log files from entries already in the database",
+	Long: `Write the project's log files for entries recorded anywhere under the
+project root, using the project's log format, rotation, and template.
+
+Log files that already exist are left alone, since they hold entries
+written since local logging was turned on. Use --overwrite to regenerate
+them from the database as well (not allowed for Obsidian daily notes).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectRoot, projectCfg, err := currentProject()
+		if err != nil {
+			return err
+		}
+		if !projectCfg.LocalLogging {
+			return fmt.Errorf("local_logging is not enabled in %s", filepath.Join(projectRoot, ".chronicle"))
+		}
+		tmpl, err := projectLogTemplate(projectRoot, projectCfg)
+		if err != nil {
+			return err
+		}
+		since, err := parseDateFlag("since", backfillSince)
+		if err != nil {
+			return err
+		}
+
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+		filter := &charm.SearchFilter{Directory: config.CanonicalDir(projectRoot, false), Since: since}
+		stored, err := client.SearchEntries(filter, 0)
+		if err != nil {
+			return fmt.Errorf("failed to search entries: %w", err)
+		}
+		entries := make([]logging.Entry, len(stored))
+		for i, entry := range stored {
+			entries[i] = toLogEntry(entry)
+		}
+
+		logDir := projectCfg.LogPath(projectRoot)
+		result, err := logging.Backfill(logDir, projectCfg.LogFormat, projectCfg.LogRotation, tmpl, entries, backfillOverwrite)
+		if err != nil {
+			return err
+		}
+
+		color.Green("Wrote %d entries to %d log files in %s", result.Entries, result.Files, logDir)
+		if len(result.Skipped) > 0 {
+			fmt.Printf("Skipped %d existing files (use --overwrite to regenerate them): %s\n",
+				len(result.Skipped), strings.Join(result.Skipped, ", "))
+		}
+
+		if projectCfg.LogLatest && result.Files > 0 {
+			if err := logging.UpdateLatest(logDir, projectCfg.LogFormat); err != nil {
+				return err
+			}
+		}
+		if projectCfg.LogIndex && result.Files > 0 {
+			if err := logging.WriteIndex(logDir, tmpl == nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// currentProject finds the project containing the working directory and
+// loads its .chronicle config.
+func currentProject() (string, *config.ProjectConfig, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	projectRoot, err := findProjectRoot(cwd)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find project: %w", err)
+	}
+	if projectRoot == "" {
+		return "", nil, fmt.Errorf("not inside a project (no .chronicle file found)")
+	}
+
+	projectCfg, err := config.LoadProjectConfig(filepath.Join(projectRoot, ".chronicle"))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load project config: %w", err)
+	}
+	return projectRoot, projectCfg, nil
+}
+
+func init() {
+	logsBackfillCmd.Flags().StringVar(&backfillSince, "since", "", "Only backfill entries from this date on (natural language or ISO)")
+	logsBackfillCmd.Flags().BoolVar(&backfillOverwrite, "overwrite", false, "Regenerate log files that already exist")
+	logsCmd.AddCommand(logsIndexCmd)
+	logsCmd.AddCommand(logsBackfillCmd)
+	rootCmd.AddCommand(logsCmd)
+}
@@ -0,0 +1,95 @@
+// ABOUTME: Maintenance and sync compact commands for compacting the local database
+// ABOUTME: Checkpoints the WAL, vacuums, and reports before/after file sizes
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Compact the local database",
+	Long: `Compact the local chronicle database for long-lived journals.
+
+Runs a WAL checkpoint, integrity check, and VACUUM on the local store and
+reports the file size before and after. It takes chronicle's write lock
+like any other write, so it waits its turn while serve, the MCP server, or
+the sync daemon is writing. Use 'chronicle sync repair' instead if the
+database is corrupted.`,
+	RunE: runCompact,
+}
+
+var syncCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Compact the local database and report reclaimed space",
+	Long: `Compact the local chronicle database.
+
+Edits and deletes leave free pages behind in the SQLite file backing the
+Charm KV store. This checkpoints the WAL, checks integrity, and runs VACUUM
+under chronicle's write lock, then reports how much space was reclaimed.
+Same as 'chronicle maintenance'.`,
+	RunE: runCompact,
+}
+
+// runCompact vacuums the local database and prints the size before and after.
+func runCompact(cmd *cobra.Command, args []string) error {
+	path, err := charm.DBPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate database: %w", err)
+	}
+
+	before := charm.DBSize(path)
+	fmt.Printf("Database: %s (%s)\n", path, formatBytes(before))
+
+	client, err := charm.GetClient()
+	if err != nil {
+		return fmt.Errorf("failed to connect to Charm: %w", err)
+	}
+	result, err := client.Compact()
+	if err != nil {
+		return fmt.Errorf("compaction failed: %w", err)
+	}
+
+	if result.WalCheckpointed {
+		color.Green("  ✓ WAL checkpointed")
+	}
+	if !result.IntegrityOK {
+		color.Red("  ✗ Integrity check failed: %s", result.Integrity)
+		return fmt.Errorf("database is corrupted; run 'chronicle sync repair --force'")
+	}
+	color.Green("  ✓ Integrity check passed")
+	if result.Vacuumed {
+		color.Green("  ✓ Database vacuumed")
+	}
+
+	after := charm.DBSize(path)
+	fmt.Printf("\nSize: %s -> %s", formatBytes(before), formatBytes(after))
+	if before > after {
+		fmt.Printf(" (saved %s)", formatBytes(before-after))
+	}
+	fmt.Println()
+	return nil
+}
+
+// formatBytes renders a byte count with a binary unit suffix.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(maintenanceCmd)
+	syncCmd.AddCommand(syncCompactCmd)
+}
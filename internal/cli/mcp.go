@@ -5,18 +5,57 @@ package cli
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
 
+	"github.com/harper/chronicle/internal/config"
 	"github.com/harper/chronicle/internal/mcp"
 	"github.com/spf13/cobra"
 )
 
+var (
+	mcpProject             string
+	mcpRequireConfirmation bool
+	mcpReadOnly            bool
+	mcpLog                 bool
+	mcpLogFile             string
+	mcpLogLevel            string
+)
+
 var mcpCmd = &cobra.Command{
 	Use:   "mcp",
 	Short: "Run the chronicle MCP server",
-	Long:  `Start the Model Context Protocol server for AI assistants to interact with chronicle over stdio.`,
+	Long: `Start the Model Context Protocol server for AI assistants to interact with chronicle over stdio.
+
+With --project, every tool and resource only sees entries recorded in the
+project directory or below it, and new entries are recorded there.
+--project . uses the project (.chronicle file) containing the current
+directory; use this in a per-repo MCP configuration.
+
+With --profile (or CHRONICLE_PROFILE), the server uses that profile's
+journal, so a work assistant never sees personal entries.
+
+With --log-file, every tool call is appended to that file with its duration
+and any error. A relative name goes in the chronicle state directory
+($XDG_STATE_HOME/chronicle), and --log alone logs to mcp.log there. At
+--log-level debug the arguments are logged too, with messages and other
+journal text replaced by their length.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		root, err := resolveMCPProject(mcpProject)
+		if err != nil {
+			return err
+		}
+
+		logger, closeLog, err := openMCPLog(mcpLogPath(), mcpLogLevel)
+		if err != nil {
+			return err
+		}
+		defer closeLog()
+
 		// Create and run server
-		server, err := mcp.NewServer()
+		server, err := mcp.NewServer(mcp.Options{ProjectRoot: root, RequireConfirmation: mcpRequireConfirmation, ReadOnly: mcpReadOnly, Log: logger})
 		if err != nil {
 			return fmt.Errorf("failed to create MCP server: %w", err)
 		}
@@ -24,6 +63,80 @@ var mcpCmd = &cobra.Command{
 	},
 }
 
+// resolveMCPProject turns the --project value into a project root. "."
+// finds the project enclosing the current directory.
+func resolveMCPProject(project string) (string, error) {
+	switch project {
+	case "":
+		return "", nil
+	case ".":
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+		root, err := findProjectRoot(cwd)
+		if err != nil {
+			return "", fmt.Errorf("failed to find project: %w", err)
+		}
+		if root == "" {
+			return "", fmt.Errorf("no project found above %s (pass --project <dir> to scope to a directory)", cwd)
+		}
+		return root, nil
+	}
+	root, err := filepath.Abs(project)
+	if err != nil {
+		return "", fmt.Errorf("invalid project directory: %w", err)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("project directory %s does not exist", root)
+	}
+	return root, nil
+}
+
+// defaultMCPLog is the log file name used for --log without --log-file.
+const defaultMCPLog = "mcp.log"
+
+// mcpLogPath returns the log file the flags ask for, or "" for none.
+func mcpLogPath() string {
+	if mcpLogFile == "" && mcpLog {
+		return defaultMCPLog
+	}
+	return mcpLogFile
+}
+
+// openMCPLog opens the --log-file (relative to the state dir) for appending and returns a logger at
+// level, plus a function closing the file. With no file it returns a nil
+// logger, turning call logging off.
+func openMCPLog(path, level string) (*slog.Logger, func(), error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, nil, fmt.Errorf("invalid --log-level %q (use debug, info, warn, or error)", level)
+	}
+	if path == "" {
+		return nil, func() {}, nil
+	}
+
+	path = config.ExpandHome(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.GetStateHome(), "chronicle", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) //nolint:gosec // Path comes from the user's own flag
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	logger := slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: lvl}))
+	return logger, func() { _ = f.Close() }, nil
+}
+
 func init() {
+	mcpCmd.Flags().StringVar(&mcpProject, "project", "", "Only see and write entries under this project directory (\".\": the current project)")
+	mcpCmd.Flags().BoolVar(&mcpReadOnly, "read-only", false, "Offer only query tools; assistants can read the journal but never change it")
+	mcpCmd.Flags().BoolVar(&mcpLog, "log", false, "Append a log of every tool call to mcp.log in the state dir")
+	mcpCmd.Flags().StringVar(&mcpLogFile, "log-file", "", "Append a log of every tool call to this file (relative names go in the state dir)")
+	mcpCmd.Flags().StringVar(&mcpLogLevel, "log-level", "info", "Log level for --log and --log-file: debug (adds redacted arguments), info, warn, or error")
+	mcpCmd.Flags().BoolVar(&mcpRequireConfirmation, "require-confirmation", false, "Ask the user before an assistant edits or deletes an entry")
 	rootCmd.AddCommand(mcpCmd)
 }
@@ -0,0 +1,67 @@
+// ABOUTME: Tests for the mcp command's flags
+// ABOUTME: Covers the project and log file flags and rejection of stray arguments
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// parseMCPFlags parses args as mcp command flags, resetting them afterwards.
+func parseMCPFlags(t *testing.T, args ...string) error {
+	t.Helper()
+	t.Cleanup(func() {
+		mcpProject, mcpLog, mcpLogFile = "", false, ""
+	})
+	if err := mcpCmd.ParseFlags(args); err != nil {
+		return err
+	}
+	return mcpCmd.ValidateArgs(mcpCmd.Flags().Args())
+}
+
+func TestMCPLogFileFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mylog.log")
+	if err := parseMCPFlags(t, "--log-file", path); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	_, closeLog, err := openMCPLog(mcpLogPath(), "info")
+	if err != nil {
+		t.Fatalf("openMCPLog: %v", err)
+	}
+	closeLog()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected %s created: %v", path, err)
+	}
+}
+
+func TestMCPLogFlag(t *testing.T) {
+	if err := parseMCPFlags(t, "--log"); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := mcpLogPath(); got != defaultMCPLog {
+		t.Errorf("mcpLogPath() = %q, want %q", got, defaultMCPLog)
+	}
+}
+
+func TestMCPRejectsStrayArguments(t *testing.T) {
+	if err := parseMCPFlags(t, "--log", "/tmp/mylog.log"); err == nil {
+		t.Error("expected a stray argument to be rejected")
+	}
+}
+
+func TestMCPProjectFlag(t *testing.T) {
+	dir := t.TempDir()
+	if err := parseMCPFlags(t, "--project", dir); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	root, err := resolveMCPProject(mcpProject)
+	if err != nil {
+		t.Fatalf("resolveMCPProject: %v", err)
+	}
+	if root != dir {
+		t.Errorf("root = %q, want %q", root, dir)
+	}
+}
@@ -0,0 +1,59 @@
+// ABOUTME: Pin and unpin commands for standing-context entries
+// ABOUTME: Pinned entries stay at the top of list output until unpinned
+package cli
+
+import (
+	"fmt"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var pinRecurrence string
+
+var pinCmd = &cobra.Command{
+	Use:   "pin <id>",
+	Short: "Pin an entry as standing context",
+	Long: `Pin an entry such as "on-call this week" or "working toward v2 launch" so
+it stays at the top of 'chronicle list' and in the MCP pinned resource until
+unpinned. Optionally record how the context recurs with --recurrence.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		if err := client.SetPinned(args[0], true, pinRecurrence); err != nil {
+			return fmt.Errorf("failed to pin entry: %w", err)
+		}
+
+		fmt.Printf("Pinned %s\n", args[0])
+		return nil
+	},
+}
+
+var unpinCmd = &cobra.Command{
+	Use:   "unpin <id>",
+	Short: "Unpin an entry",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		if err := client.SetPinned(args[0], false, ""); err != nil {
+			return fmt.Errorf("failed to unpin entry: %w", err)
+		}
+
+		fmt.Printf("Unpinned %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	pinCmd.Flags().StringVar(&pinRecurrence, "recurrence", "", "Recurrence rule (daily, weekdays, weekly, monthly)")
+	rootCmd.AddCommand(pinCmd)
+	rootCmd.AddCommand(unpinCmd)
+}
@@ -0,0 +1,139 @@
+// ABOUTME: Project command for inspecting and configuring known projects
+// ABOUTME: Lists projects with entry counts and manages per-project default tags
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var projectJSONOutput bool
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Manage projects entries are associated with",
+	Long: `Projects are created automatically the first time an entry is added from
+inside a directory tree containing a .chronicle file (or a git repository,
+when "git_projects" is set in the config).
+
+Commands:
+  list  - Show all projects with their entry counts
+  tags  - Set the default tags applied to a project's new entries`,
+}
+
+// findProjectRoot returns the project containing dir: the nearest
+// .chronicle file, or with git_projects set the nearest git repository.
+func findProjectRoot(dir string) (string, error) {
+	if cfg, err := charm.LoadConfig(); err == nil && cfg.GitProjects {
+		return config.FindProjectRootOrGit(dir)
+	}
+	return config.FindProjectRoot(dir)
+}
+
+var projectListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List projects",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		projects, err := client.ListProjects()
+		if err != nil {
+			return fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		entries, err := client.ListEntries(0)
+		if err != nil {
+			return fmt.Errorf("failed to list entries: %w", err)
+		}
+		counts := make(map[string]int)
+		for _, entry := range entries {
+			if entry.ProjectID != "" {
+				counts[entry.ProjectID]++
+			}
+		}
+
+		if jsonOutput(cmd, projectJSONOutput) {
+			type projectWithCount struct {
+				charm.Project
+				EntryCount int `json:"entry_count"`
+			}
+			out := make([]projectWithCount, len(projects))
+			for i, p := range projects {
+				out[i] = projectWithCount{Project: p, EntryCount: counts[p.ID]}
+			}
+			data, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Println("Name\tEntries\tDefault Tags\tRoot")
+		fmt.Println("----\t-------\t------------\t----")
+		for _, p := range projects {
+			fmt.Printf("%s\t%d\t%v\t%s\n", p.Name, counts[p.ID], p.DefaultTags, p.RootPath)
+		}
+		return nil
+	},
+}
+
+var projectTagsCmd = &cobra.Command{
+	Use:   "tags <project> [tag...]",
+	Short: "Set default tags for a project (no tags clears them)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		project, err := client.FindProjectByName(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to find project: %w", err)
+		}
+		if project == nil {
+			return fmt.Errorf("no project named %q (see 'chronicle project list')", args[0])
+		}
+
+		project.DefaultTags = args[1:]
+		if err := client.UpdateProject(*project); err != nil {
+			return err
+		}
+
+		fmt.Printf("Default tags for %s: %v\n", project.Name, project.DefaultTags)
+		return nil
+	},
+}
+
+// resolveProjectID maps a --project flag value to a project ID.
+// An empty name resolves to an empty ID (no filter).
+func resolveProjectID(client *charm.Client, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	project, err := client.FindProjectByName(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to find project: %w", err)
+	}
+	if project == nil {
+		return "", fmt.Errorf("no project named %q (see 'chronicle project list')", name)
+	}
+	return project.ID, nil
+}
+
+func init() {
+	projectListCmd.Flags().BoolVar(&projectJSONOutput, "json", false, "Output as JSON")
+
+	projectCmd.AddCommand(projectListCmd)
+	projectCmd.AddCommand(projectTagsCmd)
+
+	rootCmd.AddCommand(projectCmd)
+}
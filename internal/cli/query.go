@@ -0,0 +1,220 @@
+// ABOUTME: Query command for app launchers such as Alfred, Raycast, and rofi
+// ABOUTME: Ranks entries as the user types and prints them in the launcher's own format
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryLauncher string
+	queryTags     []string
+	queryType     string
+	queryLimit    int
+)
+
+// launcherWriters print ranked entries in each launcher's format. The
+// entry ID is what the launcher hands back when a result is picked.
+var launcherWriters = map[string]func(w io.Writer, results []charm.Similar, layout string) error{
+	"alfred":  writeAlfred,
+	"raycast": writeRaycast,
+	"rofi":    writeRofi,
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query --launcher alfred|raycast|rofi [text]",
+	Short: "Search entries for an app launcher",
+	Long: `Search entries the way an app launcher does, on every keystroke, and print
+the results in the launcher's format. Each word of the text matches entries
+with a word starting with it, so unfinished words still find results, and
+the best matches come first. Without text the newest entries are shown.
+
+  alfred   Script Filter JSON; each result's arg is the entry ID
+  raycast  JSON {"items": [...]} shaped like Raycast list items, with scores
+  rofi     script mode lines; the entry ID is in each row's info (ROFI_INFO)
+
+Pair it with "chronicle add" to capture from the same launcher, and
+"chronicle show <id>" to open a result.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		write, ok := launcherWriters[queryLauncher]
+		if !ok {
+			return fmt.Errorf("invalid --launcher %q (valid: %s)", queryLauncher, strings.Join(launcherNames(), ", "))
+		}
+		if err := validateEntryType(queryType); err != nil {
+			return err
+		}
+
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		filter := &charm.SearchFilter{Tags: queryTags, Type: queryType}
+		results, err := client.QueryEntries(filter, strings.Join(args, " "), queryLimit)
+		if err != nil {
+			return fmt.Errorf("failed to search entries: %w", err)
+		}
+		return write(os.Stdout, results, timestampLayout())
+	},
+}
+
+// launcherNames lists the supported launchers in order.
+func launcherNames() []string {
+	names := make([]string, 0, len(launcherWriters))
+	for name := range launcherWriters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// launcherTitle is the first line of an entry's message.
+func launcherTitle(entry *charm.Entry) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(entry.Message), "\n")
+	return strings.TrimSpace(title)
+}
+
+// launcherSubtitle summarizes an entry under its title: time, type, and
+// tags.
+func launcherSubtitle(entry *charm.Entry, layout string) string {
+	parts := []string{entry.Timestamp.Local().Format(layout), entryTypeLabel(entry.Kind())}
+	for _, tag := range entry.Tags {
+		parts = append(parts, "#"+tag)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// alfredItem is one result in Alfred's Script Filter JSON.
+type alfredItem struct {
+	UID          string      `json:"uid,omitempty"`
+	Title        string      `json:"title"`
+	Subtitle     string      `json:"subtitle,omitempty"`
+	Arg          string      `json:"arg,omitempty"`
+	Autocomplete string      `json:"autocomplete,omitempty"`
+	Valid        bool        `json:"valid"`
+	Text         *alfredText `json:"text,omitempty"`
+}
+
+// alfredText is what Alfred copies (⌘C) and shows as large type (⌘L).
+type alfredText struct {
+	Copy      string `json:"copy"`
+	LargeType string `json:"largetype"`
+}
+
+// writeAlfred prints results as Alfred Script Filter JSON. Alfred keeps
+// the order given, so the best match comes first. With no results it shows
+// a placeholder row, since an empty list looks like a broken workflow.
+func writeAlfred(w io.Writer, results []charm.Similar, layout string) error {
+	items := make([]alfredItem, 0, len(results))
+	for _, result := range results {
+		entry := result.Entry
+		title := launcherTitle(&entry)
+		items = append(items, alfredItem{
+			UID:          entry.ID,
+			Title:        title,
+			Subtitle:     launcherSubtitle(&entry, layout),
+			Arg:          entry.ID,
+			Autocomplete: title,
+			Valid:        true,
+			Text:         &alfredText{Copy: entry.Message, LargeType: entry.Message},
+		})
+	}
+	if len(items) == 0 {
+		items = append(items, alfredItem{Title: "No matching entries"})
+	}
+	return writeLauncherJSON(w, map[string]any{"items": items})
+}
+
+// raycastItem is one result, named after the props of Raycast's List.Item
+// so an extension can spread it straight in.
+type raycastItem struct {
+	ID          string              `json:"id"`
+	Title       string              `json:"title"`
+	Subtitle    string              `json:"subtitle,omitempty"`
+	Keywords    []string            `json:"keywords,omitempty"`
+	Accessories []map[string]string `json:"accessories,omitempty"`
+	Message     string              `json:"message"`
+	Score       float64             `json:"score"`
+}
+
+// writeRaycast prints results as JSON for a Raycast extension's list,
+// best first.
+func writeRaycast(w io.Writer, results []charm.Similar, layout string) error {
+	items := make([]raycastItem, 0, len(results))
+	for _, result := range results {
+		entry := result.Entry
+		accessories := make([]map[string]string, 0, len(entry.Tags)+1)
+		for _, tag := range entry.Tags {
+			accessories = append(accessories, map[string]string{"tag": "#" + tag})
+		}
+		accessories = append(accessories, map[string]string{"text": entry.Timestamp.Local().Format(layout)})
+		items = append(items, raycastItem{
+			ID:          entry.ID,
+			Title:       launcherTitle(&entry),
+			Subtitle:    entryTypeLabel(entry.Kind()),
+			Keywords:    entry.Tags,
+			Accessories: accessories,
+			Message:     entry.Message,
+			Score:       result.Score,
+		})
+	}
+	return writeLauncherJSON(w, map[string]any{"items": items})
+}
+
+// writeRofi prints one row per result for rofi's script mode. The entry
+// ID rides along as the row's info, which rofi passes back in ROFI_INFO,
+// and the tags as hidden meta text rofi filters on.
+func writeRofi(w io.Writer, results []charm.Similar, layout string) error {
+	for _, result := range results {
+		entry := result.Entry
+		row := rofiField(entry.Timestamp.Local().Format(layout) + "  " + launcherTitle(&entry))
+		row += "\x00info\x1f" + rofiField(entry.ID)
+		if len(entry.Tags) > 0 {
+			row += "\x1fmeta\x1f" + rofiField(strings.Join(entry.Tags, " "))
+		}
+		if _, err := fmt.Fprintln(w, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rofiField strips the bytes that separate rows and options in rofi's
+// script mode.
+func rofiField(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '\n', '\r', '\x00', '\x1f':
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// writeLauncherJSON writes v as compact JSON followed by a newline.
+func writeLauncherJSON(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func init() {
+	queryCmd.Flags().StringVar(&queryLauncher, "launcher", "", "Output format: "+strings.Join(launcherNames(), ", "))
+	queryCmd.Flags().StringArrayVarP(&queryTags, "tag", "t", []string{}, "Filter by tags")
+	queryCmd.Flags().StringVar(&queryType, "type", "", "Filter by entry type (note, decision, todo, milestone)")
+	queryCmd.Flags().IntVarP(&queryLimit, "limit", "n", 20, "Maximum results")
+	_ = queryCmd.MarkFlagRequired("launcher")
+	rootCmd.AddCommand(queryCmd)
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Tests for the launcher output of the query command
+// ABOUTME: Checks Alfred, Raycast, and rofi rendering of ranked entries
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func launcherResults() []charm.Similar {
+	at := time.Date(2025, 3, 4, 9, 30, 0, 0, time.Local)
+	return []charm.Similar{
+		{Entry: charm.Entry{ID: "e1", Timestamp: at, Message: "deployed v2\nwith the new cache", Tags: []string{"ops", "deploy"}}, Score: 2.5},
+		{Entry: charm.Entry{ID: "e2", Timestamp: at, Message: "chose postgres", Type: charm.EntryTypeDecision}, Score: 1},
+	}
+}
+
+func TestWriteAlfred(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeAlfred(&buf, launcherResults(), "2006-01-02 15:04"); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Items []alfredItem `json:"items"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if len(got.Items) != 2 {
+		t.Fatalf("items = %+v, want 2", got.Items)
+	}
+	first := got.Items[0]
+	if first.Arg != "e1" || first.Title != "deployed v2" || !first.Valid {
+		t.Errorf("first item = %+v", first)
+	}
+	if want := "2025-03-04 09:30  • note  #ops  #deploy"; first.Subtitle != want {
+		t.Errorf("subtitle = %q, want %q", first.Subtitle, want)
+	}
+	if first.Text == nil || first.Text.Copy != "deployed v2\nwith the new cache" {
+		t.Errorf("text = %+v, want the whole message", first.Text)
+	}
+
+	buf.Reset()
+	if err := writeAlfred(&buf, nil, time.DateOnly); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"title":"No matching entries","valid":false`) {
+		t.Errorf("empty output = %s, want an invalid placeholder item", buf.String())
+	}
+}
+
+func TestWriteRaycast(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeRaycast(&buf, launcherResults(), "2006-01-02 15:04"); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Items []raycastItem `json:"items"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if len(got.Items) != 2 || got.Items[0].ID != "e1" || got.Items[0].Score != 2.5 {
+		t.Fatalf("items = %+v", got.Items)
+	}
+	if got.Items[1].Subtitle != "◆ decision" {
+		t.Errorf("subtitle = %q", got.Items[1].Subtitle)
+	}
+	accessories := got.Items[0].Accessories
+	if len(accessories) != 3 || accessories[0]["tag"] != "#ops" || accessories[2]["text"] != "2025-03-04 09:30" {
+		t.Errorf("accessories = %v", accessories)
+	}
+
+	buf.Reset()
+	if err := writeRaycast(&buf, nil, time.DateOnly); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != `{"items":[]}` {
+		t.Errorf("empty output = %s", got)
+	}
+}
+
+func TestWriteRofi(t *testing.T) {
+	results := launcherResults()
+	results[1].Entry.ID = "e2\x1fx"
+	var buf bytes.Buffer
+	if err := writeRofi(&buf, results, "2006-01-02 15:04"); err != nil {
+		t.Fatal(err)
+	}
+	want := "2025-03-04 09:30  deployed v2\x00info\x1fe1\x1fmeta\x1fops deploy\n" +
+		"2025-03-04 09:30  chose postgres\x00info\x1fe2 x\n"
+	if buf.String() != want {
+		t.Errorf("writeRofi() = %q, want %q", buf.String(), want)
+	}
+}
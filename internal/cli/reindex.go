@@ -0,0 +1,43 @@
+// ABOUTME: Reindex command for rebuilding the per-day entry index
+// ABOUTME: Backfills index keys for entries written before the index existed
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the per-day entry index",
+	Long: `Rebuild the day index that lets date-range searches (--since, --until,
+today's summary) fetch only the entries from matching days.
+
+Entries written by older versions of chronicle have no index key, and
+searches fall back to reading every entry until this is run once on any
+linked device.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		changed, err := client.RebuildDayIndex()
+		if err != nil {
+			return err
+		}
+		if changed == 0 {
+			color.Green("Day index is up to date.")
+			return nil
+		}
+		color.Green("Updated %d day index keys.", changed)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+}
@@ -3,8 +3,12 @@
 package cli
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +26,72 @@ var rootCmd = &cobra.Command{
      📝 Timestamped logging for your development journey
 
 Chronicle logs timestamped messages with metadata to SQLite and optional project log files.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyCommandDefaults(cmd); err != nil {
+			return err
+		}
+		if err := applyProfileFlag(); err != nil {
+			return err
+		}
+		return applyProjectDatabase()
+	},
+}
+
+// profileFlag selects a separate journal for this invocation.
+var profileFlag string
+
+// applyProfileFlag exports --profile so every client created afterwards
+// (CLI and MCP server alike) opens that profile's database.
+func applyProfileFlag() error {
+	if profileFlag == "" {
+		return nil
+	}
+	if err := charm.ValidateProfile(profileFlag); err != nil {
+		return err
+	}
+	return os.Setenv(charm.ProfileEnv, profileFlag)
+}
+
+// applyProjectDatabase switches to the enclosing project's own store when
+// its .chronicle sets database and the user's config lists the project in
+// project_databases, by exporting CHRONICLE_DB and (unless a profile was
+// chosen explicitly) CHRONICLE_PROFILE. An explicit CHRONICLE_DB wins.
+func applyProjectDatabase() error {
+	if os.Getenv(config.DBPathEnv) != "" {
+		return nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	projectRoot, err := findProjectRoot(cwd)
+	if err != nil || projectRoot == "" {
+		return nil
+	}
+	projectFile := filepath.Join(projectRoot, ".chronicle")
+	projectCfg, err := config.LoadProjectConfig(projectFile)
+	if err != nil || projectCfg.Database == "" {
+		return nil
+	}
+	// A cloned repository mustn't decide where entries go, so the user
+	// lists the projects allowed their own database
+	if cfg, err := charm.LoadConfig(); err != nil || !cfg.AllowsProjectDatabase(projectRoot) {
+		fmt.Fprintf(os.Stderr, "warning: ignoring database in %s; add %q to project_databases in %s to use it\n",
+			projectFile, projectRoot, charm.ConfigPath())
+		return nil
+	}
+
+	if err := os.Setenv(config.DBPathEnv, projectCfg.DatabaseDir(projectRoot)); err != nil {
+		return err
+	}
+	if os.Getenv(charm.ProfileEnv) != "" {
+		return nil
+	}
+	profile := projectCfg.DatabaseName(projectRoot)
+	if err := charm.ValidateProfile(profile); err != nil {
+		return fmt.Errorf("invalid database_profile in %s: %w", projectFile, err)
+	}
+	return os.Setenv(charm.ProfileEnv, profile)
 }
 
 func Execute() error {
@@ -57,5 +127,5 @@ func isKnownCommand(arg string) bool {
 }
 
 func init() {
-	// Global flags can go here
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Use a separate journal (e.g. work, personal)")
 }
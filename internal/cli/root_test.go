@@ -4,8 +4,13 @@ package cli
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
 )
 
 func TestExecute(t *testing.T) {
@@ -74,3 +79,50 @@ func TestRootCommand(t *testing.T) {
 		}
 	})
 }
+
+func TestApplyProjectDatabase(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(config.DBPathEnv, "")
+	t.Setenv(charm.ProfileEnv, "")
+
+	root := t.TempDir()
+	sub := filepath.Join(root, "src")
+	_ = os.MkdirAll(sub, 0755)                                                                      //nolint:gosec // Test directory permissions
+	_ = os.WriteFile(filepath.Join(root, ".chronicle"), []byte(`database = ".chronicle-db"`), 0644) //nolint:gosec // Test file permissions
+	t.Chdir(sub)
+
+	// A project the user hasn't listed can't move the database
+	if err := applyProjectDatabase(); err != nil {
+		t.Fatalf("applyProjectDatabase failed: %v", err)
+	}
+	if got := os.Getenv(config.DBPathEnv); got != "" {
+		t.Fatalf("%s = %q for an unlisted project, want it unset", config.DBPathEnv, got)
+	}
+
+	cfg := charm.DefaultConfig()
+	cfg.ProjectDatabases = []string{root}
+	if err := charm.SaveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyProjectDatabase(); err != nil {
+		t.Fatalf("applyProjectDatabase failed: %v", err)
+	}
+	root, _ = filepath.EvalSymlinks(root)
+	if got, _ := filepath.EvalSymlinks(filepath.Dir(os.Getenv(config.DBPathEnv))); got != root {
+		t.Errorf("%s = %q, want a directory in %s", config.DBPathEnv, os.Getenv(config.DBPathEnv), root)
+	}
+	if got := os.Getenv(charm.ProfileEnv); !strings.HasPrefix(got, "project-") {
+		t.Errorf("%s = %q, want the project's profile", charm.ProfileEnv, got)
+	}
+
+	// An explicit CHRONICLE_DB is left alone
+	t.Setenv(config.DBPathEnv, "/explicit")
+	t.Setenv(charm.ProfileEnv, "")
+	if err := applyProjectDatabase(); err != nil {
+		t.Fatalf("applyProjectDatabase failed: %v", err)
+	}
+	if os.Getenv(config.DBPathEnv) != "/explicit" || os.Getenv(charm.ProfileEnv) != "" {
+		t.Errorf("explicit database overridden: %s=%q %s=%q",
+			config.DBPathEnv, os.Getenv(config.DBPathEnv), charm.ProfileEnv, os.Getenv(charm.ProfileEnv))
+	}
+}
@@ -5,6 +5,8 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/araddon/dateparse"
 	"github.com/harper/chronicle/internal/charm"
@@ -13,6 +15,9 @@ import (
 
 var (
 	searchTags       []string
+	searchType       string
+	searchProject    string
+	searchSource     string
 	searchSince      string
 	searchUntil      string
 	searchLimit      int
@@ -24,15 +29,30 @@ var searchCmd = &cobra.Command{
 	Short: "Search entries",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateEntryType(searchType); err != nil {
+			return err
+		}
+		if !charm.ValidSource(searchSource) {
+			return fmt.Errorf("invalid source %q (valid: %v)", searchSource, charm.Sources)
+		}
+
 		// Get Charm client
 		client, err := charm.GetClient()
 		if err != nil {
 			return fmt.Errorf("failed to connect to Charm: %w", err)
 		}
 
+		projectID, err := resolveProjectID(client, searchProject)
+		if err != nil {
+			return err
+		}
+
 		// Build search filter
 		filter := &charm.SearchFilter{
-			Tags: searchTags,
+			Tags:      searchTags,
+			Type:      searchType,
+			ProjectID: projectID,
+			Source:    searchSource,
 		}
 
 		if len(args) > 0 {
@@ -40,20 +60,11 @@ var searchCmd = &cobra.Command{
 		}
 
 		// Parse dates
-		if searchSince != "" {
-			since, err := dateparse.ParseAny(searchSince)
-			if err != nil {
-				return fmt.Errorf("invalid --since date: %w", err)
-			}
-			filter.Since = &since
+		if filter.Since, err = parseDateFlag("since", searchSince); err != nil {
+			return err
 		}
-
-		if searchUntil != "" {
-			until, err := dateparse.ParseAny(searchUntil)
-			if err != nil {
-				return fmt.Errorf("invalid --until date: %w", err)
-			}
-			filter.Until = &until
+		if filter.Until, err = parseDateFlag("until", searchUntil); err != nil {
+			return err
 		}
 
 		// Search
@@ -63,31 +74,37 @@ var searchCmd = &cobra.Command{
 		}
 
 		// Output
-		if searchJSONOutput {
+		if jsonOutput(cmd, searchJSONOutput) {
 			data, err := json.MarshalIndent(entries, "", "  ")
 			if err != nil {
 				return fmt.Errorf("failed to marshal JSON: %w", err)
 			}
 			fmt.Println(string(data))
-		} else {
-			fmt.Println("ID\tTimestamp\t\t\tTags\t\tMessage")
-			fmt.Println("--\t---------\t\t\t----\t\t-------")
-			for _, entry := range entries {
-				tagsStr := ""
-				if len(entry.Tags) > 0 {
-					tagsStr = fmt.Sprintf("%v", entry.Tags)
-				}
-				timestamp := entry.Timestamp.Format("2006-01-02 15:04:05")
-				fmt.Printf("%s\t%s\t%s\t%s\n", entry.ID, timestamp, tagsStr, entry.Message)
-			}
+		} else if err := printEntriesTable(os.Stdout, entries); err != nil {
+			return fmt.Errorf("failed to print entries: %w", err)
 		}
 
 		return nil
 	},
 }
 
+// parseDateFlag parses an optional --since/--until style date flag.
+func parseDateFlag(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := dateparse.ParseAny(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s date: %w", name, err)
+	}
+	return &t, nil
+}
+
 func init() {
 	searchCmd.Flags().StringArrayVarP(&searchTags, "tag", "t", []string{}, "Filter by tags")
+	searchCmd.Flags().StringVar(&searchType, "type", "", "Filter by entry type (note, decision, todo, milestone)")
+	searchCmd.Flags().StringVarP(&searchProject, "project", "p", "", "Filter by project name")
+	searchCmd.Flags().StringVar(&searchSource, "source", "", "Filter by source (cli, mcp, import, git-hook, api, shell, webhook)")
 	searchCmd.Flags().StringVar(&searchSince, "since", "", "Start date (natural language or ISO)")
 	searchCmd.Flags().StringVar(&searchUntil, "until", "", "End date (natural language or ISO)")
 	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 100, "Maximum results")
@@ -0,0 +1,304 @@
+// ABOUTME: Serve command for running the chronicle REST API server
+// ABOUTME: Also manages the API tokens that authorize requests
+package cli
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/harper/chronicle/internal/api"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+// defaultListen is where serve listens without --listen.
+const defaultListen = "127.0.0.1:8787"
+
+// defaultSocket stands for the profile's socket path when --socket is
+// given without one; the path depends on --profile, which isn't known yet
+// when flags are defined.
+const defaultSocket = "default"
+
+var (
+	serveListen string
+	serveSocket string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the REST API server",
+	Long: `Serve the journal as a JSON API for browser extensions, editor plugins,
+and scripts, along with a web UI at the server's root URL. Every API request
+needs an API token in an "Authorization: Bearer <token>" header; create one
+with 'chronicle serve token create <name>'. The web UI asks for one.
+
+Endpoints:
+  GET    /api/entries          newest entries (limit, tag, type, source, since, until)
+  POST   /api/entries          add an entry: {"message", "type", "tags", "metadata"}
+  GET    /api/entries/{id}     one entry
+  PATCH  /api/entries/{id}     change an entry's message, type, or tags
+  DELETE /api/entries/{id}     delete an entry
+  GET    /api/search?q=...     full-text search, with the same filters
+  GET    /api/tags             every tag with its entry count
+  GET    /api/stats            counts by day, hour, source, tag, and directory
+  GET    /feed                 Atom feed with the same filters; also takes ?token=
+  GET    /metrics              Prometheus metrics: entry counts, sync backlog, DB size
+  POST   /hooks/{name}         add an entry from a webhook payload (see below)
+
+Inbound hooks let CI pipelines, GitHub, and monitoring alerts add entries
+without glue code. Each hook is configured under inbound_hooks in
+charm.json, mapping the posted JSON to an entry with JSONPaths or
+templates, and has its own token from 'chronicle serve hook token <name>',
+sent as a bearer token or ?token=. A hook's token can't use the API.
+
+The server listens on 127.0.0.1 only unless --listen says otherwise.
+
+With --socket, it serves JSON-RPC 2.0 on a Unix socket instead (and HTTP
+too if --listen is also given), so editor plugins and launchers can talk
+to chronicle without tokens or a process per call. The socket is readable
+only by you; --socket alone uses chronicle.sock in the state directory.
+Send one request per line and read one response per line:
+
+  {"jsonrpc": "2.0", "id": 1, "method": "add", "params": {"message": "...", "tags": ["x"]}}
+  {"jsonrpc": "2.0", "id": 2, "method": "search", "params": {"text": "deploy", "limit": 5}}
+  {"jsonrpc": "2.0", "id": 3, "method": "list", "params": {"tags": ["work"], "since": "yesterday"}}
+
+add takes the same fields as POST /api/entries; list and search take
+tags, type, source, since, until, and limit, and search also needs text.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if serveSocket == defaultSocket {
+			serveSocket = charm.SocketPath()
+		}
+		serveHTTP := serveSocket == "" || cmd.Flags().Changed("listen")
+		var opts api.Options
+		if serveHTTP {
+			opts.Tokens = api.NewTokenStore(api.TokensPath())
+			opts.HookTokens = api.NewTokenStore(api.HookTokensPath())
+			tokens, err := opts.Tokens.List()
+			if err != nil {
+				return err
+			}
+			hookTokens, err := opts.HookTokens.List()
+			if err != nil {
+				return err
+			}
+			if len(tokens) == 0 && len(hookTokens) == 0 {
+				return fmt.Errorf("no API tokens; create one with 'chronicle serve token create <name>'")
+			}
+		}
+
+		server, err := api.NewServer(opts)
+		if err != nil {
+			return fmt.Errorf("failed to create API server: %w", err)
+		}
+		if serveHTTP && !isLoopback(serveListen) {
+			fmt.Fprintf(os.Stderr, "Warning: listening on %s exposes the journal beyond this machine; anyone with a token can read it\n", serveListen)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		errc := make(chan error, 2)
+		running := 0
+		if serveSocket != "" {
+			running++
+			fmt.Fprintf(os.Stderr, "Serving chronicle JSON-RPC on %s\n", serveSocket)
+			go func() { errc <- server.ServeSocket(ctx, serveSocket) }()
+		}
+		if serveHTTP {
+			running++
+			fmt.Fprintf(os.Stderr, "Serving the chronicle API on http://%s\n", serveListen)
+			go func() { errc <- server.ListenAndServe(ctx, serveListen) }()
+		}
+		var firstErr error
+		for ; running > 0; running-- {
+			if err := <-errc; err != nil && firstErr == nil {
+				// Take the other server down too
+				firstErr = err
+				stop()
+			}
+		}
+		return firstErr
+	},
+}
+
+var serveTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage API tokens",
+}
+
+var serveTokenCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create an API token",
+	Long: `Create an API token called name, such as the extension or plugin that will
+use it. The token is printed once; only its hash is kept.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		secret, err := api.NewTokenStore(api.TokensPath()).Create(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(secret)
+		fmt.Fprintln(os.Stderr, "Store this token now; it won't be shown again.")
+		return nil
+	},
+}
+
+var serveTokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API tokens",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tokens, err := api.NewTokenStore(api.TokensPath()).List()
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			fmt.Println("No API tokens")
+			return nil
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "Name\tCreated")
+		for _, t := range tokens {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\n", t.Name, t.Created.Local().Format(timestampLayout()))
+		}
+		return tw.Flush()
+	},
+}
+
+var serveTokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <name>",
+	Short: "Revoke an API token",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := api.NewTokenStore(api.TokensPath()).Revoke(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked token %s\n", args[0])
+		return nil
+	},
+}
+
+var serveHookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage inbound webhook tokens",
+}
+
+var serveHookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List inbound hooks and whether they have a token",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := charm.LoadConfig()
+		if err != nil {
+			return err
+		}
+		if len(cfg.InboundHooks) == 0 {
+			fmt.Printf("No inbound_hooks in %s\n", charm.ConfigPath())
+			return nil
+		}
+		tokens, err := api.NewTokenStore(api.HookTokensPath()).List()
+		if err != nil {
+			return err
+		}
+		created := make(map[string]string, len(tokens))
+		for _, t := range tokens {
+			created[t.Name] = t.Created.Local().Format(timestampLayout())
+		}
+		names := make([]string, 0, len(cfg.InboundHooks))
+		for name := range cfg.InboundHooks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "Hook\tToken created")
+		for _, name := range names {
+			when := created[name]
+			if when == "" {
+				when = "no token"
+			}
+			_, _ = fmt.Fprintf(tw, "/hooks/%s\t%s\n", name, when)
+		}
+		return tw.Flush()
+	},
+}
+
+var serveHookRotate bool
+
+var serveHookTokenCmd = &cobra.Command{
+	Use:   "token <name>",
+	Short: "Create the token for an inbound hook",
+	Long: `Create the token that authorizes posts to /hooks/<name>. The hook must be
+configured under inbound_hooks in charm.json. The token is printed once;
+only its hash is kept. With --rotate, the hook's old token stops working.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := charm.LoadConfig()
+		if err != nil {
+			return err
+		}
+		name := args[0]
+		if _, ok := cfg.InboundHooks[name]; !ok {
+			return fmt.Errorf("no inbound hook named %q in %s", name, charm.ConfigPath())
+		}
+		store := api.NewTokenStore(api.HookTokensPath())
+		if serveHookRotate {
+			// A hook without a token yet has nothing to rotate
+			_ = store.Revoke(name)
+		}
+		secret, err := store.Create(name)
+		if err != nil {
+			return fmt.Errorf("%w; pass --rotate to replace it", err)
+		}
+		fmt.Println(secret)
+		fmt.Fprintln(os.Stderr, "Store this token now; it won't be shown again.")
+		return nil
+	},
+}
+
+var serveHookRevokeCmd = &cobra.Command{
+	Use:   "revoke <name>",
+	Short: "Revoke an inbound hook's token",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := api.NewTokenStore(api.HookTokensPath()).Revoke(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked the token for hook %s\n", args[0])
+		return nil
+	},
+}
+
+// isLoopback reports whether addr, a host:port, only accepts connections
+// from this machine.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", defaultListen, "Address to listen on")
+	serveCmd.Flags().StringVar(&serveSocket, "socket", "", "Serve JSON-RPC on this Unix socket (default path when given without one)")
+	serveCmd.Flags().Lookup("socket").NoOptDefVal = defaultSocket
+	serveTokenCmd.AddCommand(serveTokenCreateCmd)
+	serveTokenCmd.AddCommand(serveTokenListCmd)
+	serveTokenCmd.AddCommand(serveTokenRevokeCmd)
+	serveCmd.AddCommand(serveTokenCmd)
+	serveHookTokenCmd.Flags().BoolVar(&serveHookRotate, "rotate", false, "Replace the hook's existing token")
+	serveHookCmd.AddCommand(serveHookListCmd)
+	serveHookCmd.AddCommand(serveHookTokenCmd)
+	serveHookCmd.AddCommand(serveHookRevokeCmd)
+	serveCmd.AddCommand(serveHookCmd)
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,153 @@
+// ABOUTME: Shell-integration command, which prints zsh or bash hooks that log slow commands
+// ABOUTME: The hooks time each command and hand it to the hidden record subcommand
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shellRecordExit     int
+	shellRecordDuration int
+)
+
+var shellIntegrationCmd = &cobra.Command{
+	Use:   "shell-integration zsh|bash",
+	Short: "Print shell hooks that log long-running commands",
+	Long: `Print hooks for zsh or bash that log every command running longer than
+shell_capture.threshold seconds (30 by default), with its exit status and
+duration as metadata. Load them from your shell's startup file:
+
+  eval "$(chronicle shell-integration zsh)"    # ~/.zshrc
+  eval "$(chronicle shell-integration bash)"   # ~/.bashrc
+
+shell_capture.allow and shell_capture.deny take regular expressions that
+pick which commands are captured. Commands mentioning passwords, tokens, or
+API keys, and commands typed with a leading space, are never captured.
+The threshold is read when the hooks are printed, so open a new shell
+after changing it.`,
+	ValidArgs:    []string{"zsh", "bash"},
+	Args:         cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := shellFilter()
+		if err != nil {
+			return err
+		}
+		threshold := int(filter.Threshold() / time.Second)
+		script := zshIntegration
+		if args[0] == "bash" {
+			script = bashIntegration
+		}
+		_, err = fmt.Fprint(cmd.OutOrStdout(), shellScript(script, hookCommand(), threshold))
+		return err
+	},
+}
+
+var shellRecordCmd = &cobra.Command{
+	Use:    "record -- <command>",
+	Short:  "Log a finished shell command (called by the shell hooks)",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := shellFilter()
+		if err != nil {
+			return err
+		}
+		duration := time.Duration(shellRecordDuration) * time.Second
+		if !filter.Captures(args[0], duration) {
+			return nil
+		}
+		_, err = addEntry(newEntry{
+			Message: strings.TrimSpace(args[0]),
+			Tags:    []string{"shell"},
+			Source:  charm.SourceShell,
+			Metadata: map[string]string{
+				"exit_status": strconv.Itoa(shellRecordExit),
+				"duration":    duration.String(),
+			},
+		})
+		return err
+	},
+}
+
+// shellFilter compiles the config's shell_capture settings.
+func shellFilter() (*config.ShellFilter, error) {
+	cfg, err := charm.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	filter, err := config.CompileShellCapture(cfg.ShellCapture)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shell_capture in %s: %w", charm.ConfigPath(), err)
+	}
+	return filter, nil
+}
+
+// shellScript fills in the chronicle command and threshold of a hook script.
+func shellScript(script, command string, threshold int) string {
+	return strings.NewReplacer("@CHRONICLE@", command, "@THRESHOLD@", strconv.Itoa(threshold)).Replace(script)
+}
+
+// zshIntegration times commands with the preexec and precmd hooks.
+const zshIntegration = `# chronicle shell integration for zsh
+zmodload zsh/datetime 2>/dev/null
+_chronicle_preexec() {
+  _chronicle_cmd=$1
+  _chronicle_start=$EPOCHSECONDS
+}
+_chronicle_precmd() {
+  local exit_status=$?
+  [[ -n $_chronicle_start ]] || return 0
+  local duration=$(( EPOCHSECONDS - _chronicle_start ))
+  local cmd=$_chronicle_cmd
+  unset _chronicle_start _chronicle_cmd
+  if (( duration >= @THRESHOLD@ )); then
+    ( @CHRONICLE@ shell-integration record --exit "$exit_status" --duration "$duration" -- "$cmd" >/dev/null 2>&1 & )
+  fi
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec _chronicle_preexec
+add-zsh-hook precmd _chronicle_precmd
+`
+
+// bashIntegration times commands with a DEBUG trap and PROMPT_COMMAND.
+// _chronicle_ready runs last in PROMPT_COMMAND so the trap ignores the
+// prompt's own commands and times only the first command of each line.
+const bashIntegration = `# chronicle shell integration for bash
+_chronicle_preexec() {
+  [[ -n $_chronicle_at_prompt && -z $COMP_LINE && $BASH_COMMAND != _chronicle_* ]] || return 0
+  unset -v _chronicle_at_prompt
+  _chronicle_cmd=$BASH_COMMAND
+  _chronicle_start=$SECONDS
+}
+_chronicle_precmd() {
+  local exit_status=$?
+  if [[ -n $_chronicle_start ]]; then
+    local duration=$(( SECONDS - _chronicle_start ))
+    if (( duration >= @THRESHOLD@ )); then
+      ( @CHRONICLE@ shell-integration record --exit "$exit_status" --duration "$duration" -- "$_chronicle_cmd" >/dev/null 2>&1 & )
+    fi
+  fi
+  unset -v _chronicle_start _chronicle_cmd _chronicle_at_prompt
+}
+_chronicle_ready() {
+  _chronicle_at_prompt=1
+}
+trap '_chronicle_preexec' DEBUG
+PROMPT_COMMAND="_chronicle_precmd${PROMPT_COMMAND:+; $PROMPT_COMMAND}; _chronicle_ready"
+`
+
+func init() {
+	shellRecordCmd.Flags().IntVar(&shellRecordExit, "exit", 0, "The command's exit status")
+	shellRecordCmd.Flags().IntVar(&shellRecordDuration, "duration", 0, "How long the command ran, in seconds")
+	shellIntegrationCmd.AddCommand(shellRecordCmd)
+	rootCmd.AddCommand(shellIntegrationCmd)
+}
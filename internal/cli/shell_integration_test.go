@@ -0,0 +1,32 @@
+// ABOUTME: Tests for the shell-integration command
+// ABOUTME: Validates that hook scripts are filled in and parse in their shells
+package cli
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestShellScripts(t *testing.T) {
+	for shell, script := range map[string]string{"bash": bashIntegration, "zsh": zshIntegration} {
+		t.Run(shell, func(t *testing.T) {
+			out := shellScript(script, "'/opt/chronicle'", 45)
+			if strings.Contains(out, "@") {
+				t.Errorf("placeholder left in script:\n%s", out)
+			}
+			if !strings.Contains(out, ">= 45 ") || !strings.Contains(out, "'/opt/chronicle' shell-integration record") {
+				t.Errorf("threshold or command not filled in:\n%s", out)
+			}
+			path, err := exec.LookPath(shell)
+			if err != nil {
+				t.Skipf("%s not installed", shell)
+			}
+			check := exec.Command(path, "-n")
+			check.Stdin = strings.NewReader(out)
+			if msg, err := check.CombinedOutput(); err != nil {
+				t.Errorf("%s -n: %v\n%s", shell, err, msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Show command for displaying a single entry in full
+// ABOUTME: Includes metadata and inbound/outbound linked entries
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var showJSONOutput bool
+
+// linkedEntry pairs a link with a short description of the entry on its other end.
+type linkedEntry struct {
+	Relation string `json:"relation"`
+	ID       string `json:"id"`
+	Message  string `json:"message,omitempty"`
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show an entry with its metadata and links",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		entry, err := client.GetEntry(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get entry: %w", err)
+		}
+
+		outbound, err := client.OutboundLinks(entry.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load links: %w", err)
+		}
+		inbound, err := client.InboundLinks(entry.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load links: %w", err)
+		}
+
+		refs := make([]linkedEntry, 0, len(outbound))
+		for _, l := range outbound {
+			refs = append(refs, describeLink(client, l.Relation, l.ToID))
+		}
+		referencedBy := make([]linkedEntry, 0, len(inbound))
+		for _, l := range inbound {
+			referencedBy = append(referencedBy, describeLink(client, l.Relation, l.FromID))
+		}
+
+		if jsonOutput(cmd, showJSONOutput) {
+			out := struct {
+				*charm.Entry
+				Links        []linkedEntry `json:"links"`
+				ReferencedBy []linkedEntry `json:"referenced_by"`
+			}{entry, refs, referencedBy}
+			data, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("ID:         %s\n", entry.ID)
+		fmt.Printf("Timestamp:  %s\n", entry.Timestamp.Format(timestampLayout()))
+		fmt.Printf("Type:       %s\n", colorEntryType(entry.Kind(), entryTypeLabel(entry.Kind())))
+		if len(entry.Tags) > 0 {
+			fmt.Printf("Tags:       %s\n", strings.Join(entry.Tags, ", "))
+		}
+		if entry.Pinned {
+			if entry.Recurrence != "" {
+				fmt.Printf("Pinned:     yes (%s)\n", entry.Recurrence)
+			} else {
+				fmt.Println("Pinned:     yes")
+			}
+		}
+		if entry.Source != "" {
+			fmt.Printf("Source:     %s\n", entry.Source)
+		}
+		fmt.Printf("User:       %s@%s\n", entry.Username, entry.Hostname)
+		fmt.Printf("Directory:  %s\n", entry.WorkingDirectory)
+		fmt.Printf("\n%s\n", entry.Message)
+		keys := make([]string, 0, len(entry.Metadata))
+		for key := range entry.Metadata {
+			if key == "" {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("\n%s:\n%s\n", strings.ToUpper(key[:1])+key[1:], entry.Metadata[key])
+		}
+
+		printLinks("Links", refs)
+		printLinks("Referenced by", referencedBy)
+		return nil
+	},
+}
+
+// describeLink loads the entry on the other end of a link for display.
+// Missing entries (deleted or not yet synced) are shown by ID only.
+func describeLink(client *charm.Client, relation, id string) linkedEntry {
+	le := linkedEntry{Relation: relation, ID: id}
+	if other, err := client.GetEntry(id); err == nil {
+		le.Message = other.Message
+	}
+	return le
+}
+
+// printLinks prints a titled list of linked entries, if any.
+func printLinks(title string, links []linkedEntry) {
+	if len(links) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", title)
+	for _, l := range links {
+		msg := l.Message
+		if msg == "" {
+			msg = "(entry not available)"
+		}
+		fmt.Printf("  %s %s: %s\n", l.Relation, l.ID, msg)
+	}
+}
+
+func init() {
+	showCmd.Flags().BoolVar(&showJSONOutput, "json", false, "Output as JSON")
+	rootCmd.AddCommand(showCmd)
+}
@@ -0,0 +1,138 @@
+// ABOUTME: Snap command for logging an entry with a screenshot attached
+// ABOUTME: Captures with the platform's screenshot tool and links the image in the entry's metadata
+
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/desktop"
+	"github.com/spf13/cobra"
+)
+
+// screenshotKey is the metadata key holding an entry's screenshot path.
+const screenshotKey = "screenshot"
+
+var (
+	snapTags []string
+	snapType string
+	snapFull bool
+	snapFile string
+)
+
+var snapCmd = &cobra.Command{
+	Use:   "snap [message]",
+	Short: "Add an entry with a screenshot",
+	Long: `Take a screenshot and add an entry linked to it, to keep visual context
+such as a dashboard or an error dialog with the log. You select a region or
+window to capture unless --full is given; Windows always captures the whole
+screen. --file attaches an existing image instead.
+
+Screenshots are copied into the attachments directory beside the database
+and the entry's "screenshot" metadata holds the path. They stay on this
+machine: sync carries the entry but not the image.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		message := args[0]
+		if strings.TrimSpace(message) == "" {
+			return fmt.Errorf("message cannot be empty")
+		}
+		if err := validateEntryType(snapType); err != nil {
+			return err
+		}
+
+		ext := ".png"
+		if snapFile != "" {
+			ext = strings.ToLower(filepath.Ext(snapFile))
+		}
+		path, err := newAttachmentPath(charm.AttachmentsDir(), time.Now(), ext)
+		if err != nil {
+			return err
+		}
+		if snapFile != "" {
+			err = copyAttachment(snapFile, path)
+		} else {
+			err = desktop.Screenshot(path, snapFull)
+		}
+		if errors.Is(err, desktop.ErrScreenshotCancelled) {
+			return fmt.Errorf("%w; no entry added", err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save screenshot: %w", err)
+		}
+
+		id, err := addEntry(newEntry{
+			Message:  message,
+			Type:     snapType,
+			Tags:     snapTags,
+			Source:   charm.SourceCLI,
+			Metadata: map[string]string{screenshotKey: path},
+		})
+		if err != nil {
+			_ = os.Remove(path)
+			return err
+		}
+		fmt.Printf("Entry created (ID: %s)\nScreenshot: %s\n", id, path)
+		return nil
+	},
+}
+
+// newAttachmentPath returns an unused path in dir for a file saved at
+// now, creating dir if needed. Names sort by time and carry a random
+// suffix so two saves in the same second don't collide.
+func newAttachmentPath(dir string, now time.Time, ext string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, now.Format("20060102-150405")+"-"+hex.EncodeToString(suffix)+ext), nil
+}
+
+// copyAttachment copies the file at src to dst, which must not exist.
+func copyAttachment(src, dst string) error {
+	in, err := os.Open(src) //nolint:gosec // The user names the file to attach
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	if info, err := in.Stat(); err != nil {
+		return err
+	} else if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", src)
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) //nolint:gosec // dst is in the attachments directory
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+func init() {
+	snapCmd.Flags().StringArrayVarP(&snapTags, "tag", "t", []string{}, "Add tags to entry")
+	snapCmd.Flags().StringVar(&snapType, "type", "", "Entry type (note, decision, todo, milestone)")
+	snapCmd.Flags().BoolVar(&snapFull, "full", false, "Capture the whole screen instead of a selection")
+	snapCmd.Flags().StringVar(&snapFile, "file", "", "Attach an existing image instead of taking a screenshot")
+	rootCmd.AddCommand(snapCmd)
+}
@@ -0,0 +1,59 @@
+// ABOUTME: Tests for storing snap attachments
+// ABOUTME: Checks attachment naming and copying without taking screenshots
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAttachmentPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "attachments")
+	now := time.Date(2025, 3, 4, 9, 30, 15, 0, time.UTC)
+	first, err := newAttachmentPath(dir, now, ".png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newAttachmentPath(dir, now, ".png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("two saves in one second share %s", first)
+	}
+	name := filepath.Base(first)
+	if filepath.Dir(first) != dir || !strings.HasPrefix(name, "20250304-093015-") || !strings.HasSuffix(name, ".png") {
+		t.Errorf("path = %s", first)
+	}
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("attachments dir mode = %v, %v; want 0700", info.Mode().Perm(), err)
+	}
+}
+
+func TestCopyAttachment(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "dialog.png")
+	if err := os.WriteFile(src, []byte("\x89PNG data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "copy.png")
+	if err := copyAttachment(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "\x89PNG data" {
+		t.Errorf("copied %q, %v", data, err)
+	}
+
+	if err := copyAttachment(src, dst); err == nil {
+		t.Error("expected an error overwriting an existing attachment")
+	}
+	if err := copyAttachment(dir, filepath.Join(dir, "dir.png")); err == nil {
+		t.Error("expected an error attaching a directory")
+	}
+	if err := copyAttachment(filepath.Join(dir, "missing.png"), filepath.Join(dir, "x.png")); err == nil {
+		t.Error("expected an error attaching a missing file")
+	}
+}
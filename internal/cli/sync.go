@@ -1,5 +1,5 @@
 // ABOUTME: Sync subcommand for Charm cloud integration
-// ABOUTME: Provides status, link, unlink, and wipe commands (SSH key auth)
+// ABOUTME: Provides status, link, unlink, wipe, and daemon commands (SSH key auth)
 package cli
 
 import (
@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/charm/client"
 	"github.com/charmbracelet/charm/proto"
 	"github.com/fatih/color"
 	"github.com/harper/chronicle/internal/charm"
+	"github.com/mdp/qrterminal/v3"
 	"github.com/spf13/cobra"
 )
 
@@ -23,16 +25,24 @@ var syncCmd = &cobra.Command{
 Authentication is automatic via SSH keys - no login required!
 
 Commands:
-  status  - Show sync status and Charm user ID
-  link    - Link this device to another Charm account
-  unlink  - Disconnect this device from Charm
-  repair  - Repair database corruption
-  reset   - Reset database to clean state
-  wipe    - Completely wipe all data including cloud backups
+  status       - Show sync status and Charm user ID
+  now          - Push and pull immediately (--progress for counters)
+  log          - Show recent sync sessions
+  link         - Link this device to another Charm account (--code, --qr)
+  unlink       - Disconnect this device from Charm
+  devices      - List, revoke, or rename linked devices
+  export-cloud - Export every entry to a JSONL file
+  compact      - Vacuum the local database and report reclaimed space
+  fsck         - Check entries and the day index (--fix to quarantine)
+  repair       - Repair database corruption
+  reset        - Reset database to clean state
+  wipe         - Completely wipe all data including cloud backups
+  daemon       - Run a background sync loop (status, stop)
 
 Examples:
   chronicle sync status
   chronicle sync link
+  chronicle sync link --code <code>
   chronicle sync repair --force`,
 }
 
@@ -57,31 +67,73 @@ var syncStatusCmd = &cobra.Command{
 
 		fmt.Printf("Charm ID:  %s\n", id)
 		fmt.Printf("Server:    %s\n", charm.GetCharmHost())
+		if c.ReadOnly() {
+			fmt.Println("Mode:      read-only (pull only)")
+		}
+		if queued := c.OutboxCount(); queued > 0 {
+			color.Yellow("Queued:    %d entries added offline, waiting for the server", queued)
+		}
+		if count, err := c.CountEntries(nil); err == nil {
+			fmt.Printf("Entries:   %d\n", count)
+		}
 
-		if c.IsLinked() {
-			color.Green("Status:    Connected and syncing")
+		lastSync := c.LastSuccessfulSync()
+		if lastSync.IsZero() {
+			fmt.Println("Last sync: never")
 		} else {
+			fmt.Printf("Last sync: %s (%s)\n", lastSync.Local().Format(time.DateTime), formatSince(time.Since(lastSync)))
+		}
+
+		stale := c.StaleThreshold() > 0 && time.Since(lastSync) > c.StaleThreshold()
+		switch {
+		case c.Offline():
+			color.Yellow("Status:    Offline - changes are queued and will sync on next connection")
+		case !c.IsLinked():
 			color.Yellow("Status:    Not linked")
 			fmt.Println("\nRun 'chronicle sync link' to link to a Charm account.")
+		case lastSync.IsZero():
+			color.Yellow("Status:    Linked, not synced yet - run 'chronicle sync now'")
+		case stale:
+			color.Yellow("Status:    Linked, but last sync is stale - run 'chronicle sync now'")
+		default:
+			color.Green("Status:    Connected and syncing")
 		}
 
 		return nil
 	},
 }
 
+var (
+	linkCode string
+	linkQR   bool
+)
+
 var syncLinkCmd = &cobra.Command{
 	Use:   "link",
 	Short: "Link this device to a Charm account",
 	Long: `Link this device to an existing Charm account.
 
 This will generate a link code that you can enter on another device
-that's already linked to your Charm account.`,
+that's already linked to your Charm account. With --qr the code is also
+printed as a QR code for scanning from a phone or second machine.
+
+With --code, join a link session using a code generated elsewhere instead.
+No prompt is shown, so this works on headless servers.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cc, err := client.NewClientWithDefaults()
 		if err != nil {
 			return fmt.Errorf("failed to create Charm client: %w", err)
 		}
 
+		lh := &linkHandler{qr: linkQR}
+		if linkCode != "" {
+			fmt.Println("Joining link session...")
+			if err := cc.Link(lh, strings.TrimSpace(linkCode)); err != nil {
+				return fmt.Errorf("link failed: %w", err)
+			}
+			return nil
+		}
+
 		// Check if already linked
 		if _, err := cc.ID(); err == nil {
 			color.Green("Already linked to a Charm account!")
@@ -92,7 +144,6 @@ that's already linked to your Charm account.`,
 		fmt.Println("Generating link request...")
 		fmt.Println("Enter this code on a device that's already linked to your Charm account.")
 
-		lh := &linkHandler{}
 		if err := cc.LinkGen(lh); err != nil {
 			return fmt.Errorf("link failed: %w", err)
 		}
@@ -296,6 +347,9 @@ THIS CANNOT BE UNDONE!`,
 func init() {
 	// Add --force flag to repair command
 	syncRepairCmd.Flags().BoolVarP(&repairForce, "force", "f", false, "Force repair even if database appears healthy")
+	syncLinkCmd.Flags().StringVar(&linkCode, "code", "", "Join using a link code generated on another device")
+	syncLinkCmd.Flags().BoolVar(&linkQR, "qr", false, "Also print the link code as a QR code")
+	syncLinkCmd.MarkFlagsMutuallyExclusive("code", "qr")
 
 	syncCmd.AddCommand(syncStatusCmd)
 	syncCmd.AddCommand(syncLinkCmd)
@@ -307,11 +361,32 @@ func init() {
 	rootCmd.AddCommand(syncCmd)
 }
 
+// formatSince renders an elapsed duration coarsely, e.g. "5m ago".
+func formatSince(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
 // linkHandler implements proto.LinkHandler for the link flow.
-type linkHandler struct{}
+type linkHandler struct {
+	// qr prints the link code as a terminal QR code as well
+	qr bool
+}
 
 func (lh *linkHandler) TokenCreated(l *proto.Link) {
 	fmt.Printf("\nLink code: %s\n\n", l.Token)
+	if lh.qr {
+		qrterminal.GenerateHalfBlock(string(l.Token), qrterminal.L, os.Stdout)
+		fmt.Println()
+	}
 	fmt.Println("Waiting for approval...")
 }
 
@@ -0,0 +1,131 @@
+// ABOUTME: Desktop notifications for entries the sync daemon pulls in from other devices
+// ABOUTME: Remembers recent entries so each arrival is announced once, grouped by host
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/harper/chronicle/internal/desktop"
+)
+
+// arrivalWindow is how far back the daemon looks for entries from other
+// devices; older ones arriving late are not announced.
+const arrivalWindow = 24 * time.Hour
+
+// arrivalPreview is how many entries a notification lists before "and N
+// more".
+const arrivalPreview = 3
+
+// arrivals finds entries that synced in from other hosts since it last
+// looked.
+type arrivals struct {
+	client *charm.Client
+	host   string
+	// seen maps the IDs of entries already looked at to their timestamps,
+	// so they can be forgotten once they leave the window
+	seen map[string]time.Time
+}
+
+// newArrivals starts tracking with the entries already in the journal, so
+// only later arrivals are announced.
+func newArrivals(client *charm.Client) (*arrivals, error) {
+	host, _ := os.Hostname()
+	a := &arrivals{client: client, host: host, seen: make(map[string]time.Time)}
+	if _, err := a.check(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// check returns the entries from other hosts that appeared since the last
+// check.
+func (a *arrivals) check() ([]charm.Entry, error) {
+	since := time.Now().Add(-arrivalWindow)
+	entries, err := a.client.SearchEntries(&charm.SearchFilter{Since: &since}, 0)
+	if err != nil {
+		return nil, err
+	}
+	for id, timestamp := range a.seen {
+		if timestamp.Before(since) {
+			delete(a.seen, id)
+		}
+	}
+	var fresh []charm.Entry
+	for _, entry := range entries {
+		if _, ok := a.seen[entry.ID]; ok {
+			continue
+		}
+		a.seen[entry.ID] = entry.Timestamp
+		if entry.Hostname != "" && entry.Hostname != a.host {
+			fresh = append(fresh, entry)
+		}
+	}
+	return fresh, nil
+}
+
+// announce shows a desktop notification for each host with new entries
+// that cfg wants announced.
+func (a *arrivals) announce(cfg *config.DesktopNotifications) {
+	entries, err := a.check()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "checking for new entries failed: %v\n", err)
+		return
+	}
+	for _, n := range arrivalNotices(entries, cfg) {
+		if err := desktop.Notify(n.title, n.message); err != nil {
+			fmt.Fprintf(os.Stderr, "desktop notification failed: %v\n", err)
+			return
+		}
+	}
+}
+
+// arrivalNotice is one desktop notification.
+type arrivalNotice struct {
+	title   string
+	message string
+}
+
+// arrivalNotices groups the entries cfg announces by host, in order of
+// first arrival: "3 new entries from work-desktop", listing the first few.
+func arrivalNotices(entries []charm.Entry, cfg *config.DesktopNotifications) []arrivalNotice {
+	var hosts []string
+	byHost := make(map[string][]charm.Entry)
+	for _, entry := range entries {
+		if !cfg.Announces(entry.Kind()) {
+			continue
+		}
+		if _, ok := byHost[entry.Hostname]; !ok {
+			hosts = append(hosts, entry.Hostname)
+		}
+		byHost[entry.Hostname] = append(byHost[entry.Hostname], entry)
+	}
+
+	notices := make([]arrivalNotice, 0, len(hosts))
+	for _, host := range hosts {
+		hostEntries := byHost[host]
+		noun := "entries"
+		if len(hostEntries) == 1 {
+			noun = "entry"
+		}
+		var lines []string
+		for i, entry := range hostEntries {
+			if i == arrivalPreview {
+				lines = append(lines, fmt.Sprintf("and %d more", len(hostEntries)-arrivalPreview))
+				break
+			}
+			first, _, _ := strings.Cut(strings.TrimSpace(entry.Message), "\n")
+			lines = append(lines, first)
+		}
+		notices = append(notices, arrivalNotice{
+			title:   fmt.Sprintf("%d new %s from %s", len(hostEntries), noun, host),
+			message: strings.Join(lines, "\n"),
+		})
+	}
+	return notices
+}
@@ -0,0 +1,39 @@
+// ABOUTME: Tests for desktop notifications about entries arriving on sync
+// ABOUTME: Checks grouping by host, previews, and filtering by entry type
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+func TestArrivalNotices(t *testing.T) {
+	entries := []charm.Entry{
+		{Hostname: "work-desktop", Message: "deployed v2\nwith notes"},
+		{Hostname: "laptop", Message: "picked the queue design", Type: charm.EntryTypeDecision},
+		{Hostname: "work-desktop", Message: "fixed flaky test"},
+		{Hostname: "work-desktop", Message: "reviewed PR"},
+		{Hostname: "work-desktop", Message: "lunch"},
+	}
+
+	got := arrivalNotices(entries, &config.DesktopNotifications{Sync: true})
+	want := []arrivalNotice{
+		{title: "4 new entries from work-desktop", message: "deployed v2\nfixed flaky test\nreviewed PR\nand 1 more"},
+		{title: "1 new entry from laptop", message: "picked the queue design"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("notices = %+v, want %+v", got, want)
+	}
+
+	got = arrivalNotices(entries, &config.DesktopNotifications{Sync: true, Types: []string{charm.EntryTypeDecision}})
+	if len(got) != 1 || got[0].title != "1 new entry from laptop" {
+		t.Errorf("filtered notices = %+v, want only the decision", got)
+	}
+
+	if got := arrivalNotices(entries, &config.DesktopNotifications{}); len(got) != 0 {
+		t.Errorf("sync announcements off, got %+v", got)
+	}
+}
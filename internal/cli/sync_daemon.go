@@ -0,0 +1,293 @@
+// ABOUTME: Background sync daemon that pushes and pulls on an interval
+// ABOUTME: Provides sync daemon (with optional /metrics), status, and stop
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/caldav"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/harper/chronicle/internal/metrics"
+	"github.com/harper/chronicle/internal/pkm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonInterval time.Duration
+	daemonJitter   time.Duration
+	daemonWatch    time.Duration
+	daemonMetrics  string
+)
+
+// daemonSyncs and daemonFailures count the daemon's sync attempts for
+// /metrics, which reads them from another goroutine.
+var daemonSyncs, daemonFailures atomic.Int64
+
+var syncDaemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run a background sync loop",
+	Long: `Run a long-lived sync loop in the foreground.
+
+The daemon syncs every --interval plus a random delay of up to --jitter.
+Local writes are noticed by watching the database files every --watch,
+and are pushed right away instead of waiting for the next interval.
+
+When caldav or vault is configured, each successful sync is followed by a
+'chronicle caldav push' or 'chronicle vault sync'.
+
+With desktop_notifications.sync set in the config, entries that arrive
+from other devices pop up a desktop notification, one per device.
+
+With --metrics, the daemon also serves Prometheus metrics at /metrics on
+that address: entry counts, pending writes, the age of the last sync, and
+the database size, so you can alert when sync is stuck.
+
+Run it under your service manager ('chronicle sync daemon install' sets
+that up) or in the background with '&'.
+Use 'chronicle sync daemon status' and 'chronicle sync daemon stop' to
+inspect or stop it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if daemonInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		if daemonWatch <= 0 {
+			return fmt.Errorf("--watch must be positive")
+		}
+
+		statusPath := charm.DaemonStatusPath()
+		existing, err := charm.ReadDaemonStatus(statusPath)
+		if err != nil {
+			return err
+		}
+		if existing.Running() && existing.PID != os.Getpid() {
+			return fmt.Errorf("sync daemon already running (pid %d)", existing.PID)
+		}
+
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+		dbPath, err := charm.DBPath()
+		if err != nil {
+			return fmt.Errorf("failed to locate database: %w", err)
+		}
+
+		status := &charm.DaemonStatus{
+			PID:       os.Getpid(),
+			StartedAt: time.Now(),
+			Interval:  daemonInterval.String(),
+			Jitter:    daemonJitter.String(),
+		}
+		if err := charm.WriteDaemonStatus(statusPath, status); err != nil {
+			return err
+		}
+		defer func() { _ = os.Remove(statusPath) }()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Printf("Sync daemon started (pid %d, every %s + up to %s jitter)\n",
+			status.PID, daemonInterval, daemonJitter)
+		if daemonMetrics != "" {
+			if err := serveDaemonMetrics(ctx, client, dbPath); err != nil {
+				return err
+			}
+			fmt.Printf("Serving metrics on http://%s/metrics\n", daemonMetrics)
+		}
+		runSyncDaemon(ctx, client, dbPath, status, statusPath)
+		fmt.Println("Sync daemon stopped")
+		return nil
+	},
+}
+
+// runSyncDaemon syncs on a jittered interval and whenever the database
+// files change, until ctx is cancelled.
+func runSyncDaemon(ctx context.Context, client *charm.Client, dbPath string, status *charm.DaemonStatus, statusPath string) {
+	var notify *config.DesktopNotifications
+	var arrived *arrivals
+	if cfg := client.Config(); cfg != nil && cfg.DesktopNotifications != nil && cfg.DesktopNotifications.Sync {
+		notify = cfg.DesktopNotifications
+		var err error
+		if arrived, err = newArrivals(client); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: desktop notifications are off: %v\n", err)
+		}
+	}
+
+	doSync := func() {
+		status.LastSync = time.Now()
+		status.LastError = ""
+		if err := client.Sync(); err != nil {
+			status.LastError = err.Error()
+			daemonFailures.Add(1)
+			fmt.Fprintf(os.Stderr, "sync failed: %v\n", err)
+		} else {
+			status.Syncs++
+			daemonSyncs.Add(1)
+			afterSync(ctx, client)
+			if arrived != nil {
+				arrived.announce(notify)
+			}
+		}
+		if err := charm.WriteDaemonStatus(statusPath, status); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+
+	doSync()
+	lastMod := charm.DBModTime(dbPath)
+
+	timer := time.NewTimer(charm.NextSyncDelay(daemonInterval, daemonJitter))
+	defer timer.Stop()
+	watch := time.NewTicker(daemonWatch)
+	defer watch.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-watch.C:
+			if mod := charm.DBModTime(dbPath); mod.After(lastMod) {
+				doSync()
+				lastMod = charm.DBModTime(dbPath)
+			}
+		case <-timer.C:
+			doSync()
+			lastMod = charm.DBModTime(dbPath)
+			timer.Reset(charm.NextSyncDelay(daemonInterval, daemonJitter))
+		}
+	}
+}
+
+// afterSync pushes new and changed entries to the configured calendar and
+// vault, if any. Failures are reported and retried after the next sync.
+func afterSync(ctx context.Context, client *charm.Client) {
+	cfg := client.Config()
+	if cfg == nil {
+		return
+	}
+	if cfg.CalDAV != nil {
+		if _, err := caldav.PublishJournal(ctx, client, cfg.CalDAV, charm.CalDAVStatePath(), false); err != nil {
+			fmt.Fprintf(os.Stderr, "caldav push failed: %v\n", err)
+		}
+	}
+	if cfg.Vault != nil {
+		if _, err := pkm.MirrorJournal(client, cfg.Vault, false); err != nil {
+			fmt.Fprintf(os.Stderr, "vault sync failed: %v\n", err)
+		}
+	}
+}
+
+// serveDaemonMetrics serves /metrics on daemonMetrics until ctx is
+// cancelled. The address is claimed before returning so a port already in
+// use stops the daemon from starting.
+func serveDaemonMetrics(ctx context.Context, client *charm.Client, dbPath string) error {
+	collector := &metrics.Collector{
+		Client: client,
+		DBPath: dbPath,
+		Extra: func() []metrics.Metric {
+			return []metrics.Metric{
+				{Name: "chronicle_sync_daemon_syncs_total", Help: "Successful syncs by the daemon.", Type: metrics.TypeCounter,
+					Samples: []metrics.Sample{{Value: float64(daemonSyncs.Load())}}},
+				{Name: "chronicle_sync_daemon_failures_total", Help: "Failed syncs by the daemon.", Type: metrics.TypeCounter,
+					Samples: []metrics.Sample{{Value: float64(daemonFailures.Load())}}},
+			}
+		},
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", collector)
+
+	if !isLoopback(daemonMetrics) {
+		fmt.Fprintf(os.Stderr, "Warning: metrics on %s are readable by anyone who can reach this machine\n", daemonMetrics)
+	}
+	listener, err := net.Listen("tcp", daemonMetrics)
+	if err != nil {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "metrics server failed: %v\n", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	return nil
+}
+
+var syncDaemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the sync daemon is running",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, err := charm.ReadDaemonStatus(charm.DaemonStatusPath())
+		if err != nil {
+			return err
+		}
+		if !status.Running() {
+			color.Yellow("Sync daemon: not running")
+			return nil
+		}
+
+		color.Green("Sync daemon: running (pid %d)", status.PID)
+		fmt.Printf("Started:     %s\n", status.StartedAt.Local().Format(time.RFC1123))
+		fmt.Printf("Interval:    %s (+ up to %s jitter)\n", status.Interval, status.Jitter)
+		fmt.Printf("Syncs:       %d\n", status.Syncs)
+		if !status.LastSync.IsZero() {
+			fmt.Printf("Last sync:   %s\n", status.LastSync.Local().Format(time.RFC1123))
+		}
+		if status.LastError != "" {
+			color.Red("Last error:  %s", status.LastError)
+		}
+		return nil
+	},
+}
+
+var syncDaemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running sync daemon",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statusPath := charm.DaemonStatusPath()
+		status, err := charm.ReadDaemonStatus(statusPath)
+		if err != nil {
+			return err
+		}
+		if !status.Running() {
+			// Clean up a status file left behind by a crashed daemon
+			if status != nil {
+				_ = os.Remove(statusPath)
+			}
+			fmt.Println("Sync daemon is not running")
+			return nil
+		}
+
+		if err := status.Stop(); err != nil {
+			return fmt.Errorf("failed to stop sync daemon (pid %d): %w", status.PID, err)
+		}
+		color.Green("Sent stop signal to sync daemon (pid %d)", status.PID)
+		return nil
+	},
+}
+
+func init() {
+	syncDaemonCmd.Flags().DurationVar(&daemonInterval, "interval", 5*time.Minute, "Time between syncs")
+	syncDaemonCmd.Flags().DurationVar(&daemonJitter, "jitter", 30*time.Second, "Maximum random delay added to each interval")
+	syncDaemonCmd.Flags().DurationVar(&daemonWatch, "watch", 5*time.Second, "How often to check for local changes to push")
+	syncDaemonCmd.Flags().StringVar(&daemonMetrics, "metrics", "", "Serve Prometheus metrics on this address, e.g. 127.0.0.1:9464")
+
+	syncDaemonCmd.AddCommand(syncDaemonStatusCmd)
+	syncDaemonCmd.AddCommand(syncDaemonStopCmd)
+	syncCmd.AddCommand(syncDaemonCmd)
+}
@@ -0,0 +1,349 @@
+// ABOUTME: Installs the sync daemon as a systemd user service or launchd agent
+// ABOUTME: Renders the unit or plist for this binary and profile, and removes it again
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonInstallSystemd bool
+	daemonInstallLaunchd bool
+	daemonInstallDryRun  bool
+	daemonInstallForce   bool
+)
+
+// daemonUnitMarker identifies service files chronicle wrote.
+const daemonUnitMarker = "Generated by chronicle sync daemon install"
+
+// daemonService describes the sync daemon as a service manager runs it.
+type daemonService struct {
+	// Name is the systemd unit or launchd label, without extension
+	Name string
+	// Args is the full command line, starting with the executable
+	Args []string
+	// Env holds KEY=value pairs for the daemon's environment
+	Env []string
+	// LogPath is where launchd sends the daemon's output
+	LogPath string
+}
+
+var syncDaemonInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Run the sync daemon at login under systemd or launchd",
+	Long: `Write a service definition that starts 'chronicle sync daemon' at login
+and restarts it if it fails: a systemd user unit with --systemd (the default
+on Linux) or a launchd agent with --launchd (the default on macOS).
+
+The service runs this chronicle binary with the given --interval, --jitter,
+and --metrics, and with the active profile, so install once per profile to
+sync several. Use --dry-run to print the file instead of writing it. An
+existing file chronicle didn't write is left alone unless --force.`,
+	Args: cobra.NoArgs,
+	RunE: runDaemonInstall,
+}
+
+var syncDaemonUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the sync daemon's systemd or launchd service",
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonUninstall,
+}
+
+// daemonCmd is the top-level home of the service commands, so
+// 'chronicle daemon install --systemd' works as well as the sync daemon's
+// own install.
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Install chronicle's background services",
+	Long: `Install or remove the services chronicle runs in the background. Today
+that's the sync daemon; 'chronicle daemon install' is the same as
+'chronicle sync daemon install'.
+
+There are no reminder or digest units yet, since chronicle has no reminder
+or digest command for them to run.`,
+}
+
+var daemonInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: syncDaemonInstallCmd.Short,
+	Long:  syncDaemonInstallCmd.Long,
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonInstall,
+}
+
+var daemonUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: syncDaemonUninstallCmd.Short,
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonUninstall,
+}
+
+// runDaemonInstall writes the sync daemon's service file.
+func runDaemonInstall(cmd *cobra.Command, args []string) error {
+	manager, err := daemonManager()
+	if err != nil {
+		return err
+	}
+	service, err := newDaemonService(manager)
+	if err != nil {
+		return err
+	}
+	path, content := daemonServiceFile(manager, service)
+	if daemonInstallDryRun {
+		fmt.Print(content)
+		return nil
+	}
+	if err := writeDaemonServiceFile(path, content, daemonInstallForce); err != nil {
+		return err
+	}
+
+	color.Green("Wrote %s", path)
+	fmt.Println("Start it now and at every login with:")
+	if manager == "systemd" {
+		fmt.Printf("  systemctl --user daemon-reload\n  systemctl --user enable --now %s.service\n", service.Name)
+	} else {
+		fmt.Printf("  launchctl load -w %s\n", shellQuote(path))
+	}
+	return nil
+}
+
+// runDaemonUninstall removes the sync daemon's service file.
+func runDaemonUninstall(cmd *cobra.Command, args []string) error {
+	manager, err := daemonManager()
+	if err != nil {
+		return err
+	}
+	name := daemonServiceName(manager, activeProfile())
+	path := daemonServicePath(manager, name)
+	if !isChronicleServiceFile(path) {
+		fmt.Printf("No chronicle service at %s\n", path)
+		return nil
+	}
+
+	// Stop the service before its file goes
+	fmt.Println("Stop it first if it's running:")
+	if manager == "systemd" {
+		fmt.Printf("  systemctl --user disable --now %s.service\n", name)
+	} else {
+		fmt.Printf("  launchctl unload -w %s\n", shellQuote(path))
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	color.Green("Removed %s", path)
+	return nil
+}
+
+// daemonManager picks the service manager from the flags, or from the OS
+// when neither is given.
+func daemonManager() (string, error) {
+	switch {
+	case daemonInstallSystemd && daemonInstallLaunchd:
+		return "", fmt.Errorf("use either --systemd or --launchd, not both")
+	case daemonInstallSystemd:
+		return "systemd", nil
+	case daemonInstallLaunchd:
+		return "launchd", nil
+	case runtime.GOOS == "darwin":
+		return "launchd", nil
+	case runtime.GOOS == "linux":
+		return "systemd", nil
+	}
+	return "", fmt.Errorf("no service manager support on %s; pass --systemd or --launchd", runtime.GOOS)
+}
+
+// activeProfile returns the profile this invocation uses, "" for the
+// default one.
+func activeProfile() string {
+	configured := ""
+	if cfg, err := charm.LoadConfig(); err == nil {
+		configured = cfg.Profile
+	}
+	profile := charm.ResolveProfile(configured)
+	if profile == "default" {
+		return ""
+	}
+	return profile
+}
+
+// newDaemonService describes the daemon for this binary, profile, and the
+// install flags.
+func newDaemonService(manager string) (daemonService, error) {
+	if daemonInterval <= 0 {
+		return daemonService{}, fmt.Errorf("--interval must be positive")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return daemonService{}, fmt.Errorf("failed to locate chronicle: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	profile := activeProfile()
+	service := daemonService{
+		Name:    daemonServiceName(manager, profile),
+		Args:    []string{exe, "sync", "daemon", "--interval", daemonInterval.String(), "--jitter", daemonJitter.String()},
+		LogPath: charm.DaemonLogPath(),
+	}
+	if daemonMetrics != "" {
+		service.Args = append(service.Args, "--metrics", daemonMetrics)
+	}
+	if profile != "" {
+		service.Env = append(service.Env, charm.ProfileEnv+"="+profile)
+	}
+	if db := os.Getenv("CHRONICLE_DB"); db != "" {
+		service.Env = append(service.Env, "CHRONICLE_DB="+db)
+	}
+	return service, nil
+}
+
+// daemonServiceName names the unit or agent for profile.
+func daemonServiceName(manager, profile string) string {
+	if manager == "launchd" {
+		if profile != "" {
+			return "com.github.harper.chronicle.sync." + profile
+		}
+		return "com.github.harper.chronicle.sync"
+	}
+	if profile != "" {
+		return "chronicle-sync-" + profile
+	}
+	return "chronicle-sync"
+}
+
+// daemonServicePath is where the service manager looks for name.
+func daemonServicePath(manager, name string) string {
+	if manager == "launchd" {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, "Library", "LaunchAgents", name+".plist")
+	}
+	return filepath.Join(config.GetConfigHome(), "systemd", "user", name+".service")
+}
+
+// daemonServiceFile returns the path and contents of service's file.
+func daemonServiceFile(manager string, service daemonService) (string, string) {
+	path := daemonServicePath(manager, service.Name)
+	if manager == "launchd" {
+		return path, launchdPlist(service)
+	}
+	return path, systemdUnit(service)
+}
+
+// systemdUnit renders service as a systemd user unit.
+func systemdUnit(service daemonService) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", daemonUnitMarker)
+	b.WriteString("[Unit]\nDescription=chronicle sync daemon\n")
+	b.WriteString("Wants=network-online.target\nAfter=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	quoted := make([]string, len(service.Args))
+	for i, arg := range service.Args {
+		quoted[i] = systemdQuote(arg)
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(quoted, " "))
+	for _, env := range service.Env {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(env))
+	}
+	b.WriteString("Restart=on-failure\nRestartSec=30\n\n")
+	b.WriteString("[Install]\nWantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdQuote quotes arg for an ExecStart or Environment line when it
+// needs it, and escapes the % specifiers systemd would expand.
+func systemdQuote(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// launchdPlist renders service as a launchd agent property list.
+func launchdPlist(service daemonService) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	fmt.Fprintf(&b, "<!-- %s -->\n", daemonUnitMarker)
+	b.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	fmt.Fprintf(&b, "\t<key>Label</key>\n\t<string>%s</string>\n", html.EscapeString(service.Name))
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range service.Args {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", html.EscapeString(arg))
+	}
+	b.WriteString("\t</array>\n")
+	if len(service.Env) > 0 {
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, env := range service.Env {
+			key, value, _ := strings.Cut(env, "=")
+			fmt.Fprintf(&b, "\t\t<key>%s</key>\n\t\t<string>%s</string>\n", html.EscapeString(key), html.EscapeString(value))
+		}
+		b.WriteString("\t</dict>\n")
+	}
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	// Restart after a crash but not after 'chronicle sync daemon stop'
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("\t<key>ThrottleInterval</key>\n\t<integer>30</integer>\n")
+	fmt.Fprintf(&b, "\t<key>StandardOutPath</key>\n\t<string>%s</string>\n", html.EscapeString(service.LogPath))
+	fmt.Fprintf(&b, "\t<key>StandardErrorPath</key>\n\t<string>%s</string>\n", html.EscapeString(service.LogPath))
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// writeDaemonServiceFile writes content to path. A file chronicle didn't
+// write is only replaced when force is set.
+func writeDaemonServiceFile(path, content string, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force && !isChronicleServiceFile(path) {
+		return fmt.Errorf("%s already exists; pass --force to replace it", path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil { //nolint:gosec // Service files are read by the service manager
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// isChronicleServiceFile reports whether the file at path was written by
+// chronicle.
+func isChronicleServiceFile(path string) bool {
+	data, err := os.ReadFile(path) //nolint:gosec // Path is the service file's fixed location
+	return err == nil && strings.Contains(string(data), daemonUnitMarker)
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{syncDaemonInstallCmd, syncDaemonUninstallCmd, daemonInstallCmd, daemonUninstallCmd} {
+		cmd.Flags().BoolVar(&daemonInstallSystemd, "systemd", false, "Use a systemd user unit (default on Linux)")
+		cmd.Flags().BoolVar(&daemonInstallLaunchd, "launchd", false, "Use a launchd agent (default on macOS)")
+	}
+	for _, cmd := range []*cobra.Command{syncDaemonInstallCmd, daemonInstallCmd} {
+		cmd.Flags().DurationVar(&daemonInterval, "interval", 5*time.Minute, "Time between syncs")
+		cmd.Flags().DurationVar(&daemonJitter, "jitter", 30*time.Second, "Maximum random delay added to each interval")
+		cmd.Flags().StringVar(&daemonMetrics, "metrics", "", "Serve Prometheus metrics on this address, e.g. 127.0.0.1:9464")
+		cmd.Flags().BoolVar(&daemonInstallDryRun, "dry-run", false, "Print the service file instead of writing it")
+		cmd.Flags().BoolVar(&daemonInstallForce, "force", false, "Replace a service file chronicle didn't write")
+	}
+	syncDaemonCmd.AddCommand(syncDaemonInstallCmd)
+	syncDaemonCmd.AddCommand(syncDaemonUninstallCmd)
+	daemonCmd.AddCommand(daemonInstallCmd)
+	daemonCmd.AddCommand(daemonUninstallCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
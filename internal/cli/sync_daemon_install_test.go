@@ -0,0 +1,95 @@
+// ABOUTME: Tests for installing the sync daemon as a service
+// ABOUTME: Checks rendered systemd units and launchd plists, quoting, and overwrite protection
+package cli
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testDaemonService() daemonService {
+	return daemonService{
+		Name:    "chronicle-sync-work",
+		Args:    []string{"/Applications/My Tools/chronicle", "sync", "daemon", "--interval", "5m0s"},
+		Env:     []string{"CHRONICLE_PROFILE=work"},
+		LogPath: "/tmp/sync-daemon-work.log",
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	unit := systemdUnit(testDaemonService())
+	for _, want := range []string{
+		`ExecStart="/Applications/My Tools/chronicle" sync daemon --interval 5m0s` + "\n",
+		"Environment=CHRONICLE_PROFILE=work\n",
+		"Restart=on-failure\n",
+		"WantedBy=default.target\n",
+		daemonUnitMarker,
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit missing %q:\n%s", want, unit)
+		}
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":       "plain",
+		"with space":  `"with space"`,
+		`say "hi"`:    `"say \"hi\""`,
+		"100%":        "100%%",
+		"":            `""`,
+		`back\slash`:  `"back\\slash"`,
+		"KEY=a value": `"KEY=a value"`,
+	}
+	for arg, want := range tests {
+		if got := systemdQuote(arg); got != want {
+			t.Errorf("systemdQuote(%q) = %s, want %s", arg, got, want)
+		}
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	service := testDaemonService()
+	service.Args = append(service.Args, "--metrics", "127.0.0.1:9464&x")
+	plist := launchdPlist(service)
+	if err := xml.Unmarshal([]byte(plist), new(struct{})); err != nil {
+		t.Fatalf("plist is not valid XML: %v\n%s", err, plist)
+	}
+	for _, want := range []string{
+		"<string>/Applications/My Tools/chronicle</string>",
+		"<string>127.0.0.1:9464&amp;x</string>",
+		"<key>CHRONICLE_PROFILE</key>\n\t\t<string>work</string>",
+		"<string>/tmp/sync-daemon-work.log</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist missing %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestWriteDaemonServiceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "systemd", "user", "chronicle-sync.service")
+	unit := systemdUnit(testDaemonService())
+	if err := writeDaemonServiceFile(path, unit, false); err != nil {
+		t.Fatalf("write new file: %v", err)
+	}
+	if err := writeDaemonServiceFile(path, unit, false); err != nil {
+		t.Errorf("replacing chronicle's own file should succeed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("[Service]\nExecStart=/bin/true\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeDaemonServiceFile(path, unit, false); err == nil {
+		t.Error("expected refusal to replace a file chronicle didn't write")
+	}
+	if err := writeDaemonServiceFile(path, unit, true); err != nil {
+		t.Errorf("--force should replace it: %v", err)
+	}
+	if !isChronicleServiceFile(path) {
+		t.Error("file should be chronicle's after a forced write")
+	}
+}
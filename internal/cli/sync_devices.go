@@ -0,0 +1,176 @@
+// ABOUTME: Sync devices commands for managing devices linked to the Charm account
+// ABOUTME: Provides list, revoke, and rename subcommands
+
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	devicesJSONOutput bool
+	devicesRevokeYes  bool
+)
+
+var syncDevicesCmd = &cobra.Command{
+	Use:   "devices",
+	Short: "Manage devices linked to your Charm account",
+	Long: `Manage the devices that can sync this chronicle.
+
+Every device is an SSH key linked to your Charm account. Devices can be
+referred to by their number in 'sync devices list', by name, or by a
+fingerprint prefix. Names are stored locally in charm.json.
+
+Commands:
+  list    - Show linked devices
+  revoke  - Unlink a lost or retired device
+  rename  - Give a device a friendly name`,
+}
+
+var syncDevicesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List linked devices",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+		devices, err := c.Devices()
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput(cmd, devicesJSONOutput) {
+			data, err := json.MarshalIndent(devices, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		lastSync := c.LastSyncTime()
+		tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "#\tNAME\tFINGERPRINT\tLINKED\tLAST SEEN")
+		for _, d := range devices {
+			name := d.Name
+			if name == "" {
+				name = "-"
+			}
+			linked := "-"
+			if d.LinkedAt != nil {
+				linked = d.LinkedAt.Local().Format("2006-01-02")
+			}
+			// The server doesn't record key usage; only this device's last sync is known
+			seen := "unknown"
+			if d.Current {
+				name += " (this device)"
+				if !lastSync.IsZero() {
+					seen = lastSync.Local().Format(time.DateTime)
+				}
+			}
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", d.Index, name, d.Fingerprint[:12], linked, seen)
+		}
+		return tw.Flush()
+	},
+}
+
+var syncDevicesRevokeCmd = &cobra.Command{
+	Use:   "revoke <device>",
+	Short: "Unlink a device from your Charm account",
+	Long: `Unlink a device so it can no longer sync.
+
+The device keeps any data it already has locally, but can't push or pull
+anymore. Revoking this device itself requires --yes.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+		devices, err := c.Devices()
+		if err != nil {
+			return err
+		}
+		device, err := charm.FindDevice(devices, args[0])
+		if err != nil {
+			return err
+		}
+
+		label := deviceLabel(device)
+		if device.Current && !devicesRevokeYes {
+			return fmt.Errorf("%s is this device; pass --yes to revoke it anyway", label)
+		}
+		if !devicesRevokeYes {
+			fmt.Printf("Revoke %s? It will no longer be able to sync. [y/N]: ", label)
+			reader := bufio.NewReader(os.Stdin)
+			confirmation, _ := reader.ReadString('\n')
+			confirmation = strings.TrimSpace(strings.ToLower(confirmation))
+			if confirmation != "y" && confirmation != "yes" {
+				fmt.Println("Aborted.")
+				return nil
+			}
+		}
+
+		if err := c.RevokeDevice(device); err != nil {
+			return fmt.Errorf("failed to revoke %s: %w", label, err)
+		}
+		color.Green("Revoked %s", label)
+		return nil
+	},
+}
+
+var syncDevicesRenameCmd = &cobra.Command{
+	Use:   "rename <device> <name>",
+	Short: "Name a linked device",
+	Long:  `Give a device a friendly name. Pass an empty name ("") to clear it.`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+		devices, err := c.Devices()
+		if err != nil {
+			return err
+		}
+		device, err := charm.FindDevice(devices, args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := c.RenameDevice(device, args[1]); err != nil {
+			return fmt.Errorf("failed to rename device: %w", err)
+		}
+		color.Green("Renamed device #%d to %q", device.Index, strings.TrimSpace(args[1]))
+		return nil
+	},
+}
+
+// deviceLabel describes a device by name if it has one, else by number.
+func deviceLabel(d *charm.Device) string {
+	if d.Name != "" {
+		return fmt.Sprintf("device #%d (%s)", d.Index, d.Name)
+	}
+	return fmt.Sprintf("device #%d", d.Index)
+}
+
+func init() {
+	syncDevicesListCmd.Flags().BoolVar(&devicesJSONOutput, "json", false, "Output as JSON")
+	syncDevicesRevokeCmd.Flags().BoolVarP(&devicesRevokeYes, "yes", "y", false, "Skip confirmation")
+
+	syncDevicesCmd.AddCommand(syncDevicesListCmd)
+	syncDevicesCmd.AddCommand(syncDevicesRevokeCmd)
+	syncDevicesCmd.AddCommand(syncDevicesRenameCmd)
+	syncCmd.AddCommand(syncDevicesCmd)
+}
@@ -0,0 +1,67 @@
+// ABOUTME: Sync export-cloud command dumping every synced entry to JSONL
+// ABOUTME: Pulls first so entries missing locally are included
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var exportLocalOnly bool
+
+var syncExportCmd = &cobra.Command{
+	Use:   "export-cloud <file>",
+	Short: "Export every entry to a JSONL file",
+	Long: `Pull everything from Charm Cloud, then write every entry to a JSON-lines
+file. Run this before 'sync reset' or 'sync wipe' to keep a copy you control.
+
+The export includes entries hidden by ignored_hosts and can be restored with
+'chronicle import legacy <file>'. Use --local to skip the pull when offline.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		if !exportLocalOnly {
+			if err := c.Sync(); err != nil {
+				return fmt.Errorf("failed to pull from cloud (use --local to export what's here): %w", err)
+			}
+		}
+
+		// Write to a temp file first so a failed export never leaves a
+		// truncated file that looks complete
+		path := args[0]
+		tmp, err := os.CreateTemp(filepath.Dir(path), ".chronicle-export-*")
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+		defer func() { _ = os.Remove(tmp.Name()) }()
+
+		count, err := c.ExportEntries(tmp)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			return fmt.Errorf("failed to save export: %w", err)
+		}
+
+		color.Green("Exported %d entries to %s", count, path)
+		return nil
+	},
+}
+
+func init() {
+	syncExportCmd.Flags().BoolVar(&exportLocalOnly, "local", false, "Export local data without pulling first")
+	syncCmd.AddCommand(syncExportCmd)
+}
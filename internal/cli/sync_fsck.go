@@ -0,0 +1,113 @@
+// ABOUTME: Sync fsck command for checking stored entries and the day index
+// ABOUTME: Reports damaged entries and index drift, and quarantines them with --fix
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fsckFix        bool
+	fsckJSONOutput bool
+)
+
+var syncFsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check stored entries and the day index for damage",
+	Long: `Check every stored entry and the day index.
+
+Reports entries whose value can't be parsed or that are missing an ID,
+timestamp, or message. Searches skip these silently. Also reports entries
+missing from the day index and index keys that no longer match an entry.
+
+With --fix, damaged entries are moved under the corrupt: prefix, where
+they no longer show up but are kept for inspection, and the index is
+repaired. Entries that can't be read at all are only reported.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		result, err := client.Fsck(fsckFix)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput(cmd, fsckJSONOutput) {
+			data, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+		} else {
+			printFsckResult(result)
+		}
+
+		if !result.OK() && !fsckFix {
+			return fmt.Errorf("%d damaged entries and %d index problems found (run with --fix to repair)",
+				len(result.Damaged), len(result.IndexMissing)+len(result.IndexStale))
+		}
+		if n := unreadableCount(result); n > 0 {
+			return fmt.Errorf("%d entries could not be read and were left in place", n)
+		}
+		return nil
+	},
+}
+
+// printFsckResult writes a human-readable fsck report to stdout.
+func printFsckResult(result *charm.FsckResult) {
+	fmt.Printf("Checked: %d entries\n", result.Checked)
+	for _, d := range result.Damaged {
+		if d.Quarantined {
+			color.Yellow("  ✓ quarantined %s: %s", d.ID, d.Problem)
+		} else {
+			color.Red("  ✗ %s: %s", d.ID, d.Problem)
+		}
+	}
+
+	verb := "missing"
+	if result.Fixed {
+		verb = "added"
+	}
+	if n := len(result.IndexMissing); n > 0 {
+		color.Yellow("  ! day index: %d entries %s", n, verb)
+	}
+	verb = "stale"
+	if result.Fixed {
+		verb = "removed"
+	}
+	if n := len(result.IndexStale); n > 0 {
+		color.Yellow("  ! day index: %d keys %s", n, verb)
+	}
+
+	switch {
+	case result.OK():
+		color.Green("No problems found.")
+	case result.Fixed && unreadableCount(result) == 0:
+		color.Green("Repaired.")
+	}
+}
+
+// unreadableCount returns how many damaged entries were not quarantined.
+func unreadableCount(result *charm.FsckResult) int {
+	n := 0
+	for _, d := range result.Damaged {
+		if !d.Quarantined {
+			n++
+		}
+	}
+	return n
+}
+
+func init() {
+	syncFsckCmd.Flags().BoolVar(&fsckFix, "fix", false, "Quarantine damaged entries and repair the day index")
+	syncFsckCmd.Flags().BoolVar(&fsckJSONOutput, "json", false, "Output as JSON")
+	syncCmd.AddCommand(syncFsckCmd)
+}
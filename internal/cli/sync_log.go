@@ -0,0 +1,85 @@
+// ABOUTME: Sync log command showing recent sync sessions
+// ABOUTME: Reads the local session history written after every sync
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncLogLimit      int
+	syncLogJSONOutput bool
+)
+
+var syncLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show recent sync sessions",
+	Long: `Show when this device last synced, how long each sync took, and
+whether it failed.
+
+Pushed and pulled counts are recorded for 'chronicle sync now' and when an
+on_remote_change hook is configured; automatic syncs after writes skip the
+extra check and show '-'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, err := charm.ReadSyncLog(charm.SyncLogPath(), syncLogLimit)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput(cmd, syncLogJSONOutput) {
+			data, err := json.MarshalIndent(sessions, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(sessions) == 0 {
+			fmt.Println("No syncs recorded yet.")
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "STARTED\tDURATION\tPUSHED\tPULLED\tRESULT")
+		// Newest first
+		for i := len(sessions) - 1; i >= 0; i-- {
+			s := sessions[i]
+			result := color.GreenString("ok")
+			if s.Error != "" {
+				result = color.RedString("error: %s", s.Error)
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				s.Started.Local().Format(time.DateTime),
+				s.Duration.Round(time.Millisecond),
+				optionalCount(s.Pushed),
+				optionalCount(s.Pulled),
+				result)
+		}
+		return tw.Flush()
+	},
+}
+
+// optionalCount formats a count that may not have been measured.
+func optionalCount[T int64 | uint64](n *T) string {
+	if n == nil {
+		return "-"
+	}
+	return strconv.FormatUint(uint64(*n), 10)
+}
+
+func init() {
+	syncLogCmd.Flags().IntVarP(&syncLogLimit, "limit", "n", 20, "Number of sessions to show (0 for all)")
+	syncLogCmd.Flags().BoolVar(&syncLogJSONOutput, "json", false, "Output as JSON")
+	syncCmd.AddCommand(syncLogCmd)
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Sync now command for an immediate push and pull
+// ABOUTME: Optionally shows a live progress line and pushed/pulled counters
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var syncNowProgress bool
+
+var syncNowCmd = &cobra.Command{
+	Use:   "now",
+	Short: "Push queued changes and pull remote ones now",
+	Long: `Sync immediately instead of waiting for the next write or the daemon.
+
+With --progress, a live status line shows elapsed time while the sync runs,
+followed by how many queued writes were pushed and how many changes were
+pulled. Press Ctrl-C to abort a long sync.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		stopProgress := func() {}
+		if syncNowProgress {
+			stopProgress = startSyncProgress(os.Stderr)
+		}
+
+		report, err := client.SyncWithReport(ctx)
+		stopProgress()
+		if err != nil {
+			return fmt.Errorf("sync failed: %w", err)
+		}
+
+		color.Green("Synced in %s", report.Duration.Round(time.Millisecond))
+		if syncNowProgress {
+			fmt.Printf("  pushed:  %d\n", report.Pushed)
+			fmt.Printf("  pulled:  %d\n", report.Pulled)
+			if report.Pending > 0 {
+				color.Yellow("  pending: %d", report.Pending)
+			}
+		}
+		return nil
+	},
+}
+
+// spinnerFrames animate the progress line.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// startSyncProgress redraws an elapsed-time line on w until the returned
+// stop function is called, which clears the line before returning.
+func startSyncProgress(w io.Writer) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	start := time.Now()
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		for frame := 0; ; frame++ {
+			select {
+			case <-done:
+				_, _ = fmt.Fprint(w, "\r\033[K")
+				return
+			case <-ticker.C:
+				elapsed := time.Since(start).Truncate(time.Second)
+				_, _ = fmt.Fprintf(w, "\r%s syncing... %s", spinnerFrames[frame%len(spinnerFrames)], elapsed)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func init() {
+	syncNowCmd.Flags().BoolVar(&syncNowProgress, "progress", false, "Show live progress and pushed/pulled counts")
+	syncCmd.AddCommand(syncNowCmd)
+}
@@ -0,0 +1,26 @@
+// ABOUTME: Tests for sync command helpers
+// ABOUTME: Covers the coarse "time since" formatting used by sync status
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatSince(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{3 * time.Hour, "3h ago"},
+		{47 * time.Hour, "47h ago"},
+		{72 * time.Hour, "3d ago"},
+	}
+	for _, tt := range tests {
+		if got := formatSince(tt.d); got != tt.want {
+			t.Errorf("formatSince(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// ABOUTME: Tag command for managing tags across existing entries
+// ABOUTME: Bulk add/remove/rename plus a one-time normalize migration
+package cli
+
+import (
+	"fmt"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage entry tags",
+	Long: `Manage tags across many entries at once.
+
+Commands:
+  add        - Add a tag to the given entries
+  remove     - Remove a tag from the given entries
+  rename     - Rename a tag everywhere (merges into an existing tag)
+  normalize  - Trim, lowercase, and dedupe tags on existing entries`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <tag> <id> [id...]",
+	Short: "Add a tag to entries",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		updated, err := client.AddTagToEntries(args[1:], args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Tagged %d entries with %q\n", updated, args[0])
+		return nil
+	},
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove <tag> <id> [id...]",
+	Short: "Remove a tag from entries",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		updated, err := client.RemoveTagFromEntries(args[1:], args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Removed %q from %d entries\n", args[0], updated)
+		return nil
+	},
+}
+
+var tagRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag on every entry",
+	Long: `Rename a tag on every entry. If an entry already has the new tag, the
+two are merged, so this also works for merging duplicate tags.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		updated, err := client.RenameTag(args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Renamed %q to %q on %d entries\n", args[0], args[1], updated)
+		return nil
+	},
+}
+
+var tagNormalizeCmd = &cobra.Command{
+	Use:   "normalize",
+	Short: "Normalize tags on existing entries",
+	Long: `Rewrite existing entries so their tags follow the normalization policy:
+surrounding whitespace trimmed, internal whitespace collapsed, lowercased,
+and duplicates removed. New entries are normalized on write unless
+"normalize_tags" is set to false in the config.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		updated, err := client.NormalizeAllTags()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Normalized tags on %d entries\n", updated)
+		return nil
+	},
+}
+
+func init() {
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagRenameCmd)
+	tagCmd.AddCommand(tagNormalizeCmd)
+	rootCmd.AddCommand(tagCmd)
+}
@@ -0,0 +1,61 @@
+// ABOUTME: Vault command mirroring entries into Obsidian or Logseq daily notes
+// ABOUTME: Bridges the journal into existing note-taking workflows
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/pkm"
+	"github.com/spf13/cobra"
+)
+
+var vaultDryRun bool
+
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Mirror entries into an Obsidian or Logseq vault",
+}
+
+var vaultSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Update the vault's daily notes from the journal",
+	Long: `Mirror the last vault.days days of entries (30 by default) into the daily
+notes of the Obsidian or Logseq vault at vault.path. Each day's entries go
+in a block chronicle owns, with tags linked to their pages, so the tag
+pages' backlinks gather everything logged under them. Edited and deleted
+entries are updated on the next run; the rest of each note is left alone.
+
+The sync daemon runs this after every sync when vault is configured.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+		cfg := client.Config()
+		if cfg == nil || cfg.Vault == nil {
+			return fmt.Errorf("no vault settings in %s", charm.ConfigPath())
+		}
+
+		result, err := pkm.MirrorJournal(client, cfg.Vault, vaultDryRun)
+		if err != nil {
+			return fmt.Errorf("vault sync failed: %w", err)
+		}
+		verb := "Updated"
+		if vaultDryRun {
+			verb = "Would update"
+		}
+		color.Green("%s %d notes, removed %d, %d unchanged", verb, result.Updated, result.Removed, result.Unchanged)
+		return nil
+	},
+}
+
+func init() {
+	vaultSyncCmd.Flags().BoolVar(&vaultDryRun, "dry-run", false, "Show how many notes would change without writing them")
+	vaultCmd.AddCommand(vaultSyncCmd)
+	rootCmd.AddCommand(vaultCmd)
+}
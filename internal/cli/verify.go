@@ -0,0 +1,66 @@
+// ABOUTME: Verify command for detecting corrupted entries
+// ABOUTME: Recomputes per-entry checksums and reports divergent records
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/spf13/cobra"
+)
+
+var verifyJSONOutput bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check stored entries for corruption",
+	Long: `Recompute the checksum of every stored entry and report entries whose
+content no longer matches, or that cannot be decoded at all.
+
+Entries created before checksums were introduced are reported as
+unchecked. Useful after 'chronicle sync repair' or 'chronicle sync reset'.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := charm.GetClient()
+		if err != nil {
+			return fmt.Errorf("failed to connect to Charm: %w", err)
+		}
+
+		result, err := client.VerifyEntries()
+		if err != nil {
+			return fmt.Errorf("failed to verify entries: %w", err)
+		}
+
+		if jsonOutput(cmd, verifyJSONOutput) {
+			data, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON: %w", err)
+			}
+			fmt.Println(string(data))
+		} else {
+			fmt.Printf("Checked: %d\n", result.Checked)
+			fmt.Printf("Unchecked (no checksum): %d\n", result.Unchecked)
+			for _, id := range result.Mismatched {
+				color.Red("  ✗ checksum mismatch: %s", id)
+			}
+			for _, id := range result.Undecodable {
+				color.Red("  ✗ cannot decode: %s", id)
+			}
+			if result.OK() {
+				color.Green("All entries verified.")
+			}
+		}
+
+		if !result.OK() {
+			return fmt.Errorf("%d corrupted entries found", len(result.Mismatched)+len(result.Undecodable))
+		}
+		return nil
+	},
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyJSONOutput, "json", false, "Output as JSON")
+	rootCmd.AddCommand(verifyCmd)
+}
@@ -0,0 +1,80 @@
+// ABOUTME: Model-assisted auto-tagging settings for add and MCP add_entry
+// ABOUTME: Names the OpenAI-compatible endpoint, model, and how long an add may wait for it
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// AutoTagAPIKeyEnv holds the auto-tagging API key so it can stay out of
+// the config file; it overrides auto_tag.api_key.
+const AutoTagAPIKeyEnv = "CHRONICLE_AUTO_TAG_API_KEY"
+
+// Defaults for unset auto-tagging settings.
+const (
+	DefaultAutoTagTimeoutMS = 1500
+	DefaultAutoTagMaxTags   = 3
+)
+
+// AutoTag configures asking a language model for an entry's tags and type
+// when it's added. Its suggestions are merged with the tag rules'.
+type AutoTag struct {
+	// URL is the API base of an OpenAI-compatible server, such as
+	// https://api.openai.com/v1 or a local http://localhost:11434/v1
+	URL   string `json:"url"`
+	Model string `json:"model"`
+	// APIKey is sent as a bearer token; CHRONICLE_AUTO_TAG_API_KEY
+	// overrides it, and local servers usually need none
+	APIKey string `json:"api_key,omitempty"`
+	// TimeoutMS is the most an add waits for the model (1500 by default);
+	// past it the entry is added without the model's suggestions
+	TimeoutMS int `json:"timeout_ms,omitempty"`
+	// MaxTags caps the tags taken from the model (3 by default)
+	MaxTags int `json:"max_tags,omitempty"`
+}
+
+// Validate checks the auto-tagging settings.
+func (a *AutoTag) Validate() error {
+	u, err := url.Parse(a.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q must be an http or https URL", a.URL)
+	}
+	if a.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if a.TimeoutMS < 0 {
+		return fmt.Errorf("timeout_ms must not be negative")
+	}
+	if a.MaxTags < 0 {
+		return fmt.Errorf("max_tags must not be negative")
+	}
+	return nil
+}
+
+// Timeout returns how long an add waits for the model.
+func (a *AutoTag) Timeout() time.Duration {
+	ms := a.TimeoutMS
+	if ms == 0 {
+		ms = DefaultAutoTagTimeoutMS
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// TagLimit returns the most tags taken from the model.
+func (a *AutoTag) TagLimit() int {
+	if a.MaxTags == 0 {
+		return DefaultAutoTagMaxTags
+	}
+	return a.MaxTags
+}
+
+// Secret returns the API key, preferring CHRONICLE_AUTO_TAG_API_KEY.
+func (a *AutoTag) Secret() string {
+	if env := os.Getenv(AutoTagAPIKeyEnv); env != "" {
+		return env
+	}
+	return a.APIKey
+}
@@ -0,0 +1,105 @@
+// ABOUTME: CalDAV publishing settings for chronicle caldav push
+// ABOUTME: Names the calendar collection, credentials, and which entries to publish
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// CalDAVPasswordEnv holds the CalDAV password so it can stay out of the
+// config file; it overrides caldav.password.
+const CalDAVPasswordEnv = "CHRONICLE_CALDAV_PASSWORD"
+
+// Calendar components entries can be published as.
+const (
+	CalDAVEvent   = "vevent"
+	CalDAVJournal = "vjournal"
+)
+
+// Defaults for unset CalDAV settings.
+const (
+	DefaultCalDAVDays         = 30
+	DefaultCalDAVEventMinutes = 15
+)
+
+// CalDAV configures publishing entries to a CalDAV calendar.
+type CalDAV struct {
+	// URL is the calendar collection, e.g. a Nextcloud or Fastmail
+	// calendar's CalDAV address
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	// Password, often an app password; CHRONICLE_CALDAV_PASSWORD
+	// overrides it
+	Password string `json:"password,omitempty"`
+
+	// Component is vevent (default), shown by every calendar app, or
+	// vjournal, which only some show
+	Component string `json:"component,omitempty"`
+	// Tags limits publishing to entries with one of these tags; all
+	// entries when empty
+	Tags []string `json:"tags,omitempty"`
+	// Days is how far back entries are published (30 by default)
+	Days int `json:"days,omitempty"`
+	// EventMinutes is how long events last when an entry has no duration
+	// of its own (15 by default)
+	EventMinutes int `json:"event_minutes,omitempty"`
+}
+
+// Validate checks the CalDAV settings.
+func (c *CalDAV) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q must be an http or https URL", c.URL)
+	}
+	switch c.Component {
+	case "", CalDAVEvent, CalDAVJournal:
+	default:
+		return fmt.Errorf("component %q is not one of %s, %s", c.Component, CalDAVEvent, CalDAVJournal)
+	}
+	if c.Days < 0 {
+		return fmt.Errorf("days must not be negative")
+	}
+	if c.EventMinutes < 0 {
+		return fmt.Errorf("event_minutes must not be negative")
+	}
+	return nil
+}
+
+// ComponentName returns the calendar component to publish, vevent unless
+// set.
+func (c *CalDAV) ComponentName() string {
+	if c.Component == "" {
+		return CalDAVEvent
+	}
+	return c.Component
+}
+
+// Window returns how far back entries are published.
+func (c *CalDAV) Window() time.Duration {
+	days := c.Days
+	if days == 0 {
+		days = DefaultCalDAVDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// EventLength returns how long an event lasts without a duration of its
+// own.
+func (c *CalDAV) EventLength() time.Duration {
+	minutes := c.EventMinutes
+	if minutes == 0 {
+		minutes = DefaultCalDAVEventMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// Secret returns the password, preferring CHRONICLE_CALDAV_PASSWORD.
+func (c *CalDAV) Secret() string {
+	if env := os.Getenv(CalDAVPasswordEnv); env != "" {
+		return env
+	}
+	return c.Password
+}
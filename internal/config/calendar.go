@@ -0,0 +1,61 @@
+// ABOUTME: Calendar settings shared by the CLI and MCP server
+// ABOUTME: Parses week_starts_on and maps date_format presets to Go layouts
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Date format presets for the date_format setting.
+const (
+	DateFormatISO = "iso" // 2006-01-02, the default
+	DateFormatUS  = "us"  // 01/02/2006
+	DateFormatEU  = "eu"  // 02/01/2006
+)
+
+// DateFormats lists the valid date_format presets.
+var DateFormats = []string{DateFormatISO, DateFormatUS, DateFormatEU}
+
+var dateLayouts = map[string]string{
+	DateFormatISO: time.DateOnly,
+	DateFormatUS:  "01/02/2006",
+	DateFormatEU:  "02/01/2006",
+}
+
+// DateLayout returns the Go time layout for a date_format preset. Empty
+// means iso.
+func DateLayout(format string) (string, error) {
+	if format == "" {
+		format = DateFormatISO
+	}
+	layout, ok := dateLayouts[strings.ToLower(format)]
+	if !ok {
+		return "", fmt.Errorf("unknown date_format %q (use %s)", format, strings.Join(DateFormats, ", "))
+	}
+	return layout, nil
+}
+
+// ParseWeekday parses a week_starts_on value: a day name such as "monday"
+// or its three-letter abbreviation. Empty means Monday.
+func ParseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return time.Monday, nil
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown week_starts_on %q (use a day name such as monday or sunday)", name)
+}
+
+// StartOfWeek returns midnight, in t's location, of the most recent start
+// day on or before t.
+func StartOfWeek(t time.Time, start time.Weekday) time.Time {
+	offset := (int(t.Weekday()) - int(start) + 7) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,67 @@
+// ABOUTME: Tests for calendar settings
+// ABOUTME: Validates week_starts_on parsing, week starts, and date_format presets
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWeekday(t *testing.T) {
+	tests := map[string]time.Weekday{
+		"":          time.Monday,
+		"monday":    time.Monday,
+		"Sunday":    time.Sunday,
+		" sat ":     time.Saturday,
+		"THU":       time.Thursday,
+		"wednesday": time.Wednesday,
+	}
+	for name, want := range tests {
+		got, err := ParseWeekday(name)
+		if err != nil || got != want {
+			t.Errorf("ParseWeekday(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	for _, bad := range []string{"funday", "mo", "1"} {
+		if _, err := ParseWeekday(bad); err == nil {
+			t.Errorf("ParseWeekday(%q): expected error", bad)
+		}
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	// Wednesday afternoon
+	now := time.Date(2025, 11, 26, 15, 30, 0, 0, time.UTC)
+	tests := map[time.Weekday]int{
+		time.Monday:    24,
+		time.Sunday:    23,
+		time.Saturday:  22,
+		time.Wednesday: 26,
+		time.Thursday:  20,
+	}
+	for start, day := range tests {
+		want := time.Date(2025, 11, day, 0, 0, 0, 0, time.UTC)
+		if got := StartOfWeek(now, start); !got.Equal(want) {
+			t.Errorf("StartOfWeek(%v) = %v, want %v", start, got, want)
+		}
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	date := time.Date(2025, 11, 4, 0, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		"":    "2025-11-04",
+		"iso": "2025-11-04",
+		"US":  "11/04/2025",
+		"eu":  "04/11/2025",
+	}
+	for format, want := range tests {
+		layout, err := DateLayout(format)
+		if err != nil || date.Format(layout) != want {
+			t.Errorf("DateLayout(%q) formats %q, %v; want %q", format, date.Format(layout), err, want)
+		}
+	}
+	if _, err := DateLayout("yyyy-mm-dd"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
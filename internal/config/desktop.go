@@ -0,0 +1,24 @@
+// ABOUTME: Desktop notification settings
+// ABOUTME: Picks which events pop up a notification on this machine
+package config
+
+// DesktopNotifications chooses the events announced with a desktop
+// notification. Sync arrivals are the only event so far; there is no
+// reminder command to announce.
+type DesktopNotifications struct {
+	// Sync announces entries that arrive from other devices while the
+	// sync daemon runs
+	Sync bool `json:"sync"`
+	// Types limits sync announcements to entries of these types; all
+	// entries when empty
+	Types []string `json:"types,omitempty"`
+}
+
+// Announces reports whether an entry of type entryType arriving on sync
+// should be announced.
+func (d *DesktopNotifications) Announces(entryType string) bool {
+	if d == nil || !d.Sync {
+		return false
+	}
+	return len(d.Types) == 0 || contains(d.Types, entryType)
+}
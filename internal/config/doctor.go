@@ -0,0 +1,121 @@
+// ABOUTME: Config file validation for chronicle config doctor
+// ABOUTME: Reports unknown keys, bad values, and conflicting settings with file and line
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Project log formats and rotations. They must match the logging package's;
+// config does not import logging so the values are duplicated here.
+var (
+	logFormats   = []string{"markdown", "jsonl", "obsidian", "json"}
+	logRotations = []string{"daily", "weekly", "monthly"}
+)
+
+// Diagnostic is one problem found in a config file.
+type Diagnostic struct {
+	File string `json:"file"`
+	// Line is where the problem is, starting at 1; 0 when it isn't tied
+	// to one line
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%s: %s", d.File, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+}
+
+// CheckProjectConfig validates the .chronicle file at path. Problems with
+// its contents are diagnostics; only failing to read it is an error.
+func CheckProjectConfig(path string) ([]Diagnostic, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path is a project config the user asked to check
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+	report := func(key, format string, args ...any) {
+		diags = append(diags, Diagnostic{File: path, Line: KeyLine(data, key), Message: fmt.Sprintf(format, args...)})
+	}
+
+	var cfg ProjectConfig
+	md, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			return []Diagnostic{{File: path, Line: perr.Position.Line, Message: perr.Message}}, nil
+		}
+		return []Diagnostic{{File: path, Message: err.Error()}}, nil
+	}
+
+	for _, key := range md.Undecoded() {
+		report(key[len(key)-1], "unknown key %q", key.String())
+	}
+	if md.IsDefined("log_format") && !contains(logFormats, cfg.LogFormat) {
+		report("log_format", "log_format %q is not one of %s", cfg.LogFormat, strings.Join(logFormats, ", "))
+	}
+	if md.IsDefined("log_rotation") && !contains(logRotations, cfg.LogRotation) {
+		report("log_rotation", "log_rotation %q is not one of %s", cfg.LogRotation, strings.Join(logRotations, ", "))
+	}
+	if cfg.LogFormat == "obsidian" && cfg.LogRotation != "" && cfg.LogRotation != "daily" {
+		report("log_rotation", "log_format obsidian writes daily notes and can't use %s rotation", cfg.LogRotation)
+	}
+	if cfg.LogTemplate != "" && cfg.LogTemplateFile != "" {
+		report("log_template", "log_template is ignored because log_template_file is set")
+	}
+	if (cfg.LogTemplate != "" || cfg.LogTemplateFile != "") && cfg.LogFormat == "jsonl" {
+		report("log_format", "log templates are only used for markdown logs, not jsonl")
+	}
+	if cfg.DatabaseProfile != "" && cfg.Database == "" {
+		report("database_profile", "database_profile is ignored without database")
+	}
+	if _, err := CompileTagRules(cfg.TagRules); err != nil {
+		report("tag_rules", "%v", err)
+	}
+	if err := ValidateNotifications(cfg.Notifications); err != nil {
+		report("notifications", "%v", err)
+	}
+	return diags, nil
+}
+
+// KeyLine returns the first line, starting at 1, where key is set in a
+// TOML or JSON config file, or 0 if it can't be found.
+func KeyLine(data []byte, key string) int {
+	q := regexp.QuoteMeta(key)
+	re := regexp.MustCompile(`^\s*(` + q + `\s*=|\[\[?\s*` + q + `\s*\]\]?)|"` + q + `"\s*:`)
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if re.Match(line) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// OffsetLine returns the line, starting at 1, holding byte offset in data.
+func OffsetLine(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
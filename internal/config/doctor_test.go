@@ -0,0 +1,77 @@
+// ABOUTME: Tests for .chronicle validation
+// ABOUTME: Checks unknown keys, bad values, conflicts, and reported lines
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckProjectConfig(t *testing.T) {
+	check := func(t *testing.T, content string) []Diagnostic {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), ".chronicle")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		diags, err := CheckProjectConfig(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return diags
+	}
+
+	t.Run("valid config has no problems", func(t *testing.T) {
+		if diags := check(t, "local_logging = true\nlog_format = \"jsonl\"\n"); len(diags) != 0 {
+			t.Errorf("got %v, want none", diags)
+		}
+	})
+
+	tests := []struct {
+		name, content string
+		line          int
+		want          string
+	}{
+		{"unknown key", "local_logging = true\nlog_fromat = \"jsonl\"\n", 2, `unknown key "log_fromat"`},
+		{"bad log_format", "local_logging = true\n\nlog_format = \"yaml\"\n", 3, `log_format "yaml"`},
+		{"bad log_rotation", "log_rotation = \"hourly\"\n", 1, `log_rotation "hourly"`},
+		{"obsidian rotation", "log_format = \"obsidian\"\nlog_rotation = \"weekly\"\n", 2, "daily notes"},
+		{"both templates", "log_template = \"x\"\nlog_template_file = \"t.md\"\n", 1, "log_template is ignored"},
+		{"profile without database", "database_profile = \"work\"\n", 1, "without database"},
+		{"bad notification", "[[notifications]]\nservice = \"teams\"\nurl = \"https://example.com\"\n", 1, `unknown service "teams"`},
+		{"parse error", "local_logging = true\nlog_format = \n", 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := check(t, tt.content)
+			if len(diags) != 1 {
+				t.Fatalf("got %v, want one diagnostic", diags)
+			}
+			if diags[0].Line != tt.line || !strings.Contains(diags[0].Message, tt.want) {
+				t.Errorf("got %s, want line %d containing %q", diags[0], tt.line, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing file is an error", func(t *testing.T) {
+		if _, err := CheckProjectConfig(filepath.Join(t.TempDir(), ".chronicle")); err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+}
+
+func TestKeyLine(t *testing.T) {
+	data := []byte("{\n  \"charm_host\": \"x\",\n  \"hooks\": {}\n}\n")
+	if got := KeyLine(data, "hooks"); got != 3 {
+		t.Errorf("json: got %d, want 3", got)
+	}
+	data = []byte("log_dir = \"logs\"\n\n[[tag_rules]]\ntags = [\"a\"]\n")
+	if got := KeyLine(data, "tag_rules"); got != 3 {
+		t.Errorf("toml table: got %d, want 3", got)
+	}
+	if got := KeyLine(data, "missing"); got != 0 {
+		t.Errorf("missing: got %d, want 0", got)
+	}
+}
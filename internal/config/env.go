@@ -0,0 +1,97 @@
+// ABOUTME: CHRONICLE_* environment variable overrides for configuration
+// ABOUTME: Layers the environment over config files; command-line flags layer over both
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts every chronicle environment variable.
+const EnvPrefix = "CHRONICLE_"
+
+// EnvSetting ties an environment variable to the setting it overrides.
+type EnvSetting struct {
+	// Env is the variable name, e.g. CHRONICLE_SYNC_SERVER
+	Env string
+	// Set parses the variable's value and stores it in the setting
+	Set func(value string) error
+}
+
+// ApplyEnv overrides each setting whose variable is set and not empty.
+// Precedence is flags, then the environment, then the config file; flags
+// are applied by their commands after this.
+func ApplyEnv(settings []EnvSetting) error {
+	for _, s := range settings {
+		value, ok := os.LookupEnv(s.Env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := s.Set(value); err != nil {
+			return fmt.Errorf("invalid %s: %w", s.Env, err)
+		}
+	}
+	return nil
+}
+
+// EnvString stores the value as is.
+func EnvString(p *string) func(string) error {
+	return func(value string) error {
+		*p = value
+		return nil
+	}
+}
+
+// EnvBool accepts the values strconv.ParseBool does (1, true, 0, false, ...).
+func EnvBool(p *bool) func(string) error {
+	return func(value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		*p = b
+		return nil
+	}
+}
+
+// EnvInt accepts a whole number.
+func EnvInt(p *int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		*p = n
+		return nil
+	}
+}
+
+// EnvDuration accepts a Go duration such as 30m or 1h.
+func EnvDuration(p *time.Duration) func(string) error {
+	return func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 30m or 1h", value)
+		}
+		*p = d
+		return nil
+	}
+}
+
+// EnvList accepts a comma-separated list, ignoring blank items.
+func EnvList(p *[]string) func(string) error {
+	return func(value string) error {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*p = items
+		return nil
+	}
+}
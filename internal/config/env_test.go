@@ -0,0 +1,66 @@
+// ABOUTME: Tests for CHRONICLE_* environment overrides
+// ABOUTME: Validates unset variables, parsing, and invalid values
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyEnv(t *testing.T) {
+	t.Run("leaves unset and empty variables alone", func(t *testing.T) {
+		t.Setenv("CHRONICLE_TEST_STRING", "")
+		s := "from-file"
+		if err := ApplyEnv([]EnvSetting{
+			{Env: "CHRONICLE_TEST_STRING", Set: EnvString(&s)},
+			{Env: "CHRONICLE_TEST_UNSET", Set: EnvString(&s)},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if s != "from-file" {
+			t.Errorf("got %q, want from-file", s)
+		}
+	})
+
+	t.Run("parses each kind", func(t *testing.T) {
+		t.Setenv("CHRONICLE_TEST_STRING", "env")
+		t.Setenv("CHRONICLE_TEST_BOOL", "true")
+		t.Setenv("CHRONICLE_TEST_INT", "42")
+		t.Setenv("CHRONICLE_TEST_DURATION", "90m")
+		t.Setenv("CHRONICLE_TEST_LIST", "a, b,,c")
+
+		var (
+			s string
+			b bool
+			n int
+			d time.Duration
+			l []string
+		)
+		if err := ApplyEnv([]EnvSetting{
+			{Env: "CHRONICLE_TEST_STRING", Set: EnvString(&s)},
+			{Env: "CHRONICLE_TEST_BOOL", Set: EnvBool(&b)},
+			{Env: "CHRONICLE_TEST_INT", Set: EnvInt(&n)},
+			{Env: "CHRONICLE_TEST_DURATION", Set: EnvDuration(&d)},
+			{Env: "CHRONICLE_TEST_LIST", Set: EnvList(&l)},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if s != "env" || !b || n != 42 || d != 90*time.Minute {
+			t.Errorf("got %q %v %d %v", s, b, n, d)
+		}
+		if want := []string{"a", "b", "c"}; !reflect.DeepEqual(l, want) {
+			t.Errorf("got %v, want %v", l, want)
+		}
+	})
+
+	t.Run("names the variable in errors", func(t *testing.T) {
+		t.Setenv("CHRONICLE_TEST_INT", "lots")
+		var n int
+		err := ApplyEnv([]EnvSetting{{Env: "CHRONICLE_TEST_INT", Set: EnvInt(&n)}})
+		if err == nil || !strings.Contains(err.Error(), "CHRONICLE_TEST_INT") {
+			t.Errorf("got %v, want error naming CHRONICLE_TEST_INT", err)
+		}
+	})
+}
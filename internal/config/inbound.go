@@ -0,0 +1,382 @@
+// ABOUTME: Inbound webhook mappings from arbitrary JSON payloads to entries
+// ABOUTME: Each field is a JSONPath like $.head_commit.message or a text/template over the payload
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// InboundHook turns a payload posted to the REST server's /hooks/<name>
+// into an entry. Every field is either a JSONPath starting with $, such as
+// $.commits[0].message, or a text/template over the payload, such as
+// "{{.repository.name}}: {{.action}}"; text without either is used as is.
+type InboundHook struct {
+	// Message is the entry's message and must not come out empty
+	Message string `json:"message"`
+	// Type is the entry's type, one of the entry types or empty
+	Type string `json:"type,omitempty"`
+	// Tags are added to the entry; a path that finds a list adds each item
+	Tags []string `json:"tags,omitempty"`
+	// Metadata are added to the entry's metadata; empty values are left out
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// When, if set, skips payloads for which it's empty, false, or 0, such
+	// as CI runs that passed
+	When string `json:"when,omitempty"`
+}
+
+// inboundHookName is what a hook's name may contain, since it's part of
+// the URL.
+var inboundHookName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// HookEntry is the entry an inbound hook maps a payload to.
+type HookEntry struct {
+	Message  string
+	Type     string
+	Tags     []string
+	Metadata map[string]string
+}
+
+// CompiledInboundHook is an InboundHook ready to map payloads.
+type CompiledInboundHook struct {
+	message  hookField
+	kind     hookField
+	tags     []hookField
+	metadata map[string]hookField
+	when     *hookField
+}
+
+// CompileInboundHooks checks and compiles hooks by name.
+func CompileInboundHooks(hooks map[string]InboundHook) (map[string]*CompiledInboundHook, error) {
+	names := make([]string, 0, len(hooks))
+	for name := range hooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	compiled := make(map[string]*CompiledInboundHook, len(hooks))
+	for _, name := range names {
+		if !inboundHookName.MatchString(name) {
+			return nil, fmt.Errorf("hook name %q must be lowercase letters, digits, - and _", name)
+		}
+		c, err := compileInboundHook(hooks[name])
+		if err != nil {
+			return nil, fmt.Errorf("hook %s: %w", name, err)
+		}
+		compiled[name] = c
+	}
+	return compiled, nil
+}
+
+func compileInboundHook(h InboundHook) (*CompiledInboundHook, error) {
+	if strings.TrimSpace(h.Message) == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	c := &CompiledInboundHook{metadata: make(map[string]hookField, len(h.Metadata))}
+	var err error
+	if c.message, err = compileHookField("message", h.Message); err != nil {
+		return nil, err
+	}
+	if c.kind, err = compileHookField("type", h.Type); err != nil {
+		return nil, err
+	}
+	for i, tag := range h.Tags {
+		f, err := compileHookField(fmt.Sprintf("tags[%d]", i), tag)
+		if err != nil {
+			return nil, err
+		}
+		c.tags = append(c.tags, f)
+	}
+	for key, value := range h.Metadata {
+		if key == "" {
+			return nil, fmt.Errorf("metadata keys cannot be empty")
+		}
+		if c.metadata[key], err = compileHookField("metadata."+key, value); err != nil {
+			return nil, err
+		}
+	}
+	if h.When != "" {
+		when, err := compileHookField("when", h.When)
+		if err != nil {
+			return nil, err
+		}
+		c.when = &when
+	}
+	return c, nil
+}
+
+// Map turns payload, decoded JSON, into an entry. It returns false when the
+// hook's when condition skips the payload.
+func (c *CompiledInboundHook) Map(payload any) (HookEntry, bool, error) {
+	if c.when != nil {
+		values, err := c.when.values(payload)
+		if err != nil {
+			return HookEntry{}, false, err
+		}
+		if !truthy(values) {
+			return HookEntry{}, false, nil
+		}
+	}
+
+	var entry HookEntry
+	var err error
+	if entry.Message, err = c.message.value(payload); err != nil {
+		return HookEntry{}, false, err
+	}
+	if entry.Message == "" {
+		return HookEntry{}, false, fmt.Errorf("payload gave an empty message")
+	}
+	if entry.Type, err = c.kind.value(payload); err != nil {
+		return HookEntry{}, false, err
+	}
+	for _, f := range c.tags {
+		values, err := f.values(payload)
+		if err != nil {
+			return HookEntry{}, false, err
+		}
+		for _, v := range values {
+			if v != "" {
+				entry.Tags = append(entry.Tags, v)
+			}
+		}
+	}
+	for key, f := range c.metadata {
+		v, err := f.value(payload)
+		if err != nil {
+			return HookEntry{}, false, err
+		}
+		if v == "" {
+			continue
+		}
+		if entry.Metadata == nil {
+			entry.Metadata = make(map[string]string)
+		}
+		entry.Metadata[key] = v
+	}
+	return entry, true, nil
+}
+
+// truthy reports whether a when condition's values let a payload through.
+func truthy(values []string) bool {
+	for _, v := range values {
+		switch strings.ToLower(v) {
+		case "", "false", "0", "null":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// hookField is one compiled mapping: a JSONPath, a template, or neither
+// for an empty field.
+type hookField struct {
+	path []pathStep
+	tmpl *template.Template
+}
+
+// hookTemplateFuncs are the helpers available to hook templates.
+var hookTemplateFuncs = template.FuncMap{
+	"join": func(elems []any, sep string) string {
+		return strings.Join(stringValues(elems), sep)
+	},
+	"firstLine": func(s string) string {
+		line, _, _ := strings.Cut(s, "\n")
+		return strings.TrimSpace(line)
+	},
+	"default": func(fallback string, v any) string {
+		if s := stringValue(v); s != "" {
+			return s
+		}
+		return fallback
+	},
+}
+
+func compileHookField(name, text string) (hookField, error) {
+	switch {
+	case text == "":
+		return hookField{}, nil
+	case strings.HasPrefix(text, "$") && !strings.Contains(text, "{{"):
+		steps, err := parseJSONPath(text)
+		if err != nil {
+			return hookField{}, fmt.Errorf("%s: %w", name, err)
+		}
+		return hookField{path: steps}, nil
+	}
+	tmpl, err := template.New(name).Funcs(hookTemplateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return hookField{}, fmt.Errorf("%s: invalid template: %w", name, err)
+	}
+	return hookField{tmpl: tmpl}, nil
+}
+
+// values renders the field for payload. A path may find several values;
+// a template always gives one.
+func (f hookField) values(payload any) ([]string, error) {
+	switch {
+	case f.path != nil:
+		return stringValues(evalJSONPath(f.path, payload)), nil
+	case f.tmpl != nil:
+		var sb strings.Builder
+		if err := f.tmpl.Execute(&sb, payload); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", f.tmpl.Name(), err)
+		}
+		// Keys missing from a map render as "<no value>" even with
+		// missingkey=zero
+		return []string{strings.TrimSpace(strings.ReplaceAll(sb.String(), "<no value>", ""))}, nil
+	}
+	return nil, nil
+}
+
+// value renders the field as one string, joining a path's values.
+func (f hookField) value(payload any) (string, error) {
+	values, err := f.values(payload)
+	return strings.Join(values, ", "), err
+}
+
+// pathStep is one step of a JSONPath: a key, an index, or * for every
+// item.
+type pathStep struct {
+	key   string
+	index int
+	all   bool
+	isKey bool
+}
+
+// parseJSONPath parses the subset of JSONPath hooks use: $ followed by
+// .key, ['key'], [n], [-n] from the end, and [*] or .* for every item.
+func parseJSONPath(expr string) ([]pathStep, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expr), "$")
+	if !ok {
+		return nil, fmt.Errorf("JSONPath %q must start with $", expr)
+	}
+	steps := []pathStep{}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			switch key {
+			case "":
+				return nil, fmt.Errorf("JSONPath %q has an empty key", expr)
+			case "*":
+				steps = append(steps, pathStep{all: true})
+			default:
+				steps = append(steps, pathStep{key: key, isKey: true})
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q has an unclosed [", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				steps = append(steps, pathStep{all: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, pathStep{key: inner[1 : len(inner)-1], isKey: true})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("JSONPath %q has an invalid index [%s]", expr, inner)
+				}
+				steps = append(steps, pathStep{index: n})
+			}
+		default:
+			return nil, fmt.Errorf("JSONPath %q: expected . or [ at %q", expr, rest)
+		}
+	}
+	return steps, nil
+}
+
+// evalJSONPath returns every value steps reach from v; missing keys and
+// out-of-range indexes reach nothing.
+func evalJSONPath(steps []pathStep, v any) []any {
+	current := []any{v}
+	for _, step := range steps {
+		var next []any
+		for _, node := range current {
+			switch n := node.(type) {
+			case map[string]any:
+				if step.all {
+					keys := make([]string, 0, len(n))
+					for k := range n {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, n[k])
+					}
+				} else if child, ok := n[step.key]; ok && step.isKey {
+					next = append(next, child)
+				}
+			case []any:
+				switch {
+				case step.all:
+					next = append(next, n...)
+				case !step.isKey:
+					i := step.index
+					if i < 0 {
+						i += len(n)
+					}
+					if i >= 0 && i < len(n) {
+						next = append(next, n[i])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	// A path ending at a list stands for its items
+	if len(current) == 1 {
+		if list, ok := current[0].([]any); ok {
+			return list
+		}
+	}
+	return current
+}
+
+// stringValues renders each of values as text, leaving out nulls.
+func stringValues(values []any) []string {
+	var out []string
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		out = append(out, stringValue(v))
+	}
+	return out
+}
+
+// stringValue renders a decoded JSON value as text: strings and numbers
+// as they are, objects and lists as compact JSON.
+func stringValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
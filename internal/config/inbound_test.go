@@ -0,0 +1,116 @@
+// ABOUTME: Tests for inbound webhook mappings
+// ABOUTME: Checks JSONPath lookups, templates, when conditions, and config validation
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const githubPush = `{
+	"ref": "refs/heads/main",
+	"repository": {"name": "chronicle", "full_name": "harper/chronicle", "topics": ["go", "cli"]},
+	"head_commit": {"message": "Fix the sync lock\n\nIt was held across retries.", "id": "abc123"},
+	"commits": [{"message": "first"}, {"message": "second"}],
+	"size": 1234567
+}`
+
+func decodePayload(t *testing.T, data string) any {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var payload any
+	if err := dec.Decode(&payload); err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestInboundHookMap(t *testing.T) {
+	hooks, err := CompileInboundHooks(map[string]InboundHook{"github": {
+		Message: `{{.repository.name}}: {{firstLine .head_commit.message}}`,
+		Type:    "deploy",
+		Tags:    []string{"github", "$.repository.topics", "$.missing"},
+		Metadata: map[string]string{
+			"commit": "$.head_commit.id",
+			"last":   "$.commits[-1].message",
+			"size":   "$.size",
+			"all":    "$.commits[*].message",
+			"branch": `{{.branch | default "none"}}`,
+			"empty":  "$.nope",
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok, err := hooks["github"].Map(decodePayload(t, githubPush))
+	if err != nil || !ok {
+		t.Fatalf("Map() = %v, %v", ok, err)
+	}
+	want := HookEntry{
+		Message: "chronicle: Fix the sync lock",
+		Type:    "deploy",
+		Tags:    []string{"github", "go", "cli"},
+		Metadata: map[string]string{
+			"commit": "abc123",
+			"last":   "second",
+			"size":   "1234567",
+			"all":    "first, second",
+			"branch": "none",
+		},
+	}
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("Map() = %+v, want %+v", entry, want)
+	}
+}
+
+func TestInboundHookWhen(t *testing.T) {
+	hooks, err := CompileInboundHooks(map[string]InboundHook{"ci": {
+		Message: "Build {{.build.number}} {{.build.status}}",
+		When:    `{{if eq .build.status "failed"}}true{{end}}`,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		payload string
+		want    bool
+	}{
+		{`{"build": {"number": 12, "status": "failed"}}`, true},
+		{`{"build": {"number": 13, "status": "passed"}}`, false},
+	}
+	for _, tt := range tests {
+		entry, ok, err := hooks["ci"].Map(decodePayload(t, tt.payload))
+		if err != nil || ok != tt.want {
+			t.Errorf("Map(%s) = %v, %v; want %v", tt.payload, ok, err, tt.want)
+		}
+		if ok && entry.Message != "Build 12 failed" {
+			t.Errorf("message = %q", entry.Message)
+		}
+	}
+
+	if _, _, err := hooks["ci"].Map(decodePayload(t, `{}`)); err == nil {
+		t.Error("expected an error for a payload without a message")
+	}
+}
+
+func TestCompileInboundHooksErrors(t *testing.T) {
+	tests := map[string]map[string]InboundHook{
+		"bad name":      {"CI Alerts": {Message: "x"}},
+		"no message":    {"ci": {Type: "deploy"}},
+		"unclosed [":    {"ci": {Message: "$.commits[0"}},
+		"bad index":     {"ci": {Message: "$.commits[first]"}},
+		"bad template":  {"ci": {Message: "{{.build"}},
+		"empty key":     {"ci": {Message: "$..build"}},
+		"bad tag":       {"ci": {Message: "x", Tags: []string{"{{end}}"}}},
+		"empty meta":    {"ci": {Message: "x", Metadata: map[string]string{"": "y"}}},
+		"bad condition": {"ci": {Message: "x", When: "$.status[0"}},
+	}
+	for name, hooks := range tests {
+		if _, err := CompileInboundHooks(hooks); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// ABOUTME: Chat notifications posting selected entries to a team channel
+// ABOUTME: Shared by the global JSON config and project .chronicle TOML files
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Notification services.
+const (
+	NotifySlack   = "slack"
+	NotifyDiscord = "discord"
+)
+
+// NotifyServices lists every notification service.
+var NotifyServices = []string{NotifySlack, NotifyDiscord}
+
+// Notification posts new entries matching Tags or Types to a chat service's
+// incoming webhook URL.
+type Notification struct {
+	// Service is the chat service behind URL: "slack" or "discord"
+	Service string `json:"service" toml:"service"`
+	URL     string `json:"url" toml:"url"`
+
+	// Tags and Types select the entries posted: an entry with one of the
+	// tags or one of the types is sent. Every entry is sent when both are
+	// empty.
+	Tags  []string `json:"tags,omitempty" toml:"tags"`
+	Types []string `json:"types,omitempty" toml:"types"`
+
+	// Profiles limits the notification to these profiles ("default" for
+	// the default one); all profiles when empty
+	Profiles []string `json:"profiles,omitempty" toml:"profiles"`
+}
+
+// Validate checks the notification's service and URL.
+func (n Notification) Validate() error {
+	if !contains(NotifyServices, n.Service) {
+		return fmt.Errorf("unknown service %q (valid: %v)", n.Service, NotifyServices)
+	}
+	u, err := url.Parse(n.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q must be an http or https URL", n.URL)
+	}
+	return nil
+}
+
+// ValidateNotifications checks every notification, naming the first bad one.
+func ValidateNotifications(notifications []Notification) error {
+	for i, n := range notifications {
+		if err := n.Validate(); err != nil {
+			return fmt.Errorf("notifications[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Wants reports whether an entry of entryType with tags, written under
+// profile, should be posted.
+func (n Notification) Wants(profile, entryType string, tags []string) bool {
+	if profile == "" {
+		profile = "default"
+	}
+	if len(n.Profiles) > 0 && !contains(n.Profiles, profile) {
+		return false
+	}
+	if len(n.Tags) == 0 && len(n.Types) == 0 {
+		return true
+	}
+	if contains(n.Types, entryType) {
+		return true
+	}
+	for _, tag := range n.Tags {
+		if contains(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// ABOUTME: Path resolution for the local database and recorded working directories
+// ABOUTME: Applies CHRONICLE_DB override and canonicalizes directories with optional ~ form
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DBPathEnv overrides the database location from the environment.
+const DBPathEnv = "CHRONICLE_DB"
+
+// ResolveDBDir returns the directory that holds the local database.
+// CHRONICLE_DB takes precedence over the configured value; a leading ~ is
+// expanded to the home directory. Returns empty string when neither is set,
+// meaning the storage library's default location should be used.
+func ResolveDBDir(configured string) string {
+	dir := configured
+	if env := os.Getenv(DBPathEnv); env != "" {
+		dir = env
+	}
+	if dir == "" {
+		return ""
+	}
+	return ExpandHome(dir)
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// CanonicalDir resolves symlinks in dir so the same directory reached via
+// different mount points or links records identically. If homeRelative is
+// set, a path inside the home directory is rewritten as ~/... so it groups
+// together across machines with different user names. Paths that cannot be
+// resolved are returned cleaned but otherwise unchanged.
+func CanonicalDir(dir string, homeRelative bool) string {
+	if dir == "" || dir == "~" || strings.HasPrefix(dir, "~/") {
+		return dir
+	}
+
+	canonical := filepath.Clean(dir)
+	if resolved, err := filepath.EvalSymlinks(canonical); err == nil {
+		canonical = resolved
+	}
+	if !homeRelative {
+		return canonical
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return canonical
+	}
+	if resolved, err := filepath.EvalSymlinks(home); err == nil {
+		home = resolved
+	}
+	rel, err := filepath.Rel(home, canonical)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return canonical
+	}
+	if rel == "." {
+		return "~"
+	}
+	return "~/" + filepath.ToSlash(rel)
+}
+
+// DirWithin reports whether dir is root or below it. A leading ~ in either
+// is expanded, so entries recorded home-relative still match an absolute
+// root. Neither path is resolved further; both should already be canonical.
+func DirWithin(dir, root string) bool {
+	if dir == "" || root == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(ExpandHome(root)), filepath.Clean(ExpandHome(dir)))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,107 @@
+// ABOUTME: Tests for database location resolution
+// ABOUTME: Validates env precedence and home directory expansion
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveDBDir(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	t.Run("empty when nothing configured", func(t *testing.T) {
+		t.Setenv(DBPathEnv, "")
+		if got := ResolveDBDir(""); got != "" {
+			t.Errorf("got %q, want empty", got)
+		}
+	})
+
+	t.Run("uses configured value", func(t *testing.T) {
+		t.Setenv(DBPathEnv, "")
+		if got := ResolveDBDir("/data/chronicle"); got != "/data/chronicle" {
+			t.Errorf("got %q, want /data/chronicle", got)
+		}
+	})
+
+	t.Run("env overrides config", func(t *testing.T) {
+		t.Setenv(DBPathEnv, "/env/chronicle")
+		if got := ResolveDBDir("/data/chronicle"); got != "/env/chronicle" {
+			t.Errorf("got %q, want /env/chronicle", got)
+		}
+	})
+
+	t.Run("expands home", func(t *testing.T) {
+		t.Setenv(DBPathEnv, "")
+		want := filepath.Join(home, "sync", "chronicle")
+		if got := ResolveDBDir("~/sync/chronicle"); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestCanonicalDir(t *testing.T) {
+	tmp := t.TempDir()
+	realDir := filepath.Join(tmp, "real")
+	if err := os.Mkdir(realDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(tmp, "link")
+	if err := os.Symlink(realDir, link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+	wantReal, _ := filepath.EvalSymlinks(realDir)
+
+	t.Run("resolves symlinks", func(t *testing.T) {
+		if got := CanonicalDir(link, false); got != wantReal {
+			t.Errorf("got %q, want %q", got, wantReal)
+		}
+	})
+
+	t.Run("keeps unresolvable paths", func(t *testing.T) {
+		if got := CanonicalDir("/does/not/exist/", false); got != "/does/not/exist" {
+			t.Errorf("got %q, want /does/not/exist", got)
+		}
+	})
+
+	t.Run("rewrites paths under home", func(t *testing.T) {
+		t.Setenv("HOME", tmp)
+		if got := CanonicalDir(link, true); got != "~/real" {
+			t.Errorf("got %q, want ~/real", got)
+		}
+		if got := CanonicalDir(tmp, true); got != "~" {
+			t.Errorf("got %q, want ~", got)
+		}
+	})
+
+	t.Run("leaves paths outside home absolute", func(t *testing.T) {
+		t.Setenv("HOME", realDir)
+		if got := CanonicalDir(tmp, true); got == "~" || got[0] == '~' {
+			t.Errorf("got %q, want absolute path", got)
+		}
+	})
+}
+
+func TestDirWithin(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	tests := []struct {
+		dir, root string
+		want      bool
+	}{
+		{"/home/u/repo", "/home/u/repo", true},
+		{"/home/u/repo/sub/dir", "/home/u/repo", true},
+		{"~/repo/sub", "/home/u/repo", true},
+		{"/home/u/repo2", "/home/u/repo", false},
+		{"/home/u", "/home/u/repo", false},
+		{"", "/home/u/repo", false},
+	}
+	for _, tt := range tests {
+		if got := DirWithin(tt.dir, tt.root); got != tt.want {
+			t.Errorf("DirWithin(%q, %q) = %v, want %v", tt.dir, tt.root, got, tt.want)
+		}
+	}
+}
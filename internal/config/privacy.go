@@ -0,0 +1,136 @@
+// ABOUTME: Privacy rules that suppress or hash entry metadata by directory
+// ABOUTME: Keeps sensitive working directories and hostnames out of stored and synced entries
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Privacy actions for a PrivacyRule field. Empty keeps the value.
+const (
+	PrivacySuppress = "suppress"
+	PrivacyHash     = "hash"
+)
+
+// PrivacyRule changes what is recorded for entries created in a directory
+// matching any of Paths. Paths are globs where ~ is the home directory, *
+// and ? match within one path element, and ** matches any depth; a
+// trailing /** also matches the directory itself.
+type PrivacyRule struct {
+	Paths            []string `json:"paths"`
+	WorkingDirectory string   `json:"working_directory,omitempty"`
+	Hostname         string   `json:"hostname,omitempty"`
+}
+
+// CompiledPrivacyRule is a PrivacyRule ready to match directories.
+type CompiledPrivacyRule struct {
+	PrivacyRule
+	res []*regexp.Regexp
+}
+
+// CompilePrivacyRules checks and compiles rules. A rule needs at least one
+// path and an action for the working directory or hostname.
+func CompilePrivacyRules(rules []PrivacyRule) ([]CompiledPrivacyRule, error) {
+	compiled := make([]CompiledPrivacyRule, 0, len(rules))
+	for i, rule := range rules {
+		if len(rule.Paths) == 0 {
+			return nil, fmt.Errorf("privacy rule %d has no paths", i+1)
+		}
+		for _, field := range [][2]string{{"working_directory", rule.WorkingDirectory}, {"hostname", rule.Hostname}} {
+			if action := field[1]; action != "" && action != PrivacySuppress && action != PrivacyHash {
+				return nil, fmt.Errorf("privacy rule %d: %s must be %s or %s, not %q", i+1, field[0], PrivacySuppress, PrivacyHash, action)
+			}
+		}
+		if rule.WorkingDirectory == "" && rule.Hostname == "" {
+			return nil, fmt.Errorf("privacy rule %d needs working_directory or hostname", i+1)
+		}
+		c := CompiledPrivacyRule{PrivacyRule: rule}
+		for _, path := range rule.Paths {
+			re, err := globRegexp(filepath.ToSlash(filepath.Clean(ExpandHome(path))))
+			if err != nil {
+				return nil, fmt.Errorf("privacy rule %d: invalid path %q: %w", i+1, path, err)
+			}
+			c.res = append(c.res, re)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// globRegexp translates a path glob to an anchored regular expression.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	rest := glob
+	suffix := "$"
+	if strings.HasSuffix(rest, "/**") {
+		rest = strings.TrimSuffix(rest, "/**")
+		suffix = "(/.*)?$"
+	}
+	for i := 0; i < len(rest); i++ {
+		switch {
+		case strings.HasPrefix(rest[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case rest[i] == '*':
+			b.WriteString("[^/]*")
+		case rest[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(rest[i : i+1]))
+		}
+	}
+	b.WriteString(suffix)
+	return regexp.Compile(b.String())
+}
+
+// Matches reports whether the rule applies to entries created in dir.
+func (r CompiledPrivacyRule) Matches(dir string) bool {
+	dir = filepath.ToSlash(filepath.Clean(ExpandHome(dir)))
+	for _, re := range r.res {
+		if re.MatchString(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyPrivacy returns the working directory and hostname to record for an
+// entry created in dir on hostname, after every rule matching dir (or its
+// canonical form) is applied. Suppressed values are empty; hashed ones are
+// "hash:" and a short SHA-256 digest, so entries from the same place still
+// group together.
+func ApplyPrivacy(rules []CompiledPrivacyRule, dir, hostname string) (string, string) {
+	canonical := CanonicalDir(dir, false)
+	workingDir := dir
+	for _, rule := range rules {
+		if !rule.Matches(dir) && !rule.Matches(canonical) {
+			continue
+		}
+		workingDir = redact(rule.WorkingDirectory, workingDir, canonical)
+		hostname = redact(rule.Hostname, hostname, hostname)
+	}
+	return workingDir, hostname
+}
+
+// redact applies action to value; hashing uses hashSource so the same
+// directory hashes alike however it was reached.
+func redact(action, value, hashSource string) string {
+	switch action {
+	case PrivacySuppress:
+		return ""
+	case PrivacyHash:
+		if value == "" || strings.HasPrefix(value, "hash:") {
+			return value
+		}
+		sum := sha256.Sum256([]byte(hashSource))
+		return "hash:" + hex.EncodeToString(sum[:8])
+	default:
+		return value
+	}
+}
@@ -0,0 +1,71 @@
+// ABOUTME: Tests for privacy rules
+// ABOUTME: Validates glob matching, suppression, hashing, and rule checks
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompilePrivacyRules(t *testing.T) {
+	bad := []PrivacyRule{
+		{WorkingDirectory: PrivacySuppress},
+		{Paths: []string{"/x"}},
+		{Paths: []string{"/x"}, Hostname: "scramble"},
+	}
+	for _, rule := range bad {
+		if _, err := CompilePrivacyRules([]PrivacyRule{rule}); err == nil {
+			t.Errorf("CompilePrivacyRules(%+v): expected error", rule)
+		}
+	}
+}
+
+func TestPrivacyRuleMatches(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	rules, err := CompilePrivacyRules([]PrivacyRule{
+		{Paths: []string{"~/clients/secret-co/**", "/srv/*/private"}, WorkingDirectory: PrivacySuppress},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]bool{
+		filepath.Join(home, "clients", "secret-co"):             true,
+		filepath.Join(home, "clients", "secret-co", "api", "x"): true,
+		"~/clients/secret-co/web":                               true,
+		filepath.Join(home, "clients", "secret-co-2"):           false,
+		"/srv/acme/private":                                     true,
+		"/srv/acme/deep/private":                                false,
+	}
+	for dir, want := range tests {
+		if got := rules[0].Matches(dir); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func TestApplyPrivacy(t *testing.T) {
+	rules, err := CompilePrivacyRules([]PrivacyRule{
+		{Paths: []string{"/work/secret/**"}, WorkingDirectory: PrivacyHash, Hostname: PrivacySuppress},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, host := ApplyPrivacy(rules, "/work/public", "laptop")
+	if dir != "/work/public" || host != "laptop" {
+		t.Errorf("unmatched dir changed: %q %q", dir, host)
+	}
+
+	dir, host = ApplyPrivacy(rules, "/work/secret/api", "laptop")
+	if !strings.HasPrefix(dir, "hash:") || host != "" {
+		t.Errorf("got %q %q, want hashed dir and no hostname", dir, host)
+	}
+	if again, _ := ApplyPrivacy(rules, "/work/secret/api", "laptop"); again != dir {
+		t.Errorf("hash not stable: %q then %q", dir, again)
+	}
+}
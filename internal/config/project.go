@@ -3,8 +3,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
@@ -13,50 +15,279 @@ type ProjectConfig struct {
 	LocalLogging bool   `toml:"local_logging"`
 	LogDir       string `toml:"log_dir"`
 	LogFormat    string `toml:"log_format"`
+	LogRotation  string `toml:"log_rotation"`
+
+	// ObsidianVault, if set, is where entries are written instead of
+	// LogDir: an Obsidian vault or its daily notes folder
+	ObsidianVault string `toml:"obsidian_vault"`
+
+	// LogLatest keeps latest.log in the log directory pointing at the
+	// newest log file
+	LogLatest bool `toml:"log_latest"`
+
+	// LogIndex keeps INDEX.md in the log directory up to date on every write
+	LogIndex bool `toml:"log_index"`
+
+	// LogTemplate is a text/template for markdown entries; LogTemplateFile
+	// names a file holding one, relative to the project root
+	LogTemplate     string `toml:"log_template"`
+	LogTemplateFile string `toml:"log_template_file"`
+
+	// DefaultTags are added to every entry created inside the project
+	DefaultTags []string `toml:"default_tags"`
+	// TagPrefix is put in front of an entry's own tags (not DefaultTags)
+	TagPrefix string `toml:"tag_prefix"`
+
+	// Database, if set, keeps the project's entries in their own store in
+	// this directory (relative to the project root) instead of the global one
+	Database string `toml:"database"`
+	// DatabaseProfile names that store's journal, which syncs separately
+	// from others (default: "project-" and the project directory's name)
+	DatabaseProfile string `toml:"database_profile"`
+
+	// TagRules add to the global auto-tagging rules inside this project
+	TagRules []TagRule `toml:"tag_rules"`
+
+	// Notifications post this project's entries to chat, alongside any in
+	// the global config
+	Notifications []Notification `toml:"notifications"`
 }
 
 // FindProjectRoot walks up from dir looking for .chronicle file
 // Returns empty string if not found.
 func FindProjectRoot(dir string) (string, error) {
-	absDir, err := filepath.Abs(dir)
+	return findMarker(dir, ".chronicle")
+}
+
+// FindProjectRootOrGit is FindProjectRoot, falling back to the nearest
+// directory holding .git (a directory, or a file in worktrees and
+// submodules) when no .chronicle file is found.
+func FindProjectRootOrGit(dir string) (string, error) {
+	root, err := FindProjectRoot(dir)
+	if err != nil || root != "" {
+		return root, err
+	}
+	return findMarker(dir, ".git")
+}
+
+// FindProjectConfigs returns every .chronicle file from dir up to the home
+// directory, outermost first, so nested projects can layer their settings.
+func FindProjectConfigs(dir string) ([]string, error) {
+	dirs, err := searchDirs(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for i := len(dirs) - 1; i >= 0; i-- {
+		path := filepath.Join(dirs[i], ".chronicle")
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// findMarker walks up from dir to the first directory containing marker,
+// stopping at the home directory. Returns empty string if not found.
+func findMarker(dir, marker string) (string, error) {
+	dirs, err := searchDirs(dir)
 	if err != nil {
 		return "", err
 	}
+	for _, d := range dirs {
+		if _, err := os.Stat(filepath.Join(d, marker)); err == nil {
+			return d, nil
+		}
+	}
+	return "", nil
+}
+
+// searchDirs returns dir and its parents, nearest first, up to the home
+// directory or the filesystem root.
+func searchDirs(dir string) ([]string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
 
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	var dirs []string
 	current := absDir
 	for {
-		chroniclePath := filepath.Join(current, ".chronicle")
-		if _, err := os.Stat(chroniclePath); err == nil {
-			return current, nil
-		}
+		dirs = append(dirs, current)
 
 		parent := filepath.Dir(current)
 
 		// Stop at filesystem root or home directory
 		if parent == current || current == homeDir {
-			return "", nil
+			return dirs, nil
 		}
 
 		current = parent
 	}
 }
 
-// LoadProjectConfig loads .chronicle config from path.
+// LoadProjectConfig loads .chronicle config from path, layered over any
+// .chronicle files in the directories above it: each file overrides the
+// keys it sets. Relative paths from a parent file stay relative to that
+// file's directory. A missing file (a project found by its .git directory)
+// gives the defaults plus whatever its parents set.
 func LoadProjectConfig(path string) (*ProjectConfig, error) {
 	var cfg ProjectConfig
 
 	// Set defaults
 	cfg.LogDir = "logs"
 	cfg.LogFormat = "markdown"
+	cfg.LogRotation = "daily"
+
+	parents, err := FindProjectConfigs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	for _, parent := range parents {
+		if sameFile(parent, path) {
+			continue
+		}
+		if err := cfg.decodeParent(parent); err != nil {
+			return nil, err
+		}
+	}
 
-	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &cfg, nil
+		}
 		return nil, err
 	}
+	if md.IsDefined("database") && !md.IsDefined("database_profile") {
+		cfg.DatabaseProfile = ""
+	}
 
 	return &cfg, nil
 }
+
+// decodeParent layers the parent .chronicle file at path onto c, pinning
+// its relative paths and default database profile to the parent's directory.
+func (c *ProjectConfig) decodeParent(path string) error {
+	md, err := toml.DecodeFile(path, c)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	for key, p := range map[string]*string{
+		"log_dir":           &c.LogDir,
+		"log_template_file": &c.LogTemplateFile,
+		"obsidian_vault":    &c.ObsidianVault,
+		"database":          &c.Database,
+	} {
+		if md.IsDefined(key) && *p != "" && !filepath.IsAbs(ExpandHome(*p)) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	if md.IsDefined("database") && !md.IsDefined("database_profile") {
+		// Named after this file's directory, not an inherited profile
+		c.DatabaseProfile = ""
+		c.DatabaseProfile = c.DatabaseName(dir)
+	}
+	return nil
+}
+
+// sameFile reports whether a and b name the same path once made absolute.
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// ApplyTags returns tags with TagPrefix put in front of any that lack it,
+// followed by the DefaultTags not already present.
+func (c *ProjectConfig) ApplyTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		if tag != "" && !seen[strings.ToLower(tag)] {
+			seen[strings.ToLower(tag)] = true
+			out = append(out, tag)
+		}
+	}
+	prefix := strings.ToLower(c.TagPrefix)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !strings.HasPrefix(strings.ToLower(tag), prefix) {
+			tag = c.TagPrefix + tag
+		}
+		add(tag)
+	}
+	for _, tag := range c.DefaultTags {
+		add(strings.TrimSpace(tag))
+	}
+	return out
+}
+
+// DatabaseDir returns the directory holding the project's own store, or ""
+// if it uses the global one.
+func (c *ProjectConfig) DatabaseDir(root string) string {
+	if c.Database == "" {
+		return ""
+	}
+	dir := ExpandHome(c.Database)
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(root, dir)
+}
+
+// DatabaseName returns the profile name of the project's own store:
+// DatabaseProfile, or "project-" and root's base name made safe for
+// database names.
+func (c *ProjectConfig) DatabaseName(root string) string {
+	if c.DatabaseProfile != "" {
+		return c.DatabaseProfile
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, strings.ToLower(filepath.Base(root)))
+	return "project-" + name
+}
+
+// LogPath returns the directory the project's log files go in: LogDir, or
+// the Obsidian vault if set, resolved against root if relative.
+func (c *ProjectConfig) LogPath(root string) string {
+	dir := c.LogDir
+	if c.ObsidianVault != "" {
+		dir = c.ObsidianVault
+	}
+	dir = ExpandHome(dir)
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(root, dir)
+}
+
+// EntryTemplate returns the project's markdown entry template text, or ""
+// for the built-in layout. A template file is resolved against root and
+// takes precedence over an inline template.
+func (c *ProjectConfig) EntryTemplate(root string) (string, error) {
+	if c.LogTemplateFile == "" {
+		return c.LogTemplate, nil
+	}
+	path := ExpandHome(c.LogTemplateFile)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from the project's own config
+	if err != nil {
+		return "", fmt.Errorf("failed to read log template: %w", err)
+	}
+	return string(data), nil
+}
@@ -5,6 +5,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -70,3 +71,174 @@ log_format = "json"
 		t.Errorf("got LogFormat %s, want json", cfg.LogFormat)
 	}
 }
+
+func TestEntryTemplate(t *testing.T) {
+	root := t.TempDir()
+
+	cfg := &ProjectConfig{LogTemplate: "- {{.Message}}"}
+	if text, err := cfg.EntryTemplate(root); err != nil || text != "- {{.Message}}" {
+		t.Errorf("inline template = %q, %v", text, err)
+	}
+
+	_ = os.WriteFile(filepath.Join(root, "entry.tmpl"), []byte("* {{.Message}}\n"), 0644) //nolint:gosec // Test file permissions
+	cfg.LogTemplateFile = "entry.tmpl"
+	if text, err := cfg.EntryTemplate(root); err != nil || text != "* {{.Message}}\n" {
+		t.Errorf("template file = %q, %v; want it to win over the inline template", text, err)
+	}
+
+	cfg.LogTemplateFile = "missing.tmpl"
+	if _, err := cfg.EntryTemplate(root); err == nil {
+		t.Error("expected an error for a missing template file")
+	}
+
+	if text, err := (&ProjectConfig{}).EntryTemplate(root); err != nil || text != "" {
+		t.Errorf("no template = %q, %v; want empty", text, err)
+	}
+}
+
+func TestLogPath(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "repo")
+	vault := filepath.Join(string(filepath.Separator), "notes", "Daily")
+	tests := []struct {
+		cfg  ProjectConfig
+		want string
+	}{
+		{ProjectConfig{LogDir: "logs"}, filepath.Join(root, "logs")},
+		{ProjectConfig{LogDir: "logs", ObsidianVault: vault}, vault},
+		{ProjectConfig{LogDir: "logs", ObsidianVault: "vault"}, filepath.Join(root, "vault")},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.LogPath(root); got != tt.want {
+			t.Errorf("LogPath(%+v) = %s, want %s", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestFindProjectRootOrGit(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	repo := filepath.Join(tmpDir, "repo")
+	subDir := filepath.Join(repo, "src", "pkg")
+	_ = os.MkdirAll(filepath.Join(repo, ".git"), 0755) //nolint:gosec // Test directory permissions
+	_ = os.MkdirAll(subDir, 0755)                      //nolint:gosec // Test directory permissions
+
+	if root, err := FindProjectRoot(subDir); err != nil || root != "" {
+		t.Errorf("FindProjectRoot = %q, %v; want no project without .chronicle", root, err)
+	}
+	if root, err := FindProjectRootOrGit(subDir); err != nil || root != repo {
+		t.Errorf("FindProjectRootOrGit = %q, %v; want %s", root, err, repo)
+	}
+
+	// Worktrees and submodules have a .git file instead of a directory
+	worktree := filepath.Join(tmpDir, "worktree")
+	_ = os.MkdirAll(worktree, 0755)                                                         //nolint:gosec // Test directory permissions
+	_ = os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: ../repo/.git"), 0644) //nolint:gosec // Test file permissions
+	if root, err := FindProjectRootOrGit(worktree); err != nil || root != worktree {
+		t.Errorf("FindProjectRootOrGit = %q, %v; want %s", root, err, worktree)
+	}
+
+	// A .chronicle file anywhere above wins over a nearer repository
+	_ = os.WriteFile(filepath.Join(tmpDir, ".chronicle"), []byte(""), 0644) //nolint:gosec // Test file permissions
+	if root, err := FindProjectRootOrGit(subDir); err != nil || root != tmpDir {
+		t.Errorf("FindProjectRootOrGit = %q, %v; want %s", root, err, tmpDir)
+	}
+}
+
+func TestLoadProjectConfigMissing(t *testing.T) {
+	cfg, err := LoadProjectConfig(filepath.Join(t.TempDir(), ".chronicle"))
+	if err != nil {
+		t.Fatalf("LoadProjectConfig failed: %v", err)
+	}
+	if cfg.LocalLogging || cfg.LogDir != "logs" || cfg.LogFormat != "markdown" {
+		t.Errorf("missing config should give the defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadProjectConfigNested(t *testing.T) {
+	root := t.TempDir()
+	pkg := filepath.Join(root, "packages", "api")
+	if err := os.MkdirAll(pkg, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	parent := `
+local_logging = true
+log_format = "jsonl"
+log_dir = "shared-logs"
+default_tags = ["monorepo"]
+database = ".chronicle-db"
+
+[[tag_rules]]
+keywords = ["deploy"]
+tags = ["ops"]
+`
+	child := `
+log_format = "markdown"
+default_tags = ["api"]
+`
+	if err := os.WriteFile(filepath.Join(root, ".chronicle"), []byte(parent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pkg, ".chronicle"), []byte(child), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := FindProjectConfigs(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0] != filepath.Join(root, ".chronicle") {
+		t.Fatalf("FindProjectConfigs = %v, want root then package", paths)
+	}
+
+	cfg, err := LoadProjectConfig(filepath.Join(pkg, ".chronicle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.LocalLogging || len(cfg.TagRules) != 1 {
+		t.Errorf("parent settings not inherited: %+v", cfg)
+	}
+	if cfg.LogFormat != "markdown" || strings.Join(cfg.DefaultTags, ",") != "api" {
+		t.Errorf("child should override parent: format %q, tags %v", cfg.LogFormat, cfg.DefaultTags)
+	}
+	if got := cfg.LogPath(pkg); got != filepath.Join(root, "shared-logs") {
+		t.Errorf("LogPath = %q, want parent's log dir", got)
+	}
+	if got := cfg.DatabaseDir(pkg); got != filepath.Join(root, ".chronicle-db") {
+		t.Errorf("DatabaseDir = %q, want parent's database", got)
+	}
+	if want := (&ProjectConfig{}).DatabaseName(root); cfg.DatabaseName(pkg) != want {
+		t.Errorf("DatabaseName = %q, want %q from the parent", cfg.DatabaseName(pkg), want)
+	}
+}
+
+func TestApplyTags(t *testing.T) {
+	cfg := &ProjectConfig{TagPrefix: "proj-", DefaultTags: []string{"chronicle", "oss"}}
+	got := cfg.ApplyTags([]string{"bug", "proj-api", "Proj-UI", " ", "oss"})
+	want := []string{"proj-bug", "proj-api", "Proj-UI", "proj-oss", "chronicle", "oss"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ApplyTags = %v, want %v", got, want)
+	}
+
+	if got := (&ProjectConfig{}).ApplyTags([]string{"a", "a"}); strings.Join(got, ",") != "a" {
+		t.Errorf("ApplyTags without settings = %v, want [a]", got)
+	}
+}
+
+func TestProjectDatabase(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "work", "Client Site")
+
+	if dir := (&ProjectConfig{}).DatabaseDir(root); dir != "" {
+		t.Errorf("DatabaseDir without database = %q, want empty", dir)
+	}
+	cfg := &ProjectConfig{Database: ".chronicle-db"}
+	if dir := cfg.DatabaseDir(root); dir != filepath.Join(root, ".chronicle-db") {
+		t.Errorf("DatabaseDir = %q", dir)
+	}
+	if name := cfg.DatabaseName(root); name != "project-client-site" {
+		t.Errorf("DatabaseName = %q, want project-client-site", name)
+	}
+	cfg.DatabaseProfile = "acme"
+	if name := cfg.DatabaseName(root); name != "acme" {
+		t.Errorf("DatabaseName = %q, want acme", name)
+	}
+}
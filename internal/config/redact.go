@@ -0,0 +1,93 @@
+// ABOUTME: Secret detection for entry messages before they're stored or synced
+// ABOUTME: Built-in detectors for common credentials plus user regexes; scrubs or refuses
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Redaction modes, chosen with redact_secrets in the config.
+const (
+	RedactScrub  = "scrub"
+	RedactRefuse = "refuse"
+	RedactOff    = "off"
+)
+
+// RedactModes lists the valid redaction modes. Empty means scrub.
+var RedactModes = []string{RedactScrub, RedactRefuse, RedactOff}
+
+// redactedText replaces each secret found when scrubbing.
+const redactedText = "[REDACTED]"
+
+// secretDetector finds one kind of secret.
+type secretDetector struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// builtinDetectors catch credentials that should never land in a journal.
+var builtinDetectors = []secretDetector{
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"aws_secret_key", regexp.MustCompile(`(?i)aws_secret_access_key\s*[=:]\s*["']?[A-Za-z0-9/+=]{40}`)},
+	{"bearer_token", regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]{16,}=*`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// Redactor scrubs or refuses messages containing secrets.
+type Redactor struct {
+	mode      string
+	detectors []secretDetector
+}
+
+// NewRedactor builds a Redactor for mode using the built-in detectors and
+// patterns, the user's own regular expressions.
+func NewRedactor(mode string, patterns []string) (*Redactor, error) {
+	if mode == "" {
+		mode = RedactScrub
+	}
+	if !contains(RedactModes, mode) {
+		return nil, fmt.Errorf("invalid redact_secrets %q (use %s)", mode, strings.Join(RedactModes, ", "))
+	}
+	r := &Redactor{mode: mode, detectors: builtinDetectors}
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redact pattern %d: %w", i+1, err)
+		}
+		r.detectors = append(r.detectors, secretDetector{name: fmt.Sprintf("pattern %d", i+1), re: re})
+	}
+	return r, nil
+}
+
+// SecretError is returned when a message with secrets is refused.
+type SecretError struct {
+	// Kinds names the detectors that matched
+	Kinds []string
+}
+
+func (e *SecretError) Error() string {
+	return fmt.Sprintf("message looks like it contains a secret (%s)", strings.Join(e.Kinds, ", "))
+}
+
+// Redact returns text with every secret replaced by [REDACTED] and the
+// kinds found. In refuse mode a message with secrets is a *SecretError
+// instead; with mode off, or a nil Redactor, text is returned unchanged.
+func (r *Redactor) Redact(text string) (string, []string, error) {
+	if r == nil || r.mode == RedactOff {
+		return text, nil, nil
+	}
+	var kinds []string
+	for _, d := range r.detectors {
+		if d.re.MatchString(text) {
+			kinds = append(kinds, d.name)
+			text = d.re.ReplaceAllString(text, redactedText)
+		}
+	}
+	if len(kinds) > 0 && r.mode == RedactRefuse {
+		return "", kinds, &SecretError{Kinds: kinds}
+	}
+	return text, kinds, nil
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Shell command capture settings for chronicle shell-integration
+// ABOUTME: Decides which finished commands are logged by duration and allow/deny patterns
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultShellThreshold is how long, in seconds, a command must run before
+// it is captured when shell_capture.threshold isn't set.
+const DefaultShellThreshold = 30
+
+// ShellCapture configures which shell commands are logged.
+type ShellCapture struct {
+	// Threshold is the seconds a command must run to be captured
+	Threshold int `json:"threshold,omitempty"`
+	// Allow, when set, captures only commands matching one of these
+	// regular expressions
+	Allow []string `json:"allow,omitempty"`
+	// Deny never captures commands matching one of these regular
+	// expressions, whatever Allow says
+	Deny []string `json:"deny,omitempty"`
+}
+
+// builtinShellDeny keeps commands that name credentials out of the journal
+// even when no deny patterns are configured.
+var builtinShellDeny = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)passw(or)?d|secret|token|api[-_]?key|credential|authorization:`),
+}
+
+// ShellFilter is a compiled ShellCapture.
+type ShellFilter struct {
+	threshold time.Duration
+	allow     []*regexp.Regexp
+	deny      []*regexp.Regexp
+}
+
+// CompileShellCapture validates sc and compiles its patterns. A nil sc
+// uses the defaults.
+func CompileShellCapture(sc *ShellCapture) (*ShellFilter, error) {
+	f := &ShellFilter{threshold: DefaultShellThreshold * time.Second, deny: builtinShellDeny}
+	if sc == nil {
+		return f, nil
+	}
+	if sc.Threshold < 0 {
+		return nil, fmt.Errorf("threshold must not be negative")
+	}
+	if sc.Threshold > 0 {
+		f.threshold = time.Duration(sc.Threshold) * time.Second
+	}
+	for _, pattern := range sc.Allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allow pattern %q: %w", pattern, err)
+		}
+		f.allow = append(f.allow, re)
+	}
+	for _, pattern := range sc.Deny {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deny pattern %q: %w", pattern, err)
+		}
+		f.deny = append(f.deny, re)
+	}
+	return f, nil
+}
+
+// Threshold is how long a command must run to be captured.
+func (f *ShellFilter) Threshold() time.Duration {
+	return f.threshold
+}
+
+// Captures reports whether command, having run for duration, should be
+// logged. Commands typed with a leading space are never captured, as with
+// the shells' own ignorespace history settings.
+func (f *ShellFilter) Captures(command string, duration time.Duration) bool {
+	if strings.TrimSpace(command) == "" || strings.HasPrefix(command, " ") || duration < f.threshold {
+		return false
+	}
+	for _, re := range f.deny {
+		if re.MatchString(command) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, re := range f.allow {
+		if re.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+// ABOUTME: Tests for shell command capture settings
+// ABOUTME: Validates thresholds, allow/deny patterns, and the built-in secret filter
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShellFilterCaptures(t *testing.T) {
+	filter, err := CompileShellCapture(&ShellCapture{
+		Threshold: 10,
+		Allow:     []string{`^(make|go|docker) `},
+		Deny:      []string{`^docker login`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		command  string
+		duration time.Duration
+		want     bool
+	}{
+		{"make build", 12 * time.Second, true},
+		{"make build", 9 * time.Second, false},
+		{"sleep 60", time.Minute, false},
+		{"docker login registry", time.Minute, false},
+		{"go test ./... -token=abc", time.Minute, false},
+		{" make release", time.Minute, false},
+		{"", time.Minute, false},
+	}
+	for _, tt := range tests {
+		if got := filter.Captures(tt.command, tt.duration); got != tt.want {
+			t.Errorf("Captures(%q, %v) = %v, want %v", tt.command, tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestCompileShellCaptureDefaults(t *testing.T) {
+	filter, err := CompileShellCapture(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Threshold() != DefaultShellThreshold*time.Second {
+		t.Errorf("threshold = %v, want %ds", filter.Threshold(), DefaultShellThreshold)
+	}
+	if !filter.Captures("npm install", time.Minute) {
+		t.Error("expected a slow command to be captured")
+	}
+	if filter.Captures("mysql --password=hunter2", time.Minute) {
+		t.Error("expected a command with a password to be skipped")
+	}
+
+	for _, bad := range []*ShellCapture{{Threshold: -1}, {Allow: []string{"("}}, {Deny: []string{"["}}} {
+		if _, err := CompileShellCapture(bad); err == nil {
+			t.Errorf("CompileShellCapture(%+v): expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// ABOUTME: Auto-tagging rules mapping keywords or regexes to tags
+// ABOUTME: Shared by the global JSON config and project .chronicle TOML files
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TagRule adds Tags to an entry whose text contains any of Keywords or
+// matches Pattern, ignoring case.
+type TagRule struct {
+	Keywords []string `json:"keywords,omitempty" toml:"keywords"`
+	Pattern  string   `json:"pattern,omitempty" toml:"pattern"`
+	Tags     []string `json:"tags" toml:"tags"`
+}
+
+// DefaultTagRules are the built-in keyword rules, applied before the
+// config's and the project's.
+var DefaultTagRules = []TagRule{
+	{Keywords: []string{"deploy", "release"}, Tags: []string{"deployment"}},
+	{Keywords: []string{"fix", "bug"}, Tags: []string{"bug-fix"}},
+	{Keywords: []string{"decid", "chose"}, Tags: []string{"decision"}},
+	{Keywords: []string{"learn", "discover"}, Tags: []string{"learning"}},
+	{Keywords: []string{"test"}, Tags: []string{"testing"}},
+}
+
+// CompiledTagRule is a TagRule ready to match text.
+type CompiledTagRule struct {
+	TagRule
+	re *regexp.Regexp
+}
+
+// CompileTagRules checks and compiles rules. A rule needs at least one tag
+// and a keyword or pattern.
+func CompileTagRules(rules []TagRule) ([]CompiledTagRule, error) {
+	compiled := make([]CompiledTagRule, 0, len(rules))
+	for i, rule := range rules {
+		if len(rule.Tags) == 0 {
+			return nil, fmt.Errorf("tag rule %d has no tags", i+1)
+		}
+		if len(rule.Keywords) == 0 && rule.Pattern == "" {
+			return nil, fmt.Errorf("tag rule %d needs keywords or a pattern", i+1)
+		}
+		c := CompiledTagRule{TagRule: rule}
+		if rule.Pattern != "" {
+			re, err := regexp.Compile("(?i)" + rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("tag rule %d: invalid pattern: %w", i+1, err)
+			}
+			c.re = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// Matches reports whether the rule applies to text.
+func (r CompiledTagRule) Matches(text string) bool {
+	lower := strings.ToLower(text)
+	for _, keyword := range r.Keywords {
+		if keyword != "" && strings.Contains(lower, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return r.re != nil && r.re.MatchString(text)
+}
@@ -0,0 +1,57 @@
+// ABOUTME: Tests for auto-tagging rules
+// ABOUTME: Covers validation, keyword and pattern matching, and loading from TOML
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompileTagRules(t *testing.T) {
+	rules, err := CompileTagRules([]TagRule{
+		{Keywords: []string{"Postgres"}, Tags: []string{"db"}},
+		{Pattern: `\bJIRA-\d+\b`, Tags: []string{"ticket"}},
+	})
+	if err != nil {
+		t.Fatalf("CompileTagRules: %v", err)
+	}
+	if !rules[0].Matches("migrated postgres to 16") || rules[0].Matches("mysql") {
+		t.Error("keyword rule should match case-insensitively and only its keyword")
+	}
+	if !rules[1].Matches("closed jira-1234") || rules[1].Matches("JIRA-abc") {
+		t.Error("pattern rule should match case-insensitively and only its pattern")
+	}
+
+	for _, bad := range []TagRule{
+		{Keywords: []string{"x"}},
+		{Tags: []string{"x"}},
+		{Pattern: "(", Tags: []string{"x"}},
+	} {
+		if _, err := CompileTagRules([]TagRule{bad}); err == nil {
+			t.Errorf("CompileTagRules(%+v) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestLoadProjectConfigTagRules(t *testing.T) {
+	configContent := `
+[[tag_rules]]
+keywords = ["terraform", "tf plan"]
+tags = ["infra"]
+
+[[tag_rules]]
+pattern = 'PROJ-\d+'
+tags = ["ticket", "proj"]
+`
+	configPath := filepath.Join(t.TempDir(), ".chronicle")
+	_ = os.WriteFile(configPath, []byte(configContent), 0644) //nolint:gosec // Test file permissions
+
+	cfg, err := LoadProjectConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig failed: %v", err)
+	}
+	if len(cfg.TagRules) != 2 || cfg.TagRules[0].Tags[0] != "infra" || cfg.TagRules[1].Pattern != `PROJ-\d+` {
+		t.Errorf("got tag rules %+v", cfg.TagRules)
+	}
+}
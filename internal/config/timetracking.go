@@ -0,0 +1,65 @@
+// ABOUTME: Time-tracking export settings for chronicle export
+// ABOUTME: Maps tags to billing projects and holds the Toggl account used for CSV import and API push
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// TogglAPITokenEnv holds the Toggl API token so it can stay out of the
+// config file; it overrides time_tracking.toggl_api_token.
+const TogglAPITokenEnv = "CHRONICLE_TOGGL_API_TOKEN"
+
+// TimeTracking configures exporting entries with a duration to Toggl
+// Track or Timewarrior.
+type TimeTracking struct {
+	// Projects maps tags to project names. An entry goes to the project of
+	// the first of its tags listed here, and to none without one
+	Projects map[string]string `json:"projects,omitempty"`
+	// Billable marks entries with a project billable in Toggl
+	Billable bool `json:"billable,omitempty"`
+	// Email is the Toggl account the CSV is for; Toggl's CSV import
+	// requires it
+	Email string `json:"email,omitempty"`
+	// TogglWorkspaceID is the workspace export --push adds time entries to
+	TogglWorkspaceID int64 `json:"toggl_workspace_id,omitempty"`
+	// TogglAPIToken is the token from Toggl's profile page;
+	// CHRONICLE_TOGGL_API_TOKEN overrides it
+	TogglAPIToken string `json:"toggl_api_token,omitempty"`
+}
+
+// Validate checks the time-tracking settings.
+func (t *TimeTracking) Validate() error {
+	for tag, project := range t.Projects {
+		if tag == "" || project == "" {
+			return fmt.Errorf("projects maps %q to %q; both must be set", tag, project)
+		}
+	}
+	if t.TogglWorkspaceID < 0 {
+		return fmt.Errorf("toggl_workspace_id must not be negative")
+	}
+	return nil
+}
+
+// Project returns the project for an entry with tags, or "" if none of
+// them has one.
+func (t *TimeTracking) Project(tags []string) string {
+	if t == nil {
+		return ""
+	}
+	for _, tag := range tags {
+		if project, ok := t.Projects[tag]; ok {
+			return project
+		}
+	}
+	return ""
+}
+
+// Secret returns the Toggl API token, preferring CHRONICLE_TOGGL_API_TOKEN.
+func (t *TimeTracking) Secret() string {
+	if env := os.Getenv(TogglAPITokenEnv); env != "" {
+		return env
+	}
+	return t.TogglAPIToken
+}
@@ -0,0 +1,61 @@
+// ABOUTME: Speech-to-text settings for importing voice notes
+// ABOUTME: Names the Whisper-compatible endpoint, model, and language
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// TranscriptionAPIKeyEnv holds the transcription API key so it can stay
+// out of the config file; it overrides transcription.api_key.
+const TranscriptionAPIKeyEnv = "CHRONICLE_TRANSCRIPTION_API_KEY"
+
+// DefaultTranscriptionModel is OpenAI's Whisper model, used when
+// transcription.model isn't set.
+const DefaultTranscriptionModel = "whisper-1"
+
+// Transcription configures turning voice notes into text with an
+// OpenAI-compatible audio transcription endpoint.
+type Transcription struct {
+	// URL is the API base, such as https://api.openai.com/v1 or a local
+	// Whisper server's http://localhost:8000/v1
+	URL string `json:"url"`
+	// Model is the transcription model (whisper-1 by default)
+	Model string `json:"model,omitempty"`
+	// APIKey is sent as a bearer token; CHRONICLE_TRANSCRIPTION_API_KEY
+	// overrides it, and local servers usually need none
+	APIKey string `json:"api_key,omitempty"`
+	// Language is the ISO-639-1 code of the spoken language, e.g. "en";
+	// detected when empty
+	Language string `json:"language,omitempty"`
+}
+
+// Validate checks the transcription settings.
+func (t *Transcription) Validate() error {
+	u, err := url.Parse(t.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q must be an http or https URL", t.URL)
+	}
+	if t.Language != "" && len(t.Language) != 2 {
+		return fmt.Errorf("language %q must be a two-letter ISO-639-1 code", t.Language)
+	}
+	return nil
+}
+
+// ModelName returns the transcription model, whisper-1 unless set.
+func (t *Transcription) ModelName() string {
+	if t.Model == "" {
+		return DefaultTranscriptionModel
+	}
+	return t.Model
+}
+
+// Secret returns the API key, preferring CHRONICLE_TRANSCRIPTION_API_KEY.
+func (t *Transcription) Secret() string {
+	if env := os.Getenv(TranscriptionAPIKeyEnv); env != "" {
+		return env
+	}
+	return t.APIKey
+}
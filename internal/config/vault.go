@@ -0,0 +1,90 @@
+// ABOUTME: Vault mirror settings for chronicle vault sync
+// ABOUTME: Names the Obsidian or Logseq vault, its daily notes folder, and which entries to mirror
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Vault formats entries can be mirrored into.
+const (
+	VaultObsidian = "obsidian"
+	VaultLogseq   = "logseq"
+)
+
+// ObsidianDailyNoteLayout names Obsidian daily notes DD-MM-YYYY. The
+// obsidian project log format and the vault mirror both use it, so pointed
+// at the same vault they write to the same note for a day.
+const ObsidianDailyNoteLayout = "02-01-2006"
+
+// LogseqJournalLayout names Logseq journal pages YYYY_MM_DD, Logseq's
+// default.
+const LogseqJournalLayout = "2006_01_02"
+
+// DefaultVaultDays is how far back entries are mirrored when vault.days
+// isn't set.
+const DefaultVaultDays = 30
+
+// Vault configures mirroring entries into an Obsidian or Logseq vault's
+// daily notes.
+type Vault struct {
+	// Path is the vault's root directory
+	Path string `json:"path"`
+	// Format is obsidian (default) or logseq
+	Format string `json:"format,omitempty"`
+	// Folder holds the daily notes, relative to Path: the vault root for
+	// Obsidian and "journals" for Logseq unless set
+	Folder string `json:"folder,omitempty"`
+	// Days is how far back entries are mirrored (30 by default)
+	Days int `json:"days,omitempty"`
+	// Tags limits the mirror to entries with one of these tags; all
+	// entries when empty
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Validate checks the vault settings.
+func (v *Vault) Validate() error {
+	if v.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	switch v.Format {
+	case "", VaultObsidian, VaultLogseq:
+	default:
+		return fmt.Errorf("format %q is not one of %s, %s", v.Format, VaultObsidian, VaultLogseq)
+	}
+	if filepath.IsAbs(v.Folder) {
+		return fmt.Errorf("folder %q must be relative to the vault", v.Folder)
+	}
+	if v.Days < 0 {
+		return fmt.Errorf("days must not be negative")
+	}
+	return nil
+}
+
+// FormatName returns the vault format, obsidian unless set.
+func (v *Vault) FormatName() string {
+	if v.Format == "" {
+		return VaultObsidian
+	}
+	return v.Format
+}
+
+// NotesDir returns the directory holding the vault's daily notes.
+func (v *Vault) NotesDir() string {
+	folder := v.Folder
+	if folder == "" && v.FormatName() == VaultLogseq {
+		folder = "journals"
+	}
+	return filepath.Join(ExpandHome(v.Path), folder)
+}
+
+// Window returns how far back entries are mirrored.
+func (v *Vault) Window() time.Duration {
+	days := v.Days
+	if days == 0 {
+		days = DefaultVaultDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
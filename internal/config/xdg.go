@@ -1,5 +1,5 @@
 // ABOUTME: XDG Base Directory specification helpers
-// ABOUTME: Resolves data and config directories with fallbacks
+// ABOUTME: Resolves data, config, and state directories with fallbacks
 package config
 
 import (
@@ -16,6 +16,17 @@ func GetDataHome() string {
 	return filepath.Join(home, ".local", "share")
 }
 
+// GetStateHome returns XDG_STATE_HOME or fallback to ~/.local/state. It
+// holds runtime state such as PID files, logs, and sync markers, kept apart
+// from the data next to the database.
+func GetStateHome() string {
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
+		return xdg
+	}
+	home := os.Getenv("HOME")
+	return filepath.Join(home, ".local", "state")
+}
+
 // GetConfigHome returns XDG_CONFIG_HOME or fallback to ~/.config.
 func GetConfigHome() string {
 	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
@@ -54,3 +54,20 @@ func TestGetConfigHome(t *testing.T) {
 		}
 	})
 }
+
+func TestGetStateHome(t *testing.T) {
+	t.Run("uses XDG_STATE_HOME when set", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "/custom/state")
+		if got := GetStateHome(); got != "/custom/state" {
+			t.Errorf("got %s, want /custom/state", got)
+		}
+	})
+
+	t.Run("falls back to HOME/.local/state", func(t *testing.T) {
+		t.Setenv("XDG_STATE_HOME", "")
+		want := filepath.Join(os.Getenv("HOME"), ".local", "state")
+		if got := GetStateHome(); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+// ABOUTME: Cross-platform desktop notifications through the OS's own tools
+// ABOUTME: Uses notify-send on Linux and BSD, osascript on macOS, and a PowerShell toast on Windows
+package desktop
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// appName is the application notifications are shown from.
+const appName = "chronicle"
+
+// toastScript shows a Windows toast whose title and message come from the
+// environment, so they never need quoting for PowerShell.
+const toastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:CHRONICLE_NOTIFY_TITLE)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:CHRONICLE_NOTIFY_MESSAGE)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('` + appName + `').Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+
+// Notify shows a desktop notification with title and message. It fails if
+// the platform's notification tool is missing or errors.
+func Notify(title, message string) error {
+	cmd, err := command(runtime.GOOS, title, message)
+	if err != nil {
+		return err
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %s", cmd.Args[0], msg)
+		}
+		return fmt.Errorf("%s: %w", cmd.Args[0], err)
+	}
+	return nil
+}
+
+// command builds the notification command for goos.
+func command(goos, title, message string) (*exec.Cmd, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return exec.Command("osascript", "-e", script), nil
+	case "windows":
+		cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+		cmd.Env = append(os.Environ(), "CHRONICLE_NOTIFY_TITLE="+title, "CHRONICLE_NOTIFY_MESSAGE="+message)
+		return cmd, nil
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return exec.Command("notify-send", "--app-name="+appName, "--", title, message), nil
+	}
+	return nil, fmt.Errorf("desktop notifications aren't supported on %s", goos)
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,46 @@
+// ABOUTME: Tests for desktop notification commands
+// ABOUTME: Checks each platform's command line without showing anything
+package desktop
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	title, message := `3 new entries from "work"`, `fixed C:\temp bug`
+
+	cmd, err := command("linux", title, message)
+	if err != nil {
+		t.Fatalf("linux: %v", err)
+	}
+	if want := []string{"notify-send", "--app-name=chronicle", "--", title, message}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("linux args = %q, want %q", cmd.Args, want)
+	}
+
+	cmd, err = command("darwin", title, message)
+	if err != nil {
+		t.Fatalf("darwin: %v", err)
+	}
+	want := `display notification "fixed C:\\temp bug" with title "3 new entries from \"work\""`
+	if len(cmd.Args) != 3 || cmd.Args[0] != "osascript" || cmd.Args[2] != want {
+		t.Errorf("darwin args = %q, want script %q", cmd.Args, want)
+	}
+
+	cmd, err = command("windows", title, message)
+	if err != nil {
+		t.Fatalf("windows: %v", err)
+	}
+	if strings.Contains(strings.Join(cmd.Args, " "), message) {
+		t.Error("windows should pass text through the environment, not the script")
+	}
+	env := strings.Join(cmd.Env, "\n")
+	if !strings.Contains(env, "CHRONICLE_NOTIFY_TITLE="+title) || !strings.Contains(env, "CHRONICLE_NOTIFY_MESSAGE="+message) {
+		t.Error("windows environment is missing the title or message")
+	}
+
+	if _, err := command("plan9", title, message); err == nil {
+		t.Error("expected an error for an unsupported platform")
+	}
+}
@@ -0,0 +1,141 @@
+// ABOUTME: Screenshots through the OS's own capture tools
+// ABOUTME: Uses screencapture on macOS, the first installed tool on Linux and BSD, and PowerShell on Windows
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrScreenshotCancelled means the capture tool exited without saving an
+// image, usually because the user dismissed the selection.
+var ErrScreenshotCancelled = errors.New("screenshot cancelled")
+
+// screenScript saves the whole virtual screen as a PNG at the path in the
+// environment, so the path never needs quoting for PowerShell.
+const screenScript = `Add-Type -AssemblyName System.Windows.Forms, System.Drawing
+$bounds = [System.Windows.Forms.SystemInformation]::VirtualScreen
+$bitmap = New-Object System.Drawing.Bitmap $bounds.Width, $bounds.Height
+$graphics = [System.Drawing.Graphics]::FromImage($bitmap)
+$graphics.CopyFromScreen($bounds.Left, $bounds.Top, 0, 0, $bitmap.Size)
+$bitmap.Save($env:CHRONICLE_SCREENSHOT_PATH, [System.Drawing.Imaging.ImageFormat]::Png)`
+
+// screenshotTool is a capture program and its arguments for saving to
+// path, either the whole screen or a region the user selects.
+type screenshotTool struct {
+	name string
+	args func(path string, full bool) []string
+	// wayland says whether this row is for Wayland sessions or X11 ones;
+	// tools that work under both are listed once for each
+	wayland bool
+}
+
+// unixScreenshotTools are tried in order on Linux and BSD.
+var unixScreenshotTools = []screenshotTool{
+	{"grim", func(path string, full bool) []string {
+		if full {
+			return []string{"grim", path}
+		}
+		// slurp prints the geometry the user drags out
+		return []string{"sh", "-c", `geometry=$(slurp) && grim -g "$geometry" "$1"`, "sh", path}
+	}, true},
+	{"gnome-screenshot", gnomeScreenshotArgs, true},
+	{"spectacle", spectacleArgs, true},
+	{"gnome-screenshot", gnomeScreenshotArgs, false},
+	{"spectacle", spectacleArgs, false},
+	{"maim", func(path string, full bool) []string {
+		if full {
+			return []string{"maim", path}
+		}
+		return []string{"maim", "-s", path}
+	}, false},
+	{"scrot", func(path string, full bool) []string {
+		if full {
+			return []string{"scrot", "-o", path}
+		}
+		return []string{"scrot", "-s", "-o", path}
+	}, false},
+	{"import", func(path string, full bool) []string {
+		if full {
+			return []string{"import", "-window", "root", path}
+		}
+		return []string{"import", path}
+	}, false},
+}
+
+func gnomeScreenshotArgs(path string, full bool) []string {
+	if full {
+		return []string{"gnome-screenshot", "-f", path}
+	}
+	return []string{"gnome-screenshot", "-a", "-f", path}
+}
+
+func spectacleArgs(path string, full bool) []string {
+	if full {
+		return []string{"spectacle", "-b", "-n", "-f", "-o", path}
+	}
+	return []string{"spectacle", "-b", "-n", "-r", "-o", path}
+}
+
+// Screenshot saves a PNG screenshot at path: a region or window the user
+// selects, or with full the whole screen. Windows always captures the
+// whole screen. It returns ErrScreenshotCancelled if no image was saved.
+func Screenshot(path string, full bool) error {
+	cmd, err := screenshotCommand(runtime.GOOS, path, full, os.Getenv("WAYLAND_DISPLAY") != "", exec.LookPath)
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, &stderr
+	runErr := cmd.Run()
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		return nil
+	}
+	_ = os.Remove(path)
+	if runErr != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", ErrScreenshotCancelled, msg)
+		}
+	}
+	return ErrScreenshotCancelled
+}
+
+// screenshotCommand builds the capture command for goos, picking the
+// first tool lookPath finds on Linux and BSD.
+func screenshotCommand(goos, path string, full, wayland bool, lookPath func(string) (string, error)) (*exec.Cmd, error) {
+	switch goos {
+	case "darwin":
+		if full {
+			return exec.Command("screencapture", "-x", path), nil
+		}
+		return exec.Command("screencapture", "-i", "-x", path), nil
+	case "windows":
+		cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", screenScript)
+		cmd.Env = append(os.Environ(), "CHRONICLE_SCREENSHOT_PATH="+path)
+		return cmd, nil
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+		var tried []string
+		for _, tool := range unixScreenshotTools {
+			if tool.wayland != wayland {
+				continue
+			}
+			tried = append(tried, tool.name)
+			if _, err := lookPath(tool.name); err != nil {
+				continue
+			}
+			if tool.name == "grim" && !full {
+				if _, err := lookPath("slurp"); err != nil {
+					continue
+				}
+			}
+			args := tool.args(path, full)
+			return exec.Command(args[0], args[1:]...), nil
+		}
+		return nil, fmt.Errorf("no screenshot tool found; install one of %s", strings.Join(tried, ", "))
+	}
+	return nil, fmt.Errorf("screenshots aren't supported on %s", goos)
+}
@@ -0,0 +1,69 @@
+// ABOUTME: Tests for screenshot capture commands
+// ABOUTME: Checks tool selection per platform and session without capturing anything
+package desktop
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// installed returns a lookPath that finds only names.
+func installed(names ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, n := range names {
+			if n == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestScreenshotCommand(t *testing.T) {
+	const path = "/tmp/shot.png"
+	tests := []struct {
+		name    string
+		goos    string
+		full    bool
+		wayland bool
+		have    []string
+		want    []string
+	}{
+		{"mac region", "darwin", false, false, nil, []string{"screencapture", "-i", "-x", path}},
+		{"mac full", "darwin", true, false, nil, []string{"screencapture", "-x", path}},
+		{"x11 prefers gnome", "linux", false, false, []string{"scrot", "gnome-screenshot"}, []string{"gnome-screenshot", "-a", "-f", path}},
+		{"x11 falls back", "linux", true, false, []string{"scrot", "grim"}, []string{"scrot", "-o", path}},
+		{"wayland grim full", "linux", true, true, []string{"grim"}, []string{"grim", path}},
+		{"wayland grim region", "freebsd", false, true, []string{"grim", "slurp"}, []string{"sh", "-c", `geometry=$(slurp) && grim -g "$geometry" "$1"`, "sh", path}},
+		{"wayland grim needs slurp", "linux", false, true, []string{"grim", "spectacle"}, []string{"spectacle", "-b", "-n", "-r", "-o", path}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := screenshotCommand(tt.goos, path, tt.full, tt.wayland, installed(tt.have...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cmd.Args, tt.want) {
+				t.Errorf("args = %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+
+	cmd, err := screenshotCommand("windows", path, false, false, installed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(strings.Join(cmd.Args, " "), path) || !strings.Contains(strings.Join(cmd.Env, "\n"), "CHRONICLE_SCREENSHOT_PATH="+path) {
+		t.Error("windows should pass the path through the environment")
+	}
+
+	// X11-only tools don't count under Wayland
+	if _, err := screenshotCommand("linux", path, false, true, installed("maim")); err == nil || !strings.Contains(err.Error(), "grim") {
+		t.Errorf("err = %v, want a hint naming Wayland tools", err)
+	}
+	if _, err := screenshotCommand("plan9", path, false, false, installed()); err == nil {
+		t.Error("expected an error for an unsupported platform")
+	}
+}
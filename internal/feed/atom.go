@@ -0,0 +1,180 @@
+// ABOUTME: Atom feed rendering for chronicle entries
+// ABOUTME: Shared by the feed command and the REST server's /feed endpoint
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+// titleLength caps entry titles, in characters.
+const titleLength = 80
+
+// Options describes the feed itself.
+type Options struct {
+	// Title names the feed; "chronicle" when empty
+	Title string
+	// ID identifies the feed to readers and should stay the same across
+	// regenerations; derived from Title when empty
+	ID string
+	// SelfURL, if set, is where the feed is published
+	SelfURL string
+	// Updated is the feed's timestamp when it has no entries
+	Updated time.Time
+}
+
+// DefaultTitle names a feed of entries with any of tags.
+func DefaultTitle(tags []string) string {
+	if len(tags) == 0 {
+		return "chronicle"
+	}
+	return "chronicle: " + strings.Join(tags, ", ")
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Updated   string      `xml:"updated"`
+	Link      *atomLink   `xml:"link,omitempty"`
+	Author    atomPerson  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomText       `xml:"content"`
+}
+
+// WriteAtom writes entries, newest first, as an Atom feed. Only what an
+// entry says is published: its message, type, tags, author, and metadata,
+// never the host or directory it was logged from.
+func WriteAtom(w io.Writer, entries []charm.Entry, opts Options) error {
+	if opts.Title == "" {
+		opts.Title = "chronicle"
+	}
+	if opts.ID == "" {
+		opts.ID = "urn:chronicle:feed:" + strings.ReplaceAll(strings.ToLower(opts.Title), " ", "-")
+	}
+	updated := opts.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	sorted := make([]charm.Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.After(sorted[j].Timestamp) })
+	if len(sorted) > 0 {
+		updated = sorted[0].Timestamp
+	}
+
+	feed := atomFeed{
+		Title:     opts.Title,
+		ID:        opts.ID,
+		Updated:   atomTime(updated),
+		Author:    atomPerson{Name: "chronicle"},
+		Generator: "chronicle",
+	}
+	if opts.SelfURL != "" {
+		feed.Link = &atomLink{Rel: "self", Href: opts.SelfURL}
+	}
+	for _, e := range sorted {
+		feed.Entries = append(feed.Entries, atomEntryFor(e))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return fmt.Errorf("write feed: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// atomEntryFor converts a chronicle entry to an Atom entry.
+func atomEntryFor(e charm.Entry) atomEntry {
+	entry := atomEntry{
+		Title:     entryTitle(e),
+		ID:        "urn:uuid:" + e.ID,
+		Published: atomTime(e.Timestamp),
+		Updated:   atomTime(e.Timestamp),
+		Content:   atomText{Type: "text", Body: entryContent(e)},
+	}
+	if e.Username != "" {
+		entry.Author = &atomPerson{Name: e.Username}
+	}
+	for _, tag := range e.Tags {
+		entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+	}
+	return entry
+}
+
+// entryTitle is the first line of the message, shortened, with the type in
+// front of anything but a note.
+func entryTitle(e charm.Entry) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(e.Message), "\n")
+	if runes := []rune(title); len(runes) > titleLength {
+		title = strings.TrimSpace(string(runes[:titleLength-1])) + "…"
+	}
+	if kind := e.Kind(); kind != charm.EntryTypeNote {
+		title = "[" + kind + "] " + title
+	}
+	return title
+}
+
+// entryContent is the full message followed by any metadata, one
+// "key: value" line each.
+func entryContent(e charm.Entry) string {
+	var b strings.Builder
+	b.WriteString(e.Message)
+	keys := make([]string, 0, len(e.Metadata))
+	for key := range e.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		if i == 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "\n%s: %s", key, e.Metadata[key])
+	}
+	return b.String()
+}
+
+// atomTime formats t as an RFC 3339 date, as Atom requires.
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,87 @@
+// ABOUTME: Tests for Atom feed rendering
+// ABOUTME: Validates feed structure, entry ordering, titles, and omitted fields
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func TestWriteAtom(t *testing.T) {
+	older := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 3, 2, 17, 30, 0, 0, time.UTC)
+	entries := []charm.Entry{
+		{ID: "a", Timestamp: older, Message: "fixed the flaky test", Tags: []string{"public"}, Username: "harper", Hostname: "laptop", WorkingDirectory: "/home/harper/secret-client"},
+		{ID: "b", Timestamp: newer, Message: "launched v2\nwith notes", Type: charm.EntryTypeMilestone, Metadata: map[string]string{"version": "2.0"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAtom(&buf, entries, Options{Title: "work", SelfURL: "https://example.com/feed.xml"}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	var got atomFeed
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("feed isn't valid XML: %v\n%s", err, out)
+	}
+	if got.Title != "work" || got.ID != "urn:chronicle:feed:work" || got.Updated != "2026-03-02T17:30:00Z" {
+		t.Errorf("unexpected feed header %+v", got)
+	}
+	if got.Link == nil || got.Link.Href != "https://example.com/feed.xml" {
+		t.Errorf("self link = %+v", got.Link)
+	}
+	if len(got.Entries) != 2 || got.Entries[0].ID != "urn:uuid:b" {
+		t.Fatalf("entries not newest first: %+v", got.Entries)
+	}
+	if got.Entries[0].Title != "[milestone] launched v2" {
+		t.Errorf("title = %q", got.Entries[0].Title)
+	}
+	if got.Entries[0].Content.Body != "launched v2\nwith notes\n\nversion: 2.0" {
+		t.Errorf("content = %q", got.Entries[0].Content.Body)
+	}
+	if e := got.Entries[1]; e.Author == nil || e.Author.Name != "harper" || len(e.Categories) != 1 || e.Categories[0].Term != "public" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	for _, private := range []string{"laptop", "secret-client"} {
+		if strings.Contains(out, private) {
+			t.Errorf("feed leaks %q", private)
+		}
+	}
+}
+
+func TestWriteAtomEmpty(t *testing.T) {
+	updated := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := WriteAtom(&buf, nil, Options{Updated: updated}); err != nil {
+		t.Fatal(err)
+	}
+	var got atomFeed
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "chronicle" || got.Updated != "2026-01-01T00:00:00Z" || len(got.Entries) != 0 {
+		t.Errorf("unexpected empty feed %+v", got)
+	}
+}
+
+func TestEntryTitleTruncates(t *testing.T) {
+	title := entryTitle(charm.Entry{Message: strings.Repeat("é", 100)})
+	if n := len([]rune(title)); n != titleLength || !strings.HasSuffix(title, "…") {
+		t.Errorf("title has %d characters: %q", n, title)
+	}
+}
+
+func TestDefaultTitle(t *testing.T) {
+	if got := DefaultTitle(nil); got != "chronicle" {
+		t.Errorf("DefaultTitle(nil) = %q", got)
+	}
+	if got := DefaultTitle([]string{"public", "oss"}); got != "chronicle: public, oss" {
+		t.Errorf("DefaultTitle = %q", got)
+	}
+}
@@ -0,0 +1,143 @@
+// ABOUTME: Importer for voice notes, transcribed by a Whisper-compatible endpoint
+// ABOUTME: Turns each recording into an entry tagged voice, dated when it was recorded
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+// audioNamespace seeds the UUIDs of imported voice notes, derived from the
+// recording's bytes so importing it twice doesn't duplicate it.
+var audioNamespace = uuid.MustParse("9c4e7a12-5d3b-4f80-b6a1-2e8f0c7d3b95")
+
+// VoiceTag is the tag every imported voice note gets.
+const VoiceTag = "voice"
+
+// transcribeTimeout bounds transcribing one recording, which can take a
+// while for long notes.
+const transcribeTimeout = 5 * time.Minute
+
+// maxAudioBytes is the largest recording sent for transcription, OpenAI's
+// limit.
+const maxAudioBytes = 25 << 20
+
+// audioExtensions are the formats Whisper endpoints accept.
+var audioExtensions = map[string]bool{
+	".flac": true, ".m4a": true, ".mp3": true, ".mp4": true, ".mpeg": true,
+	".mpga": true, ".oga": true, ".ogg": true, ".wav": true, ".webm": true,
+}
+
+// IsAudioFile reports whether path has an extension import audio
+// transcribes.
+func IsAudioFile(path string) bool {
+	return audioExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// AudioEntryID is the ID of the entry imported from a recording with
+// these bytes.
+func AudioEntryID(data []byte) string {
+	return uuid.NewSHA1(audioNamespace, data).String()
+}
+
+// ReadAudio transcribes the recording at path into an entry tagged voice,
+// dated when the file was last written, which is when recording stopped.
+// The file's name is kept as audio_file metadata.
+func ReadAudio(ctx context.Context, cfg *config.Transcription, path string) (charm.Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return charm.Entry{}, err
+	}
+	if info.Size() > maxAudioBytes {
+		return charm.Entry{}, fmt.Errorf("%s is larger than the %d MB transcription limit", path, maxAudioBytes>>20)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // User-chosen import file
+	if err != nil {
+		return charm.Entry{}, err
+	}
+	text, err := Transcribe(ctx, cfg, filepath.Base(path), data)
+	if err != nil {
+		return charm.Entry{}, err
+	}
+	if text == "" {
+		return charm.Entry{}, fmt.Errorf("no speech found in %s", path)
+	}
+	return charm.Entry{
+		ID:        AudioEntryID(data),
+		Timestamp: info.ModTime(),
+		Message:   text,
+		Tags:      []string{VoiceTag},
+		Source:    charm.SourceImport,
+		Metadata:  map[string]string{"audio_file": filepath.Base(path)},
+	}, nil
+}
+
+// Transcribe sends the recording named name to cfg's audio transcription
+// endpoint and returns the text, trimmed.
+func Transcribe(ctx context.Context, cfg *config.Transcription, name string, data []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	fields := map[string]string{"model": cfg.ModelName(), "response_format": "json"}
+	if cfg.Language != "" {
+		fields["language"] = cfg.Language
+	}
+	for key, value := range fields {
+		if err := form.WriteField(key, value); err != nil {
+			return "", err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, transcribeTimeout)
+	defer cancel()
+	endpoint := strings.TrimRight(cfg.URL, "/") + "/audio/transcriptions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if key := cfg.Secret(); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	reply, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("transcribe %s: server returned %s: %s", name, resp.Status, strings.TrimSpace(string(reply)))
+	}
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(reply, &result); err != nil {
+		return "", fmt.Errorf("transcribe %s: not a transcription response: %w", name, err)
+	}
+	return strings.TrimSpace(result.Text), nil
+}
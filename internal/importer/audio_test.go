@@ -0,0 +1,113 @@
+// ABOUTME: Tests for importing voice notes
+// ABOUTME: Uses a fake Whisper endpoint to check the upload, the entry, and errors
+package importer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/config"
+)
+
+func TestReadAudio(t *testing.T) {
+	var got struct {
+		fields   map[string]string
+		filename string
+		audio    string
+		auth     string
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		got.fields = map[string]string{}
+		for key, values := range r.MultipartForm.Value {
+			got.fields[key] = values[0]
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		got.filename, got.audio, got.auth = header.Filename, string(data), r.Header.Get("Authorization")
+		_, _ = io.WriteString(w, `{"text": "  Remember to rotate the staging keys.\n"}`)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "memo.m4a")
+	if err := os.WriteFile(path, []byte("fake audio"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	recorded := time.Date(2025, 3, 4, 8, 15, 0, 0, time.UTC)
+	if err := os.Chtimes(path, recorded, recorded); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.TranscriptionAPIKeyEnv, "sk-test")
+	cfg := &config.Transcription{URL: srv.URL + "/v1", Language: "en"}
+
+	entry, err := ReadAudio(context.Background(), cfg, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.filename != "memo.m4a" || got.audio != "fake audio" || got.auth != "Bearer sk-test" {
+		t.Errorf("upload = %q %q with auth %q", got.filename, got.audio, got.auth)
+	}
+	if got.fields["model"] != "whisper-1" || got.fields["language"] != "en" || got.fields["response_format"] != "json" {
+		t.Errorf("fields = %v", got.fields)
+	}
+	if entry.Message != "Remember to rotate the staging keys." || !entry.Timestamp.Equal(recorded) {
+		t.Errorf("entry = %q at %s", entry.Message, entry.Timestamp)
+	}
+	if len(entry.Tags) != 1 || entry.Tags[0] != VoiceTag || entry.Metadata["audio_file"] != "memo.m4a" {
+		t.Errorf("entry tags %v, metadata %v", entry.Tags, entry.Metadata)
+	}
+	if entry.ID != AudioEntryID([]byte("fake audio")) || entry.ID == AudioEntryID([]byte("other audio")) {
+		t.Errorf("ID %s isn't derived from the recording", entry.ID)
+	}
+}
+
+func TestReadAudioErrors(t *testing.T) {
+	reply := `{"text": " "}`
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, reply)
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "silence.wav")
+	if err := os.WriteFile(path, []byte("quiet"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Transcription{URL: srv.URL}
+
+	if _, err := ReadAudio(context.Background(), cfg, path); err == nil {
+		t.Error("expected an error for a recording without speech")
+	}
+	status, reply = http.StatusUnauthorized, `{"error": "bad key"}`
+	if _, err := ReadAudio(context.Background(), cfg, path); err == nil {
+		t.Error("expected an error when the server refuses")
+	}
+	if _, err := ReadAudio(context.Background(), cfg, filepath.Join(t.TempDir(), "missing.mp3")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestIsAudioFile(t *testing.T) {
+	for path, want := range map[string]bool{"memo.M4A": true, "a/b.ogg": true, "notes.txt": false, "wav": false} {
+		if got := IsAudioFile(path); got != want {
+			t.Errorf("IsAudioFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
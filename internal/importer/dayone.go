@@ -0,0 +1,190 @@
+// ABOUTME: Importer for Day One JSON exports, as a zip archive or a bare journal file
+// ABOUTME: Keeps creation dates, tags, and stars, and maps location into entry metadata
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/chronicle/internal/charm"
+)
+
+// zipMagic is the header every zip archive starts with.
+var zipMagic = []byte("PK\x03\x04")
+
+// dayOneMoment matches a photo, video, or audio embed, which has no
+// meaning outside Day One.
+var dayOneMoment = regexp.MustCompile(`!\[[^\]]*\]\(dayone-moment:[^)]*\)`)
+
+// dayOneEscape matches the backslash escapes Day One's Markdown puts in
+// front of punctuation.
+var dayOneEscape = regexp.MustCompile(`\\([\\.\-!#*_+()\[\]{}<>|~` + "`" + `])`)
+
+// dayOneEntry is one entry of a Day One JSON export.
+type dayOneEntry struct {
+	UUID           string          `json:"uuid"`
+	CreationDate   time.Time       `json:"creationDate"`
+	Text           string          `json:"text"`
+	Tags           []string        `json:"tags"`
+	Starred        bool            `json:"starred"`
+	TimeZone       string          `json:"timeZone"`
+	CreationDevice string          `json:"creationDevice"`
+	Location       *dayOneLocation `json:"location"`
+}
+
+// dayOneLocation is where a Day One entry was written.
+type dayOneLocation struct {
+	PlaceName          string   `json:"placeName"`
+	LocalityName       string   `json:"localityName"`
+	AdministrativeArea string   `json:"administrativeArea"`
+	Country            string   `json:"country"`
+	Latitude           *float64 `json:"latitude"`
+	Longitude          *float64 `json:"longitude"`
+}
+
+// ReadDayOneFile reads entries from a Day One export: the zip archive Day
+// One produces, holding one JSON file per journal, or one of those JSON
+// files on its own. Entries from a zip are tagged with their journal's name
+// unless it's the default "Journal".
+func ReadDayOneFile(file string) ([]charm.Entry, error) {
+	data, err := os.ReadFile(file) //nolint:gosec // User-chosen import file
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, zipMagic) {
+		return ReadDayOneJSON(bytes.NewReader(data), "")
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open export: %w", err)
+	}
+	var entries []charm.Entry
+	found := false
+	for _, f := range archive.File {
+		if path.Ext(f.Name) != ".json" || strings.HasPrefix(path.Base(f.Name), ".") {
+			continue
+		}
+		found = true
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", f.Name, err)
+		}
+		journal := strings.TrimSuffix(path.Base(f.Name), ".json")
+		if journal == "Journal" {
+			journal = ""
+		}
+		read, err := ReadDayOneJSON(rc, journal)
+		_ = rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		entries = append(entries, read...)
+	}
+	if !found {
+		return nil, fmt.Errorf("no journal JSON files in export")
+	}
+	return entries, nil
+}
+
+// ReadDayOneJSON parses one journal of a Day One JSON export. A non-empty
+// journal name is added as a tag.
+func ReadDayOneJSON(r io.Reader, journal string) ([]charm.Entry, error) {
+	var export struct {
+		Entries []dayOneEntry `json:"entries"`
+	}
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("decode export: %w", err)
+	}
+
+	entries := make([]charm.Entry, 0, len(export.Entries))
+	for i, e := range export.Entries {
+		message := dayOneText(e.Text)
+		if message == "" {
+			continue
+		}
+		if e.CreationDate.IsZero() {
+			return nil, fmt.Errorf("entry %d: missing creationDate", i+1)
+		}
+		timestamp := e.CreationDate
+		if loc, err := time.LoadLocation(e.TimeZone); err == nil && e.TimeZone != "" {
+			timestamp = timestamp.In(loc)
+		}
+		entry := charm.Entry{
+			Timestamp: timestamp,
+			Message:   message,
+			Hostname:  e.CreationDevice,
+			Tags:      append([]string(nil), e.Tags...),
+			Pinned:    e.Starred,
+			Metadata:  dayOneMetadata(e.Location),
+		}
+		if journal != "" {
+			entry.Tags = append(entry.Tags, journal)
+		}
+		entry.ID = dayOneID(e.UUID, &entry)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// dayOneText strips media embeds and Markdown escapes from an entry's text.
+func dayOneText(text string) string {
+	text = dayOneMoment.ReplaceAllString(text, "")
+	text = dayOneEscape.ReplaceAllString(text, "$1")
+	return strings.TrimSpace(text)
+}
+
+// dayOneMetadata maps a location into entry metadata: location is the
+// place's name and address, latitude and longitude its coordinates.
+func dayOneMetadata(loc *dayOneLocation) map[string]string {
+	if loc == nil {
+		return nil
+	}
+	metadata := make(map[string]string)
+	var parts []string
+	for _, part := range []string{loc.PlaceName, loc.LocalityName, loc.AdministrativeArea, loc.Country} {
+		if part = strings.TrimSpace(part); part != "" && !containsString(parts, part) {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) > 0 {
+		metadata["location"] = strings.Join(parts, ", ")
+	}
+	if loc.Latitude != nil && loc.Longitude != nil {
+		metadata["latitude"] = strconv.FormatFloat(*loc.Latitude, 'f', -1, 64)
+		metadata["longitude"] = strconv.FormatFloat(*loc.Longitude, 'f', -1, 64)
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+// dayOneID reuses Day One's UUID for an entry, so importing an export twice
+// doesn't duplicate it, falling back to one derived from the entry.
+func dayOneID(id string, entry *charm.Entry) string {
+	if parsed, err := uuid.Parse(id); err == nil {
+		return parsed.String()
+	}
+	return mapLegacyID(id, entry)
+}
+
+// containsString reports whether list holds s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+// ABOUTME: Tests for the Day One importer
+// ABOUTME: Reads journal JSON on its own and inside a zip export
+package importer
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const dayOneJournal = `{
+  "metadata": {"version": "1.0"},
+  "entries": [
+    {
+      "uuid": "9F3C2A5B4E6D4F708192A3B4C5D6E7F8",
+      "creationDate": "2024-05-06T18:30:00Z",
+      "timeZone": "America/Chicago",
+      "creationDevice": "Harper's iPhone",
+      "starred": true,
+      "tags": ["travel"],
+      "text": "Landed in Chicago\\. Finally\\!\n\n![](dayone-moment://ABC123)\n",
+      "location": {
+        "placeName": "O'Hare International Airport",
+        "localityName": "Chicago",
+        "administrativeArea": "IL",
+        "country": "United States",
+        "latitude": 41.9742,
+        "longitude": -87.9073
+      }
+    },
+    {"uuid": "0000", "creationDate": "2024-05-07T08:00:00Z", "text": "   "}
+  ]
+}`
+
+func TestReadDayOneJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Journal.json")
+	if err := os.WriteFile(path, []byte(dayOneJournal), 0600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadDayOneFile(path)
+	if err != nil {
+		t.Fatalf("ReadDayOneFile: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want the empty one skipped", len(entries))
+	}
+
+	e := entries[0]
+	if e.ID != "9f3c2a5b-4e6d-4f70-8192-a3b4c5d6e7f8" {
+		t.Errorf("ID = %q, want Day One's UUID", e.ID)
+	}
+	if e.Message != "Landed in Chicago. Finally!" {
+		t.Errorf("message = %q", e.Message)
+	}
+	if want := time.Date(2024, 5, 6, 18, 30, 0, 0, time.UTC); !e.Timestamp.Equal(want) || e.Timestamp.Location().String() != "America/Chicago" {
+		t.Errorf("timestamp = %v, want %v in America/Chicago", e.Timestamp, want)
+	}
+	if !e.Pinned || e.Hostname != "Harper's iPhone" || !reflect.DeepEqual(e.Tags, []string{"travel"}) {
+		t.Errorf("entry = %+v", e)
+	}
+	want := map[string]string{
+		"location":  "O'Hare International Airport, Chicago, IL, United States",
+		"latitude":  "41.9742",
+		"longitude": "-87.9073",
+	}
+	if !reflect.DeepEqual(e.Metadata, want) {
+		t.Errorf("metadata = %v, want %v", e.Metadata, want)
+	}
+}
+
+func TestReadDayOneZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(path) //nolint:gosec // Test file in a temp dir
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"Journal.json", "Work.json"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(dayOneJournal)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := zw.Create("photos/abc.jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadDayOneFile(path)
+	if err != nil {
+		t.Fatalf("ReadDayOneFile: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if !reflect.DeepEqual(entries[0].Tags, []string{"travel"}) {
+		t.Errorf("default journal tags = %v", entries[0].Tags)
+	}
+	if !reflect.DeepEqual(entries[1].Tags, []string{"travel", "Work"}) {
+		t.Errorf("Work journal tags = %v", entries[1].Tags)
+	}
+}
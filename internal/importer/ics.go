@@ -0,0 +1,538 @@
+// ABOUTME: Importer turning past calendar events into entries, from an .ics file, feed, or CalDAV calendar
+// ABOUTME: Expands common recurrence rules and records each meeting's length as duration metadata
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/chronicle/internal/caldav"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+// icsNamespace seeds the UUIDs of imported events, derived from each
+// occurrence's UID and start so importing a calendar twice doesn't
+// duplicate it.
+var icsNamespace = uuid.MustParse("3d2a8c41-7b5e-4f96-a0d3-6e1c9b8f4a27")
+
+// MeetingTag is the tag every imported calendar event gets.
+const MeetingTag = "meeting"
+
+// icsFetchTimeout bounds downloading a calendar feed.
+const icsFetchTimeout = 30 * time.Second
+
+// maxOccurrences caps how many times one recurring event is expanded.
+const maxOccurrences = 10000
+
+// icsDuration matches an RFC 5545 duration such as PT1H30M or P1D.
+var icsDuration = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// icsWeekdays maps BYDAY codes to weekdays.
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// icsProp is one content line: a property name, its parameters, and value.
+type icsProp struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// icsEvent is the part of a VEVENT an entry is made from.
+type icsEvent struct {
+	uid          string
+	summary      string
+	location     string
+	status       string
+	categories   []string
+	start        time.Time
+	length       time.Duration
+	allDay       bool
+	rrule        string
+	exdates      []time.Time
+	recurrenceID time.Time
+}
+
+// ReadICSFile reads past events from an .ics file, an http(s) or webcal
+// calendar feed, or a CalDAV calendar collection. For URLs, a username in
+// the URL is used with the password from CHRONICLE_CALDAV_PASSWORD.
+func ReadICSFile(source string) ([]charm.Entry, error) {
+	now := time.Now()
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "webcal") {
+		data, err := os.ReadFile(source) //nolint:gosec // User-chosen import file
+		if err != nil {
+			return nil, err
+		}
+		return ReadICS(data, now)
+	}
+
+	objects, err := fetchCalendar(u, now)
+	if err != nil {
+		return nil, err
+	}
+	var entries []charm.Entry
+	for _, object := range objects {
+		read, err := ReadICS(object, now)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, read...)
+	}
+	return entries, nil
+}
+
+// fetchCalendar downloads a calendar feed (a webcal URL or one ending in
+// .ics) or queries a CalDAV collection for everything up to now.
+func fetchCalendar(u *url.URL, now time.Time) ([][]byte, error) {
+	username, password := "", os.Getenv(config.CalDAVPasswordEnv)
+	if u.User != nil {
+		username = u.User.Username()
+		if p, ok := u.User.Password(); ok {
+			password = p
+		}
+	}
+	target := *u
+	target.User = nil
+	feed := target.Scheme == "webcal" || strings.HasSuffix(strings.ToLower(target.Path), ".ics")
+	if target.Scheme == "webcal" {
+		target.Scheme = "https"
+	}
+
+	ctx := context.Background()
+	if !feed {
+		dav, err := caldav.NewClient(target.String(), username, password)
+		if err != nil {
+			return nil, err
+		}
+		return dav.Events(ctx, time.Unix(0, 0), now)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "chronicle")
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := (&http.Client{Timeout: icsFetchTimeout}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: server returned %s", target.Redacted(), resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{data}, nil
+}
+
+// ReadICS turns the events in iCalendar data that ended by now into
+// entries tagged meeting (and with the event's categories). Each entry is
+// dated when its event ended, like a shell command's, and records the
+// event's length as duration metadata and its location as location.
+// Recurring events become one entry per past occurrence. All-day and
+// cancelled events are skipped, as are events chronicle published itself.
+func ReadICS(data []byte, now time.Time) ([]charm.Entry, error) {
+	events, err := parseICS(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Occurrences moved or changed by a RECURRENCE-ID override are taken
+	// from the override, not the recurring event
+	overridden := make(map[string]bool)
+	for _, ev := range events {
+		if !ev.recurrenceID.IsZero() {
+			overridden[ev.uid+"\x00"+ev.recurrenceID.UTC().Format(time.RFC3339)] = true
+		}
+	}
+
+	var entries []charm.Entry
+	for _, ev := range events {
+		if ev.allDay || strings.EqualFold(ev.status, "CANCELLED") || strings.HasSuffix(ev.uid, "@chronicle") {
+			continue
+		}
+		starts := []time.Time{ev.start}
+		if ev.rrule != "" && ev.recurrenceID.IsZero() {
+			starts = occurrences(ev, now)
+		}
+		for _, start := range starts {
+			key := ev.uid + "\x00" + start.UTC().Format(time.RFC3339)
+			if ev.recurrenceID.IsZero() && overridden[key] {
+				continue
+			}
+			end := start.Add(ev.length)
+			if end.After(now) {
+				continue
+			}
+			entries = append(entries, eventEntry(ev, start, end))
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.Before(entries[j].Timestamp) })
+	return entries, nil
+}
+
+// eventEntry makes the entry for one occurrence of ev.
+func eventEntry(ev icsEvent, start, end time.Time) charm.Entry {
+	message := strings.TrimSpace(ev.summary)
+	if message == "" {
+		message = "(untitled event)"
+	}
+	tags := []string{MeetingTag}
+	for _, category := range ev.categories {
+		if category = strings.TrimSpace(category); category != "" && !containsString(tags, category) {
+			tags = append(tags, category)
+		}
+	}
+	metadata := make(map[string]string)
+	if ev.length > 0 {
+		metadata["duration"] = ev.length.String()
+	}
+	if location := strings.TrimSpace(ev.location); location != "" {
+		metadata["location"] = location
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	uid := ev.uid
+	if uid == "" {
+		uid = message
+	}
+	return charm.Entry{
+		ID:        uuid.NewSHA1(icsNamespace, []byte(uid+"\x00"+start.UTC().Format(time.RFC3339))).String(),
+		Timestamp: end,
+		Message:   message,
+		Tags:      tags,
+		Metadata:  metadata,
+	}
+}
+
+// parseICS returns the VEVENTs in iCalendar data.
+func parseICS(data []byte) ([]icsEvent, error) {
+	var events []icsEvent
+	var current *icsEvent
+	var end time.Time
+	var duration time.Duration
+	nested := 0
+	for i, line := range unfoldICS(string(data)) {
+		prop, ok := parseICSLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT") && current == nil:
+			current, end, duration = &icsEvent{}, time.Time{}, -1
+			continue
+		case current == nil:
+			continue
+		case prop.name == "BEGIN":
+			nested++
+			continue
+		case prop.name == "END" && nested > 0:
+			nested--
+			continue
+		case prop.name == "END":
+			switch {
+			case duration >= 0:
+				current.length = duration
+			case !end.IsZero() && end.After(current.start):
+				current.length = end.Sub(current.start)
+			}
+			if !current.start.IsZero() {
+				events = append(events, *current)
+			}
+			current = nil
+			continue
+		case nested > 0:
+			continue
+		}
+
+		var err error
+		switch prop.name {
+		case "UID":
+			current.uid = prop.value
+		case "SUMMARY":
+			current.summary = unescapeICS(prop.value)
+		case "LOCATION":
+			current.location = unescapeICS(prop.value)
+		case "STATUS":
+			current.status = prop.value
+		case "CATEGORIES":
+			for _, category := range splitICSList(prop.value) {
+				current.categories = append(current.categories, unescapeICS(category))
+			}
+		case "DTSTART":
+			current.start, current.allDay, err = parseICSTime(prop.value, prop.params)
+		case "DTEND":
+			end, _, err = parseICSTime(prop.value, prop.params)
+		case "DURATION":
+			duration, err = parseICSDuration(prop.value)
+		case "RRULE":
+			current.rrule = prop.value
+		case "EXDATE":
+			for _, value := range strings.Split(prop.value, ",") {
+				var t time.Time
+				if t, _, err = parseICSTime(value, prop.params); err != nil {
+					break
+				}
+				current.exdates = append(current.exdates, t)
+			}
+		case "RECURRENCE-ID":
+			current.recurrenceID, _, err = parseICSTime(prop.value, prop.params)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", i+1, prop.name, err)
+		}
+	}
+	return events, nil
+}
+
+// unfoldICS splits iCalendar text into content lines, joining folded ones.
+func unfoldICS(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseICSLine splits a content line into its name, parameters, and value.
+func parseICSLine(line string) (icsProp, bool) {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icsProp{}, false
+	}
+	parts := strings.Split(line[:colon], ";")
+	prop := icsProp{name: strings.ToUpper(parts[0]), params: make(map[string]string), value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return prop, true
+}
+
+// splitICSList splits a comma-separated TEXT list, keeping escaped commas.
+func splitICSList(value string) []string {
+	var items []string
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			items = append(items, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(items, b.String())
+}
+
+// unescapeICS undoes TEXT escaping.
+func unescapeICS(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";").Replace(s)
+}
+
+// parseICSTime parses a DATE or DATE-TIME value, in UTC when it ends in
+// Z, in its TZID zone, or else in local time. It reports whether the value
+// was a date.
+func parseICSTime(value string, params map[string]string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseICSDuration parses a DURATION value.
+func parseICSDuration(value string) (time.Duration, error) {
+	m := icsDuration.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	var d time.Duration
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// occurrences returns the starts of ev's occurrences up to now, following
+// its RRULE's FREQ, INTERVAL, COUNT, UNTIL, and weekly BYDAY, less its
+// EXDATEs. Rules using other parts yield only the first occurrence.
+func occurrences(ev icsEvent, now time.Time) []time.Time {
+	rule := make(map[string]string)
+	for _, part := range strings.Split(ev.rrule, ";") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			rule[strings.ToUpper(key)] = strings.ToUpper(value)
+		}
+	}
+	interval := 1
+	if n, err := strconv.Atoi(rule["INTERVAL"]); err == nil && n > 0 {
+		interval = n
+	}
+	count := -1
+	if n, err := strconv.Atoi(rule["COUNT"]); err == nil {
+		count = n
+	}
+	limit := now
+	if until, _, err := parseICSTime(rule["UNTIL"], nil); err == nil && until.Before(limit) {
+		limit = until
+	}
+	for key := range rule {
+		switch key {
+		case "FREQ", "INTERVAL", "COUNT", "UNTIL", "WKST":
+		case "BYDAY":
+			if rule["FREQ"] != "WEEKLY" {
+				return []time.Time{ev.start}
+			}
+		default:
+			return []time.Time{ev.start}
+		}
+	}
+
+	var step func(i int) []time.Time
+	switch rule["FREQ"] {
+	case "DAILY":
+		step = func(i int) []time.Time { return []time.Time{ev.start.AddDate(0, 0, i*interval)} }
+	case "WEEKLY":
+		days, ok := weeklyDays(ev.start, rule["BYDAY"])
+		if !ok {
+			return []time.Time{ev.start}
+		}
+		// Each step is one week, starting from the Sunday before the first
+		// occurrence; days before it in that first week are skipped below
+		weekStart := ev.start.AddDate(0, 0, -int(ev.start.Weekday()))
+		step = func(i int) []time.Time {
+			week := weekStart.AddDate(0, 0, 7*i*interval)
+			starts := make([]time.Time, len(days))
+			for j, day := range days {
+				starts[j] = week.AddDate(0, 0, int(day))
+			}
+			return starts
+		}
+	case "MONTHLY", "YEARLY":
+		months := interval
+		if rule["FREQ"] == "YEARLY" {
+			months *= 12
+		}
+		step = func(i int) []time.Time {
+			t := ev.start.AddDate(0, i*months, 0)
+			if t.Day() != ev.start.Day() {
+				// The month is too short for this day, so it's skipped
+				return nil
+			}
+			return []time.Time{t}
+		}
+	default:
+		return []time.Time{ev.start}
+	}
+
+	var starts []time.Time
+	found := 0
+	for i := 0; i < maxOccurrences; i++ {
+		for _, start := range step(i) {
+			if start.Before(ev.start) {
+				continue
+			}
+			if start.After(limit) || (count >= 0 && found >= count) {
+				return starts
+			}
+			found++
+			if !excluded(start, ev.exdates) {
+				starts = append(starts, start)
+			}
+		}
+	}
+	return starts
+}
+
+// weeklyDays returns the weekdays a weekly rule's BYDAY names, in week
+// order, or start's weekday when it names none. It fails on days with an
+// ordinal, like 1MO, which only monthly and yearly rules use.
+func weeklyDays(start time.Time, byDay string) ([]time.Weekday, bool) {
+	if byDay == "" {
+		return []time.Weekday{start.Weekday()}, true
+	}
+	var days []time.Weekday
+	for _, code := range strings.Split(byDay, ",") {
+		day, ok := icsWeekdays[code]
+		if !ok {
+			return nil, false
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+	return days, true
+}
+
+// excluded reports whether start is one of exdates.
+func excluded(start time.Time, exdates []time.Time) bool {
+	for _, exdate := range exdates {
+		if exdate.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
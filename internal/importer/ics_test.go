@@ -0,0 +1,180 @@
+// ABOUTME: Tests for the calendar importer
+// ABOUTME: Parses sample iCalendar data, expands recurrences, and queries a fake CalDAV server
+package importer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const icsCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:planning-1\r\n" +
+	"DTSTART:20240304T150000Z\r\n" +
+	"DTEND:20240304T160000Z\r\n" +
+	"SUMMARY:Quarterly planning\\, round 2\r\n" +
+	"LOCATION:Room 4\r\n" +
+	"CATEGORIES:work,planning\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"SUMMARY:Alarm\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"DTSTART;TZID=America/New_York:20240304T093000\r\n" +
+	"DURATION:PT15M\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR\r\n" +
+	"EXDATE;TZID=America/New_York:20240306T093000\r\n" +
+	"SUMMARY:Standup with a very long title that the calendar app folded acr\r\n" +
+	" oss two lines\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"RECURRENCE-ID;TZID=America/New_York:20240308T093000\r\n" +
+	"DTSTART;TZID=America/New_York:20240308T110000\r\n" +
+	"DURATION:PT30M\r\n" +
+	"SUMMARY:Standup (moved)\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:offsite\r\n" +
+	"DTSTART;VALUE=DATE:20240305\r\n" +
+	"SUMMARY:Offsite\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled\r\n" +
+	"DTSTART:20240305T150000Z\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"SUMMARY:Cancelled sync\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:abc@chronicle\r\n" +
+	"DTSTART:20240305T150000Z\r\n" +
+	"SUMMARY:Published by chronicle\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:future\r\n" +
+	"DTSTART:20240320T150000Z\r\n" +
+	"SUMMARY:Not yet\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestReadICS(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	now := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	entries, err := ReadICS([]byte(icsCalendar), now)
+	if err != nil {
+		t.Fatalf("ReadICS: %v", err)
+	}
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Timestamp.UTC().Format(time.RFC3339)+" "+e.Message)
+	}
+	want := []string{
+		"2024-03-04T14:45:00Z Standup with a very long title that the calendar app folded across two lines",
+		"2024-03-04T16:00:00Z Quarterly planning, round 2",
+		"2024-03-08T16:30:00Z Standup (moved)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("entries =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	planning := entries[1]
+	if !reflect.DeepEqual(planning.Tags, []string{"meeting", "work", "planning"}) {
+		t.Errorf("tags = %v", planning.Tags)
+	}
+	if want := map[string]string{"duration": "1h0m0s", "location": "Room 4"}; !reflect.DeepEqual(planning.Metadata, want) {
+		t.Errorf("metadata = %v, want %v", planning.Metadata, want)
+	}
+	if entries[0].Timestamp.Location().String() != ny.String() {
+		t.Errorf("standup should keep its time zone, got %v", entries[0].Timestamp.Location())
+	}
+
+	again, _ := ReadICS([]byte(icsCalendar), now)
+	if again[0].ID != entries[0].ID || entries[0].ID == entries[2].ID {
+		t.Error("IDs should be stable per occurrence and distinct across occurrences")
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	start := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rule string
+		want int
+	}{
+		{"FREQ=DAILY;COUNT=5", 5},
+		{"FREQ=DAILY;INTERVAL=10;UNTIL=20240301T000000Z", 3},
+		{"FREQ=MONTHLY", 6},          // months with a 31st, before December's
+		{"FREQ=WEEKLY;BYDAY=1MO", 1}, // unsupported, first occurrence only
+		{"FREQ=MONTHLY;BYMONTHDAY=1", 1},
+	}
+	for _, tt := range tests {
+		got := occurrences(icsEvent{start: start, rrule: tt.rule}, now)
+		if len(got) != tt.want {
+			t.Errorf("%s: got %d occurrences, want %d", tt.rule, len(got), tt.want)
+		}
+	}
+}
+
+func TestParseICSDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"PT1H30M": 90 * time.Minute,
+		"P1DT2H":  26 * time.Hour,
+		"P2W":     14 * 24 * time.Hour,
+		"-PT5M":   -5 * time.Minute,
+	}
+	for value, want := range tests {
+		if got, err := parseICSDuration(value); err != nil || got != want {
+			t.Errorf("parseICSDuration(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	if _, err := parseICSDuration("1 hour"); err == nil {
+		t.Error("expected an error for a malformed duration")
+	}
+}
+
+func TestReadICSFromCalDAV(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); r.Method != "REPORT" || user != "me" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">
+  <d:response>
+    <d:href>/cal/1.ics</d:href>
+    <d:propstat><d:prop><cal:calendar-data>BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:one
+DTSTART:20240304T150000Z
+DTEND:20240304T153000Z
+SUMMARY:1:1 with Sam
+END:VEVENT
+END:VCALENDAR
+</cal:calendar-data></d:prop></d:propstat>
+  </d:response>
+</d:multistatus>`))
+	}))
+	defer server.Close()
+
+	t.Setenv("CHRONICLE_CALDAV_PASSWORD", "secret")
+	source := strings.Replace(server.URL, "http://", "http://me@", 1) + "/cal/"
+	entries, err := ReadICSFile(source)
+	if err != nil {
+		t.Fatalf("ReadICSFile: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Message != "1:1 with Sam" || entries[0].Metadata["duration"] != "30m0s" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
@@ -0,0 +1,186 @@
+// ABOUTME: Importer for jrnl journals, in its plain-text or JSON export format
+// ABOUTME: Keeps original timestamps and turns @tags into chronicle tags
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/chronicle/internal/charm"
+)
+
+// jrnlNamespace seeds the UUIDs of imported jrnl entries, derived from each
+// entry's timestamp and text so importing a journal twice doesn't duplicate
+// it.
+var jrnlNamespace = uuid.MustParse("0b6f3d7e-8a41-4c2f-9f0e-2d7c5b1a9e34")
+
+// jrnlTimeLayouts are the timestamp formats jrnl has written: its current
+// default ("%Y-%m-%d %I:%M:%S %p") and older ones.
+var jrnlTimeLayouts = []string{
+	"2006-01-02 03:04:05 PM",
+	"2006-01-02 03:04 PM",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// jrnlHeader matches the line starting an entry, with the date in brackets
+// (jrnl 2 and later) or bare (jrnl 1). A star after the date marks a starred
+// entry.
+var jrnlHeader = regexp.MustCompile(`^(?:\[(\d{4}-\d{2}-\d{2} [^\]]+)\]|(\d{4}-\d{2}-\d{2} \d{1,2}:\d{2}(?::\d{2})?(?: [AP]M)?))(?: (\*))?(?: (.*))?$`)
+
+// jrnlTag matches an @tag in entry text.
+var jrnlTag = regexp.MustCompile(`(?:^|[\s(])@([\p{L}\p{N}_][\p{L}\p{N}_/-]*)`)
+
+// ReadJrnlFile reads entries from a jrnl journal file or the output of
+// jrnl --export json, detecting which from the contents.
+func ReadJrnlFile(path string) ([]charm.Entry, error) {
+	f, err := os.Open(path) //nolint:gosec // User-chosen import file
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	br := bufio.NewReader(f)
+	if first, err := peekNonSpace(br); err == nil && first == '{' {
+		return ReadJrnlJSON(br)
+	}
+	return ReadJrnl(br)
+}
+
+// ReadJrnl parses a plain-text jrnl journal. Each entry starts with a
+// timestamped line; the lines up to the next one are its body. Times are
+// local, as jrnl writes them.
+func ReadJrnl(r io.Reader) ([]charm.Entry, error) {
+	var entries []charm.Entry
+	var current *charm.Entry
+	var lines []string
+	flush := func() {
+		if current == nil {
+			return
+		}
+		text := strings.TrimSpace(strings.Join(lines, "\n"))
+		if text != "" {
+			current.Message = text
+			current.Tags = jrnlTags(text)
+			current.ID = jrnlID(current)
+			entries = append(entries, *current)
+		}
+		current, lines = nil, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if m := jrnlHeader.FindStringSubmatch(line); m != nil {
+			stamp := m[1] + m[2]
+			if timestamp, err := parseJrnlTime(stamp); err == nil {
+				flush()
+				current = &charm.Entry{Timestamp: timestamp, Pinned: m[3] == "*"}
+				lines = []string{m[4]}
+				continue
+			} else if m[1] != "" {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+		}
+		if current == nil {
+			if strings.TrimSpace(line) != "" {
+				return nil, fmt.Errorf("line %d: text before the first entry", lineNo)
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return entries, nil
+}
+
+// jrnlJSONEntry is one entry of jrnl --export json.
+type jrnlJSONEntry struct {
+	Title   string   `json:"title"`
+	Body    string   `json:"body"`
+	Date    string   `json:"date"`
+	Time    string   `json:"time"`
+	Tags    []string `json:"tags"`
+	Starred bool     `json:"starred"`
+}
+
+// ReadJrnlJSON parses the output of jrnl --export json.
+func ReadJrnlJSON(r io.Reader) ([]charm.Entry, error) {
+	var export struct {
+		Entries []jrnlJSONEntry `json:"entries"`
+	}
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("decode export: %w", err)
+	}
+
+	entries := make([]charm.Entry, 0, len(export.Entries))
+	for i, e := range export.Entries {
+		timestamp, err := parseJrnlTime(e.Date + " " + e.Time)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		message := strings.TrimSpace(e.Title)
+		if body := strings.TrimSpace(e.Body); body != "" {
+			message += "\n" + body
+		}
+		if message == "" {
+			continue
+		}
+		tags := make([]string, 0, len(e.Tags))
+		for _, tag := range e.Tags {
+			if tag = strings.TrimLeft(tag, "@#"); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		entry := charm.Entry{Timestamp: timestamp, Message: message, Tags: tags, Pinned: e.Starred}
+		entry.ID = jrnlID(&entry)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseJrnlTime parses a jrnl timestamp in local time.
+func parseJrnlTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range jrnlTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+}
+
+// jrnlTags returns the distinct @tags in text, without the @, in order of
+// first use.
+func jrnlTags(text string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, m := range jrnlTag.FindAllStringSubmatch(text, -1) {
+		tag := strings.TrimRight(m[1], "/-")
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// jrnlID derives a stable UUID from an entry's timestamp and message.
+func jrnlID(entry *charm.Entry) string {
+	name := entry.Timestamp.UTC().Format(time.RFC3339Nano) + "\x00" + entry.Message
+	return uuid.NewSHA1(jrnlNamespace, []byte(name)).String()
+}
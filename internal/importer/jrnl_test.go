@@ -0,0 +1,100 @@
+// ABOUTME: Tests for the jrnl importer
+// ABOUTME: Covers the plain-text journal format across jrnl versions and the JSON export
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const jrnlJournal = `[2024-03-01 09:15:00 AM] Standup with @work team. Went long.
+Talked about the @release plan.
+
+[2024-03-01 02:30:00 PM] * Shipped the thing @work @Work
+
+2023-12-31 23:59 Old jrnl 1 entry, email me@example.com
+`
+
+func TestReadJrnl(t *testing.T) {
+	entries, err := ReadJrnl(strings.NewReader(jrnlJournal))
+	if err != nil {
+		t.Fatalf("ReadJrnl: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+
+	first := entries[0]
+	if want := time.Date(2024, 3, 1, 9, 15, 0, 0, time.Local); !first.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", first.Timestamp, want)
+	}
+	if want := "Standup with @work team. Went long.\nTalked about the @release plan."; first.Message != want {
+		t.Errorf("message = %q, want %q", first.Message, want)
+	}
+	if !reflect.DeepEqual(first.Tags, []string{"work", "release"}) {
+		t.Errorf("tags = %v", first.Tags)
+	}
+	if first.Pinned {
+		t.Error("unstarred entry pinned")
+	}
+
+	second := entries[1]
+	if second.Timestamp.Hour() != 14 || !second.Pinned {
+		t.Errorf("second entry = %+v, want starred at 14:30", second)
+	}
+	if !reflect.DeepEqual(second.Tags, []string{"work"}) {
+		t.Errorf("tags = %v, want case-insensitive dedupe", second.Tags)
+	}
+
+	third := entries[2]
+	if third.Timestamp.Year() != 2023 || len(third.Tags) != 0 {
+		t.Errorf("third entry = %+v, want 2023 with no tags", third)
+	}
+
+	again, _ := ReadJrnl(strings.NewReader(jrnlJournal))
+	if again[0].ID != first.ID || first.ID == second.ID {
+		t.Error("IDs should be stable per entry and distinct across entries")
+	}
+}
+
+func TestReadJrnlRejectsStrayText(t *testing.T) {
+	if _, err := ReadJrnl(strings.NewReader("not a journal\n")); err == nil {
+		t.Error("expected an error for text before the first entry")
+	}
+	if _, err := ReadJrnl(strings.NewReader("[2024-13-45 99:00] nope\n")); err == nil {
+		t.Error("expected an error for a bad timestamp")
+	}
+}
+
+func TestReadJrnlJSON(t *testing.T) {
+	export := `{
+  "tags": {"@work": 1},
+  "entries": [
+    {"title": "Planning.", "body": "Sketched the roadmap.", "date": "2024-03-02", "time": "16:05", "tags": ["@work"], "starred": true},
+    {"title": "", "body": "", "date": "2024-03-03", "time": "10:00", "tags": [], "starred": false}
+  ]
+}`
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := os.WriteFile(path, []byte(export), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadJrnlFile(path)
+	if err != nil {
+		t.Fatalf("ReadJrnlFile: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want the empty one skipped", len(entries))
+	}
+	e := entries[0]
+	if e.Message != "Planning.\nSketched the roadmap." || !e.Pinned || !reflect.DeepEqual(e.Tags, []string{"work"}) {
+		t.Errorf("entry = %+v", e)
+	}
+	if want := time.Date(2024, 3, 2, 16, 5, 0, 0, time.Local); !e.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", e.Timestamp, want)
+	}
+}
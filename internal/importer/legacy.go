@@ -0,0 +1,254 @@
+// ABOUTME: Importers for legacy chronicle data (old SQLite backups, KV JSON exports)
+// ABOUTME: Maps pre-UUID integer IDs onto stable UUIDs so history survives upgrades
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harper/chronicle/internal/charm"
+	_ "modernc.org/sqlite" // SQLite driver for reading legacy backups
+)
+
+// sqliteMagic is the header every SQLite database file starts with.
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// legacyNamespace seeds the UUIDs generated for integer IDs. The old ID is
+// hashed together with the entry's timestamp and message, so importing the
+// same backup twice maps each entry to the same new ID instead of duplicating
+// it, while entry 1 from two different machines still gets distinct IDs.
+var legacyNamespace = uuid.MustParse("6f1f9a52-3c1e-4d0a-9a57-5d0b3e6c2a11")
+
+// legacyTimeLayouts are the timestamp formats older builds wrote to SQLite.
+var legacyTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// ReadLegacyFile reads entries from a legacy SQLite backup or a JSON/JSONL
+// KV export, detecting the format from the file contents.
+func ReadLegacyFile(path string) ([]charm.Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	header := make([]byte, len(sqliteMagic))
+	n, _ := io.ReadFull(f, header)
+	if n == len(sqliteMagic) && bytes.Equal(header, sqliteMagic) {
+		return ReadLegacySQLite(path)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return ReadLegacyJSON(f)
+}
+
+// ReadLegacySQLite reads entries and tags from an old chronicle SQLite
+// database. Both the original integer-ID schema and the later UUID schema
+// are supported; integer IDs are mapped to UUIDs.
+func ReadLegacySQLite(path string) ([]charm.Entry, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("open backup: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	rows, err := db.Query(`SELECT id, timestamp, message, hostname, username, working_directory FROM entries`)
+	if err != nil {
+		return nil, fmt.Errorf("select entries: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	// Map old IDs (integer or text) to entries for the tags pass
+	idMap := make(map[string]*charm.Entry)
+	var order []string
+
+	for rows.Next() {
+		var oldID, ts any
+		var message, hostname, username, workingDir string
+		if err := rows.Scan(&oldID, &ts, &message, &hostname, &username, &workingDir); err != nil {
+			return nil, fmt.Errorf("scan entry: %w", err)
+		}
+
+		timestamp, err := parseLegacyTime(ts)
+		if err != nil {
+			return nil, fmt.Errorf("entry %v: %w", oldID, err)
+		}
+
+		key := fmt.Sprint(oldID)
+		entry := &charm.Entry{
+			Timestamp:        timestamp,
+			Message:          message,
+			Hostname:         hostname,
+			Username:         username,
+			WorkingDirectory: workingDir,
+		}
+		entry.ID = mapLegacyID(key, entry)
+		idMap[key] = entry
+		order = append(order, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read entries: %w", err)
+	}
+
+	tagRows, err := db.Query(`SELECT entry_id, tag FROM tags`)
+	if err != nil {
+		return nil, fmt.Errorf("select tags: %w", err)
+	}
+	defer func() { _ = tagRows.Close() }()
+
+	for tagRows.Next() {
+		var entryID any
+		var tag string
+		if err := tagRows.Scan(&entryID, &tag); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		entry, ok := idMap[fmt.Sprint(entryID)]
+		if !ok {
+			continue // orphan tag, skip
+		}
+		entry.Tags = append(entry.Tags, tag)
+	}
+	if err := tagRows.Err(); err != nil {
+		return nil, fmt.Errorf("read tags: %w", err)
+	}
+
+	entries := make([]charm.Entry, 0, len(order))
+	for _, key := range order {
+		entries = append(entries, *idMap[key])
+	}
+	return entries, nil
+}
+
+// legacyJSONEntry is an exported KV entry whose ID may be a number.
+type legacyJSONEntry struct {
+	charm.Entry
+	ID json.RawMessage `json:"id"`
+}
+
+// ReadLegacyJSON reads entries from a JSON array or JSON-lines KV export.
+// Numeric IDs from older exports are mapped to UUIDs.
+func ReadLegacyJSON(r io.Reader) ([]charm.Entry, error) {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []legacyJSONEntry
+	if first == '[' {
+		if err := json.NewDecoder(br).Decode(&raw); err != nil {
+			return nil, fmt.Errorf("decode export: %w", err)
+		}
+	} else {
+		dec := json.NewDecoder(br)
+		for {
+			var e legacyJSONEntry
+			if err := dec.Decode(&e); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("decode export line %d: %w", len(raw)+1, err)
+			}
+			raw = append(raw, e)
+		}
+	}
+
+	entries := make([]charm.Entry, 0, len(raw))
+	for i, e := range raw {
+		entry := e.Entry
+		entry.Checksum = ""
+		id, err := legacyJSONID(e.ID, &entry)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		entry.ID = id
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// legacyJSONID returns a UUID for a JSON id that may be a string, number, or missing.
+func legacyJSONID(raw json.RawMessage, entry *charm.Entry) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return mapLegacyID("", entry), nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return mapLegacyID(s, entry), nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return "", fmt.Errorf("invalid id %s", raw)
+	}
+	return mapLegacyID(n.String(), entry), nil
+}
+
+// mapLegacyID keeps existing UUIDs and derives a stable UUID for anything else.
+func mapLegacyID(id string, entry *charm.Entry) string {
+	if _, err := uuid.Parse(id); err == nil {
+		return id
+	}
+	name := id + "\x00" + entry.Timestamp.UTC().Format(time.RFC3339Nano) + "\x00" + entry.Message
+	return uuid.NewSHA1(legacyNamespace, []byte(name)).String()
+}
+
+// parseLegacyTime converts a scanned SQLite timestamp into a time.Time.
+func parseLegacyTime(v any) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case int64:
+		return time.Unix(t, 0), nil
+	case []byte:
+		return parseLegacyTimeString(string(t))
+	case string:
+		return parseLegacyTimeString(t)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp %v", v)
+	}
+}
+
+// parseLegacyTimeString tries each known layout, then unix seconds.
+func parseLegacyTimeString(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range legacyTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+}
+
+// peekNonSpace returns the first non-whitespace byte without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, fmt.Errorf("empty export: %w", err)
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = br.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
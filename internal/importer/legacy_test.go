@@ -0,0 +1,132 @@
+// ABOUTME: Tests for legacy SQLite and JSON export importers
+// ABOUTME: Builds throwaway legacy databases in temp dirs
+package importer
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func createLegacyDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "backup.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	stmts := []string{
+		`CREATE TABLE entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			message TEXT NOT NULL,
+			hostname TEXT NOT NULL,
+			username TEXT NOT NULL,
+			working_directory TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL,
+			tag TEXT NOT NULL
+		)`,
+		`INSERT INTO entries (timestamp, message, hostname, username, working_directory)
+			VALUES ('2025-11-29 14:30:00', 'deployed v1', 'mbp', 'harper', '/src/app')`,
+		`INSERT INTO entries (timestamp, message, hostname, username, working_directory)
+			VALUES ('2025-11-30T09:00:00Z', 'fixed bug', 'mbp', 'harper', '/src/app')`,
+		`INSERT INTO tags (entry_id, tag) VALUES (1, 'work'), (1, 'deploy'), (99, 'orphan')`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+	return path
+}
+
+func TestReadLegacySQLite(t *testing.T) {
+	path := createLegacyDB(t)
+
+	entries, err := ReadLegacyFile(path)
+	if err != nil {
+		t.Fatalf("ReadLegacyFile: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	first := entries[0]
+	if _, err := uuid.Parse(first.ID); err != nil {
+		t.Errorf("expected UUID, got %q", first.ID)
+	}
+	if first.Message != "deployed v1" || first.Timestamp.Day() != 29 {
+		t.Errorf("unexpected entry: %+v", first)
+	}
+	if strings.Join(first.Tags, ",") != "work,deploy" {
+		t.Errorf("Tags = %v, want [work deploy]", first.Tags)
+	}
+	if len(entries[1].Tags) != 0 {
+		t.Errorf("expected no tags on second entry, got %v", entries[1].Tags)
+	}
+
+	// Re-reading maps to the same IDs so re-imports don't duplicate
+	again, err := ReadLegacySQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[0].ID != first.ID {
+		t.Error("expected stable IDs across imports")
+	}
+}
+
+func TestReadLegacyJSON(t *testing.T) {
+	const existing = "0b6f7a44-4f7e-4e8b-a7a8-0d5b2f1e9c33"
+	input := `{"id": 7, "timestamp": "2025-11-29T14:30:00Z", "message": "old export", "tags": ["work"]}
+{"id": "` + existing + `", "timestamp": "2025-11-30T14:30:00Z", "message": "uuid export", "checksum": "stale"}
+`
+	entries, err := ReadLegacyJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadLegacyJSON: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if _, err := uuid.Parse(entries[0].ID); err != nil {
+		t.Errorf("expected numeric ID mapped to UUID, got %q", entries[0].ID)
+	}
+	if entries[1].ID != existing {
+		t.Errorf("expected existing UUID kept, got %q", entries[1].ID)
+	}
+	if entries[1].Checksum != "" {
+		t.Error("expected imported checksum to be cleared")
+	}
+
+	array, err := ReadLegacyJSON(strings.NewReader("  [" + strings.ReplaceAll(strings.TrimSpace(input), "\n", ",") + "]"))
+	if err != nil {
+		t.Fatalf("ReadLegacyJSON array: %v", err)
+	}
+	if len(array) != 2 || array[0].ID != entries[0].ID {
+		t.Errorf("array form should match JSONL form, got %+v", array)
+	}
+}
+
+func TestParseLegacyTimeString(t *testing.T) {
+	for _, s := range []string{
+		"2025-11-29 14:30:00",
+		"2025-11-29T14:30:00Z",
+		"2025-11-29 14:30:00.123456789-08:00",
+		"1764426600",
+	} {
+		if _, err := parseLegacyTimeString(s); err != nil {
+			t.Errorf("parseLegacyTimeString(%q): %v", s, err)
+		}
+	}
+	if _, err := parseLegacyTimeString("yesterday"); err == nil {
+		t.Error("expected error for unrecognized timestamp")
+	}
+}
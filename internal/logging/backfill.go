@@ -0,0 +1,68 @@
+// ABOUTME: Backfilling project log files from entries already in the database
+// ABOUTME: Groups entries by log file and writes files that don't exist yet (or all, when overwriting)
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+)
+
+// BackfillResult reports what Backfill wrote.
+type BackfillResult struct {
+	Files   int
+	Entries int
+	// Skipped lists existing files left alone because overwrite was off
+	Skipped []string
+}
+
+// Backfill writes entries to the log files they belong in, oldest first.
+// Files that already exist are skipped unless overwrite is set, in which
+// case they are regenerated from entries alone. Obsidian daily notes hold
+// the user's own writing too, so they are never overwritten.
+func Backfill(logDir, format, rotation string, tmpl *template.Template, entries []Entry, overwrite bool) (BackfillResult, error) {
+	var result BackfillResult
+	if overwrite && format == FormatObsidian {
+		return result, fmt.Errorf("refusing to overwrite Obsidian daily notes")
+	}
+
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	byFile := make(map[string][]Entry)
+	var names []string
+	for _, entry := range sorted {
+		name, err := LogFileName(format, rotation, entry.Timestamp)
+		if err != nil {
+			return result, err
+		}
+		if _, ok := byFile[name]; !ok {
+			names = append(names, name)
+		}
+		byFile[name] = append(byFile[name], entry)
+	}
+
+	for _, name := range names {
+		path := filepath.Join(logDir, name)
+		if _, err := os.Stat(path); err == nil {
+			if !overwrite {
+				result.Skipped = append(result.Skipped, name)
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				return result, fmt.Errorf("failed to replace %s: %w", name, err)
+			}
+		}
+		for _, entry := range byFile[name] {
+			if err := WriteProjectLog(logDir, format, rotation, tmpl, entry); err != nil {
+				return result, fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+		result.Files++
+		result.Entries += len(byFile[name])
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+// ABOUTME: Tests for backfilling project logs from stored entries
+// ABOUTME: Covers grouping by file, skipping existing files, and overwriting
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackfill(t *testing.T) {
+	logDir := t.TempDir()
+	at := func(day, hour int) time.Time { return time.Date(2025, 11, day, hour, 0, 0, 0, time.Local) }
+	// Newest first, the way the database returns them
+	entries := []Entry{
+		{Timestamp: at(29, 9), Message: "third"},
+		{Timestamp: at(28, 15), Message: "second"},
+		{Timestamp: at(28, 9), Message: "first"},
+	}
+
+	// The 29th was written after local logging was turned on
+	existing := filepath.Join(logDir, "2025-11-29.log")
+	_ = os.WriteFile(existing, []byte("## 09:00:00 - third\n\n"), 0644) //nolint:gosec // Test file permissions
+
+	result, err := Backfill(logDir, "markdown", "", nil, entries, false)
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if result.Files != 1 || result.Entries != 2 || len(result.Skipped) != 1 || result.Skipped[0] != "2025-11-29.log" {
+		t.Errorf("got result %+v", result)
+	}
+	content, err := os.ReadFile(filepath.Join(logDir, "2025-11-28.log")) //nolint:gosec // Reading test file
+	if err != nil {
+		t.Fatalf("failed to read backfilled log: %v", err)
+	}
+	if first, second := strings.Index(string(content), "first"), strings.Index(string(content), "second"); first < 0 || second < first {
+		t.Errorf("backfilled entries should be oldest first:\n%s", content)
+	}
+
+	// Overwriting regenerates both files instead of appending to them
+	result, err = Backfill(logDir, "markdown", "", nil, entries, true)
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if result.Files != 2 || result.Entries != 3 || len(result.Skipped) != 0 {
+		t.Errorf("got result %+v", result)
+	}
+	content, _ = os.ReadFile(filepath.Join(logDir, "2025-11-28.log")) //nolint:gosec // Reading test file
+	if strings.Count(string(content), "first") != 1 {
+		t.Errorf("overwrite should replace the file:\n%s", content)
+	}
+}
+
+func TestBackfillObsidianOverwrite(t *testing.T) {
+	entries := []Entry{{Timestamp: time.Now(), Message: "x"}}
+	if _, err := Backfill(t.TempDir(), FormatObsidian, "", nil, entries, true); err == nil {
+		t.Error("expected overwriting Obsidian notes to be refused")
+	}
+}
@@ -0,0 +1,239 @@
+// ABOUTME: Generated INDEX.md for a project log directory
+// ABOUTME: Lists each log file with its period, entry count, and most used tags
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/config"
+)
+
+// IndexFileName is the index written into a project's log directory.
+const IndexFileName = "INDEX.md"
+
+// indexTopTags is how many tags each index row lists.
+const indexTopTags = 5
+
+// entryHeading matches the heading the built-in markdown layout starts each
+// entry with, so other headings in a note (an Obsidian daily note's own
+// sections, say) aren't counted.
+var entryHeading = regexp.MustCompile(`^## (\d{4}-\d{2}-\d{2} )?\d{2}:\d{2}:\d{2} - `)
+
+// TagCount is how many entries in a log file carry Tag.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// IndexFile describes one project log file. Entries is -1 when the file's
+// entries can't be counted (markdown written with a custom template).
+type IndexFile struct {
+	Name    string
+	Period  string
+	Start   time.Time
+	Entries int
+	Tags    []TagCount
+}
+
+// BuildIndex describes every log file in logDir, newest period first.
+// Files whose names aren't a daily, weekly, or monthly period are skipped.
+// countMarkdown says whether markdown files use the built-in layout, whose
+// headings and tag lines can be counted.
+func BuildIndex(logDir string, countMarkdown bool) ([]IndexFile, error) {
+	dirEntries, err := os.ReadDir(logDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log directory: %w", err)
+	}
+
+	var files []IndexFile
+	for _, de := range dirEntries {
+		ext := filepath.Ext(de.Name())
+		if de.IsDir() || (ext != ".log" && ext != ".jsonl" && ext != ".md") {
+			continue
+		}
+		start, period, ok := parseLogPeriod(strings.TrimSuffix(de.Name(), ext))
+		if ext == ".md" {
+			start, period, ok = parseObsidianPeriod(strings.TrimSuffix(de.Name(), ext))
+		}
+		if !ok {
+			continue
+		}
+		count, tags, err := countLogFile(filepath.Join(logDir, de.Name()), countMarkdown)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, IndexFile{Name: de.Name(), Period: period, Start: start, Entries: count, Tags: tags})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].Start.Equal(files[j].Start) {
+			return files[i].Start.After(files[j].Start)
+		}
+		return files[i].Name > files[j].Name
+	})
+	return files, nil
+}
+
+// WriteIndex regenerates logDir's INDEX.md from the log files in it.
+func WriteIndex(logDir string, countMarkdown bool) error {
+	files, err := BuildIndex(logDir, countMarkdown)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Project Log Index\n\n")
+	sb.WriteString("_Generated by chronicle. Edits will be overwritten._\n\n")
+	sb.WriteString("| File | Period | Entries | Tags |\n")
+	sb.WriteString("|------|--------|---------|------|\n")
+	for _, f := range files {
+		entries, tags := "–", "–"
+		if f.Entries >= 0 {
+			entries = fmt.Sprintf("%d", f.Entries)
+			parts := make([]string, len(f.Tags))
+			for i, tc := range f.Tags {
+				parts[i] = fmt.Sprintf("%s (%d)", tc.Tag, tc.Count)
+			}
+			tags = strings.Join(parts, ", ")
+		}
+		sb.WriteString(fmt.Sprintf("| [%s](%s) | %s | %s | %s |\n", f.Name, f.Name, f.Period, entries, tags))
+	}
+
+	// Write through a temp file so readers never see a half-written index
+	tmp, err := os.CreateTemp(logDir, ".index-*.md")
+	if err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(sb.String()); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil { //nolint:gosec // Standard file permissions for log files
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(logDir, IndexFileName)); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// parseLogPeriod reads a log file name (without extension) written by
+// LogFileBase, returning when its period starts and a readable label.
+func parseLogPeriod(base string) (time.Time, string, bool) {
+	if t, err := time.ParseInLocation("2006-01-02", base, time.Local); err == nil {
+		return t, t.Format("2006-01-02 (Monday)"), true
+	}
+	if t, err := time.ParseInLocation("2006-01", base, time.Local); err == nil {
+		return t, t.Format("January 2006"), true
+	}
+	var year, week int
+	if n, err := fmt.Sscanf(base, "%4d-W%2d", &year, &week); err == nil && n == 2 && base == fmt.Sprintf("%04d-W%02d", year, week) && week >= 1 && week <= 53 {
+		start := isoWeekStart(year, week)
+		return start, "Week of " + start.Format("2006-01-02"), true
+	}
+	return time.Time{}, "", false
+}
+
+// parseObsidianPeriod reads the name (without extension) of an Obsidian
+// daily note.
+func parseObsidianPeriod(base string) (time.Time, string, bool) {
+	t, err := time.ParseInLocation(config.ObsidianDailyNoteLayout, base, time.Local)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return t, t.Format("2006-01-02 (Monday)"), true
+}
+
+// isoWeekStart returns the Monday starting ISO week of year.
+func isoWeekStart(year, week int) time.Time {
+	// January 4th is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.Local)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	return monday.AddDate(0, 0, (week-1)*7)
+}
+
+// countLogFile counts the entries in a log file and their most used tags.
+// JSON and jsonl files have one entry per line; built-in markdown has one
+// "## HH:MM:SS - " heading per entry and a "- **Tags**:" line when tagged.
+func countLogFile(path string, countMarkdown bool) (int, []TagCount, error) {
+	f, err := os.Open(path) //nolint:gosec // Path is inside the project's log directory
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read log file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	count := 0
+	tagCounts := make(map[string]int)
+	format := ""
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if format == "" {
+			format = "markdown"
+			if strings.HasPrefix(line, "{") {
+				format = "json"
+			}
+			if format == "markdown" && !countMarkdown {
+				return -1, nil, nil
+			}
+		}
+
+		switch {
+		case format == "json":
+			var record struct {
+				Tags []string `json:"tags"`
+			}
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				continue
+			}
+			count++
+			for _, tag := range record.Tags {
+				tagCounts[tag]++
+			}
+		case entryHeading.MatchString(line):
+			count++
+		case strings.HasPrefix(line, "- **Tags**: "):
+			for _, tag := range strings.Split(strings.TrimPrefix(line, "- **Tags**: "), ", ") {
+				tagCounts[tag]++
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, nil, fmt.Errorf("failed to read log file: %w", err)
+	}
+	return count, topTagCounts(tagCounts, indexTopTags), nil
+}
+
+// topTagCounts returns up to n tags, most used first, ties by name.
+func topTagCounts(counts map[string]int, n int) []TagCount {
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	if len(tags) > n {
+		tags = tags[:n]
+	}
+	return tags
+}
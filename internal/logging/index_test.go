@@ -0,0 +1,115 @@
+// ABOUTME: Tests for the generated project log index
+// ABOUTME: Covers period parsing, entry and tag counting, and INDEX.md output
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogPeriod(t *testing.T) {
+	tests := []struct {
+		base  string
+		start time.Time
+		label string
+	}{
+		{"2025-11-29", time.Date(2025, 11, 29, 0, 0, 0, 0, time.Local), "2025-11-29 (Saturday)"},
+		{"2025-W48", time.Date(2025, 11, 24, 0, 0, 0, 0, time.Local), "Week of 2025-11-24"},
+		{"2025-W01", time.Date(2024, 12, 30, 0, 0, 0, 0, time.Local), "Week of 2024-12-30"},
+		{"2025-11", time.Date(2025, 11, 1, 0, 0, 0, 0, time.Local), "November 2025"},
+	}
+	for _, tt := range tests {
+		start, label, ok := parseLogPeriod(tt.base)
+		if !ok || !start.Equal(tt.start) || label != tt.label {
+			t.Errorf("parseLogPeriod(%q) = %v, %q, %v; want %v, %q", tt.base, start, label, ok, tt.start, tt.label)
+		}
+	}
+	for _, bad := range []string{"INDEX", "notes", "2025-W1", "2025-W60"} {
+		if _, _, ok := parseLogPeriod(bad); ok {
+			t.Errorf("parseLogPeriod(%q) should not match", bad)
+		}
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	logDir := t.TempDir()
+	write := func(format, rotation string, day int, tags ...string) {
+		t.Helper()
+		entry := Entry{
+			Timestamp: time.Date(2025, 11, day, 10, 0, 0, 0, time.Local),
+			Message:   "did things",
+			Tags:      tags,
+		}
+		if err := WriteProjectLog(logDir, format, rotation, nil, entry); err != nil {
+			t.Fatalf("WriteProjectLog failed: %v", err)
+		}
+	}
+	write("markdown", "", 28, "work", "deploy")
+	write("markdown", "", 28, "work")
+	write("markdown", "", 29)
+	write("jsonl", "", 29, "bug")
+	write("markdown", RotationMonthly, 3, "old")
+	_ = os.WriteFile(filepath.Join(logDir, "notes.txt"), []byte("not a log"), 0644) //nolint:gosec // Test file permissions
+
+	if err := WriteIndex(logDir, true); err != nil {
+		t.Fatalf("WriteIndex failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(logDir, IndexFileName)) //nolint:gosec // Reading test file
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	got := string(content)
+
+	rows := []string{
+		"| [2025-11-29.log](2025-11-29.log) | 2025-11-29 (Saturday) | 1 |  |",
+		"| [2025-11-29.jsonl](2025-11-29.jsonl) | 2025-11-29 (Saturday) | 1 | bug (1) |",
+		"| [2025-11-28.log](2025-11-28.log) | 2025-11-28 (Friday) | 2 | work (2), deploy (1) |",
+		"| [2025-11.log](2025-11.log) | November 2025 | 1 | old (1) |",
+	}
+	last := -1
+	for _, row := range rows {
+		i := strings.Index(got, row)
+		if i < 0 {
+			t.Fatalf("index missing row %q:\n%s", row, got)
+		}
+		if i < last {
+			t.Errorf("row %q out of order:\n%s", row, got)
+		}
+		last = i
+	}
+	if strings.Contains(got, "notes.txt") || strings.Contains(got, "INDEX.md](") {
+		t.Errorf("index should only list log files:\n%s", got)
+	}
+
+	// Rewriting replaces the index rather than appending to it
+	if err := WriteIndex(logDir, true); err != nil {
+		t.Fatalf("WriteIndex failed: %v", err)
+	}
+	again, _ := os.ReadFile(filepath.Join(logDir, IndexFileName)) //nolint:gosec // Reading test file
+	if string(again) != got {
+		t.Errorf("index changed on rewrite:\n%s", again)
+	}
+}
+
+func TestWriteIndexCustomTemplate(t *testing.T) {
+	logDir := t.TempDir()
+	tmpl, err := ParseEntryTemplate("* {{.Message}}")
+	if err != nil {
+		t.Fatalf("ParseEntryTemplate failed: %v", err)
+	}
+	entry := Entry{Timestamp: time.Date(2025, 11, 29, 10, 0, 0, 0, time.Local), Message: "templated", Tags: []string{"x"}}
+	if err := WriteProjectLog(logDir, "markdown", "", tmpl, entry); err != nil {
+		t.Fatalf("WriteProjectLog failed: %v", err)
+	}
+
+	files, err := BuildIndex(logDir, false)
+	if err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Entries != -1 {
+		t.Errorf("templated markdown should not be counted, got %+v", files)
+	}
+}
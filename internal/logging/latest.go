@@ -0,0 +1,87 @@
+// ABOUTME: A stable "latest" name for the newest project log file
+// ABOUTME: Kept as a symlink, or a copy where symlinks aren't available
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// latestBase is the name, without extension, of the latest log link.
+const latestBase = "latest"
+
+// UpdateLatest points latest.log (latest.jsonl, latest.md) in logDir at the
+// newest log file of format. Where symlinks can't be made, the newest
+// file is copied instead. Does nothing if there are no log files yet.
+func UpdateLatest(logDir, format string) error {
+	ext := logFileExt(format)
+	newest, err := newestLogFile(logDir, format)
+	if err != nil || newest == "" {
+		return err
+	}
+	latest := filepath.Join(logDir, latestBase+ext)
+
+	// Replace through a temp name so the link never disappears for readers
+	tmp := filepath.Join(logDir, ".latest-tmp"+ext)
+	_ = os.Remove(tmp)
+	if err := os.Symlink(newest, tmp); err != nil {
+		if err := copyFile(filepath.Join(logDir, newest), tmp); err != nil {
+			return fmt.Errorf("failed to update %s: %w", filepath.Base(latest), err)
+		}
+	}
+	if err := os.Rename(tmp, latest); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to update %s: %w", filepath.Base(latest), err)
+	}
+	return nil
+}
+
+// newestLogFile returns the name of format's log file in logDir covering
+// the latest period, or "" if there is none.
+func newestLogFile(logDir, format string) (string, error) {
+	dirEntries, err := os.ReadDir(logDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read log directory: %w", err)
+	}
+	ext := logFileExt(format)
+	var newest string
+	var newestStart time.Time
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || filepath.Ext(name) != ext {
+			continue
+		}
+		base := strings.TrimSuffix(name, ext)
+		start, _, ok := parseLogPeriod(base)
+		if format == FormatObsidian {
+			start, _, ok = parseObsidianPeriod(base)
+		}
+		if ok && (newest == "" || start.After(newestStart) || (start.Equal(newestStart) && name > newest)) {
+			newest, newestStart = name, start
+		}
+	}
+	return newest, nil
+}
+
+// copyFile copies src to dst, replacing dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) //nolint:gosec // Path is inside the project's log directory
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644) //nolint:gosec // Standard file permissions for log files
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,48 @@
+// ABOUTME: Tests for the latest log link
+// ABOUTME: Covers following the newest file and leaving other files out of the index
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUpdateLatest(t *testing.T) {
+	logDir := t.TempDir()
+	if err := UpdateLatest(logDir, "markdown"); err != nil {
+		t.Fatalf("UpdateLatest on an empty directory failed: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(logDir, "latest.log")); !os.IsNotExist(err) {
+		t.Error("latest.log should not exist without log files")
+	}
+
+	for _, day := range []int{28, 29} {
+		entry := Entry{Timestamp: time.Date(2025, 11, day, 9, 0, 0, 0, time.Local), Message: "day entry"}
+		if err := WriteProjectLog(logDir, "markdown", "", nil, entry); err != nil {
+			t.Fatalf("WriteProjectLog failed: %v", err)
+		}
+		if err := UpdateLatest(logDir, "markdown"); err != nil {
+			t.Fatalf("UpdateLatest failed: %v", err)
+		}
+	}
+
+	latest := filepath.Join(logDir, "latest.log")
+	if target, err := os.Readlink(latest); err == nil && target != "2025-11-29.log" {
+		t.Errorf("latest.log points at %s, want 2025-11-29.log", target)
+	}
+	want, _ := os.ReadFile(filepath.Join(logDir, "2025-11-29.log")) //nolint:gosec // Reading test file
+	got, err := os.ReadFile(latest)                                 //nolint:gosec // Reading test file
+	if err != nil || string(got) != string(want) {
+		t.Errorf("latest.log = %q, %v; want the contents of 2025-11-29.log", got, err)
+	}
+
+	files, err := BuildIndex(logDir, true)
+	if err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("index should skip latest.log, got %+v", files)
+	}
+}
@@ -0,0 +1,29 @@
+// ABOUTME: Unix locking of a project's log directory
+// ABOUTME: Flocks the directory itself, which leaves no lock file among the logs
+
+//go:build !windows
+
+package logging
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockDir blocks until it holds an exclusive lock on dir and returns the
+// function that releases it.
+func lockDir(dir string) (func(), error) {
+	lock, err := os.Open(dir) //nolint:gosec // Locking the project's own log directory
+	if err != nil {
+		return nil, fmt.Errorf("open log directory: %w", err)
+	}
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		_ = lock.Close()
+		return nil, fmt.Errorf("lock log directory: %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+		_ = lock.Close()
+	}, nil
+}
@@ -0,0 +1,43 @@
+// ABOUTME: Windows locking of a project's log directory
+// ABOUTME: Directories can't be locked there, so a lock file in the temp directory stands in for it
+
+//go:build windows
+
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockDir blocks until it holds an exclusive lock on dir and returns the
+// function that releases it. The lock file is named after dir's path and
+// kept out of the logs.
+func lockDir(dir string) (func(), error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("lock log directory: %w", err)
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(abs)))
+	path := filepath.Join(os.TempDir(), "chronicle-log-"+hex.EncodeToString(sum[:8])+".lock")
+	lock, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("open log directory lock: %w", err)
+	}
+	var ol windows.Overlapped
+	if err := windows.LockFileEx(windows.Handle(lock.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, &ol); err != nil {
+		_ = lock.Close()
+		return nil, fmt.Errorf("lock log directory: %w", err)
+	}
+	return func() {
+		var ol windows.Overlapped
+		_ = windows.UnlockFileEx(windows.Handle(lock.Fd()), 0, 1, 0, &ol)
+		_ = lock.Close()
+	}, nil
+}
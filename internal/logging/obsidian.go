@@ -0,0 +1,216 @@
+// ABOUTME: Obsidian daily-note log format with YAML frontmatter
+// ABOUTME: Appends entries to DD-MM-YYYY.md notes and keeps their date and tags frontmatter current
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// FormatObsidian writes entries into Obsidian daily notes.
+const FormatObsidian = "obsidian"
+
+// writeObsidianNote adds entry to the daily note at path, creating the note
+// with date and tags frontmatter or merging the entry's tags into an
+// existing note's frontmatter. The rest of an existing note is kept as is.
+func writeObsidianNote(path string, tmpl *template.Template, entry Entry) error {
+	t := entry.Timestamp.Local()
+
+	body := formatMarkdown(entry, "15:04:05")
+	if tmpl != nil {
+		var err error
+		if body, err = formatTemplate(tmpl, entry, "15:04:05"); err != nil {
+			return err
+		}
+	}
+
+	existing, err := os.ReadFile(path) //nolint:gosec // Path is inside the configured log directory
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	tags := obsidianTags(entry.Tags)
+	var note string
+	if len(existing) == 0 {
+		note = newFrontmatter(t.Format("2006-01-02"), tags) + "\n" + body
+	} else {
+		note = mergeFrontmatterTags(string(existing), t.Format("2006-01-02"), tags)
+		// Leave one blank line between the existing note and the entry
+		if !strings.HasSuffix(note, "\n") {
+			note += "\n"
+		}
+		if !strings.HasSuffix(note, "\n\n") {
+			note += "\n"
+		}
+		note += body
+	}
+
+	// Write through a temp file so Obsidian never sees a half-written note
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".chronicle-*.md")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(note); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil { //nolint:gosec // Standard file permissions for log files
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// obsidianTags converts chronicle tags to Obsidian tags, which can't hold
+// spaces or most punctuation.
+func obsidianTags(tags []string) []string {
+	var out []string
+	for _, tag := range tags {
+		converted := strings.Map(func(r rune) rune {
+			switch {
+			case r == '-' || r == '_' || r == '/':
+				return r
+			case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r > 127:
+				return r
+			default:
+				return '-'
+			}
+		}, strings.TrimSpace(tag))
+		if converted != "" && !containsString(out, converted) {
+			out = append(out, converted)
+		}
+	}
+	return out
+}
+
+// newFrontmatter renders frontmatter for a new daily note.
+func newFrontmatter(date string, tags []string) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.WriteString("date: " + date + "\n")
+	if len(tags) > 0 {
+		sb.WriteString("tags:\n")
+		for _, tag := range tags {
+			sb.WriteString("  - " + tag + "\n")
+		}
+	}
+	sb.WriteString("---\n")
+	return sb.String()
+}
+
+// mergeFrontmatterTags adds tags missing from note's frontmatter, which
+// may list them as a block ("tags:" then "- a" lines) or inline
+// ("tags: [a, b]" or "tags: a"). A note without frontmatter gets some.
+func mergeFrontmatterTags(note, date string, tags []string) string {
+	lines := strings.SplitAfter(note, "\n")
+	if strings.TrimSpace(lines[0]) != "---" {
+		return newFrontmatter(date, tags) + "\n" + note
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		// An unterminated block isn't frontmatter Obsidian would read
+		return newFrontmatter(date, tags) + "\n" + note
+	}
+	if len(tags) == 0 {
+		return note
+	}
+
+	tagsLine := -1
+	for i := 1; i < end; i++ {
+		if strings.HasPrefix(lines[i], "tags:") {
+			tagsLine = i
+			break
+		}
+	}
+
+	var head []string
+	switch {
+	case tagsLine < 0:
+		head = append(head, lines[:end]...)
+		head = append(head, "tags:\n")
+		for _, tag := range tags {
+			head = append(head, "  - "+tag+"\n")
+		}
+
+	case strings.TrimSpace(strings.TrimPrefix(lines[tagsLine], "tags:")) == "":
+		// Block list: the items are the indented "- " lines that follow
+		last := tagsLine
+		var have []string
+		for i := tagsLine + 1; i < end; i++ {
+			item := strings.TrimSpace(lines[i])
+			if !strings.HasPrefix(item, "- ") && item != "-" {
+				break
+			}
+			have = append(have, unquoteYAML(strings.TrimSpace(strings.TrimPrefix(item, "-"))))
+			last = i
+		}
+		head = append(head, lines[:last+1]...)
+		for _, tag := range tags {
+			if !containsString(have, tag) {
+				head = append(head, "  - "+tag+"\n")
+			}
+		}
+		head = append(head, lines[last+1:end]...)
+
+	default:
+		// Inline list or single value
+		value := strings.TrimSpace(strings.TrimPrefix(lines[tagsLine], "tags:"))
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+		var have []string
+		for _, item := range strings.Split(value, ",") {
+			if item = unquoteYAML(strings.TrimSpace(item)); item != "" {
+				have = append(have, item)
+			}
+		}
+		merged := have
+		for _, tag := range tags {
+			if !containsString(merged, tag) {
+				merged = append(merged, tag)
+			}
+		}
+		head = append(head, lines[:tagsLine]...)
+		head = append(head, "tags: ["+strings.Join(merged, ", ")+"]\n")
+		head = append(head, lines[tagsLine+1:end]...)
+	}
+
+	return strings.Join(head, "") + strings.Join(lines[end:], "")
+}
+
+// unquoteYAML strips the quotes from a quoted YAML scalar.
+func unquoteYAML(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// containsString reports whether list holds s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checkObsidianRotation rejects rotations other than daily, since
+// Obsidian notes are per day.
+func checkObsidianRotation(rotation string) error {
+	if rotation != "" && rotation != RotationDaily {
+		return fmt.Errorf("log format %s writes daily notes and can't use %s rotation", FormatObsidian, rotation)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// ABOUTME: Tests for the Obsidian daily-note log format
+// ABOUTME: Covers note naming, frontmatter creation, and merging tags into existing notes
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteProjectLogObsidian(t *testing.T) {
+	dir := t.TempDir()
+	write := func(msg string, tags ...string) {
+		t.Helper()
+		entry := Entry{
+			Timestamp:        time.Date(2025, 11, 29, 14, 32, 15, 0, time.Local),
+			Message:          msg,
+			Tags:             tags,
+			Username:         "harper",
+			Hostname:         "laptop",
+			WorkingDirectory: "/repo",
+		}
+		if err := WriteProjectLog(dir, FormatObsidian, "", nil, entry); err != nil {
+			t.Fatalf("WriteProjectLog failed: %v", err)
+		}
+	}
+	write("deployed v2.1.0", "work", "deployment")
+	write("fixed login", "work", "bug fix")
+
+	content, err := os.ReadFile(filepath.Join(dir, "29-11-2025.md")) //nolint:gosec // Reading test file
+	if err != nil {
+		t.Fatalf("failed to read daily note: %v", err)
+	}
+	want := "---\ndate: 2025-11-29\ntags:\n  - work\n  - deployment\n  - bug-fix\n---\n\n" +
+		"## 14:32:15 - deployed v2.1.0\n- **Tags**: work, deployment\n- **User**: harper@laptop\n- **Directory**: /repo\n\n" +
+		"## 14:32:15 - fixed login\n- **Tags**: work, bug fix\n- **User**: harper@laptop\n- **Directory**: /repo\n\n"
+	if string(content) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", content, want)
+	}
+
+	if err := WriteProjectLog(dir, FormatObsidian, RotationWeekly, nil, Entry{Timestamp: time.Now()}); err == nil {
+		t.Error("expected an error for weekly obsidian notes")
+	}
+}
+
+func TestMergeFrontmatterTags(t *testing.T) {
+	tests := []struct {
+		name string
+		note string
+		want string
+	}{
+		{
+			name: "no frontmatter",
+			note: "# My day\n",
+			want: "---\ndate: 2025-11-29\ntags:\n  - work\n---\n\n# My day\n",
+		},
+		{
+			name: "frontmatter without tags",
+			note: "---\nmood: good\n---\n# My day\n",
+			want: "---\nmood: good\ntags:\n  - work\n---\n# My day\n",
+		},
+		{
+			name: "block list",
+			note: "---\ntags:\n  - daily\n  - \"work\"\nmood: good\n---\nbody\n",
+			want: "---\ntags:\n  - daily\n  - \"work\"\nmood: good\n---\nbody\n",
+		},
+		{
+			name: "inline list",
+			note: "---\ntags: [daily]\n---\nbody\n",
+			want: "---\ntags: [daily, work]\n---\nbody\n",
+		},
+		{
+			name: "single value",
+			note: "---\ntags: daily\n---\nbody\n",
+			want: "---\ntags: [daily, work]\n---\nbody\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeFrontmatterTags(tt.note, "2025-11-29", []string{"work"}); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteProjectLogObsidianExistingNote(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "29-11-2025.md")
+	_ = os.WriteFile(path, []byte("---\ntags:\n  - daily\n---\n# Saturday\n\nWent for a walk."), 0644) //nolint:gosec // Test file permissions
+
+	entry := Entry{Timestamp: time.Date(2025, 11, 29, 9, 0, 0, 0, time.Local), Message: "planned week", Tags: []string{"planning"}}
+	if err := WriteProjectLog(dir, FormatObsidian, "", nil, entry); err != nil {
+		t.Fatalf("WriteProjectLog failed: %v", err)
+	}
+	content, _ := os.ReadFile(path) //nolint:gosec // Reading test file
+	got := string(content)
+	if !strings.HasPrefix(got, "---\ntags:\n  - daily\n  - planning\n---\n# Saturday\n\nWent for a walk.\n\n## 09:00:00 - planned week\n") {
+		t.Errorf("existing note not preserved:\n%s", got)
+	}
+
+	files, err := BuildIndex(dir, true)
+	if err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Entries != 1 || files[0].Period != "2025-11-29 (Saturday)" {
+		t.Errorf("index of daily notes = %+v", files)
+	}
+}
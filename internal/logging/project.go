@@ -1,5 +1,5 @@
 // ABOUTME: Project log file writing
-// ABOUTME: Formats entries as markdown or JSON and appends to daily logs
+// ABOUTME: Formats entries as markdown (optionally templated), JSON, versioned JSONL, or Obsidian daily notes
 package logging
 
 import (
@@ -8,22 +8,108 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 	"time"
+
+	"github.com/harper/chronicle/internal/config"
 )
 
+// defaultEntryType is the entry type that gets no Type line in markdown.
+// It must match charm.EntryTypeNote; logging does not import charm so the
+// value is duplicated here.
+const defaultEntryType = "note"
+
+// JSONLSchemaVersion is written on every jsonl record. It changes only when
+// a field is removed or changes meaning; new fields may appear without it.
+const JSONLSchemaVersion = 1
+
+// jsonlRecord is one line of a jsonl project log. Keys are snake_case and
+// always appear in this order.
+type jsonlRecord struct {
+	SchemaVersion    int      `json:"schema_version"`
+	ID               string   `json:"id"`
+	Timestamp        string   `json:"timestamp"`
+	Type             string   `json:"type"`
+	Message          string   `json:"message"`
+	Tags             []string `json:"tags"`
+	Hostname         string   `json:"hostname"`
+	Username         string   `json:"username"`
+	WorkingDirectory string   `json:"working_directory"`
+}
+
 // Entry represents a log entry for project logging.
 type Entry struct {
 	ID               string    `json:"id"`
 	Timestamp        time.Time `json:"timestamp"`
 	Message          string    `json:"message"`
+	Type             string    `json:"type,omitempty"`
 	Hostname         string    `json:"hostname"`
 	Username         string    `json:"username"`
 	WorkingDirectory string    `json:"working_directory"`
 	Tags             []string  `json:"tags"`
 }
 
-// WriteProjectLog appends entry to project log file.
-func WriteProjectLog(logDir, format string, entry Entry) error {
+// Log rotation policies: how much time one project log file covers.
+const (
+	RotationDaily   = "daily"
+	RotationWeekly  = "weekly"
+	RotationMonthly = "monthly"
+)
+
+// Rotations lists every valid log rotation policy.
+var Rotations = []string{RotationDaily, RotationWeekly, RotationMonthly}
+
+// LogFileBase returns the log file name, without extension, holding an
+// entry written at t (local time) under rotation: 2025-11-29 for daily,
+// 2025-W48 (ISO week) for weekly, and 2025-11 for monthly. Empty rotation
+// means daily.
+func LogFileBase(rotation string, t time.Time) (string, error) {
+	t = t.Local()
+	switch rotation {
+	case "", RotationDaily:
+		return t.Format("2006-01-02"), nil
+	case RotationWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week), nil
+	case RotationMonthly:
+		return t.Format("2006-01"), nil
+	default:
+		return "", fmt.Errorf("unknown log rotation %q (use %s)", rotation, strings.Join(Rotations, ", "))
+	}
+}
+
+// LogFileName returns the name of the log file, in format and rotation,
+// that holds an entry written at t.
+func LogFileName(format, rotation string, t time.Time) (string, error) {
+	if format == FormatObsidian {
+		if err := checkObsidianRotation(rotation); err != nil {
+			return "", err
+		}
+		return t.Local().Format(config.ObsidianDailyNoteLayout) + logFileExt(format), nil
+	}
+	base, err := LogFileBase(rotation, t)
+	if err != nil {
+		return "", err
+	}
+	return base + logFileExt(format), nil
+}
+
+// logFileExt returns the extension of format's log files.
+func logFileExt(format string) string {
+	switch format {
+	case "jsonl":
+		return ".jsonl"
+	case FormatObsidian:
+		return ".md"
+	default:
+		return ".log"
+	}
+}
+
+// WriteProjectLog appends entry to the project log file that rotation
+// (daily, weekly, or monthly; empty means daily) assigns it to. A non-nil
+// tmpl replaces the built-in markdown layout; other formats ignore it.
+func WriteProjectLog(logDir, format, rotation string, tmpl *template.Template, entry Entry) error {
 	// Validate timestamp is not zero
 	if entry.Timestamp.IsZero() {
 		return fmt.Errorf("entry timestamp is zero")
@@ -37,13 +123,26 @@ func WriteProjectLog(logDir, format string, entry Entry) error {
 		return err
 	}
 
-	// Determine log file name (one per day in local time)
-	date := entry.Timestamp.Local().Format("2006-01-02")
-	logFile := filepath.Join(logDir, date+".log")
+	name, err := LogFileName(format, rotation, entry.Timestamp)
+	if err != nil {
+		return err
+	}
+	if format == FormatObsidian {
+		return withLogLock(logDir, func() error {
+			return writeObsidianNote(filepath.Join(logDir, name), tmpl, entry)
+		})
+	}
+	logFile := filepath.Join(logDir, name)
 
 	// Format entry
 	var content string
 	switch format {
+	case "jsonl":
+		data, err := FormatJSONL(entry)
+		if err != nil {
+			return err
+		}
+		content = string(data) + "\n"
 	case "json":
 		data, err := json.Marshal(entry)
 		if err != nil {
@@ -53,26 +152,63 @@ func WriteProjectLog(logDir, format string, entry Entry) error {
 	case "markdown":
 		fallthrough
 	default:
-		content = formatMarkdown(entry)
+		// Files spanning more than a day need the date on each entry
+		layout := "15:04:05"
+		if rotation != "" && rotation != RotationDaily {
+			layout = "2006-01-02 15:04:05"
+		}
+		if tmpl == nil {
+			content = formatMarkdown(entry, layout)
+			break
+		}
+		content, err = formatTemplate(tmpl, entry, layout)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Append to file
-	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // Standard file permissions for log files
+	// Append the whole entry in one write, holding the lock so another
+	// process's entry can't land in the middle of it
+	return withLogLock(logDir, func() error {
+		f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // Standard file permissions for log files
+		if err != nil {
+			return err
+		}
+		if _, err := f.WriteString(content); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
+
+// withLogLock runs fn holding an exclusive lock on logDir, so concurrent
+// writers (say the CLI and an MCP server) take turns.
+func withLogLock(logDir string, fn func() error) error {
+	unlock, err := lockDir(logDir)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = f.Close() }()
+	defer unlock()
+	return fn()
+}
 
-	_, err = f.WriteString(content)
-	return err
+// FormatMarkdown renders entry the way daily markdown project logs store it.
+func FormatMarkdown(entry Entry) string {
+	return formatMarkdown(entry, "15:04:05")
 }
 
-func formatMarkdown(entry Entry) string {
+// formatMarkdown renders entry with its timestamp in layout.
+func formatMarkdown(entry Entry, layout string) string {
 	var sb strings.Builder
 
-	timeStr := entry.Timestamp.Format("15:04:05")
+	timeStr := entry.Timestamp.Format(layout)
 	sb.WriteString(fmt.Sprintf("## %s - %s\n", timeStr, entry.Message))
 
+	if entry.Type != "" && entry.Type != defaultEntryType {
+		sb.WriteString(fmt.Sprintf("- **Type**: %s\n", entry.Type))
+	}
+
 	if len(entry.Tags) > 0 {
 		sb.WriteString(fmt.Sprintf("- **Tags**: %s\n", strings.Join(entry.Tags, ", ")))
 	}
@@ -83,3 +219,27 @@ func formatMarkdown(entry Entry) string {
 
 	return sb.String()
 }
+
+// FormatJSONL renders entry as one jsonl record, without the newline. The
+// timestamp is RFC 3339 with its UTC offset, the type is always set, and
+// tags are an empty list rather than null.
+func FormatJSONL(entry Entry) ([]byte, error) {
+	record := jsonlRecord{
+		SchemaVersion:    JSONLSchemaVersion,
+		ID:               entry.ID,
+		Timestamp:        entry.Timestamp.Format(time.RFC3339Nano),
+		Type:             entry.Type,
+		Message:          entry.Message,
+		Tags:             entry.Tags,
+		Hostname:         entry.Hostname,
+		Username:         entry.Username,
+		WorkingDirectory: entry.WorkingDirectory,
+	}
+	if record.Type == "" {
+		record.Type = defaultEntryType
+	}
+	if record.Tags == nil {
+		record.Tags = []string{}
+	}
+	return json.Marshal(record)
+}
@@ -3,9 +3,11 @@
 package logging
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -23,7 +25,7 @@ func TestWriteProjectLog(t *testing.T) {
 		Tags:             []string{"work", "test"},
 	}
 
-	err := WriteProjectLog(logDir, "markdown", entry)
+	err := WriteProjectLog(logDir, "markdown", "", nil, entry)
 	if err != nil {
 		t.Fatalf("WriteProjectLog failed: %v", err)
 	}
@@ -49,6 +51,42 @@ func TestWriteProjectLog(t *testing.T) {
 	if string(content) != expectedContent {
 		t.Errorf("got:\n%s\nwant:\n%s", string(content), expectedContent)
 	}
+
+	t.Run("decision entry includes type line", func(t *testing.T) {
+		typedDir := filepath.Join(tmpDir, "decision-logs")
+		decision := entry
+		decision.Type = "decision"
+
+		if err := WriteProjectLog(typedDir, "markdown", "", nil, decision); err != nil {
+			t.Fatalf("WriteProjectLog failed: %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(typedDir, "2025-11-29.log")) //nolint:gosec // Reading test file
+		if err != nil {
+			t.Fatalf("failed to read log file: %v", err)
+		}
+		if !strings.Contains(string(content), "- **Type**: decision\n") {
+			t.Errorf("expected type line in:\n%s", string(content))
+		}
+	})
+
+	t.Run("note entry omits type line", func(t *testing.T) {
+		noteDir := filepath.Join(tmpDir, "note-logs")
+		note := entry
+		note.Type = "note"
+
+		if err := WriteProjectLog(noteDir, "markdown", "", nil, note); err != nil {
+			t.Fatalf("WriteProjectLog failed: %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(noteDir, "2025-11-29.log")) //nolint:gosec // Reading test file
+		if err != nil {
+			t.Fatalf("failed to read log file: %v", err)
+		}
+		if strings.Contains(string(content), "**Type**") {
+			t.Errorf("expected no type line for note entry, got:\n%s", string(content))
+		}
+	})
 }
 
 func TestWriteProjectLogJSON(t *testing.T) {
@@ -64,7 +102,7 @@ func TestWriteProjectLogJSON(t *testing.T) {
 		Tags:             []string{"work"},
 	}
 
-	err := WriteProjectLog(logDir, "json", entry)
+	err := WriteProjectLog(logDir, "json", "", nil, entry)
 	if err != nil {
 		t.Fatalf("WriteProjectLog failed: %v", err)
 	}
@@ -83,6 +121,44 @@ func TestWriteProjectLogJSON(t *testing.T) {
 	}
 }
 
+func TestWriteProjectLogJSONL(t *testing.T) {
+	logDir := filepath.Join(t.TempDir(), "logs")
+
+	entries := []Entry{
+		{
+			ID:               "abc",
+			Timestamp:        time.Date(2025, 11, 29, 14, 30, 0, 0, time.UTC),
+			Message:          "test message",
+			Hostname:         "testhost",
+			Username:         "testuser",
+			WorkingDirectory: "/test/dir",
+		},
+		{
+			ID:        "def",
+			Timestamp: time.Date(2025, 11, 29, 15, 0, 0, 0, time.UTC),
+			Message:   "chose sqlite",
+			Type:      "decision",
+			Tags:      []string{"db"},
+		},
+	}
+	for _, entry := range entries {
+		if err := WriteProjectLog(logDir, "jsonl", "", nil, entry); err != nil {
+			t.Fatalf("WriteProjectLog failed: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(logDir, "2025-11-29.jsonl")) //nolint:gosec // Reading test file
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	want := `{"schema_version":1,"id":"abc","timestamp":"2025-11-29T14:30:00Z","type":"note","message":"test message","tags":[],"hostname":"testhost","username":"testuser","working_directory":"/test/dir"}
+{"schema_version":1,"id":"def","timestamp":"2025-11-29T15:00:00Z","type":"decision","message":"chose sqlite","tags":["db"],"hostname":"","username":"","working_directory":""}
+`
+	if string(content) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", content, want)
+	}
+}
+
 func TestWriteProjectLogMultipleEntries(t *testing.T) {
 	tmpDir := t.TempDir()
 	logDir := filepath.Join(tmpDir, "logs")
@@ -106,12 +182,12 @@ func TestWriteProjectLogMultipleEntries(t *testing.T) {
 	}
 
 	// Write both entries
-	err := WriteProjectLog(logDir, "markdown", entry1)
+	err := WriteProjectLog(logDir, "markdown", "", nil, entry1)
 	if err != nil {
 		t.Fatalf("WriteProjectLog failed: %v", err)
 	}
 
-	err = WriteProjectLog(logDir, "markdown", entry2)
+	err = WriteProjectLog(logDir, "markdown", "", nil, entry2)
 	if err != nil {
 		t.Fatalf("WriteProjectLog failed: %v", err)
 	}
@@ -128,3 +204,112 @@ func TestWriteProjectLogMultipleEntries(t *testing.T) {
 		t.Errorf("log file should contain both entries: %s", contentStr)
 	}
 }
+
+func TestLogFileBase(t *testing.T) {
+	// Sunday 2025-11-30 is in ISO week 48; Monday 2024-12-30 is in 2025-W01
+	tests := []struct {
+		rotation string
+		at       time.Time
+		want     string
+	}{
+		{"", time.Date(2025, 11, 30, 12, 0, 0, 0, time.Local), "2025-11-30"},
+		{RotationDaily, time.Date(2025, 11, 30, 12, 0, 0, 0, time.Local), "2025-11-30"},
+		{RotationWeekly, time.Date(2025, 11, 30, 12, 0, 0, 0, time.Local), "2025-W48"},
+		{RotationWeekly, time.Date(2024, 12, 30, 12, 0, 0, 0, time.Local), "2025-W01"},
+		{RotationMonthly, time.Date(2025, 11, 30, 12, 0, 0, 0, time.Local), "2025-11"},
+	}
+	for _, tt := range tests {
+		got, err := LogFileBase(tt.rotation, tt.at)
+		if err != nil || got != tt.want {
+			t.Errorf("LogFileBase(%q, %v) = %q, %v; want %q", tt.rotation, tt.at, got, err, tt.want)
+		}
+	}
+	if _, err := LogFileBase("hourly", time.Now()); err == nil {
+		t.Error("expected an error for an unknown rotation")
+	}
+}
+
+func TestWriteProjectLogMonthly(t *testing.T) {
+	logDir := filepath.Join(t.TempDir(), "logs")
+	for _, day := range []int{3, 17} {
+		entry := Entry{
+			Timestamp:        time.Date(2025, 11, day, 9, 0, 0, 0, time.Local),
+			Message:          fmt.Sprintf("entry on the %d", day),
+			Hostname:         "testhost",
+			Username:         "testuser",
+			WorkingDirectory: "/test/dir",
+		}
+		if err := WriteProjectLog(logDir, "markdown", RotationMonthly, nil, entry); err != nil {
+			t.Fatalf("WriteProjectLog failed: %v", err)
+		}
+	}
+
+	files, err := os.ReadDir(logDir)
+	if err != nil || len(files) != 1 || files[0].Name() != "2025-11.log" {
+		t.Fatalf("log files = %v, %v; want only 2025-11.log", files, err)
+	}
+	content, err := os.ReadFile(filepath.Join(logDir, "2025-11.log")) //nolint:gosec // Reading test file
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "## 2025-11-03 09:00:00 - entry on the 3") || !strings.Contains(string(content), "## 2025-11-17 09:00:00 - entry on the 17") {
+		t.Errorf("monthly log should date each entry:\n%s", content)
+	}
+
+	if err := WriteProjectLog(logDir, "markdown", "hourly", nil, Entry{Timestamp: time.Now()}); err == nil {
+		t.Error("expected an error for an unknown rotation")
+	}
+}
+
+func TestWriteProjectLogConcurrent(t *testing.T) {
+	const writers, perWriter = 8, 25
+	at := time.Date(2025, 11, 29, 12, 0, 0, 0, time.Local)
+
+	for _, format := range []string{"markdown", "jsonl", FormatObsidian} {
+		t.Run(format, func(t *testing.T) {
+			logDir := t.TempDir()
+			// Large messages make torn or interleaved writes easy to spot
+			body := strings.Repeat("x", 32*1024)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, writers*perWriter)
+			for w := 0; w < writers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < perWriter; i++ {
+						entry := Entry{
+							Timestamp: at,
+							Message:   fmt.Sprintf("writer-%d-entry-%d %s", w, i, body),
+							Tags:      []string{fmt.Sprintf("writer-%d", w)},
+						}
+						errs <- WriteProjectLog(logDir, format, "", nil, entry)
+					}
+				}(w)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Fatalf("WriteProjectLog failed: %v", err)
+				}
+			}
+
+			name, _ := LogFileName(format, "", at)
+			content, err := os.ReadFile(filepath.Join(logDir, name)) //nolint:gosec // Reading test file
+			if err != nil {
+				t.Fatalf("failed to read log: %v", err)
+			}
+			for w := 0; w < writers; w++ {
+				for i := 0; i < perWriter; i++ {
+					if want := fmt.Sprintf("writer-%d-entry-%d %s", w, i, body); strings.Count(string(content), want) != 1 {
+						t.Fatalf("entry %d of writer %d missing or torn", i, w)
+					}
+				}
+				if format == FormatObsidian && !strings.Contains(string(content), fmt.Sprintf("  - writer-%d\n", w)) {
+					t.Errorf("frontmatter lost the tag of writer %d", w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// ABOUTME: User-defined text/template layouts for markdown project log entries
+// ABOUTME: Lets a project's .chronicle match an existing changelog or devlog style
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateData is what an entry template executes against: the entry's
+// fields plus its timestamp formatted the way the built-in layout would
+// show it for the log's rotation.
+type templateData struct {
+	Entry
+	Time string
+}
+
+// templateFuncs are the helpers available to entry templates.
+var templateFuncs = template.FuncMap{
+	"join": func(elems []string, sep string) string { return strings.Join(elems, sep) },
+	"date": func(t time.Time, layout string) string { return t.Format(layout) },
+}
+
+// ParseEntryTemplate parses text as the template for markdown project log
+// entries. Templates see the Entry fields (.Message, .Tags, .Type,
+// .Timestamp, ...), .Time, and the join and date helpers.
+func ParseEntryTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("entry").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid entry template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// formatTemplate renders entry with tmpl, ending it with a newline so the
+// next entry starts on its own line.
+func formatTemplate(tmpl *template.Template, entry Entry, layout string) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, templateData{Entry: entry, Time: entry.Timestamp.Format(layout)}); err != nil {
+		return "", fmt.Errorf("failed to render entry template: %w", err)
+	}
+	out := sb.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return out, nil
+}
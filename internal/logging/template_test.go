@@ -0,0 +1,69 @@
+// ABOUTME: Tests for templated markdown project log entries
+// ABOUTME: Covers template fields, helpers, and parse errors
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteProjectLogTemplate(t *testing.T) {
+	tmpl, err := ParseEntryTemplate(`### {{date .Timestamp "Jan 2"}} {{.Time}}: {{.Message}}{{if .Tags}} [{{join .Tags ", "}}]{{end}}`)
+	if err != nil {
+		t.Fatalf("ParseEntryTemplate failed: %v", err)
+	}
+
+	logDir := t.TempDir()
+	for _, entry := range []Entry{
+		{Timestamp: time.Date(2025, 11, 29, 14, 32, 15, 0, time.Local), Message: "deployed v2.1.0", Tags: []string{"work", "deployment"}},
+		{Timestamp: time.Date(2025, 11, 29, 15, 0, 0, 0, time.Local), Message: "lunch"},
+	} {
+		if err := WriteProjectLog(logDir, "markdown", "", tmpl, entry); err != nil {
+			t.Fatalf("WriteProjectLog failed: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(logDir, "2025-11-29.log")) //nolint:gosec // Reading test file
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	want := "### Nov 29 14:32:15: deployed v2.1.0 [work, deployment]\n### Nov 29 15:00:00: lunch\n"
+	if string(content) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", content, want)
+	}
+}
+
+func TestWriteProjectLogTemplateIgnoredForJSONL(t *testing.T) {
+	tmpl, err := ParseEntryTemplate("{{.Message}}")
+	if err != nil {
+		t.Fatalf("ParseEntryTemplate failed: %v", err)
+	}
+	logDir := t.TempDir()
+	entry := Entry{Timestamp: time.Date(2025, 11, 29, 9, 0, 0, 0, time.Local), Message: "hello"}
+	if err := WriteProjectLog(logDir, "jsonl", "", tmpl, entry); err != nil {
+		t.Fatalf("WriteProjectLog failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(logDir, "2025-11-29.jsonl")) //nolint:gosec // Reading test file
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if content[0] != '{' {
+		t.Errorf("jsonl log should ignore the template, got %s", content)
+	}
+}
+
+func TestParseEntryTemplateErrors(t *testing.T) {
+	if _, err := ParseEntryTemplate("{{.Message"); err == nil {
+		t.Error("expected a parse error")
+	}
+
+	tmpl, err := ParseEntryTemplate("{{.NoSuchField}}")
+	if err != nil {
+		t.Fatalf("ParseEntryTemplate failed: %v", err)
+	}
+	if err := WriteProjectLog(t.TempDir(), "markdown", "", tmpl, Entry{Timestamp: time.Now()}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
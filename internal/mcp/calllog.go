@@ -0,0 +1,104 @@
+// ABOUTME: Debug logging of MCP tool calls for diagnosing assistant integrations
+// ABOUTME: Records each call's tool, duration, and error, with journal text redacted from arguments
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// loggedArgs are the argument names whose values are logged as given.
+// Anything else may hold journal text or a confirm token and is redacted.
+var loggedArgs = map[string]bool{
+	"id":              true,
+	"session_id":      true,
+	"type":            true,
+	"tags":            true,
+	"limit":           true,
+	"days":            true,
+	"cursor":          true,
+	"period":          true,
+	"timeframe":       true,
+	"since":           true,
+	"until":           true,
+	"project":         true,
+	"allow_duplicate": true,
+}
+
+// logCall records one tool call. Failures log at warn level; successful
+// calls at info, with the redacted arguments added at debug.
+func (s *Server) logCall(ctx context.Context, tool string, input any, elapsed time.Duration, err error) {
+	if s.logger == nil {
+		return
+	}
+	attrs := []any{"tool", tool, "duration", elapsed.Round(time.Microsecond)}
+	if s.logger.Enabled(ctx, slog.LevelDebug) {
+		attrs = append(attrs, "args", redactArgs(input))
+	}
+	if err == nil {
+		s.logger.InfoContext(ctx, "tool call", attrs...)
+		return
+	}
+
+	var te *toolError
+	if errors.As(err, &te) {
+		attrs = append(attrs, "code", te.Code, "error", te.Message)
+		if te.Err != nil && te.Err.Error() != te.Message {
+			attrs = append(attrs, "cause", te.Err.Error())
+		}
+	} else {
+		attrs = append(attrs, "error", err.Error())
+	}
+	s.logger.WarnContext(ctx, "tool call failed", attrs...)
+}
+
+// redactArgs renders a tool's input as JSON, replacing the value of every
+// argument not in loggedArgs with its length.
+func redactArgs(input any) string {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Sprintf("<unloggable: %v>", err)
+	}
+	var args map[string]any
+	if err := json.Unmarshal(data, &args); err != nil {
+		return string(data)
+	}
+	for name, value := range args {
+		if !loggedArgs[name] {
+			args[name] = redacted(value)
+		}
+	}
+	data, err = json.Marshal(args)
+	if err != nil {
+		return fmt.Sprintf("<unloggable: %v>", err)
+	}
+	return string(data)
+}
+
+// redacted hides text in value while keeping its shape: strings become
+// their length, and lists and objects are redacted element by element.
+// Numbers, booleans, and nulls say nothing about the journal and are kept.
+func redacted(value any) any {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("[redacted %d chars]", len(v))
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = redacted(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = redacted(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
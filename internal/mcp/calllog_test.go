@@ -0,0 +1,56 @@
+// ABOUTME: Tests for MCP tool call logging
+// ABOUTME: Covers argument redaction and what a logged call records
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactArgs(t *testing.T) {
+	msg := "deployed the secret project"
+	got := redactArgs(EditEntryInput{ID: "abc", Message: &msg, Tags: []string{"work"}, ConfirmToken: "tok123"})
+	want := `{"confirm_token":"[redacted 6 chars]","id":"abc","message":"[redacted 27 chars]","tags":["work"]}`
+	if got != want {
+		t.Errorf("redactArgs() = %s, want %s", got, want)
+	}
+
+	got = redactArgs(LogDecisionInput{Decision: "use sqlite", Alternatives: []string{"postgres"}})
+	if strings.Contains(got, "sqlite") || strings.Contains(got, "postgres") {
+		t.Errorf("redactArgs() = %s, leaks journal text", got)
+	}
+}
+
+func TestLogCall(t *testing.T) {
+	var buf bytes.Buffer
+	s := &Server{logger: slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))}
+	ctx := context.Background()
+
+	s.logCall(ctx, "add_entry", AddEntryInput{Message: "hello"}, 3*time.Millisecond, nil)
+	line := buf.String()
+	if !strings.Contains(line, "tool=add_entry") || !strings.Contains(line, "duration=3ms") || strings.Contains(line, "args=") {
+		t.Errorf("info log = %q, want tool and duration without args", line)
+	}
+
+	buf.Reset()
+	s.logCall(ctx, "edit_entry", EditEntryInput{ID: "x"}, time.Millisecond, classifyError(errors.New("disk on fire")))
+	line = buf.String()
+	if !strings.Contains(line, "level=WARN") || !strings.Contains(line, "code=internal") || !strings.Contains(line, "disk on fire") {
+		t.Errorf("failure log = %q, want a warning with code and error", line)
+	}
+
+	buf.Reset()
+	s.logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	s.logCall(ctx, "add_entry", AddEntryInput{Message: "hello"}, time.Millisecond, nil)
+	if line = buf.String(); !strings.Contains(line, "[redacted 5 chars]") || strings.Contains(line, "hello") {
+		t.Errorf("debug log = %q, want redacted args", line)
+	}
+
+	// No logger means logging is off
+	(&Server{}).logCall(ctx, "add_entry", AddEntryInput{}, 0, nil)
+}
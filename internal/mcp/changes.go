@@ -0,0 +1,137 @@
+// ABOUTME: what_changed_since tool for picking a conversation back up
+// ABOUTME: Returns only entries newer than a timestamp, with a compact diff-style summary
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultChangesLimit is how many entries what_changed_since returns by default.
+const defaultChangesLimit = 50
+
+// WhatChangedSinceInput defines input for what_changed_since tool.
+type WhatChangedSinceInput struct {
+	Since string `json:"since" jsonschema:"ISO timestamp, e.g. when the last conversation ended (2025-11-24T17:30:00Z)" jsonschema_extras:"required=true"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Maximum entries to return, newest kept (default 50)"`
+}
+
+// WhatChangedSinceOutput defines the output for what_changed_since tool.
+type WhatChangedSinceOutput struct {
+	Since     string         `json:"since"`
+	Count     int            `json:"count" jsonschema:"Entries created after since, including any left out by limit"`
+	Truncated bool           `json:"truncated,omitempty" jsonschema:"Set when only the newest limit entries are returned"`
+	Types     map[string]int `json:"types" jsonschema:"Entry count per type"`
+	Tags      []charm.Count  `json:"tags" jsonschema:"Tags used, most used first"`
+	Summary   string         `json:"summary" jsonschema:"One + line per entry, oldest first"`
+	Entries   []EntryData    `json:"entries" jsonschema:"The new entries, oldest first"`
+}
+
+// handleWhatChangedSince implements the what_changed_since tool.
+func (s *Server) handleWhatChangedSince(ctx context.Context, req *mcp.CallToolRequest, input WhatChangedSinceInput) (*mcp.CallToolResult, WhatChangedSinceOutput, error) {
+	since, err := parseTimestamp(input.Since)
+	if err != nil {
+		return nil, WhatChangedSinceOutput{}, err
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultChangesLimit
+	}
+
+	entries, err := s.client.SearchEntries(s.scoped(&charm.SearchFilter{Since: &since}), 0)
+	if err != nil {
+		return nil, WhatChangedSinceOutput{}, fmt.Errorf("failed to search entries: %w", err)
+	}
+	output := changesSince(since, entries, limit)
+
+	text := output.Summary
+	if output.Count == 0 {
+		text = fmt.Sprintf("Nothing new since %s.", output.Since)
+	} else if output.Truncated {
+		text += fmt.Sprintf("(showing the newest %d; raise limit for the rest)\n", len(output.Entries))
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+	return result, output, nil
+}
+
+// parseTimestamp parses what_changed_since's since argument. RFC 3339 is
+// expected, but other unambiguous formats are accepted in local time.
+func parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, invalidInput("since is required")
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	t, err := dateparse.ParseLocal(value)
+	if err != nil {
+		return time.Time{}, &toolError{Code: codeInvalidInput, Message: fmt.Sprintf("invalid since timestamp %q", value), Hint: "Use an ISO timestamp like 2025-11-24T17:30:00Z."}
+	}
+	return t, nil
+}
+
+// changesSince builds what_changed_since output from entries strictly newer
+// than since, keeping the newest limit of them.
+func changesSince(since time.Time, entries []charm.Entry, limit int) WhatChangedSinceOutput {
+	output := WhatChangedSinceOutput{
+		Since:   since.Local().Format("2006-01-02 15:04:05"),
+		Types:   make(map[string]int),
+		Tags:    []charm.Count{},
+		Entries: []EntryData{},
+	}
+
+	var newer []charm.Entry
+	for _, entry := range entries {
+		if entry.Timestamp.After(since) {
+			newer = append(newer, entry)
+		}
+	}
+	sort.SliceStable(newer, func(i, j int) bool {
+		return newer[i].Timestamp.Before(newer[j].Timestamp)
+	})
+	output.Count = len(newer)
+	if len(newer) > limit {
+		newer = newer[len(newer)-limit:]
+		output.Truncated = true
+	}
+
+	tags := make(map[string]int)
+	var summary strings.Builder
+	if output.Count > 0 {
+		summary.WriteString(fmt.Sprintf("%d new entries since %s:\n", output.Count, output.Since))
+	}
+	for _, entry := range newer {
+		output.Types[entry.Kind()]++
+		for _, tag := range entry.Tags {
+			tags[tag]++
+		}
+		output.Entries = append(output.Entries, toEntryData(entry))
+
+		message, _, _ := strings.Cut(entry.Message, "\n")
+		summary.WriteString(fmt.Sprintf("+ %s", entry.Timestamp.Local().Format("Jan 2 15:04")))
+		if kind := entry.Kind(); kind != charm.EntryTypeNote {
+			summary.WriteString(fmt.Sprintf(" [%s]", kind))
+		}
+		summary.WriteString(" " + message)
+		if len(entry.Tags) > 0 {
+			summary.WriteString(fmt.Sprintf(" (%s)", strings.Join(entry.Tags, ", ")))
+		}
+		summary.WriteString("\n")
+	}
+	output.Tags = append(output.Tags, sortedCounts(tags)...)
+	output.Summary = summary.String()
+	return output
+}
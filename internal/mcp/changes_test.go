@@ -0,0 +1,68 @@
+// ABOUTME: Tests for the what_changed_since tool
+// ABOUTME: Covers timestamp parsing and filtering, truncation, and the summary
+package mcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	got, err := parseTimestamp("2025-11-24T17:30:00Z")
+	if err != nil || !got.Equal(time.Date(2025, 11, 24, 17, 30, 0, 0, time.UTC)) {
+		t.Errorf("parseTimestamp(RFC 3339) = %v, %v", got, err)
+	}
+	got, err = parseTimestamp("2025-11-24 09:00")
+	if err != nil || !got.Equal(time.Date(2025, 11, 24, 9, 0, 0, 0, time.Local)) {
+		t.Errorf("parseTimestamp(local) = %v, %v", got, err)
+	}
+	for _, value := range []string{"", "  ", "whenever"} {
+		if _, err := parseTimestamp(value); err == nil {
+			t.Errorf("parseTimestamp(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestChangesSince(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 11, 24, h, 0, 0, 0, time.Local) }
+	// Newest first, as SearchEntries returns them; the filter's since is inclusive
+	entries := []charm.Entry{
+		{ID: "4", Timestamp: at(12), Message: "merged PR\nwith details", Tags: []string{"work"}},
+		{ID: "3", Timestamp: at(11), Message: "chose sqlite", Type: charm.EntryTypeDecision, Tags: []string{"work", "db"}},
+		{ID: "2", Timestamp: at(10), Message: "standup"},
+		{ID: "1", Timestamp: at(9), Message: "already seen"},
+	}
+
+	out := changesSince(at(9), entries, 10)
+	if out.Count != 3 || out.Truncated || len(out.Entries) != 3 || out.Entries[0].ID != "2" {
+		t.Fatalf("got %d entries (count %d), first %v; want 2, 3, 4", len(out.Entries), out.Count, out.Entries)
+	}
+	if out.Types[charm.EntryTypeNote] != 2 || out.Types[charm.EntryTypeDecision] != 1 {
+		t.Errorf("types = %v, want 2 notes and 1 decision", out.Types)
+	}
+	if out.Tags[0] != (charm.Count{Value: "work", Count: 2}) {
+		t.Errorf("tags = %v, want work:2 first", out.Tags)
+	}
+	want := "3 new entries since 2025-11-24 09:00:00:\n" +
+		"+ Nov 24 10:00 standup\n" +
+		"+ Nov 24 11:00 [decision] chose sqlite (work, db)\n" +
+		"+ Nov 24 12:00 merged PR (work)\n"
+	if out.Summary != want {
+		t.Errorf("summary =\n%s\nwant\n%s", out.Summary, want)
+	}
+
+	out = changesSince(at(9), entries, 1)
+	if out.Count != 3 || !out.Truncated || len(out.Entries) != 1 || out.Entries[0].ID != "4" {
+		t.Errorf("limited to 1: count %d, truncated %v, entries %v; want the newest of 3", out.Count, out.Truncated, out.Entries)
+	}
+	if !strings.HasPrefix(out.Summary, "3 new entries") {
+		t.Errorf("limited summary = %q, want the full count", out.Summary)
+	}
+
+	if out := changesSince(at(12), entries, 10); out.Count != 0 || out.Summary != "" {
+		t.Errorf("nothing newer: count %d, summary %q", out.Count, out.Summary)
+	}
+}
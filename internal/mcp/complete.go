@@ -0,0 +1,95 @@
+// ABOUTME: MCP argument completion for tags and timeframes
+// ABOUTME: Suggests existing tags so assistants reuse spellings instead of inventing new ones
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCompletions is the most values a completion may return (per the MCP spec).
+const maxCompletions = 100
+
+// timeframePhrases are the range phrases tools accept, offered as completions.
+var timeframePhrases = []string{
+	"today", "yesterday", "this week", "last week", "this month", "last month",
+	"last hour", "last 4 hours", "last 24 hours",
+}
+
+// handleComplete implements completion/complete. MCP only completes prompt
+// and resource template arguments, so any argument named like a tag or a
+// timeframe is completed, wherever it appears.
+func (s *Server) handleComplete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	arg := req.Params.Argument
+	var candidates []string
+	switch arg.Name {
+	case "tag", "tags":
+		stats, err := s.client.Stats(s.scoped(nil))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags: %w", err)
+		}
+		for _, tag := range stats.TopTags(0) {
+			candidates = append(candidates, tag.Value)
+		}
+	case "since", "until", "period", "timeframe":
+		candidates = timeframePhrases
+	}
+	return completion(candidates, arg.Value), nil
+}
+
+// completion returns the candidates starting with prefix, ignoring case and
+// keeping their order.
+func completion(candidates []string, prefix string) *mcp.CompleteResult {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	values := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			values = append(values, candidate)
+		}
+	}
+	details := mcp.CompletionResultDetails{Values: values, Total: len(values)}
+	if len(values) > maxCompletions {
+		details.Values = values[:maxCompletions]
+		details.HasMore = true
+	}
+	return &mcp.CompleteResult{Completion: details}
+}
+
+// handleTagEntries implements the chronicle://tags/{tag} resource template.
+func (s *Server) handleTagEntries(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	tag, err := url.PathUnescape(strings.TrimPrefix(req.Params.URI, "chronicle://tags/"))
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	if tag = charm.NormalizeTag(tag); tag == "" {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	entries, err := s.client.SearchEntries(s.scoped(&charm.SearchFilter{Tags: []string{tag}}), 50)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search entries: %w", err)
+	}
+	if entries == nil {
+		entries = []charm.Entry{}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	result := &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      req.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		},
+	}
+	return result, nil
+}
@@ -0,0 +1,53 @@
+// ABOUTME: Tests for MCP argument completion
+// ABOUTME: Covers prefix matching, the result cap, and timeframe suggestions
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCompletion(t *testing.T) {
+	got := completion([]string{"work", "Wiki", "deploy", "writing"}, " W")
+	if want := []string{"work", "Wiki", "writing"}; !reflect.DeepEqual(got.Completion.Values, want) {
+		t.Errorf("completion values = %v, want %v", got.Completion.Values, want)
+	}
+	if got := completion(nil, "x"); got.Completion.Values == nil || len(got.Completion.Values) != 0 {
+		t.Errorf("no candidates = %#v, want an empty list", got.Completion.Values)
+	}
+
+	many := make([]string, maxCompletions+5)
+	for i := range many {
+		many[i] = fmt.Sprintf("tag%d", i)
+	}
+	got = completion(many, "")
+	if len(got.Completion.Values) != maxCompletions || !got.Completion.HasMore || got.Completion.Total != len(many) {
+		t.Errorf("capped completion = %d values, hasMore %v, total %d", len(got.Completion.Values), got.Completion.HasMore, got.Completion.Total)
+	}
+}
+
+func TestHandleCompleteTimeframes(t *testing.T) {
+	// Timeframes don't touch the journal, so a bare server is enough
+	s := &Server{}
+	req := &mcp.CompleteRequest{Params: &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "any"},
+		Argument: mcp.CompleteParamsArgument{Name: "period", Value: "last"},
+	}}
+	got, err := s.handleComplete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleComplete: %v", err)
+	}
+	want := []string{"last week", "last month", "last hour", "last 4 hours", "last 24 hours"}
+	if !reflect.DeepEqual(got.Completion.Values, want) {
+		t.Errorf("values = %v, want %v", got.Completion.Values, want)
+	}
+
+	req.Params.Argument = mcp.CompleteParamsArgument{Name: "message", Value: "x"}
+	if got, err := s.handleComplete(context.Background(), req); err != nil || len(got.Completion.Values) != 0 {
+		t.Errorf("unknown argument = %v, %v; want no values", got, err)
+	}
+}
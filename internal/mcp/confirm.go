@@ -0,0 +1,120 @@
+// ABOUTME: Human confirmation for destructive MCP tools (edit_entry, delete_entry)
+// ABOUTME: Uses MCP elicitation when the client supports it, else a two-step confirm token
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// confirmTTL is how long a confirm token stays valid.
+const confirmTTL = 5 * time.Minute
+
+// Error codes for confirmation results.
+const (
+	codeConfirmationRequired = "confirmation_required"
+	codeDeclined             = "declined"
+)
+
+// confirmations tracks confirm tokens handed out and not yet used.
+type confirmations struct {
+	mu      sync.Mutex
+	pending map[string]pendingConfirm
+}
+
+// pendingConfirm is the change a token approves.
+type pendingConfirm struct {
+	action  string
+	expires time.Time
+}
+
+// issue returns a new token approving action.
+func (c *confirmations) issue(action string, now time.Time) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate confirm token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		c.pending = make(map[string]pendingConfirm)
+	}
+	for t, p := range c.pending {
+		if now.After(p.expires) {
+			delete(c.pending, t)
+		}
+	}
+	c.pending[token] = pendingConfirm{action: action, expires: now.Add(confirmTTL)}
+	return token, nil
+}
+
+// redeem uses up token, reporting whether it approves exactly action.
+func (c *confirmations) redeem(token, action string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[token]
+	if !ok || p.action != action || now.After(p.expires) {
+		return false
+	}
+	delete(c.pending, token)
+	return true
+}
+
+// confirm returns nil once the user has approved action. With confirmation
+// off it always does. Otherwise the user is asked through elicitation if
+// the client supports it; if not, the first call returns a
+// confirmation_required error carrying a token, and a repeat call with
+// that token goes ahead.
+func (s *Server) confirm(ctx context.Context, req *mcp.CallToolRequest, action, token string) error {
+	if !s.requireConfirmation {
+		return nil
+	}
+	now := time.Now()
+	if token != "" {
+		if s.confirms.redeem(token, action, now) {
+			return nil
+		}
+		return invalidInput("confirm_token is invalid, expired, or was issued for a different change")
+	}
+
+	if canElicit(req) {
+		res, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+			Message:         "Allow the assistant to " + action + "?",
+			RequestedSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+		})
+		if err == nil {
+			if res.Action == "accept" {
+				return nil
+			}
+			return &toolError{Code: codeDeclined, Message: "the user did not approve: " + action, Hint: "Don't retry unless the user asks for this change again."}
+		}
+		// Fall back to a confirm token if the client failed to ask
+	}
+
+	token, err := s.confirms.issue(action, now)
+	if err != nil {
+		return err
+	}
+	return &toolError{
+		Code:    codeConfirmationRequired,
+		Message: "this change needs the user's approval: " + action,
+		Hint:    fmt.Sprintf("Show the user the change and ask them to approve it. If they do, call the tool again with the same arguments and confirm_token %q (valid for %s).", token, confirmTTL),
+	}
+}
+
+// canElicit reports whether the calling client can ask its user directly.
+func canElicit(req *mcp.CallToolRequest) bool {
+	if req == nil || req.Session == nil {
+		return false
+	}
+	params := req.Session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
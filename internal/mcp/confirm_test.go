@@ -0,0 +1,56 @@
+// ABOUTME: Tests for confirmation of destructive MCP tools
+// ABOUTME: Covers the two-step confirm token flow without a client session
+package mcp
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestConfirmTokenFlow(t *testing.T) {
+	s := &Server{requireConfirmation: true}
+	ctx := context.Background()
+	action := `delete entry abc ("old note")`
+
+	err := s.confirm(ctx, nil, action, "")
+	var te *toolError
+	if !errors.As(err, &te) || te.Code != codeConfirmationRequired {
+		t.Fatalf("first call = %v, want confirmation_required", err)
+	}
+	token := regexp.MustCompile(`confirm_token "([0-9a-f]+)"`).FindStringSubmatch(te.Hint)
+	if token == nil {
+		t.Fatalf("no token in hint %q", te.Hint)
+	}
+
+	if err := s.confirm(ctx, nil, `delete entry other ("x")`, token[1]); err == nil {
+		t.Error("expected a token for one change to be refused for another")
+	}
+	if err := s.confirm(ctx, nil, action, token[1]); err != nil {
+		t.Errorf("confirmed call = %v, want nil", err)
+	}
+	if err := s.confirm(ctx, nil, action, token[1]); err == nil {
+		t.Error("expected a token to work only once")
+	}
+}
+
+func TestConfirmTokenExpires(t *testing.T) {
+	var c confirmations
+	now := time.Now()
+	token, err := c.issue("delete entry abc", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.redeem(token, "delete entry abc", now.Add(confirmTTL+time.Second)) {
+		t.Error("expected an expired token to be refused")
+	}
+}
+
+func TestConfirmOffAllowsEverything(t *testing.T) {
+	s := &Server{}
+	if err := s.confirm(context.Background(), nil, "delete entry abc", ""); err != nil {
+		t.Errorf("confirm with confirmation off = %v, want nil", err)
+	}
+}
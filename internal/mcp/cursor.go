@@ -0,0 +1,66 @@
+// ABOUTME: Opaque pagination cursors for the list_entries and search_entries tools
+// ABOUTME: A cursor encodes the timestamp and ID of the last entry on the previous page
+package mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+// encodeCursor returns the cursor for the page after entry.
+func encodeCursor(entry charm.Entry) string {
+	raw := entry.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + entry.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor splits a cursor back into its timestamp and ID. An empty
+// cursor is the first page and decodes to a zero time.
+func decodeCursor(cursor string) (time.Time, string, error) {
+	if cursor == "" {
+		return time.Time{}, "", nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", invalidInput("invalid cursor")
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return time.Time{}, "", invalidInput("invalid cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, "", invalidInput("invalid cursor")
+	}
+	return t, id, nil
+}
+
+// searchPage fetches one page of entries matching filter after cursor.
+// The returned next cursor is empty on the last page.
+func (s *Server) searchPage(filter *charm.SearchFilter, limit int, cursor string) ([]charm.Entry, string, error) {
+	afterTimestamp, afterID, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	// Fetch one extra entry to learn whether there is another page
+	entries, err := s.client.SearchEntriesPage(s.scoped(filter), limit+1, afterTimestamp, afterID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(entries) <= limit {
+		return entries, "", nil
+	}
+	entries = entries[:limit]
+	return entries, encodeCursor(entries[limit-1]), nil
+}
+
+// moreHint tells the assistant how to continue when there is another page.
+func moreHint(next string) string {
+	if next == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (more available: pass cursor %q for the next page)", next)
+}
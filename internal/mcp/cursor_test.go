@@ -0,0 +1,30 @@
+// ABOUTME: Tests for MCP pagination cursors
+// ABOUTME: Round-trips cursors and rejects malformed ones
+package mcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	ts := time.Date(2025, 11, 26, 15, 30, 0, 123456789, time.FixedZone("PST", -8*3600))
+	gotTS, gotID, err := decodeCursor(encodeCursor(charm.Entry{ID: "abc-123", Timestamp: ts}))
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if !gotTS.Equal(ts) || gotID != "abc-123" {
+		t.Errorf("decoded %v %q, want %v abc-123", gotTS, gotID, ts)
+	}
+
+	if ts, id, err := decodeCursor(""); err != nil || !ts.IsZero() || id != "" {
+		t.Errorf("empty cursor = %v %q %v, want the first page", ts, id, err)
+	}
+	for _, bad := range []string{"!!!", "bm90LWEtY3Vyc29y", "MjAyNS0xMS0yNlQxNTozMDowMFp8"} {
+		if _, _, err := decodeCursor(bad); err == nil {
+			t.Errorf("decodeCursor(%q) succeeded, want an error", bad)
+		}
+	}
+}
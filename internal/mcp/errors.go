@@ -0,0 +1,149 @@
+// ABOUTME: Structured error results for MCP tools
+// ABOUTME: Tags failures with a stable code and a remediation hint so assistants know whether to retry
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	charmproto "github.com/charmbracelet/charm/proto"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Error codes returned in tool error results.
+const (
+	codeInvalidInput     = "invalid_input"
+	codeNotFound         = "not_found"
+	codeDBLocked         = "db_locked"
+	codeOffline          = "offline"
+	codeSyncUnconfigured = "sync_unconfigured"
+	codeReadOnly         = "read_only"
+	codeRateLimited      = "rate_limited"
+	codeSecretDetected   = "secret_detected"
+	codeInternal         = "internal"
+)
+
+// toolError is a tool failure with a code an assistant can branch on.
+type toolError struct {
+	Code    string
+	Message string
+	// Hint says what to do about it: retry, fix the input, or ask the user
+	// to run a command
+	Hint string
+	Err  error
+}
+
+// Error renders the code, message, and hint as the tool result text.
+func (e *toolError) Error() string {
+	msg := fmt.Sprintf("[%s] %s", e.Code, e.Message)
+	if e.Hint != "" {
+		msg += "\nHint: " + e.Hint
+	}
+	return msg
+}
+
+func (e *toolError) Unwrap() error {
+	return e.Err
+}
+
+// invalidInput reports a problem with the tool arguments.
+func invalidInput(format string, args ...any) error {
+	return &toolError{Code: codeInvalidInput, Message: fmt.Sprintf(format, args...), Hint: "Fix the arguments and call the tool again."}
+}
+
+// notFound reports a missing entry or session.
+func notFound(format string, args ...any) error {
+	return &toolError{Code: codeNotFound, Message: fmt.Sprintf(format, args...), Hint: "Check the ID with list_entries or search_entries."}
+}
+
+// redactMessage scrubs secrets from message, or refuses it, as
+// redact_secrets says. Scrubbed secrets are reported on stderr.
+func (s *Server) redactMessage(message string) (string, error) {
+	clean, kinds, err := s.redactor.Redact(message)
+	var secretErr *config.SecretError
+	if errors.As(err, &secretErr) {
+		return "", &toolError{Code: codeSecretDetected, Message: err.Error(), Hint: "Remove the secret from the message and call the tool again."}
+	}
+	if len(kinds) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: redacted a secret (%s) from an MCP entry\n", strings.Join(kinds, ", "))
+	}
+	return clean, nil
+}
+
+// classifyError turns err into a toolError, keeping one that already is.
+func classifyError(err error) error {
+	// A toolError's own message already says what failed, so it is
+	// returned without the context it was wrapped in
+	var te *toolError
+	if errors.As(err, &te) {
+		return te
+	}
+
+	classified := &toolError{Code: codeInternal, Message: err.Error(), Err: err}
+	switch {
+	case errors.Is(err, charm.ErrReadOnly):
+		classified.Code = codeReadOnly
+		classified.Hint = "This device is read-only. Tell the user; writes must happen on another device."
+	case charm.IsLocked(err):
+		classified.Code = codeDBLocked
+		classified.Hint = "Another chronicle process is writing. Retry in a few seconds."
+	case errors.Is(err, charmproto.ErrMissingSSHAuth):
+		classified.Code = codeSyncUnconfigured
+		classified.Hint = "Sync isn't set up on this device. Ask the user to run `chronicle sync link`."
+	case charm.IsOffline(err):
+		classified.Code = codeOffline
+		classified.Hint = "The Charm server is unreachable. Retry later, or ask the user to check `chronicle sync status`."
+	case charm.IsNotFound(err):
+		classified.Code = codeNotFound
+		classified.Hint = "Check the ID with list_entries or search_entries."
+	}
+	return classified
+}
+
+// addWriteTool is addTool for a tool that changes the journal. Read-only
+// servers don't register it at all, so assistants never see it. Unless the
+// tool says otherwise it is annotated as only adding entries.
+func addWriteTool[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	if s.readOnly {
+		return
+	}
+	if tool.Annotations == nil {
+		tool.Annotations = &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), OpenWorldHint: boolPtr(false)}
+	}
+	addTool(s, tool, handler)
+}
+
+// destructiveAnnotations marks a tool that changes or removes existing
+// entries, so clients can ask the user before running it.
+func destructiveAnnotations() *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{DestructiveHint: boolPtr(true), IdempotentHint: true, OpenWorldHint: boolPtr(false)}
+}
+
+// boolPtr returns a pointer to b, for optional annotation hints.
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// addTool registers a tool whose errors are returned as structured results.
+// A tool without annotations is marked as a read-only query, which clients
+// may run without asking.
+func addTool[In, Out any](s *Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	if tool.Annotations == nil {
+		tool.Annotations = &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true, OpenWorldHint: boolPtr(false)}
+	}
+	mcp.AddTool(s.mcpServer, tool, func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		start := time.Now()
+		result, output, err := handler(ctx, req, input)
+		if err != nil {
+			err = classifyError(err)
+		}
+		s.logCall(ctx, tool.Name, input, time.Since(start), err)
+		return result, output, err
+	})
+}
@@ -1,10 +1,14 @@
 // ABOUTME: MCP prompt definitions for chronicle
-// ABOUTME: Provides static context to AI assistants about chronicle capabilities
+// ABOUTME: Provides context about chronicle and a daily-standup prompt built from recent entries
 package mcp
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/harper/chronicle/internal/charm"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -49,4 +53,75 @@ The user has configured chronicle to track their development work and important
 	}
 
 	s.mcpServer.AddPrompt(prompt, handler)
+
+	standup := &mcp.Prompt{
+		Name:        "daily-standup",
+		Description: "Write a standup update from yesterday's and today's chronicle entries",
+	}
+	s.mcpServer.AddPrompt(standup, s.handleStandupPrompt)
+}
+
+// handleStandupPrompt implements the daily-standup prompt.
+func (s *Server) handleStandupPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	now := time.Now()
+	var days [2][]charm.Entry
+	for i, phrase := range []string{"yesterday", "today"} {
+		tf, err := resolveTimeframe(phrase, now, s.weekStart)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := s.client.SearchEntries(s.scoped(&charm.SearchFilter{Since: &tf.Since, Until: &tf.Until}), 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search entries: %w", err)
+		}
+		days[i] = entries
+	}
+
+	result := &mcp.GetPromptResult{
+		Description: "Daily standup from chronicle entries",
+		Messages: []*mcp.PromptMessage{
+			{
+				Role: "user",
+				Content: &mcp.TextContent{
+					Text: standupPrompt(days[0], days[1]),
+				},
+			},
+		},
+	}
+	return result, nil
+}
+
+// standupPrompt renders the standup instructions around the entries,
+// oldest first within each day.
+func standupPrompt(yesterday, today []charm.Entry) string {
+	var b strings.Builder
+	b.WriteString(`Write my daily standup update from the chronicle entries below.
+
+Use three short sections:
+- Yesterday: what I got done
+- Today: what I'm working on or planning (todo entries are plans)
+- Blockers: anything stuck or waiting on someone, or "None"
+
+Group related entries, keep each point to one line, and don't invent work
+that isn't in the entries. Mention decisions and milestones explicitly.
+`)
+	for _, day := range []struct {
+		title   string
+		entries []charm.Entry
+	}{{"Yesterday", yesterday}, {"Today", today}} {
+		b.WriteString(fmt.Sprintf("\n## %s\n", day.title))
+		if len(day.entries) == 0 {
+			b.WriteString("(no entries)\n")
+			continue
+		}
+		for i := len(day.entries) - 1; i >= 0; i-- {
+			entry := day.entries[i]
+			b.WriteString(fmt.Sprintf("- %s [%s] %s", entry.Timestamp.Format("15:04"), entry.Kind(), entry.Message))
+			if len(entry.Tags) > 0 {
+				b.WriteString(fmt.Sprintf(" (tags: %s)", strings.Join(entry.Tags, ", ")))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
 }
@@ -0,0 +1,30 @@
+// ABOUTME: Tests for MCP prompts
+// ABOUTME: Checks the daily-standup prompt text built from entries
+package mcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func TestStandupPrompt(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 11, 25, h, 0, 0, 0, time.UTC) }
+	// SearchEntries returns newest first
+	yesterday := []charm.Entry{
+		{Timestamp: at(16), Message: "shipped sync fix", Type: charm.EntryTypeMilestone, Tags: []string{"sync"}},
+		{Timestamp: at(9), Message: "reviewed PRs"},
+	}
+
+	got := standupPrompt(yesterday, nil)
+	first := strings.Index(got, "09:00 [note] reviewed PRs")
+	second := strings.Index(got, "16:00 [milestone] shipped sync fix (tags: sync)")
+	if first < 0 || second < 0 || first > second {
+		t.Errorf("expected yesterday's entries oldest first, got:\n%s", got)
+	}
+	if !strings.Contains(got, "## Today\n(no entries)") {
+		t.Errorf("expected an empty today section, got:\n%s", got)
+	}
+}
@@ -0,0 +1,127 @@
+// ABOUTME: Rate limiting and coalescing for entries written through MCP
+// ABOUTME: Keeps chatty assistants from flooding the journal with rapid-fire adds
+package mcp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+// rateWindow is the period the rate limit counts entries over.
+const rateWindow = time.Minute
+
+// writeGate decides whether an MCP write adds an entry, is merged into the
+// previous one, or is refused. The zero value lets every write through.
+type writeGate struct {
+	mu sync.Mutex
+	// limit is the most entries added per rateWindow; 0 means no limit
+	limit int
+	// coalesce merges a write into the previous entry if it came within
+	// this long of it; 0 turns merging off
+	coalesce time.Duration
+
+	added []time.Time
+	last  *coalesceTarget
+}
+
+// coalesceTarget is the most recent entry a write could be merged into.
+type coalesceTarget struct {
+	id        string
+	kind      string
+	projectID string
+	at        time.Time
+}
+
+// target returns the ID of the entry a new entry should be merged into,
+// or "" to add it normally. Only plain entries of the same type and
+// project merge; entries with metadata always stand alone.
+func (g *writeGate) target(entry *charm.Entry, now time.Time) string {
+	if g.coalesce <= 0 || g.last == nil || entry.Metadata != nil {
+		return ""
+	}
+	if now.Sub(g.last.at) > g.coalesce || g.last.kind != entry.Kind() || g.last.projectID != entry.ProjectID {
+		return ""
+	}
+	return g.last.id
+}
+
+// allow returns an error if adding another entry now would exceed the limit.
+func (g *writeGate) allow(now time.Time) error {
+	if g.limit <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-rateWindow)
+	kept := g.added[:0]
+	for _, at := range g.added {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	g.added = kept
+	if len(g.added) < g.limit {
+		return nil
+	}
+	retry := g.added[0].Add(rateWindow).Sub(now).Round(time.Second)
+	return &toolError{
+		Code:    codeRateLimited,
+		Message: fmt.Sprintf("rate limit reached: at most %d entries per minute from MCP", g.limit),
+		Hint:    fmt.Sprintf("Try again in %s, or combine related updates into one entry.", retry),
+	}
+}
+
+// record notes an entry added or merged into at now.
+func (g *writeGate) record(entry *charm.Entry, id string, merged bool, now time.Time) {
+	if !merged && g.limit > 0 {
+		g.added = append(g.added, now)
+	}
+	if entry.Metadata != nil {
+		g.last = nil
+		return
+	}
+	g.last = &coalesceTarget{id: id, kind: entry.Kind(), projectID: entry.ProjectID, at: now}
+}
+
+// addEntry stores entry through the server's write gate. merged reports
+// that it was appended to the previous entry, whose ID is returned.
+func (s *Server) addEntry(entry charm.Entry) (id string, merged bool, err error) {
+	entry.Message, err = s.redactMessage(entry.Message)
+	if err != nil {
+		return "", false, err
+	}
+
+	s.gate.mu.Lock()
+	defer s.gate.mu.Unlock()
+
+	now := time.Now()
+	if target := s.gate.target(&entry, now); target != "" {
+		if err := s.mergeInto(target, entry); err == nil {
+			s.gate.record(&entry, target, true, now)
+			return target, true, nil
+		}
+		// The previous entry was edited away or deleted; add normally
+	}
+
+	if err := s.gate.allow(now); err != nil {
+		return "", false, err
+	}
+	id, err = s.client.CreateEntry(entry)
+	if err != nil {
+		return "", false, err
+	}
+	s.gate.record(&entry, id, false, now)
+	return id, false, nil
+}
+
+// mergeInto appends entry's message and tags to the stored entry id.
+func (s *Server) mergeInto(id string, entry charm.Entry) error {
+	existing, err := s.client.GetEntry(id)
+	if err != nil {
+		return err
+	}
+	existing.Message += "\n" + entry.Message
+	existing.Tags = charm.MergeTags(existing.Tags, entry.Tags)
+	return s.client.UpdateEntry(*existing)
+}
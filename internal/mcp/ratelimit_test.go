@@ -0,0 +1,62 @@
+// ABOUTME: Tests for MCP write rate limiting and coalescing
+// ABOUTME: Exercises the write gate's decisions without a database
+package mcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func TestWriteGateRateLimit(t *testing.T) {
+	g := &writeGate{limit: 2}
+	now := time.Date(2025, 11, 26, 12, 0, 0, 0, time.UTC)
+	entry := &charm.Entry{Message: "x"}
+
+	for i := 0; i < 2; i++ {
+		if err := g.allow(now); err != nil {
+			t.Fatalf("write %d refused: %v", i+1, err)
+		}
+		g.record(entry, "id", false, now)
+	}
+	if err := g.allow(now.Add(30 * time.Second)); err == nil {
+		t.Error("expected a third write within a minute to be refused")
+	}
+	if err := g.allow(now.Add(rateWindow + time.Second)); err != nil {
+		t.Errorf("expected a write after the window to be allowed: %v", err)
+	}
+
+	var unlimited writeGate
+	for i := 0; i < 100; i++ {
+		if err := unlimited.allow(now); err != nil {
+			t.Fatalf("zero gate refused a write: %v", err)
+		}
+		unlimited.record(entry, "id", false, now)
+	}
+}
+
+func TestWriteGateCoalesce(t *testing.T) {
+	g := &writeGate{coalesce: 30 * time.Second}
+	now := time.Date(2025, 11, 26, 12, 0, 0, 0, time.UTC)
+	note := &charm.Entry{Message: "one", Type: charm.EntryTypeNote}
+	g.record(note, "first", false, now)
+
+	if got := g.target(&charm.Entry{Message: "two"}, now.Add(10*time.Second)); got != "first" {
+		t.Errorf("target = %q, want first", got)
+	}
+	if got := g.target(&charm.Entry{Message: "late"}, now.Add(time.Minute)); got != "" {
+		t.Errorf("target after the window = %q, want none", got)
+	}
+	if got := g.target(&charm.Entry{Message: "todo", Type: charm.EntryTypeTodo}, now); got != "" {
+		t.Errorf("target for another type = %q, want none", got)
+	}
+	if got := g.target(&charm.Entry{Message: "d", Metadata: map[string]string{"rationale": "r"}}, now); got != "" {
+		t.Errorf("target for an entry with metadata = %q, want none", got)
+	}
+
+	g.record(&charm.Entry{Message: "d", Metadata: map[string]string{"rationale": "r"}}, "decision", false, now)
+	if got := g.target(note, now); got != "" {
+		t.Errorf("target after an entry with metadata = %q, want none", got)
+	}
+}
@@ -45,6 +45,15 @@ func (s *Server) registerResources() {
 	}
 	s.mcpServer.AddResource(todayResource, s.handleTodaySummary)
 
+	// pinned resource
+	pinnedResource := &mcp.Resource{
+		URI:         "chronicle://pinned",
+		Name:        "Pinned Context",
+		Description: "Pinned standing-context entries (e.g. on-call, current goals)",
+		MIMEType:    "application/json",
+	}
+	s.mcpServer.AddResource(pinnedResource, s.handlePinned)
+
 	// project-context resource
 	projectResource := &mcp.Resource{
 		URI:         "chronicle://project-context",
@@ -53,13 +62,40 @@ func (s *Server) registerResources() {
 		MIMEType:    "application/json",
 	}
 	s.mcpServer.AddResource(projectResource, s.handleProjectContext)
+
+	// context resource
+	contextResource := &mcp.Resource{
+		URI:         "chronicle://context",
+		Name:        "Context Snapshot",
+		Description: "Recent entries, today's summary, this week's top tags, and project context in one document",
+		MIMEType:    "application/json",
+	}
+	s.mcpServer.AddResource(contextResource, s.handleContext)
+
+	// tag entries template; clients can complete {tag} from existing tags
+	tagEntries := &mcp.ResourceTemplate{
+		URITemplate: "chronicle://tags/{tag}",
+		Name:        "Entries by Tag",
+		Description: "The 50 most recent entries with a tag",
+		MIMEType:    "application/json",
+	}
+	s.mcpServer.AddResourceTemplate(tagEntries, s.handleTagEntries)
+
+	// tag-rules resource
+	tagRulesResource := &mcp.Resource{
+		URI:         "chronicle://tag-rules",
+		Name:        "Tag Rules",
+		Description: "Auto-tagging rules remember_this applies (built-in, config, and project), so assistants can follow the same conventions",
+		MIMEType:    "application/json",
+	}
+	s.mcpServer.AddResource(tagRulesResource, s.handleTagRules)
 }
 
 // handleRecentActivity implements the recent-activity resource.
 func (s *Server) handleRecentActivity(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	entries, err := s.client.ListEntries(10)
+	entries, err := s.recentEntries()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list entries: %w", err)
+		return nil, err
 	}
 
 	data, err := json.MarshalIndent(entries, "", "  ")
@@ -80,10 +116,52 @@ func (s *Server) handleRecentActivity(ctx context.Context, req *mcp.ReadResource
 	return result, nil
 }
 
+// recentEntries returns the last 10 entries in scope.
+func (s *Server) recentEntries() ([]charm.Entry, error) {
+	entries, err := s.client.SearchEntries(s.scoped(nil), 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	// Fill in the type for entries written before types existed
+	for i := range entries {
+		entries[i].Type = entries[i].Kind()
+	}
+	return entries, nil
+}
+
+// handlePinned implements the pinned resource.
+func (s *Server) handlePinned(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	entries, err := s.client.SearchEntries(s.scoped(&charm.SearchFilter{PinnedOnly: true}), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pinned entries: %w", err)
+	}
+	if entries == nil {
+		entries = []charm.Entry{}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      "chronicle://pinned",
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		},
+	}
+
+	return result, nil
+}
+
 // handleTags implements the tags resource.
 func (s *Server) handleTags(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 	// Get all entries and count tags
-	entries, err := s.client.ListEntries(0) // 0 = no limit
+	entries, err := s.client.SearchEntries(s.scoped(nil), 0) // 0 = no limit
 	if err != nil {
 		return nil, fmt.Errorf("failed to list entries: %w", err)
 	}
@@ -116,6 +194,26 @@ func (s *Server) handleTags(ctx context.Context, req *mcp.ReadResourceRequest) (
 
 // handleTodaySummary implements the today-summary resource.
 func (s *Server) handleTodaySummary(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	summary, err := s.todaySummary()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      "chronicle://today-summary",
+				MIMEType: "text/markdown",
+				Text:     summary,
+			},
+		},
+	}
+
+	return result, nil
+}
+
+// todaySummary renders today's entries as Markdown.
+func (s *Server) todaySummary() (string, error) {
 	// Get entries from today
 	now := time.Now()
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
@@ -124,9 +222,9 @@ func (s *Server) handleTodaySummary(ctx context.Context, req *mcp.ReadResourceRe
 		Since: &startOfDay,
 	}
 
-	entries, err := s.client.SearchEntries(filter, 0) // 0 = no limit
+	entries, err := s.client.SearchEntries(s.scoped(filter), 0) // 0 = no limit
 	if err != nil {
-		return nil, fmt.Errorf("failed to search entries: %w", err)
+		return "", fmt.Errorf("failed to search entries: %w", err)
 	}
 
 	var summary strings.Builder
@@ -136,18 +234,43 @@ func (s *Server) handleTodaySummary(ctx context.Context, req *mcp.ReadResourceRe
 		summary.WriteString("No entries logged today yet.\n")
 	} else {
 		for _, entry := range entries {
-			summary.WriteString(fmt.Sprintf("- **%s**: %s\n",
+			summary.WriteString(fmt.Sprintf("- **%s** (%s): %s\n",
 				entry.Timestamp.Format("15:04:05"),
+				entry.Kind(),
 				entry.Message))
 		}
 	}
+	return summary.String(), nil
+}
+
+// projectContextData is the project-context resource's document.
+type projectContextData struct {
+	HasProjectConfig bool                  `json:"has_project_config"`
+	ProjectRoot      string                `json:"project_root,omitempty"`
+	Config           *config.ProjectConfig `json:"config,omitempty"`
+	Project          *charm.Project        `json:"project,omitempty"`
+	RecentEntries    []charm.Entry         `json:"recent_entries,omitempty"`
+	Message          string                `json:"message"`
+}
+
+// handleProjectContext implements the project-context resource.
+func (s *Server) handleProjectContext(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	contextData, err := s.projectContext()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(contextData, "", "  ")
+	if err != nil {
+		return nil, err
+	}
 
 	result := &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
-				URI:      "chronicle://today-summary",
-				MIMEType: "text/markdown",
-				Text:     summary.String(),
+				URI:      "chronicle://project-context",
+				MIMEType: "application/json",
+				Text:     string(data),
 			},
 		},
 	}
@@ -155,41 +278,92 @@ func (s *Server) handleTodaySummary(ctx context.Context, req *mcp.ReadResourceRe
 	return result, nil
 }
 
-// handleProjectContext implements the project-context resource.
-func (s *Server) handleProjectContext(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	cwd, err := os.Getwd()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get working directory: %w", err)
-	}
-
-	// Find project root
-	projectRoot, err := config.FindProjectRoot(cwd)
+// projectContext describes the project the server is scoped to, or the one
+// containing the working directory.
+func (s *Server) projectContext() (*projectContextData, error) {
+	// A project-scoped server always reports its own project
+	projectRoot, err := s.currentProjectRoot()
 	if err != nil {
 		return nil, err
 	}
 
-	var contextData struct {
-		HasProjectConfig bool                  `json:"has_project_config"`
-		ProjectRoot      string                `json:"project_root,omitempty"`
-		Config           *config.ProjectConfig `json:"config,omitempty"`
-		Message          string                `json:"message"`
-	}
+	contextData := &projectContextData{}
 
 	if projectRoot == "" {
 		contextData.Message = "No .chronicle project configuration found in current directory tree"
 	} else {
-		contextData.HasProjectConfig = true
 		contextData.ProjectRoot = projectRoot
 
 		chroniclePath := filepath.Join(projectRoot, ".chronicle")
+		if _, err := os.Stat(chroniclePath); err == nil {
+			contextData.HasProjectConfig = true
+		}
 		cfg, err := config.LoadProjectConfig(chroniclePath)
 		if err == nil {
 			contextData.Config = cfg
 			contextData.Message = "Project-specific chronicle configuration found"
+			if !contextData.HasProjectConfig {
+				contextData.Message = "Git repository used as the project (no .chronicle file); default settings apply"
+			}
 		}
+
+		// Include the project record and its most recent entries. A
+		// read-only server only looks it up instead of creating it
+		lookup := s.client.EnsureProject
+		if s.readOnly {
+			lookup = s.client.FindProjectByRoot
+		}
+		project, err := lookup(projectRoot)
+		if err == nil && project != nil {
+			contextData.Project = project
+			entries, err := s.client.SearchEntries(&charm.SearchFilter{ProjectID: project.ID}, 10)
+			if err == nil {
+				contextData.RecentEntries = entries
+			}
+		}
+	}
+	return contextData, nil
+}
+
+// contextTopTags is how many of this week's tags the context resource lists.
+const contextTopTags = 10
+
+// handleContext implements the context resource, bundling the other
+// resources for clients that preload a single one.
+func (s *Server) handleContext(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	var snapshot struct {
+		RecentEntries []charm.Entry       `json:"recent_entries"`
+		TodaySummary  string              `json:"today_summary"`
+		WeekTopTags   []charm.Count       `json:"week_top_tags"`
+		Project       *projectContextData `json:"project"`
 	}
 
-	data, err := json.MarshalIndent(contextData, "", "  ")
+	var err error
+	if snapshot.RecentEntries, err = s.recentEntries(); err != nil {
+		return nil, err
+	}
+	if snapshot.TodaySummary, err = s.todaySummary(); err != nil {
+		return nil, err
+	}
+
+	week, err := resolvePeriod("this week", time.Now(), s.weekStart)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.client.Stats(s.scoped(&charm.SearchFilter{Since: &week.Since, Until: &week.Until}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute stats: %w", err)
+	}
+	snapshot.WeekTopTags = append([]charm.Count{}, stats.TopTags(contextTopTags)...)
+
+	if snapshot.Project, err = s.projectContext(); err != nil {
+		return nil, err
+	}
+	if snapshot.RecentEntries == nil {
+		snapshot.RecentEntries = []charm.Entry{}
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +371,7 @@ func (s *Server) handleProjectContext(ctx context.Context, req *mcp.ReadResource
 	result := &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
-				URI:      "chronicle://project-context",
+				URI:      "chronicle://context",
 				MIMEType: "application/json",
 				Text:     string(data),
 			},
@@ -4,8 +4,13 @@ package mcp
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/harper/chronicle/internal/autotag"
 	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -13,10 +18,66 @@ import (
 type Server struct {
 	mcpServer *mcp.Server
 	client    *charm.Client
+	// projectRoot, when set, limits every tool and resource to entries
+	// recorded in this directory or below it
+	projectRoot string
+	// duplicateWindow is how far back remember_this looks for a duplicate;
+	// zero turns the check off
+	duplicateWindow time.Duration
+	// gate rate-limits and coalesces the entries tools add
+	gate writeGate
+	// requireConfirmation makes edit_entry and delete_entry wait for the
+	// user's approval
+	requireConfirmation bool
+	confirms            confirmations
+	// readOnly leaves out every tool that writes, for assistants that may
+	// consult the journal but never change it
+	readOnly bool
+	// logger records tool calls; nil turns call logging off
+	logger *slog.Logger
+	// profile is the journal the server reads and writes; empty is the default
+	profile string
+	// configTagRules are the global config's auto-tagging rules
+	configTagRules []config.TagRule
+	// defaultTags are the global config's tags for every entry
+	defaultTags []string
+	// privacyRules suppress or hash metadata of entries in private directories
+	privacyRules []config.CompiledPrivacyRule
+	// redactor scrubs or refuses secrets in messages; nil stores them as is
+	redactor *config.Redactor
+	// gitProjects treats a git repository without .chronicle as a project
+	gitProjects bool
+	// weekStart is the first day of "this week" and "last week"
+	weekStart time.Weekday
+	// dateLayout formats dates in export headings and summary text
+	dateLayout string
+	// autoTagger suggests tags and a type for add_entry; nil turns
+	// auto-tagging off
+	autoTagger *autotag.Tagger
+}
+
+// defaultDuplicateWindow is used when duplicate_window isn't configured.
+const defaultDuplicateWindow = 10 * time.Minute
+
+// Options configures a chronicle MCP server.
+type Options struct {
+	// ProjectRoot scopes the server to one project's entries. Empty means
+	// the whole journal is visible.
+	ProjectRoot string
+	// RequireConfirmation makes destructive tools ask the user first, in
+	// addition to the mcp_require_confirmation config option.
+	RequireConfirmation bool
+	// ReadOnly registers only the query tools. The mcp_read_only and
+	// read_only config options also turn it on.
+	ReadOnly bool
+	// Log, if set, records every tool call with its duration and any
+	// error. Arguments are included at debug level, with journal text
+	// redacted.
+	Log *slog.Logger
 }
 
 // NewServer creates a new chronicle MCP server.
-func NewServer() (*Server, error) {
+func NewServer(opts Options) (*Server, error) {
 	impl := &mcp.Implementation{
 		Name:    "chronicle",
 		Version: "0.2.0",
@@ -29,9 +90,54 @@ func NewServer() (*Server, error) {
 	}
 
 	server := &Server{
-		mcpServer: mcp.NewServer(impl, nil),
-		client:    client,
+		client:     client,
+		logger:     opts.Log,
+		weekStart:  time.Monday,
+		dateLayout: time.DateOnly,
+	}
+	server.mcpServer = mcp.NewServer(impl, &mcp.ServerOptions{CompletionHandler: server.handleComplete})
+	if opts.ProjectRoot != "" {
+		server.projectRoot = config.CanonicalDir(opts.ProjectRoot, false)
 	}
+	server.duplicateWindow = defaultDuplicateWindow
+	if cfg := client.Config(); cfg != nil {
+		if cfg.DuplicateWindow != 0 {
+			server.duplicateWindow = max(time.Duration(cfg.DuplicateWindow)*time.Second, 0)
+		}
+		server.gate.limit = cfg.MCPRateLimit
+		server.gate.coalesce = time.Duration(cfg.MCPCoalesceWindow) * time.Second
+		server.requireConfirmation = cfg.MCPRequireConfirmation
+		server.readOnly = cfg.MCPReadOnly || cfg.ReadOnly
+		server.profile = charm.ResolveProfile(cfg.Profile)
+		server.gitProjects = cfg.GitProjects
+		if _, err := config.CompileTagRules(cfg.TagRules); err != nil {
+			return nil, fmt.Errorf("invalid tag_rules in %s: %w", charm.ConfigPath(), err)
+		}
+		server.configTagRules = cfg.TagRules
+		server.defaultTags = cfg.DefaultTags
+		server.privacyRules, err = config.CompilePrivacyRules(cfg.PrivacyRules)
+		if err != nil {
+			return nil, fmt.Errorf("invalid privacy_rules in %s: %w", charm.ConfigPath(), err)
+		}
+		server.redactor, err = config.NewRedactor(cfg.RedactSecrets, cfg.RedactPatterns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret redaction settings in %s: %w", charm.ConfigPath(), err)
+		}
+		if server.weekStart, err = config.ParseWeekday(cfg.WeekStartsOn); err != nil {
+			return nil, fmt.Errorf("invalid config %s: %w", charm.ConfigPath(), err)
+		}
+		if server.dateLayout, err = config.DateLayout(cfg.DateFormat); err != nil {
+			return nil, fmt.Errorf("invalid config %s: %w", charm.ConfigPath(), err)
+		}
+		if cfg.AutoTag != nil {
+			if err := cfg.AutoTag.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid auto_tag in %s: %w", charm.ConfigPath(), err)
+			}
+			server.autoTagger = autotag.New(cfg.AutoTag, charm.AutoTagCachePath())
+		}
+	}
+	server.requireConfirmation = server.requireConfirmation || opts.RequireConfirmation
+	server.readOnly = server.readOnly || opts.ReadOnly
 
 	// Register components
 	server.registerPrompts()
@@ -41,8 +147,37 @@ func NewServer() (*Server, error) {
 	return server, nil
 }
 
+// scoped returns filter (nil for all entries) limited to the project
+// root, if the server has one. The caller's filter is not modified.
+func (s *Server) scoped(filter *charm.SearchFilter) *charm.SearchFilter {
+	if s.projectRoot == "" {
+		return filter
+	}
+	out := charm.SearchFilter{}
+	if filter != nil {
+		out = *filter
+	}
+	out.Directory = s.projectRoot
+	return &out
+}
+
+// inScope reports whether entry is visible to this server.
+func (s *Server) inScope(entry *charm.Entry) bool {
+	return s.projectRoot == "" || config.DirWithin(entry.WorkingDirectory, s.projectRoot)
+}
+
 // Run starts the MCP server with stdio transport.
 func (s *Server) Run(ctx context.Context) error {
 	transport := &mcp.StdioTransport{}
-	return s.mcpServer.Run(ctx, transport)
+	if s.logger == nil {
+		return s.mcpServer.Run(ctx, transport)
+	}
+	s.logger.Info("server started", "profile", s.profile, "project", s.projectRoot, "read_only", s.readOnly)
+	err := s.mcpServer.Run(ctx, transport)
+	if err != nil {
+		s.logger.Error("server stopped", "error", err)
+	} else {
+		s.logger.Info("server stopped")
+	}
+	return err
 }
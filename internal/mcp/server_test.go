@@ -3,7 +3,11 @@
 package mcp
 
 import (
+	"context"
 	"testing"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func TestServerTypes(t *testing.T) {
@@ -59,3 +63,97 @@ func TestSearchEntriesTypes(t *testing.T) {
 		t.Error("expected text field")
 	}
 }
+
+func TestScopedFilter(t *testing.T) {
+	unscoped := &Server{}
+	if unscoped.scoped(nil) != nil {
+		t.Error("expected an unscoped server to leave a nil filter alone")
+	}
+
+	s := &Server{projectRoot: "/src/chronicle"}
+	filter := &charm.SearchFilter{Text: "deploy"}
+	got := s.scoped(filter)
+	if got.Directory != "/src/chronicle" || got.Text != "deploy" {
+		t.Errorf("scoped filter = %+v, want text kept and directory set", got)
+	}
+	if filter.Directory != "" {
+		t.Error("expected the caller's filter to be left unchanged")
+	}
+
+	if !s.inScope(&charm.Entry{WorkingDirectory: "/src/chronicle/cmd"}) || s.inScope(&charm.Entry{WorkingDirectory: "/src/other"}) {
+		t.Error("expected inScope to follow the project root")
+	}
+}
+
+// listTools registers s's tools and lists them the way a client sees them.
+func listTools(t *testing.T, s *Server) []*mcp.Tool {
+	t.Helper()
+	s.registerTools()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := s.mcpServer.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	res, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	return res.Tools
+}
+
+func TestReadOnlyServerOmitsWriteTools(t *testing.T) {
+	names := func(readOnly bool) map[string]bool {
+		s := &Server{mcpServer: mcp.NewServer(&mcp.Implementation{Name: "test"}, nil), readOnly: readOnly}
+		set := make(map[string]bool)
+		for _, tool := range listTools(t, s) {
+			set[tool.Name] = true
+		}
+		return set
+	}
+
+	full, readOnly := names(false), names(true)
+	for _, name := range []string{"add_entry", "edit_entry", "delete_entry", "remember_this", "log_decision", "start_session", "end_session"} {
+		if !full[name] || readOnly[name] {
+			t.Errorf("%s: registered %v normally and %v read-only, want true and false", name, full[name], readOnly[name])
+		}
+	}
+	for _, name := range []string{"list_entries", "search_entries", "what_was_i_doing", "activity_stats"} {
+		if !readOnly[name] {
+			t.Errorf("%s missing from the read-only server", name)
+		}
+	}
+}
+
+func TestToolAnnotations(t *testing.T) {
+	s := &Server{mcpServer: mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)}
+	tools := make(map[string]*mcp.ToolAnnotations)
+	for _, tool := range listTools(t, s) {
+		if tool.Annotations == nil {
+			t.Fatalf("%s has no annotations", tool.Name)
+		}
+		tools[tool.Name] = tool.Annotations
+	}
+
+	for _, name := range []string{"list_entries", "search_entries", "what_was_i_doing", "related_entries"} {
+		if a := tools[name]; !a.ReadOnlyHint || !a.IdempotentHint {
+			t.Errorf("%s annotations = %+v, want read-only and idempotent", name, a)
+		}
+	}
+	for _, name := range []string{"add_entry", "remember_this", "log_decision", "start_session"} {
+		if a := tools[name]; a.ReadOnlyHint || a.DestructiveHint == nil || *a.DestructiveHint {
+			t.Errorf("%s annotations = %+v, want an additive write", name, a)
+		}
+	}
+	for _, name := range []string{"edit_entry", "delete_entry"} {
+		if a := tools[name]; a.ReadOnlyHint || a.DestructiveHint == nil || !*a.DestructiveHint {
+			t.Errorf("%s annotations = %+v, want destructive", name, a)
+		}
+	}
+}
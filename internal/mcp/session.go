@@ -0,0 +1,180 @@
+// ABOUTME: Work session tools for chronicle (start_session / end_session)
+// ABOUTME: A session is a start entry and an end entry linked together, with the duration on the end
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionTag is added to every session entry so sessions can be found by tag.
+const sessionTag = "session"
+
+// relationEnds links an end_session entry to the entry that started it.
+const relationEnds = "ends"
+
+// Metadata keys set on session entries.
+const (
+	sessionKey      = "session" // "start" or "end"
+	sessionTopic    = "topic"
+	sessionStartID  = "start_id"
+	sessionDuration = "duration_minutes"
+)
+
+// StartSessionInput defines input for start_session tool.
+type StartSessionInput struct {
+	Topic string   `json:"topic" jsonschema:"What the session is about" jsonschema_extras:"required=true"`
+	Tags  []string `json:"tags,omitempty" jsonschema:"Optional tags for the session"`
+}
+
+// EndSessionInput defines input for end_session tool.
+type EndSessionInput struct {
+	SessionID string `json:"session_id,omitempty" jsonschema:"ID returned by start_session (default: the most recent open session)"`
+	Summary   string `json:"summary,omitempty" jsonschema:"What got done during the session"`
+}
+
+// SessionOutput defines the output for start_session and end_session tools.
+type SessionOutput struct {
+	SessionID       string `json:"session_id" jsonschema:"ID of the entry that started the session"`
+	EntryID         string `json:"entry_id" jsonschema:"ID of the entry that was just created"`
+	Topic           string `json:"topic"`
+	Started         string `json:"started"`
+	Ended           string `json:"ended,omitempty"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+}
+
+// handleStartSession implements the start_session tool.
+func (s *Server) handleStartSession(ctx context.Context, req *mcp.CallToolRequest, input StartSessionInput) (*mcp.CallToolResult, SessionOutput, error) {
+	topic := strings.TrimSpace(input.Topic)
+	if topic == "" {
+		return nil, SessionOutput{}, invalidInput("topic is required")
+	}
+
+	dir, err := s.entryDir(ctx, req, "")
+	if err != nil {
+		return nil, SessionOutput{}, err
+	}
+	entry := s.newEntry(dir, "Started session: "+topic, charm.MergeTags(input.Tags, []string{sessionTag}), charm.EntryTypeNote)
+	entry.Timestamp = time.Now()
+	entry.Metadata = map[string]string{sessionKey: "start", sessionTopic: topic}
+	id, _, err := s.addEntry(entry)
+	if err != nil {
+		return nil, SessionOutput{}, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	output := SessionOutput{
+		SessionID: id,
+		EntryID:   id,
+		Topic:     topic,
+		Started:   entry.Timestamp.Format("2006-01-02 15:04:05"),
+	}
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Session %q started (ID: %s) at %s", topic, id, output.Started)},
+		},
+	}
+	return result, output, nil
+}
+
+// handleEndSession implements the end_session tool.
+func (s *Server) handleEndSession(ctx context.Context, req *mcp.CallToolRequest, input EndSessionInput) (*mcp.CallToolResult, SessionOutput, error) {
+	entries, err := s.client.SearchEntries(s.scoped(&charm.SearchFilter{Tags: []string{sessionTag}}), 0)
+	if err != nil {
+		return nil, SessionOutput{}, fmt.Errorf("failed to find sessions: %w", err)
+	}
+	start, err := findOpenSession(entries, strings.TrimSpace(input.SessionID))
+	if err != nil {
+		return nil, SessionOutput{}, err
+	}
+
+	topic := start.Metadata[sessionTopic]
+	now := time.Now()
+	minutes := int(now.Sub(start.Timestamp).Round(time.Minute) / time.Minute)
+	message := fmt.Sprintf("Ended session: %s (%s)", topic, formatMinutes(minutes))
+	if summary := strings.TrimSpace(input.Summary); summary != "" {
+		message += "\n\n" + summary
+	}
+
+	dir, err := s.entryDir(ctx, req, "")
+	if err != nil {
+		return nil, SessionOutput{}, err
+	}
+	entry := s.newEntry(dir, message, charm.MergeTags(start.Tags, []string{sessionTag}), charm.EntryTypeNote)
+	entry.Timestamp = now
+	entry.Metadata = map[string]string{
+		sessionKey:      "end",
+		sessionTopic:    topic,
+		sessionStartID:  start.ID,
+		sessionDuration: strconv.Itoa(minutes),
+	}
+	id, _, err := s.addEntry(entry)
+	if err != nil {
+		return nil, SessionOutput{}, fmt.Errorf("failed to end session: %w", err)
+	}
+
+	text := fmt.Sprintf("Session %q ended after %s (ID: %s)", topic, formatMinutes(minutes), id)
+	// The end entry names its start in metadata, so a failed link still
+	// closes the session; it only loses the navigable link.
+	if err := s.client.AddLink(id, start.ID, relationEnds); err != nil {
+		text += fmt.Sprintf("\nwarning: could not link to the start entry: %v", err)
+	}
+
+	output := SessionOutput{
+		SessionID:       start.ID,
+		EntryID:         id,
+		Topic:           topic,
+		Started:         start.Timestamp.Format("2006-01-02 15:04:05"),
+		Ended:           now.Format("2006-01-02 15:04:05"),
+		DurationMinutes: minutes,
+	}
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+	return result, output, nil
+}
+
+// findOpenSession returns the session start entry with the given ID, or the
+// most recent one if id is empty. A session with an end entry is closed.
+// entries must be sorted newest first.
+func findOpenSession(entries []charm.Entry, id string) (*charm.Entry, error) {
+	ended := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Metadata[sessionKey] == "end" {
+			ended[entry.Metadata[sessionStartID]] = true
+		}
+	}
+
+	for i, entry := range entries {
+		if entry.Metadata[sessionKey] != "start" || (id != "" && entry.ID != id) {
+			continue
+		}
+		if ended[entry.ID] {
+			if id != "" {
+				return nil, invalidInput("session %s has already ended", id)
+			}
+			continue
+		}
+		return &entries[i], nil
+	}
+
+	if id != "" {
+		return nil, notFound("session %s not found", id)
+	}
+	return nil, &toolError{Code: codeNotFound, Message: "no open session to end", Hint: "Start one with start_session."}
+}
+
+// formatMinutes renders a duration like "45m" or "1h 20m".
+func formatMinutes(minutes int) string {
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+}
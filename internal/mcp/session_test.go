@@ -0,0 +1,60 @@
+// ABOUTME: Tests for the work session tools
+// ABOUTME: Covers open-session lookup and duration formatting
+package mcp
+
+import (
+	"testing"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func TestFindOpenSession(t *testing.T) {
+	start := func(id string) charm.Entry {
+		return charm.Entry{ID: id, Metadata: map[string]string{sessionKey: "start", sessionTopic: id}}
+	}
+	end := func(id, startID string) charm.Entry {
+		return charm.Entry{ID: id, Metadata: map[string]string{sessionKey: "end", sessionStartID: startID}}
+	}
+	// Newest first: s3 is open, s2 has ended, s1 is open
+	entries := []charm.Entry{start("s3"), end("e2", "s2"), start("s2"), start("s1")}
+
+	tests := []struct {
+		id        string
+		want      string
+		wantError bool
+	}{
+		{"", "s3", false},
+		{"s1", "s1", false},
+		{"s2", "", true},
+		{"missing", "", true},
+	}
+	for _, tt := range tests {
+		got, err := findOpenSession(entries, tt.id)
+		if (err != nil) != tt.wantError {
+			t.Fatalf("findOpenSession(%q) error = %v, wantError %v", tt.id, err, tt.wantError)
+		}
+		if err == nil && got.ID != tt.want {
+			t.Errorf("findOpenSession(%q) = %s, want %s", tt.id, got.ID, tt.want)
+		}
+	}
+
+	if _, err := findOpenSession([]charm.Entry{end("e2", "s2"), start("s2")}, ""); err == nil {
+		t.Error("expected an error when every session has ended")
+	}
+}
+
+func TestFormatMinutes(t *testing.T) {
+	for minutes, want := range map[int]string{0: "0m", 45: "45m", 60: "1h 0m", 85: "1h 25m"} {
+		if got := formatMinutes(minutes); got != want {
+			t.Errorf("formatMinutes(%d) = %q, want %q", minutes, got, want)
+		}
+	}
+}
+
+func TestStartSessionRequiresTopic(t *testing.T) {
+	assertHandlersReject(t, []handlerCase{
+		{"blank topic", func(s *Server) error {
+			return handlerErr(s.handleStartSession, StartSessionInput{Topic: " "})
+		}},
+	})
+}
@@ -0,0 +1,154 @@
+// ABOUTME: Auto-tagging rules for remember_this: built-in defaults plus config and project rules
+// ABOUTME: Also serves the chronicle://tag-rules resource so assistants know the conventions
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// fallbackTag is suggested when no rule matches.
+const fallbackTag = "work"
+
+// Sources of a tag rule, reported in the tag-rules resource.
+const (
+	ruleSourceDefault = "default"
+	ruleSourceConfig  = "config"
+	ruleSourceProject = "project"
+)
+
+// tagRule is an active rule and where it came from.
+type tagRule struct {
+	config.CompiledTagRule
+	Source string `json:"source"`
+}
+
+// tagRules returns the rules in effect, in order: built-in defaults, then
+// the global config's, then those of the project at root ("" for none).
+func (s *Server) tagRules(root string) ([]tagRule, error) {
+	var rules []tagRule
+	add := func(source string, raw []config.TagRule) error {
+		compiled, err := config.CompileTagRules(raw)
+		if err != nil {
+			return err
+		}
+		for _, rule := range compiled {
+			rules = append(rules, tagRule{CompiledTagRule: rule, Source: source})
+		}
+		return nil
+	}
+
+	if err := add(ruleSourceDefault, config.DefaultTagRules); err != nil {
+		return nil, err
+	}
+	if err := add(ruleSourceConfig, s.configTagRules); err != nil {
+		return nil, fmt.Errorf("invalid tag_rules in %s: %w", charm.ConfigPath(), err)
+	}
+
+	if root == "" {
+		return rules, nil
+	}
+	chroniclePath := filepath.Join(root, ".chronicle")
+	projectCfg, err := config.LoadProjectConfig(chroniclePath)
+	if err != nil {
+		// A .chronicle file that isn't TOML only affects its own options
+		return rules, nil
+	}
+	if err := add(ruleSourceProject, projectCfg.TagRules); err != nil {
+		return nil, fmt.Errorf("invalid tag_rules in %s: %w", chroniclePath, err)
+	}
+	return rules, nil
+}
+
+// currentProjectRoot returns the server's project root, or the project
+// containing the working directory ("" if there is none).
+func (s *Server) currentProjectRoot() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	return s.projectRootFor(cwd)
+}
+
+// projectRootFor returns the server's project root, or the project
+// containing dir ("" if there is none).
+func (s *Server) projectRootFor(dir string) (string, error) {
+	if s.projectRoot != "" {
+		return s.projectRoot, nil
+	}
+	return s.findProjectRoot(dir)
+}
+
+// findProjectRoot returns the project containing dir: the nearest
+// .chronicle file, or with git_projects set the nearest git repository.
+func (s *Server) findProjectRoot(dir string) (string, error) {
+	if s.gitProjects {
+		return config.FindProjectRootOrGit(dir)
+	}
+	return config.FindProjectRoot(dir)
+}
+
+// suggestTags returns the tags of every rule matching activity or context,
+// without repeats, or the fallback tag if none match.
+func suggestTags(rules []tagRule, activity, context string) []string {
+	tags := matchingTags(rules, activity+" "+context)
+	if len(tags) == 0 {
+		tags = []string{fallbackTag}
+	}
+	return tags
+}
+
+// matchingTags returns the tags of every rule matching text, without
+// repeats.
+func matchingTags(rules []tagRule, text string) []string {
+	var tags []string
+	for _, rule := range rules {
+		if rule.Matches(text) {
+			tags = charm.MergeTags(tags, rule.Tags)
+		}
+	}
+	return tags
+}
+
+// handleTagRules implements the tag-rules resource.
+func (s *Server) handleTagRules(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	root, err := s.currentProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.tagRules(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Rules    []tagRule `json:"rules"`
+		Fallback string    `json:"fallback"`
+	}
+	doc.Rules = rules
+	doc.Fallback = fallbackTag
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      "chronicle://tag-rules",
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		},
+	}
+
+	return result, nil
+}
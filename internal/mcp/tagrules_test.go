@@ -0,0 +1,94 @@
+// ABOUTME: Tests for remember_this auto-tagging rules
+// ABOUTME: Covers merging built-in, config, and project rules, and model-assisted tagging on add_entry
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/harper/chronicle/internal/autotag"
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+// builtinTagRules returns only the default rules, as a server outside any
+// project with no configured rules would use.
+func builtinTagRules(t *testing.T) []tagRule {
+	t.Helper()
+	compiled, err := config.CompileTagRules(config.DefaultTagRules)
+	if err != nil {
+		t.Fatalf("default tag rules: %v", err)
+	}
+	rules := make([]tagRule, len(compiled))
+	for i, rule := range compiled {
+		rules[i] = tagRule{CompiledTagRule: rule, Source: ruleSourceDefault}
+	}
+	return rules
+}
+
+func TestTagRulesMerge(t *testing.T) {
+	root := t.TempDir()
+	project := "[[tag_rules]]\nkeywords = [\"terraform\"]\ntags = [\"infra\"]\n"
+	if err := os.WriteFile(filepath.Join(root, ".chronicle"), []byte(project), 0644); err != nil { //nolint:gosec // Test file permissions
+		t.Fatal(err)
+	}
+	s := &Server{
+		projectRoot:    root,
+		configTagRules: []config.TagRule{{Pattern: `ACME-\d+`, Tags: []string{"acme", "ticket"}}},
+	}
+
+	rules, err := s.tagRules(root)
+	if err != nil {
+		t.Fatalf("tagRules: %v", err)
+	}
+	sources := map[string]int{}
+	for _, rule := range rules {
+		sources[rule.Source]++
+	}
+	if sources[ruleSourceDefault] != len(config.DefaultTagRules) || sources[ruleSourceConfig] != 1 || sources[ruleSourceProject] != 1 {
+		t.Errorf("rule sources = %v", sources)
+	}
+
+	got := suggestTags(rules, "fixed terraform drift for ACME-12", "")
+	if want := []string{"bug-fix", "acme", "ticket", "infra"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestTags() = %v, want %v", got, want)
+	}
+	if got := suggestTags(rules, "lunch", ""); !reflect.DeepEqual(got, []string{fallbackTag}) {
+		t.Errorf("suggestTags() with no match = %v, want the fallback", got)
+	}
+
+	s.configTagRules = []config.TagRule{{Pattern: "(", Tags: []string{"x"}}}
+	if _, err := s.tagRules(root); err == nil {
+		t.Error("expected an error for an invalid config rule")
+	}
+}
+
+func TestAutoTag(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"choices": [{"message": {"content": "{\"tags\": [\"postgres\"], \"type\": \"decision\"}"}}]}`)
+	}))
+	defer srv.Close()
+	s := &Server{autoTagger: autotag.New(&config.AutoTag{URL: srv.URL, Model: "tiny"}, filepath.Join(t.TempDir(), "cache.json"))}
+
+	entry := charm.Entry{Message: "chose postgres after the bug bash", Tags: []string{"db"}}
+	s.autoTag(context.Background(), &entry, t.TempDir())
+	if want := []string{"db", "bug-fix", "decision", "postgres"}; !reflect.DeepEqual(entry.Tags, want) {
+		t.Errorf("tags = %v, want %v", entry.Tags, want)
+	}
+	if entry.Type != charm.EntryTypeDecision {
+		t.Errorf("type = %q, want decision", entry.Type)
+	}
+
+	// Without auto_tag nothing changes, not even from the rules
+	entry = charm.Entry{Message: "fixed the bug"}
+	(&Server{}).autoTag(context.Background(), &entry, t.TempDir())
+	if len(entry.Tags) != 0 || entry.Type != "" {
+		t.Errorf("entry changed without auto_tag: %+v", entry)
+	}
+}
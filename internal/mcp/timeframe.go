@@ -0,0 +1,97 @@
+// ABOUTME: Timeframe parsing for what_was_i_doing, summarize_period, and activity_stats
+// ABOUTME: Maps phrases like "yesterday" or "last month" to a date window and result limit
+package mcp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/config"
+)
+
+// timeframe is a resolved what_was_i_doing window.
+type timeframe struct {
+	Label string
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// Describe renders the window for the summary text.
+func (tf timeframe) Describe() string {
+	const layout = "Mon Jan 2 15:04"
+	return fmt.Sprintf("%s (%s to %s)", tf.Label, tf.Since.Format(layout), tf.Until.Format(layout))
+}
+
+// resolveTimeframe maps a timeframe phrase to a window ending no later than
+// now. Empty means today. Longer windows get a larger result limit; weeks
+// begin on weekStart.
+func resolveTimeframe(phrase string, now time.Time, weekStart time.Weekday) (timeframe, error) {
+	phrase = strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch phrase {
+	case "", "today":
+		return timeframe{Label: "today", Since: startOfDay, Until: now, Limit: 20}, nil
+	case "yesterday":
+		since := startOfDay.AddDate(0, 0, -1)
+		return timeframe{Label: "yesterday", Since: since, Until: startOfDay.Add(-time.Nanosecond), Limit: 20}, nil
+	case "this week":
+		return timeframe{Label: "this week", Since: config.StartOfWeek(now, weekStart), Until: now, Limit: 50}, nil
+	case "last hour", "past hour":
+		return timeframe{Label: "the last hour", Since: now.Add(-time.Hour), Until: now, Limit: 20}, nil
+	}
+
+	// "last N hours" / "past N hours"
+	fields := strings.Fields(phrase)
+	if len(fields) == 3 && (fields[0] == "last" || fields[0] == "past") && (fields[2] == "hours" || fields[2] == "hour") {
+		hours, err := strconv.Atoi(fields[1])
+		if err == nil && hours > 0 {
+			limit := 20
+			if hours > 24 {
+				limit = 50
+			}
+			return timeframe{
+				Label: fmt.Sprintf("the last %d hours", hours),
+				Since: now.Add(-time.Duration(hours) * time.Hour),
+				Until: now,
+				Limit: limit,
+			}, nil
+		}
+	}
+
+	return timeframe{}, invalidInput("unknown timeframe %q (use today, yesterday, this week, or last N hours)", phrase)
+}
+
+// resolvePeriod maps a summarize_period phrase to a calendar week or month.
+// Empty means this week. Weeks begin on weekStart; current periods end at now.
+func resolvePeriod(phrase string, now time.Time, weekStart time.Weekday) (timeframe, error) {
+	phrase = strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+	startOfWeek := config.StartOfWeek(now, weekStart)
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	switch phrase {
+	case "", "week", "this week":
+		return timeframe{Label: "this week", Since: startOfWeek, Until: now}, nil
+	case "last week":
+		return timeframe{Label: "last week", Since: startOfWeek.AddDate(0, 0, -7), Until: startOfWeek.Add(-time.Nanosecond)}, nil
+	case "month", "this month":
+		return timeframe{Label: "this month", Since: startOfMonth, Until: now}, nil
+	case "last month":
+		return timeframe{Label: "last month", Since: startOfMonth.AddDate(0, -1, 0), Until: startOfMonth.Add(-time.Nanosecond)}, nil
+	}
+	return timeframe{}, invalidInput("unknown period %q (use this week, last week, this month, or last month)", phrase)
+}
+
+// resolveRange accepts any phrase resolvePeriod or resolveTimeframe does.
+func resolveRange(phrase string, now time.Time, weekStart time.Weekday) (timeframe, error) {
+	if tf, err := resolvePeriod(phrase, now, weekStart); err == nil {
+		return tf, nil
+	}
+	if tf, err := resolveTimeframe(phrase, now, weekStart); err == nil {
+		return tf, nil
+	}
+	return timeframe{}, invalidInput("unknown period %q (use today, yesterday, this week, last week, this month, last month, or last N hours)", phrase)
+}
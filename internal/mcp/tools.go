@@ -6,16 +6,27 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
 
+	"github.com/araddon/dateparse"
+	"github.com/harper/chronicle/internal/autotag"
 	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+	"github.com/harper/chronicle/internal/logging"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // AddEntryInput defines the input for add_entry tool.
 type AddEntryInput struct {
 	Message string   `json:"message" jsonschema:"The message to log" jsonschema_extras:"required=true"`
-	Tags    []string `json:"tags,omitempty" jsonschema:"Optional tags to categorize the entry"`
+	Tags    []string `json:"tags,omitempty" jsonschema:"Optional tags to categorize the entry; reuse existing tags (see chronicle://tags) rather than new spellings"`
+	Type    string   `json:"type,omitempty" jsonschema:"Entry type: note, decision, todo, or milestone (default note)"`
+
+	WorkingDirectory string `json:"working_directory,omitempty" jsonschema:"Absolute path of the project you are working in, recorded as where this happened (default: the client's root)"`
 }
 
 // AddEntryOutput defines the output for add_entry tool.
@@ -23,11 +34,35 @@ type AddEntryOutput struct {
 	EntryID   string `json:"entry_id" jsonschema:"The ID of the created entry"`
 	Message   string `json:"message" jsonschema:"The logged message"`
 	Timestamp string `json:"timestamp" jsonschema:"When the entry was created"`
+	Duplicate bool   `json:"duplicate,omitempty" jsonschema:"Set when an existing entry was returned instead of adding a duplicate"`
+	Merged    bool   `json:"merged,omitempty" jsonschema:"Set when the message was appended to the previous entry instead of creating one"`
+}
+
+// EditEntryInput defines the input for edit_entry tool.
+type EditEntryInput struct {
+	ID      string   `json:"id" jsonschema:"ID of the entry to edit" jsonschema_extras:"required=true"`
+	Message *string  `json:"message,omitempty" jsonschema:"New message, replacing the old one"`
+	Tags    []string `json:"tags,omitempty" jsonschema:"New tags, replacing the old ones (empty list clears them)"`
+
+	ConfirmToken string `json:"confirm_token,omitempty" jsonschema:"Token from a confirmation_required error, once the user has approved the change"`
+}
+
+// DeleteEntryInput defines the input for delete_entry tool.
+type DeleteEntryInput struct {
+	ID           string `json:"id" jsonschema:"ID of the entry to delete" jsonschema_extras:"required=true"`
+	ConfirmToken string `json:"confirm_token,omitempty" jsonschema:"Token from a confirmation_required error, once the user has approved the deletion"`
+}
+
+// DeleteEntryOutput defines the output for delete_entry tool.
+type DeleteEntryOutput struct {
+	ID      string `json:"id"`
+	Message string `json:"message" jsonschema:"The deleted entry's message"`
 }
 
 // ListEntriesInput defines the input for list_entries tool.
 type ListEntriesInput struct {
-	Limit int `json:"limit,omitempty" jsonschema:"Maximum number of entries to return (default 10)"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"Maximum number of entries to return (default 10)"`
+	Cursor string `json:"cursor,omitempty" jsonschema:"next_cursor from the previous page, to continue where it left off"`
 }
 
 // EntryData represents a chronicle entry for output.
@@ -35,31 +70,44 @@ type EntryData struct {
 	ID        string   `json:"id"`
 	Timestamp string   `json:"timestamp"`
 	Message   string   `json:"message"`
+	Type      string   `json:"type"`
 	Tags      []string `json:"tags"`
 	Hostname  string   `json:"hostname"`
 	Username  string   `json:"username"`
 	Directory string   `json:"directory"`
+	ProjectID string   `json:"project_id,omitempty"`
+	Source    string   `json:"source,omitempty"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // ListEntriesOutput defines the output for list_entries tool.
 type ListEntriesOutput struct {
-	Entries []EntryData `json:"entries"`
-	Count   int         `json:"count"`
+	Entries    []EntryData `json:"entries"`
+	Count      int         `json:"count"`
+	NextCursor string      `json:"next_cursor,omitempty" jsonschema:"Pass as cursor to get the next page; absent on the last page"`
 }
 
 // SearchEntriesInput defines the input for search_entries tool.
 type SearchEntriesInput struct {
-	Text  string   `json:"text,omitempty" jsonschema:"Text to search for in entries"`
-	Tags  []string `json:"tags,omitempty" jsonschema:"Filter by tags"`
-	Since string   `json:"since,omitempty" jsonschema:"Start date/time (e.g. '2025-01-01' or 'yesterday')"`
-	Until string   `json:"until,omitempty" jsonschema:"End date/time"`
-	Limit int      `json:"limit,omitempty" jsonschema:"Maximum results (default 20)"`
+	Text    string   `json:"text,omitempty" jsonschema:"Text to search for in entries"`
+	Tags    []string `json:"tags,omitempty" jsonschema:"Filter by tags (existing tags are listed in chronicle://tags)"`
+	Type    string   `json:"type,omitempty" jsonschema:"Filter by entry type (note, decision, todo, milestone)"`
+	Project string   `json:"project,omitempty" jsonschema:"Filter by project name"`
+	Since   string   `json:"since,omitempty" jsonschema:"Start date/time (e.g. '2025-01-01' or 'yesterday')"`
+	Until   string   `json:"until,omitempty" jsonschema:"End date/time"`
+	Limit   int      `json:"limit,omitempty" jsonschema:"Maximum results (default 20)"`
+	Cursor  string   `json:"cursor,omitempty" jsonschema:"next_cursor from the previous page, to continue where it left off"`
 }
 
 // RememberThisInput defines input for remember_this tool.
 type RememberThisInput struct {
 	Activity string `json:"activity" jsonschema:"The activity or information to remember" jsonschema_extras:"required=true"`
 	Context  string `json:"context,omitempty" jsonschema:"Why this matters or additional context"`
+
+	AllowDuplicate bool `json:"allow_duplicate,omitempty" jsonschema:"Log even if the same thing was logged a few minutes ago"`
+
+	WorkingDirectory string `json:"working_directory,omitempty" jsonschema:"Absolute path of the project you are working in, recorded as where this happened (default: the client's root)"`
 }
 
 // WhatWasIDoingInput defines input for what_was_i_doing tool.
@@ -73,11 +121,190 @@ type WhatWasIDoingOutput struct {
 	Entries []EntryData `json:"entries"`
 }
 
+// RelatedEntriesInput defines input for related_entries tool.
+type RelatedEntriesInput struct {
+	ID    string `json:"id,omitempty" jsonschema:"Find entries similar to this entry"`
+	Text  string `json:"text,omitempty" jsonschema:"Find entries similar to this text, e.g. a bug description (used when id is not given)"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of entries to return (default 5)"`
+}
+
+// RelatedEntry is one result of related_entries.
+type RelatedEntry struct {
+	Entry EntryData `json:"entry"`
+	Score float64   `json:"score" jsonschema:"BM25 similarity; higher is closer, comparable only within one call"`
+}
+
+// RelatedEntriesOutput defines the output for related_entries tool.
+type RelatedEntriesOutput struct {
+	Entries []RelatedEntry `json:"entries" jsonschema:"Most similar first"`
+	Count   int            `json:"count"`
+}
+
+// defaultRelatedLimit is how many entries related_entries returns by default.
+const defaultRelatedLimit = 5
+
 // FindWhenIInput defines input for find_when_i tool.
 type FindWhenIInput struct {
 	What string `json:"what" jsonschema:"Description of the activity to find" jsonschema_extras:"required=true"`
 }
 
+// FindWhenIOutput defines the output for find_when_i tool.
+type FindWhenIOutput struct {
+	Entries  []EntryData `json:"entries"`
+	Count    int         `json:"count"`
+	Strategy string      `json:"strategy,omitempty" jsonschema:"How the entries matched: phrase (exact text), all_words (every word, any order), or any_words (some words, best first)"`
+}
+
+// Match strategies find_when_i tries, strictest first.
+const (
+	matchPhrase   = "phrase"
+	matchAllWords = "all_words"
+	matchAnyWords = "any_words"
+)
+
+// findWhenILimit is how many entries find_when_i returns.
+const findWhenILimit = 10
+
+// TagSummaryInput defines input for tag_summary tool.
+type TagSummaryInput struct {
+	Days  int `json:"days,omitempty" jsonschema:"Window length in days, ending now (default 30)"`
+	Limit int `json:"limit,omitempty" jsonschema:"Maximum number of tags to return (default 20)"`
+}
+
+// TagSummaryData describes one tag's usage in tag_summary output.
+type TagSummaryData struct {
+	Tag      string      `json:"tag"`
+	Count    int         `json:"count" jsonschema:"Entries with this tag in the window"`
+	Previous int         `json:"previous" jsonschema:"Entries with this tag in the window before"`
+	Trend    string      `json:"trend" jsonschema:"up, down, flat, or new compared with the window before"`
+	Examples []EntryData `json:"examples,omitempty"`
+}
+
+// TagSummaryOutput defines the output for tag_summary tool.
+type TagSummaryOutput struct {
+	Since string           `json:"since"`
+	Until string           `json:"until"`
+	Tags  []TagSummaryData `json:"tags"`
+}
+
+// LogDecisionInput defines input for log_decision tool.
+type LogDecisionInput struct {
+	Decision     string   `json:"decision" jsonschema:"What was decided" jsonschema_extras:"required=true"`
+	Alternatives []string `json:"alternatives,omitempty" jsonschema:"Options that were considered and not chosen"`
+	Rationale    string   `json:"rationale,omitempty" jsonschema:"Why this option was chosen"`
+	Consequences string   `json:"consequences,omitempty" jsonschema:"Expected effects and trade-offs"`
+	Tags         []string `json:"tags,omitempty" jsonschema:"Optional tags to categorize the decision"`
+}
+
+// Metadata keys set on log_decision entries.
+const (
+	decisionAlternatives = "alternatives"
+	decisionRationale    = "rationale"
+	decisionConsequences = "consequences"
+)
+
+// ActivityStatsInput defines input for activity_stats tool.
+type ActivityStatsInput struct {
+	Period string   `json:"period,omitempty" jsonschema:"Time range (today, yesterday, this week, last week, this month, last month, last N hours),default=this month"`
+	Text   string   `json:"text,omitempty" jsonschema:"Only count entries containing this text"`
+	Tags   []string `json:"tags,omitempty" jsonschema:"Only count entries with any of these tags"`
+	Type   string   `json:"type,omitempty" jsonschema:"Only count entries of this type (note, decision, todo, milestone)"`
+}
+
+// HourCount is how many entries were written in one hour of the day.
+type HourCount struct {
+	Hour  int `json:"hour" jsonschema:"Hour of the day, 0-23, local time"`
+	Count int `json:"count"`
+}
+
+// ActivityStatsOutput defines the output for activity_stats tool.
+type ActivityStatsOutput struct {
+	Period        string         `json:"period"`
+	Since         string         `json:"since"`
+	Until         string         `json:"until"`
+	Total         int            `json:"total"`
+	ActiveDays    int            `json:"active_days"`
+	LongestStreak int            `json:"longest_streak" jsonschema:"Most consecutive days with an entry"`
+	EntriesPerDay map[string]int `json:"entries_per_day"`
+	BusiestHours  []HourCount    `json:"busiest_hours" jsonschema:"Hours with the most entries, busiest first"`
+	TopTags       []charm.Count  `json:"top_tags"`
+	Sources       map[string]int `json:"sources"`
+}
+
+// activityStatsTopN bounds the tag and hour lists in activity_stats.
+const activityStatsTopN = 10
+
+// SummarizePeriodInput defines input for summarize_period tool.
+type SummarizePeriodInput struct {
+	Period string `json:"period,omitempty" jsonschema:"Period to summarize (this week, last week, this month, last month),default=this week"`
+}
+
+// PeriodDay groups one day's entries in summarize_period output.
+type PeriodDay struct {
+	Date    string        `json:"date"`
+	Count   int           `json:"count"`
+	Tags    []charm.Count `json:"tags,omitempty" jsonschema:"Tags used that day, most used first"`
+	Entries []EntryData   `json:"entries" jsonschema:"The day's entries, oldest first"`
+}
+
+// SummarizePeriodOutput defines the output for summarize_period tool.
+type SummarizePeriodOutput struct {
+	Period     string         `json:"period"`
+	Since      string         `json:"since"`
+	Until      string         `json:"until"`
+	Total      int            `json:"total"`
+	ActiveDays int            `json:"active_days"`
+	Types      map[string]int `json:"types" jsonschema:"Entry count per type"`
+	Tags       []charm.Count  `json:"tags" jsonschema:"Tags used in the period, most used first"`
+	Days       []PeriodDay    `json:"days" jsonschema:"Days with entries, oldest first"`
+}
+
+// ExportMarkdownInput defines input for export_markdown tool.
+type ExportMarkdownInput struct {
+	Period string   `json:"period,omitempty" jsonschema:"Time range (today, yesterday, this week, last week, this month, last month, last N hours),default=this week"`
+	Since  string   `json:"since,omitempty" jsonschema:"Start date/time (e.g. '2025-01-01'); overrides period"`
+	Until  string   `json:"until,omitempty" jsonschema:"End date/time (default now); used with since"`
+	Tags   []string `json:"tags,omitempty" jsonschema:"Only include entries with any of these tags"`
+	Type   string   `json:"type,omitempty" jsonschema:"Only include entries of this type (note, decision, todo, milestone)"`
+}
+
+// ExportMarkdownOutput defines the output for export_markdown tool.
+type ExportMarkdownOutput struct {
+	Since    string `json:"since"`
+	Until    string `json:"until"`
+	Count    int    `json:"count"`
+	Markdown string `json:"markdown" jsonschema:"The entries as a Markdown document, oldest first"`
+}
+
+// tagSummaryExamples is how many recent entries tag_summary shows per tag.
+const tagSummaryExamples = 3
+
+// toEntryData converts a stored entry to its tool output form.
+func toEntryData(entry charm.Entry) EntryData {
+	return EntryData{
+		ID:        entry.ID,
+		Timestamp: entry.Timestamp.Format("2006-01-02 15:04:05"),
+		Message:   entry.Message,
+		Type:      entry.Kind(),
+		Tags:      entry.Tags,
+		Hostname:  entry.Hostname,
+		Username:  entry.Username,
+		Directory: entry.WorkingDirectory,
+		ProjectID: entry.ProjectID,
+		Source:    entry.Source,
+		Metadata:  entry.Metadata,
+	}
+}
+
+// validateEntryType returns an error listing the valid types if kind is unknown.
+// An empty kind is allowed and means "no type given".
+func validateEntryType(kind string) error {
+	if kind == "" || charm.ValidEntryType(kind) {
+		return nil
+	}
+	return invalidInput("invalid type %q (valid: %s)", kind, strings.Join(charm.EntryTypes, ", "))
+}
+
 // registerTools adds all MCP tools to the server.
 func (s *Server) registerTools() {
 	// add_entry tool
@@ -85,46 +312,165 @@ func (s *Server) registerTools() {
 		Name:        "add_entry",
 		Description: "Log a timestamped entry to chronicle. Use this proactively when you notice the user accomplished something significant (deployed code, fixed a bug, made a decision, solved a problem, completed a task) even if they don't explicitly ask you to log it. Also use when they explicitly request logging. Logging important moments helps them recall their work later.",
 	}
-	mcp.AddTool(s.mcpServer, addEntryTool, s.handleAddEntry)
+	addWriteTool(s, addEntryTool, s.handleAddEntry)
+
+	// edit_entry tool
+	editEntryTool := &mcp.Tool{
+		Name:        "edit_entry",
+		Description: "Correct or refine an existing chronicle entry by ID, replacing its message and/or tags. Use this when you learn more context after logging, e.g. 'actually that deploy was v2.1, not v2.0'. Fields left out are unchanged.",
+		Annotations: destructiveAnnotations(),
+	}
+	addWriteTool(s, editEntryTool, s.handleEditEntry)
+
+	// delete_entry tool
+	deleteEntryTool := &mcp.Tool{
+		Name:        "delete_entry",
+		Description: "Delete an entry by ID. Only do this when the user asks; the server may require their confirmation first.",
+		Annotations: destructiveAnnotations(),
+	}
+	addWriteTool(s, deleteEntryTool, s.handleDeleteEntry)
 
 	// list_entries tool
 	listEntriesTool := &mcp.Tool{
 		Name:        "list_entries",
 		Description: "Retrieve recent chronicle entries. Use this to answer questions like 'what did I do today/recently' or 'show my recent work'.",
 	}
-	mcp.AddTool(s.mcpServer, listEntriesTool, s.handleListEntries)
+	addTool(s, listEntriesTool, s.handleListEntries)
 
 	// search_entries tool
 	searchEntriesTool := &mcp.Tool{
 		Name:        "search_entries",
 		Description: "Search chronicle history by text, tags, or date range. Use this when the user wants to find specific past activities or recall when something happened.",
 	}
-	mcp.AddTool(s.mcpServer, searchEntriesTool, s.handleSearchEntries)
+	addTool(s, searchEntriesTool, s.handleSearchEntries)
+
+	// tag_summary tool
+	tagSummaryTool := &mcp.Tool{
+		Name:        "tag_summary",
+		Description: "Tag usage statistics over a recent window: how many entries each tag has, the trend versus the window before, and a few example entries. Use this to answer questions like 'what have I spent the most time on this month'.",
+	}
+	addTool(s, tagSummaryTool, s.handleTagSummary)
+
+	// log_decision tool
+	logDecisionTool := &mcp.Tool{
+		Name:        "log_decision",
+		Description: "Record a decision with the alternatives considered, the rationale, and the expected consequences. Use this whenever the user settles an architectural or design question, so decisions can be found and reviewed later.",
+	}
+	addWriteTool(s, logDecisionTool, s.handleLogDecision)
+
+	// start_session / end_session tools
+	startSessionTool := &mcp.Tool{
+		Name:        "start_session",
+		Description: "Start a focused work session on a topic. Call end_session when the work is done; the session's duration is recorded so the user can later ask how long they spent on something.",
+	}
+	addWriteTool(s, startSessionTool, s.handleStartSession)
+
+	endSessionTool := &mcp.Tool{
+		Name:        "end_session",
+		Description: "End a work session started with start_session (the most recent open one by default), recording its duration and an optional summary of what got done.",
+	}
+	addWriteTool(s, endSessionTool, s.handleEndSession)
+
+	// activity_stats tool
+	activityStatsTool := &mcp.Tool{
+		Name:        "activity_stats",
+		Description: "Counts over a time range: total entries, entries per day, streaks, busiest hours, and top tags, optionally limited by text, tags, or type. Use this for quantitative questions like 'how many deploys this month?' instead of fetching every entry.",
+	}
+	addTool(s, activityStatsTool, s.handleActivityStats)
+
+	// summarize_period tool
+	summarizePeriodTool := &mcp.Tool{
+		Name:        "summarize_period",
+		Description: "All entries for a week or month, grouped by day with tag and type counts. Use this to write standups, retros, or weekly reports instead of paging through search results.",
+	}
+	addTool(s, summarizePeriodTool, s.handleSummarizePeriod)
+
+	// export_markdown tool
+	exportMarkdownTool := &mcp.Tool{
+		Name:        "export_markdown",
+		Description: "Render the entries from a time range, optionally limited by tags or type, as a Markdown work log in the same format as project logs. Use this to drop a ready-made log into a PR description, status email, or weekly report.",
+	}
+	addTool(s, exportMarkdownTool, s.handleExportMarkdown)
 
 	// remember_this tool
 	rememberThisTool := &mcp.Tool{
 		Name:        "remember_this",
 		Description: "Proactively log important information the user shares about their work, decisions, or progress. Use this when you notice the user accomplished something worth tracking, even if they don't explicitly ask to log it. Automatically suggests relevant tags based on context.",
 	}
-	mcp.AddTool(s.mcpServer, rememberThisTool, s.handleRememberThis)
+	addWriteTool(s, rememberThisTool, s.handleRememberThis)
 
 	// what_was_i_doing tool
 	whatWasIDoingTool := &mcp.Tool{
 		Name:        "what_was_i_doing",
 		Description: "Recall the user's recent activities and context. Use this at the start of conversations to understand what they've been working on, or when they ask 'what was I doing' or 'where did I leave off'.",
 	}
-	mcp.AddTool(s.mcpServer, whatWasIDoingTool, s.handleWhatWasIDoing)
+	addTool(s, whatWasIDoingTool, s.handleWhatWasIDoing)
+
+	// what_changed_since tool
+	whatChangedSinceTool := &mcp.Tool{
+		Name:        "what_changed_since",
+		Description: "Only the entries created after a timestamp, with a compact summary. Use this when resuming a conversation: pass the time the last one ended to catch up without re-reading everything.",
+	}
+	addTool(s, whatChangedSinceTool, s.handleWhatChangedSince)
+
+	// related_entries tool
+	relatedEntriesTool := &mcp.Tool{
+		Name:        "related_entries",
+		Description: "Find past entries most similar to an entry or a piece of text, ranked by shared distinctive words. Use this when the user hits a problem to surface 'you saw a similar bug in March, here's what you did.'",
+	}
+	addTool(s, relatedEntriesTool, s.handleRelatedEntries)
 
 	// find_when_i tool
 	findWhenITool := &mcp.Tool{
 		Name:        "find_when_i",
-		Description: "Find when the user did something specific. Use this to answer questions like 'when did I deploy X' or 'when did I fix that bug'.",
+		Description: "Find when the user did something specific. Use this to answer questions like 'when did I deploy X' or 'when did I fix that bug'. Tries the exact wording first, then looser word matches; the result says which matched.",
 	}
-	mcp.AddTool(s.mcpServer, findWhenITool, s.handleFindWhenI)
+	addTool(s, findWhenITool, s.handleFindWhenI)
 }
 
 // handleAddEntry implements the add_entry tool.
 func (s *Server) handleAddEntry(ctx context.Context, req *mcp.CallToolRequest, input AddEntryInput) (*mcp.CallToolResult, AddEntryOutput, error) {
+	if err := validateEntryType(input.Type); err != nil {
+		return nil, AddEntryOutput{}, err
+	}
+	dir, err := s.entryDir(ctx, req, input.WorkingDirectory)
+	if err != nil {
+		return nil, AddEntryOutput{}, err
+	}
+	entry := s.newEntry(dir, input.Message, input.Tags, input.Type)
+	s.autoTag(ctx, &entry, dir)
+	return s.createEntry(entry)
+}
+
+// autoTag merges the tags of tag rules matching entry's message and the
+// auto_tag model's suggestions into entry, when auto_tag is configured.
+// The model only sees the message with secrets redacted, and a model
+// that fails or times out costs only its suggestions.
+func (s *Server) autoTag(ctx context.Context, entry *charm.Entry, dir string) {
+	if s.autoTagger == nil {
+		return
+	}
+	text, _, err := s.redactor.Redact(entry.Message)
+	if err != nil {
+		// addEntry refuses the message and says why
+		return
+	}
+	var ruleTags []string
+	if root, err := s.projectRootFor(dir); err == nil {
+		if rules, err := s.tagRules(root); err == nil {
+			ruleTags = matchingTags(rules, text)
+		}
+	}
+	suggestion, err := s.autoTagger.Suggest(ctx, text)
+	if err != nil && s.logger != nil {
+		s.logger.WarnContext(ctx, "auto-tagging skipped", "error", err)
+	}
+	entry.Tags, entry.Type = autotag.Apply(entry.Tags, entry.Type, ruleTags, suggestion)
+}
+
+// newEntry builds an MCP entry stamped with this machine's host and user,
+// workingDir (see entryDir), and the detected project, if any.
+func (s *Server) newEntry(workingDir, message string, tags []string, kind string) charm.Entry {
 	// Get metadata
 	hostname, _ := os.Hostname()
 	if hostname == "" {
@@ -136,21 +482,38 @@ func (s *Server) handleAddEntry(ctx context.Context, req *mcp.CallToolRequest, i
 		username = "unknown"
 	}
 
-	workingDir, _ := os.Getwd()
-	if workingDir == "" {
-		workingDir = "unknown"
-	}
-
 	// Create entry
 	entry := charm.Entry{
-		Message:          input.Message,
+		Message:          message,
 		Hostname:         hostname,
 		Username:         username,
 		WorkingDirectory: workingDir,
-		Tags:             input.Tags,
+		Tags:             tags,
+		Type:             kind,
+		Source:           charm.SourceMCP,
+	}
+	entry.WorkingDirectory, entry.Hostname = config.ApplyPrivacy(s.privacyRules, workingDir, hostname)
+
+	// Associate the entry with the detected project, if any
+	if projectRoot, err := s.findProjectRoot(workingDir); err == nil && projectRoot != "" {
+		if projectCfg, err := config.LoadProjectConfig(filepath.Join(projectRoot, ".chronicle")); err == nil {
+			entry.Tags = projectCfg.ApplyTags(entry.Tags)
+		}
+		// A project record stores its root, so private directories get none
+		if entry.WorkingDirectory == workingDir {
+			if project, err := s.client.EnsureProject(projectRoot); err == nil {
+				entry.ProjectID = project.ID
+				entry.Tags = charm.MergeTags(entry.Tags, project.DefaultTags)
+			}
+		}
 	}
+	entry.Tags = charm.MergeTags(entry.Tags, s.defaultTags)
+	return entry
+}
 
-	id, err := s.client.CreateEntry(entry)
+// createEntry stores entry and reports it as add_entry output.
+func (s *Server) createEntry(entry charm.Entry) (*mcp.CallToolResult, AddEntryOutput, error) {
+	id, merged, err := s.addEntry(entry)
 	if err != nil {
 		return nil, AddEntryOutput{}, fmt.Errorf("failed to create entry: %w", err)
 	}
@@ -164,55 +527,188 @@ func (s *Server) handleAddEntry(ctx context.Context, req *mcp.CallToolRequest, i
 
 	output := AddEntryOutput{
 		EntryID:   id,
-		Message:   input.Message,
+		Message:   entry.Message,
 		Timestamp: timestamp,
+		Merged:    merged,
+	}
+
+	text := fmt.Sprintf("Entry created successfully (ID: %s) at %s", id, timestamp)
+	if merged {
+		text = fmt.Sprintf("Added to the previous entry (ID: %s, created %s)", id, timestamp)
+	}
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+
+	return result, output, nil
+}
+
+// handleLogDecision implements the log_decision tool.
+func (s *Server) handleLogDecision(ctx context.Context, req *mcp.CallToolRequest, input LogDecisionInput) (*mcp.CallToolResult, AddEntryOutput, error) {
+	decision := strings.TrimSpace(input.Decision)
+	if decision == "" {
+		return nil, AddEntryOutput{}, invalidInput("decision is required")
+	}
+
+	dir, err := s.entryDir(ctx, req, "")
+	if err != nil {
+		return nil, AddEntryOutput{}, err
+	}
+	entry := s.newEntry(dir, decision, input.Tags, charm.EntryTypeDecision)
+	entry.Metadata = decisionMetadata(input)
+	return s.createEntry(entry)
+}
+
+// decisionMetadata collects the non-empty log_decision fields.
+// Alternatives are stored one per line.
+func decisionMetadata(input LogDecisionInput) map[string]string {
+	metadata := make(map[string]string)
+	var alternatives []string
+	for _, alt := range input.Alternatives {
+		if alt = strings.TrimSpace(alt); alt != "" {
+			alternatives = append(alternatives, alt)
+		}
+	}
+	if len(alternatives) > 0 {
+		metadata[decisionAlternatives] = strings.Join(alternatives, "\n")
+	}
+	if rationale := strings.TrimSpace(input.Rationale); rationale != "" {
+		metadata[decisionRationale] = rationale
+	}
+	if consequences := strings.TrimSpace(input.Consequences); consequences != "" {
+		metadata[decisionConsequences] = consequences
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+// handleEditEntry implements the edit_entry tool.
+func (s *Server) handleEditEntry(ctx context.Context, req *mcp.CallToolRequest, input EditEntryInput) (*mcp.CallToolResult, EntryData, error) {
+	if strings.TrimSpace(input.ID) == "" {
+		return nil, EntryData{}, invalidInput("id is required")
+	}
+	if input.Message == nil && input.Tags == nil {
+		return nil, EntryData{}, invalidInput("nothing to change: give a new message or tags")
+	}
+	if input.Message != nil && strings.TrimSpace(*input.Message) == "" {
+		return nil, EntryData{}, invalidInput("message cannot be empty")
+	}
+	var message string
+	if input.Message != nil {
+		var err error
+		if message, err = s.redactMessage(*input.Message); err != nil {
+			return nil, EntryData{}, err
+		}
+	}
+
+	entry, err := s.client.GetEntry(input.ID)
+	if err != nil {
+		return nil, EntryData{}, fmt.Errorf("failed to find entry %s: %w", input.ID, err)
+	}
+	if !s.inScope(entry) {
+		return nil, EntryData{}, notFound("entry %s is outside project %s", input.ID, s.projectRoot)
+	}
+	if err := s.confirm(ctx, req, describeEdit(entry, input), input.ConfirmToken); err != nil {
+		return nil, EntryData{}, err
+	}
+	if input.Message != nil {
+		entry.Message = message
+	}
+	if input.Tags != nil {
+		entry.Tags = input.Tags
+	}
+	if err := s.client.UpdateEntry(*entry); err != nil {
+		return nil, EntryData{}, fmt.Errorf("failed to update entry: %w", err)
+	}
+
+	// Re-read so the output shows tags as stored (normalized)
+	if updated, err := s.client.GetEntry(entry.ID); err == nil {
+		entry = updated
 	}
 
 	result := &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("Entry created successfully (ID: %s) at %s", id, timestamp),
+				Text: fmt.Sprintf("Entry %s updated", entry.ID),
 			},
 		},
 	}
 
-	return result, output, nil
+	return result, toEntryData(*entry), nil
+}
+
+// describeEdit says what edit_entry is about to change, for confirmation.
+func describeEdit(entry *charm.Entry, input EditEntryInput) string {
+	action := fmt.Sprintf("edit entry %s (%q)", entry.ID, entry.Message)
+	if input.Message != nil {
+		action += fmt.Sprintf(", setting its message to %q", *input.Message)
+	}
+	if input.Tags != nil {
+		action += fmt.Sprintf(", setting its tags to [%s]", strings.Join(input.Tags, ", "))
+	}
+	return action
+}
+
+// handleDeleteEntry implements the delete_entry tool.
+func (s *Server) handleDeleteEntry(ctx context.Context, req *mcp.CallToolRequest, input DeleteEntryInput) (*mcp.CallToolResult, DeleteEntryOutput, error) {
+	if strings.TrimSpace(input.ID) == "" {
+		return nil, DeleteEntryOutput{}, invalidInput("id is required")
+	}
+
+	entry, err := s.client.GetEntry(input.ID)
+	if err != nil {
+		return nil, DeleteEntryOutput{}, fmt.Errorf("failed to find entry %s: %w", input.ID, err)
+	}
+	if !s.inScope(entry) {
+		return nil, DeleteEntryOutput{}, notFound("entry %s is outside project %s", input.ID, s.projectRoot)
+	}
+	action := fmt.Sprintf("delete entry %s (%q)", entry.ID, entry.Message)
+	if err := s.confirm(ctx, req, action, input.ConfirmToken); err != nil {
+		return nil, DeleteEntryOutput{}, err
+	}
+	if err := s.client.DeleteEntry(entry.ID); err != nil {
+		return nil, DeleteEntryOutput{}, fmt.Errorf("failed to delete entry: %w", err)
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Entry %s deleted", entry.ID)},
+		},
+	}
+	return result, DeleteEntryOutput{ID: entry.ID, Message: entry.Message}, nil
 }
 
 // handleListEntries implements the list_entries tool.
 func (s *Server) handleListEntries(ctx context.Context, req *mcp.CallToolRequest, input ListEntriesInput) (*mcp.CallToolResult, ListEntriesOutput, error) {
 	limit := input.Limit
-	if limit == 0 {
+	if limit <= 0 {
 		limit = 10
 	}
 
-	entries, err := s.client.ListEntries(limit)
+	entries, next, err := s.searchPage(nil, limit, input.Cursor)
 	if err != nil {
 		return nil, ListEntriesOutput{}, fmt.Errorf("failed to list entries: %w", err)
 	}
 
 	outputEntries := make([]EntryData, len(entries))
 	for i, entry := range entries {
-		outputEntries[i] = EntryData{
-			ID:        entry.ID,
-			Timestamp: entry.Timestamp.Format("2006-01-02 15:04:05"),
-			Message:   entry.Message,
-			Tags:      entry.Tags,
-			Hostname:  entry.Hostname,
-			Username:  entry.Username,
-			Directory: entry.WorkingDirectory,
-		}
+		outputEntries[i] = toEntryData(entry)
 	}
 
 	output := ListEntriesOutput{
-		Entries: outputEntries,
-		Count:   len(outputEntries),
+		Entries:    outputEntries,
+		Count:      len(outputEntries),
+		NextCursor: next,
 	}
 
 	result := &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("Retrieved %d recent entries", len(outputEntries)),
+				Text: fmt.Sprintf("Retrieved %d recent entries%s", len(outputEntries), moreHint(next)),
 			},
 		},
 	}
@@ -222,43 +718,52 @@ func (s *Server) handleListEntries(ctx context.Context, req *mcp.CallToolRequest
 
 // handleSearchEntries implements the search_entries tool.
 func (s *Server) handleSearchEntries(ctx context.Context, req *mcp.CallToolRequest, input SearchEntriesInput) (*mcp.CallToolResult, ListEntriesOutput, error) {
+	if err := validateEntryType(input.Type); err != nil {
+		return nil, ListEntriesOutput{}, err
+	}
+
 	limit := input.Limit
-	if limit == 0 {
+	if limit <= 0 {
 		limit = 20
 	}
 
 	filter := &charm.SearchFilter{
 		Text: input.Text,
 		Tags: input.Tags,
+		Type: input.Type,
 	}
 
-	entries, err := s.client.SearchEntries(filter, limit)
+	if input.Project != "" {
+		project, err := s.client.FindProjectByName(input.Project)
+		if err != nil {
+			return nil, ListEntriesOutput{}, fmt.Errorf("failed to find project: %w", err)
+		}
+		if project == nil {
+			return nil, ListEntriesOutput{}, invalidInput("no project named %q", input.Project)
+		}
+		filter.ProjectID = project.ID
+	}
+
+	entries, next, err := s.searchPage(filter, limit, input.Cursor)
 	if err != nil {
 		return nil, ListEntriesOutput{}, fmt.Errorf("failed to search entries: %w", err)
 	}
 
 	outputEntries := make([]EntryData, len(entries))
 	for i, entry := range entries {
-		outputEntries[i] = EntryData{
-			ID:        entry.ID,
-			Timestamp: entry.Timestamp.Format("2006-01-02 15:04:05"),
-			Message:   entry.Message,
-			Tags:      entry.Tags,
-			Hostname:  entry.Hostname,
-			Username:  entry.Username,
-			Directory: entry.WorkingDirectory,
-		}
+		outputEntries[i] = toEntryData(entry)
 	}
 
 	output := ListEntriesOutput{
-		Entries: outputEntries,
-		Count:   len(outputEntries),
+		Entries:    outputEntries,
+		Count:      len(outputEntries),
+		NextCursor: next,
 	}
 
 	result := &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: fmt.Sprintf("Found %d matching entries", len(outputEntries)),
+				Text: fmt.Sprintf("Found %d matching entries%s", len(outputEntries), moreHint(next)),
 			},
 		},
 	}
@@ -266,36 +771,400 @@ func (s *Server) handleSearchEntries(ctx context.Context, req *mcp.CallToolReque
 	return result, output, nil
 }
 
-// suggestTags provides smart tag suggestions based on content.
-func suggestTags(activity, context string) []string {
-	var tags []string
+// handleTagSummary implements the tag_summary tool.
+func (s *Server) handleTagSummary(ctx context.Context, req *mcp.CallToolRequest, input TagSummaryInput) (*mcp.CallToolResult, TagSummaryOutput, error) {
+	days := input.Days
+	if days <= 0 {
+		days = 30
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	until := time.Now()
+	since := until.AddDate(0, 0, -days)
+	usage, err := s.client.TagUsageBetween(s.scoped(nil), since, until, tagSummaryExamples)
+	if err != nil {
+		return nil, TagSummaryOutput{}, fmt.Errorf("failed to summarize tags: %w", err)
+	}
+
+	output := TagSummaryOutput{
+		Since: since.Format("2006-01-02 15:04:05"),
+		Until: until.Format("2006-01-02 15:04:05"),
+		Tags:  []TagSummaryData{},
+	}
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Tag usage over the last %d days:\n", days))
+	for _, u := range usage {
+		if u.Count == 0 || len(output.Tags) == limit {
+			break
+		}
+		data := TagSummaryData{
+			Tag:      u.Tag,
+			Count:    u.Count,
+			Previous: u.Previous,
+			Trend:    tagTrend(u.Count, u.Previous),
+		}
+		for _, e := range u.Examples {
+			data.Examples = append(data.Examples, toEntryData(e))
+		}
+		output.Tags = append(output.Tags, data)
+		text.WriteString(fmt.Sprintf("- %s: %d (%s, %d before)\n", u.Tag, u.Count, data.Trend, u.Previous))
+	}
+	if len(output.Tags) == 0 {
+		text.WriteString("No tagged entries.\n")
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}
+	return result, output, nil
+}
+
+// tagTrend compares a tag's count with the previous window's.
+func tagTrend(count, previous int) string {
+	switch {
+	case previous == 0:
+		return "new"
+	case count > previous:
+		return "up"
+	case count < previous:
+		return "down"
+	default:
+		return "flat"
+	}
+}
+
+// handleActivityStats implements the activity_stats tool.
+func (s *Server) handleActivityStats(ctx context.Context, req *mcp.CallToolRequest, input ActivityStatsInput) (*mcp.CallToolResult, ActivityStatsOutput, error) {
+	if err := validateEntryType(input.Type); err != nil {
+		return nil, ActivityStatsOutput{}, err
+	}
+	period := input.Period
+	if strings.TrimSpace(period) == "" {
+		period = "this month"
+	}
+	tf, err := resolveRange(period, time.Now(), s.weekStart)
+	if err != nil {
+		return nil, ActivityStatsOutput{}, err
+	}
+
+	filter := &charm.SearchFilter{Text: input.Text, Tags: input.Tags, Type: input.Type, Since: &tf.Since, Until: &tf.Until}
+	stats, err := s.client.Stats(s.scoped(filter))
+	if err != nil {
+		return nil, ActivityStatsOutput{}, fmt.Errorf("failed to compute stats: %w", err)
+	}
+	output := activityStats(tf, stats)
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("%d entries on %d days from %s.", output.Total, output.ActiveDays, tf.Describe()))
+	if output.Total > 0 {
+		text.WriteString(fmt.Sprintf(" Longest streak: %d days. Busiest hour: %02d:00.", output.LongestStreak, output.BusiestHours[0].Hour))
+		if len(output.TopTags) > 0 {
+			tags := make([]string, len(output.TopTags))
+			for i, tag := range output.TopTags {
+				tags[i] = fmt.Sprintf("%s (%d)", tag.Value, tag.Count)
+			}
+			text.WriteString(" Top tags: " + strings.Join(tags, ", ") + ".")
+		}
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}
+	return result, output, nil
+}
+
+// activityStats converts aggregate stats to activity_stats output.
+func activityStats(tf timeframe, stats *charm.Stats) ActivityStatsOutput {
+	output := ActivityStatsOutput{
+		Period:        tf.Label,
+		Since:         tf.Since.Format("2006-01-02 15:04:05"),
+		Until:         tf.Until.Format("2006-01-02 15:04:05"),
+		Total:         stats.Total,
+		ActiveDays:    len(stats.EntriesPerDay),
+		LongestStreak: stats.LongestStreak(),
+		EntriesPerDay: stats.EntriesPerDay,
+		BusiestHours:  []HourCount{},
+		TopTags:       stats.TopTags(activityStatsTopN),
+		Sources:       stats.Sources,
+	}
+	for hour, count := range stats.HourHistogram {
+		if count > 0 {
+			output.BusiestHours = append(output.BusiestHours, HourCount{Hour: hour, Count: count})
+		}
+	}
+	sort.SliceStable(output.BusiestHours, func(i, j int) bool {
+		return output.BusiestHours[i].Count > output.BusiestHours[j].Count
+	})
+	if len(output.BusiestHours) > activityStatsTopN {
+		output.BusiestHours = output.BusiestHours[:activityStatsTopN]
+	}
+	return output
+}
+
+// handleSummarizePeriod implements the summarize_period tool.
+func (s *Server) handleSummarizePeriod(ctx context.Context, req *mcp.CallToolRequest, input SummarizePeriodInput) (*mcp.CallToolResult, SummarizePeriodOutput, error) {
+	tf, err := resolvePeriod(input.Period, time.Now(), s.weekStart)
+	if err != nil {
+		return nil, SummarizePeriodOutput{}, err
+	}
+
+	entries, err := s.client.SearchEntries(s.scoped(&charm.SearchFilter{Since: &tf.Since, Until: &tf.Until}), 0)
+	if err != nil {
+		return nil, SummarizePeriodOutput{}, fmt.Errorf("failed to search entries: %w", err)
+	}
+	output := summarizePeriod(tf, entries)
+
+	var text strings.Builder
+	if output.Total == 0 {
+		text.WriteString(fmt.Sprintf("No entries from %s.\n", tf.Describe()))
+	} else {
+		text.WriteString(fmt.Sprintf("%d entries on %d days from %s.\n", output.Total, output.ActiveDays, tf.Describe()))
+	}
+	for _, day := range output.Days {
+		text.WriteString(fmt.Sprintf("\n%s (%d):\n", day.Date, day.Count))
+		for _, entry := range day.Entries {
+			text.WriteString(fmt.Sprintf("- %s", entry.Message))
+			if len(entry.Tags) > 0 {
+				text.WriteString(fmt.Sprintf(" [%s]", strings.Join(entry.Tags, ", ")))
+			}
+			text.WriteString("\n")
+		}
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}
+	return result, output, nil
+}
+
+// summarizePeriod groups entries by local day, oldest first, and counts
+// tags and types overall and per day.
+func summarizePeriod(tf timeframe, entries []charm.Entry) SummarizePeriodOutput {
+	output := SummarizePeriodOutput{
+		Period: tf.Label,
+		Since:  tf.Since.Format("2006-01-02 15:04:05"),
+		Until:  tf.Until.Format("2006-01-02 15:04:05"),
+		Total:  len(entries),
+		Types:  make(map[string]int),
+		Tags:   []charm.Count{},
+		Days:   []PeriodDay{},
+	}
 
-	combined := activity + " " + context
-	combined = strings.ToLower(combined)
+	sorted := make([]charm.Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
 
-	// Work-related keywords
-	if strings.Contains(combined, "deploy") || strings.Contains(combined, "release") {
-		tags = append(tags, "deployment")
+	tags := make(map[string]int)
+	var dayTags map[string]int
+	for _, entry := range sorted {
+		output.Types[entry.Kind()]++
+		date := entry.Timestamp.Local().Format("2006-01-02")
+		if len(output.Days) == 0 || output.Days[len(output.Days)-1].Date != date {
+			if len(output.Days) > 0 {
+				output.Days[len(output.Days)-1].Tags = sortedCounts(dayTags)
+			}
+			output.Days = append(output.Days, PeriodDay{Date: date})
+			dayTags = make(map[string]int)
+		}
+		day := &output.Days[len(output.Days)-1]
+		day.Count++
+		day.Entries = append(day.Entries, toEntryData(entry))
+		for _, tag := range entry.Tags {
+			tags[tag]++
+			dayTags[tag]++
+		}
 	}
-	if strings.Contains(combined, "fix") || strings.Contains(combined, "bug") {
-		tags = append(tags, "bug-fix")
+	if len(output.Days) > 0 {
+		output.Days[len(output.Days)-1].Tags = sortedCounts(dayTags)
 	}
-	if strings.Contains(combined, "decid") || strings.Contains(combined, "chose") {
-		tags = append(tags, "decision")
+	output.ActiveDays = len(output.Days)
+	output.Tags = append(output.Tags, sortedCounts(tags)...)
+	return output
+}
+
+// handleExportMarkdown implements the export_markdown tool.
+func (s *Server) handleExportMarkdown(ctx context.Context, req *mcp.CallToolRequest, input ExportMarkdownInput) (*mcp.CallToolResult, ExportMarkdownOutput, error) {
+	if err := validateEntryType(input.Type); err != nil {
+		return nil, ExportMarkdownOutput{}, err
 	}
-	if strings.Contains(combined, "learn") || strings.Contains(combined, "discover") {
-		tags = append(tags, "learning")
+	tf, err := exportRange(input, time.Now(), s.weekStart)
+	if err != nil {
+		return nil, ExportMarkdownOutput{}, err
+	}
+
+	filter := &charm.SearchFilter{Tags: input.Tags, Type: input.Type, Since: &tf.Since, Until: &tf.Until}
+	entries, err := s.client.SearchEntries(s.scoped(filter), 0)
+	if err != nil {
+		return nil, ExportMarkdownOutput{}, fmt.Errorf("failed to search entries: %w", err)
+	}
+
+	output := ExportMarkdownOutput{
+		Since:    tf.Since.Format("2006-01-02 15:04:05"),
+		Until:    tf.Until.Format("2006-01-02 15:04:05"),
+		Count:    len(entries),
+		Markdown: exportMarkdown(entries, s.dateLayout),
 	}
-	if strings.Contains(combined, "test") {
-		tags = append(tags, "testing")
+	text := output.Markdown
+	if output.Count == 0 {
+		text = fmt.Sprintf("No entries from %s.", tf.Describe())
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+	return result, output, nil
+}
+
+// exportRange resolves export_markdown's window: since/until when given,
+// else the period phrase, defaulting to this week.
+func exportRange(input ExportMarkdownInput, now time.Time, weekStart time.Weekday) (timeframe, error) {
+	if input.Since == "" && input.Until == "" {
+		period := input.Period
+		if strings.TrimSpace(period) == "" {
+			period = "this week"
+		}
+		return resolveRange(period, now, weekStart)
+	}
+	if input.Since == "" {
+		return timeframe{}, invalidInput("until needs since as well")
+	}
+
+	since, err := dateparse.ParseLocal(input.Since)
+	if err != nil {
+		return timeframe{}, invalidInput("invalid since date %q", input.Since)
+	}
+	until := now
+	if input.Until != "" {
+		if until, err = dateparse.ParseLocal(input.Until); err != nil {
+			return timeframe{}, invalidInput("invalid until date %q", input.Until)
+		}
+	}
+	if until.Before(since) {
+		return timeframe{}, invalidInput("until is before since")
+	}
+	return timeframe{Label: "custom range", Since: since, Until: until}, nil
+}
+
+// exportMarkdown renders entries oldest first under one heading per local
+// day, dated with dateLayout, each entry formatted as in a markdown project log.
+func exportMarkdown(entries []charm.Entry, dateLayout string) string {
+	sorted := make([]charm.Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	var sb strings.Builder
+	day := ""
+	for _, entry := range sorted {
+		local := entry.Timestamp.Local()
+		if date := local.Format(dateLayout); date != day {
+			day = date
+			sb.WriteString(fmt.Sprintf("# %s (%s)\n\n", date, local.Weekday()))
+		}
+		sb.WriteString(logging.FormatMarkdown(logging.Entry{
+			ID:               entry.ID,
+			Timestamp:        local,
+			Message:          entry.Message,
+			Type:             entry.Kind(),
+			Hostname:         entry.Hostname,
+			Username:         entry.Username,
+			WorkingDirectory: entry.WorkingDirectory,
+			Tags:             entry.Tags,
+		}))
+	}
+	return sb.String()
+}
+
+// sortedCounts orders counts by count descending, then value.
+func sortedCounts(m map[string]int) []charm.Count {
+	counts := make([]charm.Count, 0, len(m))
+	for value, count := range m {
+		counts = append(counts, charm.Count{Value: value, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+	return counts
+}
+
+// Word lists for suggestType. Matching is on whole words so that
+// "undecided" or "release notes" do not trigger a type.
+var (
+	milestoneWords = wordSet("deployed", "released", "launched", "shipped")
+	milestoneVerbs = wordSet("deploy", "release", "launch", "ship")
+	decisionWords  = wordSet("decided", "decision", "chose", "chosen")
+	todoWords      = wordSet("todo", "todos")
+	todoPhrases    = [][2]string{{"need", "to"}, {"needs", "to"}, {"follow", "up"}}
+)
+
+// wordSet builds a lookup set from a list of words.
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
 	}
+	return set
+}
+
+// suggestType infers an entry type from the activity description.
+// Milestones win over decisions ("decided to ship" is a milestone), and
+// decisions win over todos.
+func suggestType(activity string) string {
+	words := strings.FieldsFunc(strings.ToLower(activity), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
 
-	// Default tag
-	if len(tags) == 0 {
-		tags = append(tags, "work")
+	var milestone, decision, todo bool
+	for i, w := range words {
+		if milestoneWords[w] {
+			milestone = true
+		}
+		if decisionWords[w] {
+			decision = true
+			// "decided to ship" records the shipping, not just the choice
+			if i+2 < len(words) && words[i+1] == "to" && milestoneVerbs[words[i+2]] {
+				milestone = true
+			}
+		}
+		if todoWords[w] {
+			todo = true
+		}
+		if i+1 < len(words) {
+			for _, phrase := range todoPhrases {
+				if w == phrase[0] && words[i+1] == phrase[1] {
+					todo = true
+				}
+			}
+		}
 	}
 
-	return tags
+	switch {
+	case milestone:
+		return charm.EntryTypeMilestone
+	case decision:
+		return charm.EntryTypeDecision
+	case todo:
+		return charm.EntryTypeTodo
+	default:
+		return charm.EntryTypeNote
+	}
 }
 
 // handleRememberThis implements the remember_this tool.
@@ -306,36 +1175,96 @@ func (s *Server) handleRememberThis(ctx context.Context, req *mcp.CallToolReques
 		message = message + " (" + input.Context + ")"
 	}
 
-	// Smart tag suggestions based on keywords
-	tags := suggestTags(input.Activity, input.Context)
+	if !input.AllowDuplicate && s.duplicateWindow > 0 {
+		since := time.Now().Add(-s.duplicateWindow)
+		recent, err := s.client.SearchEntries(s.scoped(&charm.SearchFilter{Since: &since}), 0)
+		if err != nil {
+			return nil, AddEntryOutput{}, fmt.Errorf("failed to check for duplicates: %w", err)
+		}
+		if existing := findDuplicate(recent, message); existing != nil {
+			return duplicateResult(existing)
+		}
+	}
 
-	// Delegate to add_entry
-	addInput := AddEntryInput{
-		Message: message,
-		Tags:    tags,
+	dir, err := s.entryDir(ctx, req, input.WorkingDirectory)
+	if err != nil {
+		return nil, AddEntryOutput{}, err
 	}
 
-	return s.handleAddEntry(ctx, req, addInput)
+	// Smart tag suggestions from the built-in, config, and project rules
+	root, err := s.projectRootFor(dir)
+	if err != nil {
+		return nil, AddEntryOutput{}, err
+	}
+	rules, err := s.tagRules(root)
+	if err != nil {
+		return nil, AddEntryOutput{}, err
+	}
+	tags := suggestTags(rules, input.Activity, input.Context)
+
+	return s.createEntry(s.newEntry(dir, message, tags, suggestType(input.Activity)))
 }
 
-// handleWhatWasIDoing implements the what_was_i_doing tool.
-func (s *Server) handleWhatWasIDoing(ctx context.Context, req *mcp.CallToolRequest, input WhatWasIDoingInput) (*mcp.CallToolResult, WhatWasIDoingOutput, error) {
-	// TODO: Parse input.Timeframe and set date filters
-	// For now, just search recent entries regardless of timeframe
-	_ = input.Timeframe // silence unused warning until timeframe parsing is implemented
+// normalizeMessage reduces a message to what duplicate detection compares:
+// lowercase words, ignoring spacing and surrounding punctuation.
+func normalizeMessage(message string) string {
+	return strings.Trim(strings.Join(strings.Fields(strings.ToLower(message)), " "), ".!?,;: ")
+}
 
-	searchInput := SearchEntriesInput{
-		Limit: 20,
+// findDuplicate returns the entry in recent whose message matches message
+// once normalized, or nil.
+func findDuplicate(recent []charm.Entry, message string) *charm.Entry {
+	want := normalizeMessage(message)
+	for i := range recent {
+		if normalizeMessage(recent[i].Message) == want {
+			return &recent[i]
+		}
+	}
+	return nil
+}
+
+// duplicateResult reports an existing entry in place of a new one.
+func duplicateResult(existing *charm.Entry) (*mcp.CallToolResult, AddEntryOutput, error) {
+	timestamp := existing.Timestamp.Format("2006-01-02 15:04:05")
+	output := AddEntryOutput{
+		EntryID:   existing.ID,
+		Message:   existing.Message,
+		Timestamp: timestamp,
+		Duplicate: true,
+	}
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Already logged (ID: %s) at %s; not adding it again. Pass allow_duplicate to log it anyway.", existing.ID, timestamp),
+			},
+		},
 	}
+	return result, output, nil
+}
 
-	result, listOutput, err := s.handleSearchEntries(ctx, req, searchInput)
+// handleWhatWasIDoing implements the what_was_i_doing tool.
+func (s *Server) handleWhatWasIDoing(ctx context.Context, req *mcp.CallToolRequest, input WhatWasIDoingInput) (*mcp.CallToolResult, WhatWasIDoingOutput, error) {
+	tf, err := resolveTimeframe(input.Timeframe, time.Now(), s.weekStart)
 	if err != nil {
 		return nil, WhatWasIDoingOutput{}, err
 	}
 
+	entries, err := s.client.SearchEntries(s.scoped(&charm.SearchFilter{Since: &tf.Since, Until: &tf.Until}), tf.Limit)
+	if err != nil {
+		return nil, WhatWasIDoingOutput{}, fmt.Errorf("failed to search entries: %w", err)
+	}
+	listOutput := ListEntriesOutput{Entries: make([]EntryData, len(entries)), Count: len(entries)}
+	for i, entry := range entries {
+		listOutput.Entries[i] = toEntryData(entry)
+	}
+
 	// Build narrative summary
 	var summary strings.Builder
-	summary.WriteString(fmt.Sprintf("Based on %d recent entries:\n\n", listOutput.Count))
+	if listOutput.Count == 0 {
+		summary.WriteString(fmt.Sprintf("No entries from %s.\n", tf.Describe()))
+	} else {
+		summary.WriteString(fmt.Sprintf("Based on %d entries from %s:\n\n", listOutput.Count, tf.Describe()))
+	}
 
 	for _, entry := range listOutput.Entries {
 		summary.WriteString(fmt.Sprintf("- %s: %s", entry.Timestamp, entry.Message))
@@ -349,17 +1278,128 @@ func (s *Server) handleWhatWasIDoing(ctx context.Context, req *mcp.CallToolReque
 		Summary: summary.String(),
 		Entries: listOutput.Entries,
 	}
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: summary.String()},
+		},
+	}
 
 	return result, output, nil
 }
 
-// handleFindWhenI implements the find_when_i tool.
-func (s *Server) handleFindWhenI(ctx context.Context, req *mcp.CallToolRequest, input FindWhenIInput) (*mcp.CallToolResult, ListEntriesOutput, error) {
-	// Search using the description
-	searchInput := SearchEntriesInput{
-		Text:  input.What,
-		Limit: 10,
+// handleFindWhenI implements the find_when_i tool. It tries the exact
+// phrase first, then entries with all of its words, then entries ranked by
+// how many of its distinctive words they share, and reports which matched.
+func (s *Server) handleFindWhenI(ctx context.Context, req *mcp.CallToolRequest, input FindWhenIInput) (*mcp.CallToolResult, FindWhenIOutput, error) {
+	what := strings.TrimSpace(input.What)
+	if what == "" {
+		return nil, FindWhenIOutput{}, invalidInput("what is required")
+	}
+
+	entries, err := s.client.SearchEntries(s.scoped(&charm.SearchFilter{Text: what}), findWhenILimit)
+	if err != nil {
+		return nil, FindWhenIOutput{}, fmt.Errorf("failed to search entries: %w", err)
+	}
+	strategy := matchPhrase
+	if len(entries) == 0 {
+		similar, err := s.client.SimilarEntries(s.scoped(nil), what, "", 0)
+		if err != nil {
+			return nil, FindWhenIOutput{}, fmt.Errorf("failed to search entries: %w", err)
+		}
+		entries, strategy = fuzzyMatches(similar, what, findWhenILimit)
 	}
 
-	return s.handleSearchEntries(ctx, req, searchInput)
+	output := FindWhenIOutput{Entries: []EntryData{}, Count: len(entries), Strategy: strategy}
+	for _, entry := range entries {
+		output.Entries = append(output.Entries, toEntryData(entry))
+	}
+
+	var text string
+	switch {
+	case len(entries) == 0:
+		output.Strategy = ""
+		text = fmt.Sprintf("No entries match %q, even loosely.", what)
+	case strategy == matchPhrase:
+		text = fmt.Sprintf("Found %d entries containing %q", len(entries), what)
+	case strategy == matchAllWords:
+		text = fmt.Sprintf("No entry has that exact wording; found %d with all of its words", len(entries))
+	default:
+		text = fmt.Sprintf("No entry has that exact wording or all of its words; found %d sharing some of them, best matches first", len(entries))
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+	return result, output, nil
+}
+
+// fuzzyMatches picks find_when_i's fallback results from similarity
+// matches, best first: those containing every word of what if there are
+// any, else all of them. It returns the entries and the strategy used.
+func fuzzyMatches(similar []charm.Similar, what string, limit int) ([]charm.Entry, string) {
+	var all, some []charm.Entry
+	for _, match := range similar {
+		if charm.ContainsAllTerms(&match.Entry, what) {
+			all = append(all, match.Entry)
+		}
+		some = append(some, match.Entry)
+	}
+	entries, strategy := some, matchAnyWords
+	if len(all) > 0 {
+		entries, strategy = all, matchAllWords
+	}
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, strategy
+}
+
+// handleRelatedEntries implements the related_entries tool.
+func (s *Server) handleRelatedEntries(ctx context.Context, req *mcp.CallToolRequest, input RelatedEntriesInput) (*mcp.CallToolResult, RelatedEntriesOutput, error) {
+	id := strings.TrimSpace(input.ID)
+	query := strings.TrimSpace(input.Text)
+	if id == "" && query == "" {
+		return nil, RelatedEntriesOutput{}, invalidInput("give an entry id or text to compare against")
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultRelatedLimit
+	}
+
+	if id != "" {
+		entry, err := s.client.GetEntry(id)
+		if err != nil {
+			return nil, RelatedEntriesOutput{}, fmt.Errorf("failed to find entry %s: %w", id, err)
+		}
+		if !s.inScope(entry) {
+			return nil, RelatedEntriesOutput{}, notFound("entry %s is outside project %s", id, s.projectRoot)
+		}
+		query = charm.SimilarityQuery(entry)
+	}
+
+	similar, err := s.client.SimilarEntries(s.scoped(nil), query, id, limit)
+	if err != nil {
+		return nil, RelatedEntriesOutput{}, fmt.Errorf("failed to find related entries: %w", err)
+	}
+
+	output := RelatedEntriesOutput{Entries: []RelatedEntry{}, Count: len(similar)}
+	var text strings.Builder
+	if len(similar) == 0 {
+		text.WriteString("No related entries found.")
+	} else {
+		text.WriteString(fmt.Sprintf("Found %d related entries:\n", len(similar)))
+	}
+	for _, match := range similar {
+		output.Entries = append(output.Entries, RelatedEntry{Entry: toEntryData(match.Entry), Score: match.Score})
+		text.WriteString(fmt.Sprintf("- %s: %s (ID: %s)\n", match.Entry.Timestamp.Format(s.dateLayout), match.Entry.Message, match.Entry.ID))
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}
+	return result, output, nil
 }
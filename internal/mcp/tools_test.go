@@ -3,7 +3,14 @@
 package mcp
 
 import (
+	"context"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func TestSuggestTags(t *testing.T) {
@@ -23,7 +30,7 @@ func TestSuggestTags(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.activity, func(t *testing.T) {
-			result := suggestTags(tt.activity, tt.context)
+			result := suggestTags(builtinTagRules(t), tt.activity, tt.context)
 
 			// Check that all expected tags are present
 			for _, exp := range tt.expected {
@@ -95,3 +102,405 @@ func TestEntryData(t *testing.T) {
 		t.Error("expected tags field")
 	}
 }
+
+func TestSuggestType(t *testing.T) {
+	tests := []struct {
+		activity string
+		expected string
+	}{
+		{"decided to use SQLite", "decision"},
+		{"made a decision on the schema", "decision"},
+		{"need to update the docs", "todo"},
+		{"TODO: follow up with ops", "todo"},
+		{"deployed v2.0 to production", "milestone"},
+		{"shipped the new sync engine", "milestone"},
+		{"decided to ship on Friday", "milestone"},
+		{"refactored the parser", "note"},
+		// Negative cases: substrings and nouns must not trigger a type
+		{"undecided about the cache layer", "note"},
+		{"team decides later", "note"},
+		{"fixed release notes typo", "note"},
+		{"deployment failed again", "note"},
+		{"reviewed the todolist app", "note"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.activity, func(t *testing.T) {
+			if got := suggestType(tt.activity); got != tt.expected {
+				t.Errorf("suggestType(%q) = %q, want %q", tt.activity, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateEntryType(t *testing.T) {
+	for _, kind := range []string{"", "note", "decision", "todo", "milestone"} {
+		if err := validateEntryType(kind); err != nil {
+			t.Errorf("validateEntryType(%q) returned error: %v", kind, err)
+		}
+	}
+
+	err := validateEntryType("idea")
+	if err == nil {
+		t.Fatal("expected error for unknown type")
+	}
+	if !strings.Contains(err.Error(), "note, decision, todo, milestone") {
+		t.Errorf("expected error to list valid types, got: %v", err)
+	}
+}
+
+// handlerCase is a tool call that must fail on its input alone.
+type handlerCase struct {
+	name string
+	call func(s *Server) error
+}
+
+// assertHandlersReject runs each case in its own subtest. Handlers check
+// their input before touching the client, so a bare server is enough.
+func assertHandlersReject(t *testing.T, cases []handlerCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(&Server{}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// handlerErr calls a tool handler with input and returns only its error.
+func handlerErr[In, Out any](handler func(context.Context, *mcp.CallToolRequest, In) (*mcp.CallToolResult, Out, error), input In) error {
+	_, _, err := handler(context.Background(), nil, input)
+	return err
+}
+
+func TestHandlersRejectInvalidType(t *testing.T) {
+	assertHandlersReject(t, []handlerCase{
+		{"add_entry", func(s *Server) error {
+			return handlerErr(s.handleAddEntry, AddEntryInput{Message: "x", Type: "idea"})
+		}},
+		{"search_entries", func(s *Server) error {
+			return handlerErr(s.handleSearchEntries, SearchEntriesInput{Type: "idea"})
+		}},
+	})
+}
+
+func TestNewEntryDefaultTags(t *testing.T) {
+	// Outside any project the client isn't needed
+	s := &Server{defaultTags: []string{"work-laptop", "mcp"}}
+	entry := s.newEntry(t.TempDir(), "x", []string{"mcp", "go"}, charm.EntryTypeNote)
+	want := []string{"mcp", "go", "work-laptop"}
+	if !reflect.DeepEqual(entry.Tags, want) {
+		t.Errorf("got %v, want %v", entry.Tags, want)
+	}
+}
+
+func TestResolveTimeframe(t *testing.T) {
+	// Wednesday afternoon
+	now := time.Date(2025, 11, 26, 15, 30, 0, 0, time.UTC)
+	day := func(d, h int) time.Time { return time.Date(2025, 11, d, h, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		phrase    string
+		since     time.Time
+		until     time.Time
+		limit     int
+		wantError bool
+	}{
+		{"", day(26, 0), now, 20, false},
+		{"Today", day(26, 0), now, 20, false},
+		{"yesterday", day(25, 0), day(26, 0).Add(-time.Nanosecond), 20, false},
+		{"this week", day(24, 0), now, 50, false},
+		{"last 3 hours", now.Add(-3 * time.Hour), now, 20, false},
+		{"past  48 hours", now.Add(-48 * time.Hour), now, 50, false},
+		{"last hour", now.Add(-time.Hour), now, 20, false},
+		{"last 0 hours", time.Time{}, time.Time{}, 0, true},
+		{"last month", time.Time{}, time.Time{}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			tf, err := resolveTimeframe(tt.phrase, now, time.Monday)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("resolveTimeframe(%q) error = %v, wantError %v", tt.phrase, err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if !tf.Since.Equal(tt.since) || !tf.Until.Equal(tt.until) || tf.Limit != tt.limit {
+				t.Errorf("resolveTimeframe(%q) = %v to %v limit %d, want %v to %v limit %d",
+					tt.phrase, tf.Since, tf.Until, tf.Limit, tt.since, tt.until, tt.limit)
+			}
+		})
+	}
+}
+
+func TestResolvePeriodWeekStart(t *testing.T) {
+	// Wednesday afternoon; with Sunday weeks, this week began on the 23rd
+	now := time.Date(2025, 11, 26, 15, 30, 0, 0, time.UTC)
+	sunday := time.Date(2025, 11, 23, 0, 0, 0, 0, time.UTC)
+
+	tf, err := resolvePeriod("this week", now, time.Sunday)
+	if err != nil || !tf.Since.Equal(sunday) {
+		t.Errorf("this week = %v, %v; want %v", tf.Since, err, sunday)
+	}
+	tf, err = resolvePeriod("last week", now, time.Sunday)
+	if err != nil || !tf.Since.Equal(sunday.AddDate(0, 0, -7)) || !tf.Until.Equal(sunday.Add(-time.Nanosecond)) {
+		t.Errorf("last week = %v to %v, %v", tf.Since, tf.Until, err)
+	}
+	tf, err = resolveTimeframe("this week", now, time.Sunday)
+	if err != nil || !tf.Since.Equal(sunday) {
+		t.Errorf("timeframe this week = %v, %v; want %v", tf.Since, err, sunday)
+	}
+}
+
+func TestEditEntryValidatesInput(t *testing.T) {
+	blank := "  "
+	assertHandlersReject(t, []handlerCase{
+		{"missing id", func(s *Server) error {
+			return handlerErr(s.handleEditEntry, EditEntryInput{Tags: []string{"x"}})
+		}},
+		{"nothing to change", func(s *Server) error {
+			return handlerErr(s.handleEditEntry, EditEntryInput{ID: "abc"})
+		}},
+		{"blank message", func(s *Server) error {
+			return handlerErr(s.handleEditEntry, EditEntryInput{ID: "abc", Message: &blank})
+		}},
+	})
+}
+
+func TestTagTrend(t *testing.T) {
+	tests := []struct {
+		count, previous int
+		want            string
+	}{
+		{3, 0, "new"},
+		{3, 1, "up"},
+		{1, 3, "down"},
+		{2, 2, "flat"},
+	}
+	for _, tt := range tests {
+		if got := tagTrend(tt.count, tt.previous); got != tt.want {
+			t.Errorf("tagTrend(%d, %d) = %q, want %q", tt.count, tt.previous, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePeriod(t *testing.T) {
+	// Wednesday afternoon
+	now := time.Date(2025, 11, 26, 15, 30, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		phrase    string
+		since     time.Time
+		until     time.Time
+		wantError bool
+	}{
+		{"", day(11, 24), now, false},
+		{"last week", day(11, 17), day(11, 24).Add(-time.Nanosecond), false},
+		{"This Month", day(11, 1), now, false},
+		{"last month", day(10, 1), day(11, 1).Add(-time.Nanosecond), false},
+		{"yesterday", time.Time{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			tf, err := resolvePeriod(tt.phrase, now, time.Monday)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("resolvePeriod(%q) error = %v, wantError %v", tt.phrase, err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if !tf.Since.Equal(tt.since) || !tf.Until.Equal(tt.until) {
+				t.Errorf("resolvePeriod(%q) = %v to %v, want %v to %v", tt.phrase, tf.Since, tf.Until, tt.since, tt.until)
+			}
+		})
+	}
+}
+
+func TestSummarizePeriod(t *testing.T) {
+	at := func(d, h int) time.Time { return time.Date(2025, 11, d, h, 0, 0, 0, time.Local) }
+	entries := []charm.Entry{
+		{ID: "3", Timestamp: at(25, 9), Message: "review", Tags: []string{"work"}},
+		{ID: "1", Timestamp: at(24, 10), Message: "standup", Tags: []string{"work", "meeting"}},
+		{ID: "2", Timestamp: at(24, 16), Message: "chose sqlite", Type: charm.EntryTypeDecision, Tags: []string{"work"}},
+	}
+
+	out := summarizePeriod(timeframe{Label: "this week", Since: at(24, 0), Until: at(26, 0)}, entries)
+	if out.Total != 3 || out.ActiveDays != 2 {
+		t.Fatalf("total %d on %d days, want 3 on 2", out.Total, out.ActiveDays)
+	}
+	if out.Days[0].Date != "2025-11-24" || out.Days[0].Count != 2 || out.Days[0].Entries[0].ID != "1" {
+		t.Errorf("first day = %+v, want 2025-11-24 with entry 1 first", out.Days[0])
+	}
+	if len(out.Tags) != 2 || out.Tags[0] != (charm.Count{Value: "work", Count: 3}) {
+		t.Errorf("tags = %v, want work:3 first", out.Tags)
+	}
+	if len(out.Days[1].Tags) != 1 || out.Days[1].Tags[0] != (charm.Count{Value: "work", Count: 1}) {
+		t.Errorf("second day tags = %v, want work:1", out.Days[1].Tags)
+	}
+	if out.Types[charm.EntryTypeDecision] != 1 || out.Types[charm.EntryTypeNote] != 2 {
+		t.Errorf("types = %v, want 1 decision and 2 notes", out.Types)
+	}
+}
+
+func TestLogDecisionRequiresDecision(t *testing.T) {
+	assertHandlersReject(t, []handlerCase{
+		{"blank decision", func(s *Server) error {
+			return handlerErr(s.handleLogDecision, LogDecisionInput{Decision: "  ", Rationale: "because"})
+		}},
+	})
+}
+
+func TestDecisionMetadata(t *testing.T) {
+	got := decisionMetadata(LogDecisionInput{
+		Decision:     "use sqlite",
+		Alternatives: []string{"postgres", " ", "bolt "},
+		Rationale:    " no server to run ",
+	})
+	if got[decisionAlternatives] != "postgres\nbolt" || got[decisionRationale] != "no server to run" {
+		t.Errorf("decisionMetadata = %q", got)
+	}
+	if _, ok := got[decisionConsequences]; ok {
+		t.Error("expected no consequences key when none was given")
+	}
+	if decisionMetadata(LogDecisionInput{Decision: "x"}) != nil {
+		t.Error("expected nil metadata when only the decision was given")
+	}
+}
+
+func TestFindDuplicate(t *testing.T) {
+	recent := []charm.Entry{
+		{ID: "1", Message: "Fixed the sync bug"},
+		{ID: "2", Message: "Deployed v2 (after review)"},
+	}
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"fixed  the sync bug.", "1"},
+		{"Deployed v2 (after review)", "2"},
+		{"Deployed v2", ""},
+		{"Fixed the sync bug again", ""},
+	}
+	for _, tt := range tests {
+		got := findDuplicate(recent, tt.message)
+		if (got == nil && tt.want != "") || (got != nil && got.ID != tt.want) {
+			t.Errorf("findDuplicate(%q) = %v, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestActivityStats(t *testing.T) {
+	stats := &charm.Stats{
+		Total:         4,
+		EntriesPerDay: map[string]int{"2025-11-24": 1, "2025-11-25": 2, "2025-11-27": 1},
+		Tags:          map[string]int{"deploy": 3, "sync": 1},
+		Sources:       map[string]int{"cli": 4},
+	}
+	stats.HourHistogram[9] = 1
+	stats.HourHistogram[14] = 3
+
+	out := activityStats(timeframe{Label: "this week"}, stats)
+	if out.Total != 4 || out.ActiveDays != 3 || out.LongestStreak != 2 {
+		t.Errorf("total %d, active days %d, streak %d; want 4, 3, 2", out.Total, out.ActiveDays, out.LongestStreak)
+	}
+	if len(out.BusiestHours) != 2 || out.BusiestHours[0] != (HourCount{Hour: 14, Count: 3}) {
+		t.Errorf("busiest hours = %v, want 14:00 first", out.BusiestHours)
+	}
+	if len(out.TopTags) != 2 || out.TopTags[0].Value != "deploy" {
+		t.Errorf("top tags = %v, want deploy first", out.TopTags)
+	}
+}
+
+func TestResolveRange(t *testing.T) {
+	now := time.Date(2025, 11, 26, 15, 30, 0, 0, time.UTC)
+	for _, phrase := range []string{"this month", "yesterday", "last 6 hours"} {
+		if _, err := resolveRange(phrase, now, time.Monday); err != nil {
+			t.Errorf("resolveRange(%q): %v", phrase, err)
+		}
+	}
+	if _, err := resolveRange("last fortnight", now, time.Monday); err == nil {
+		t.Error("expected an error for an unknown period")
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	at := func(d, h int) time.Time { return time.Date(2025, 11, d, h, 0, 0, 0, time.Local) }
+	entries := []charm.Entry{
+		{ID: "2", Timestamp: at(25, 9), Message: "shipped v2", Type: charm.EntryTypeMilestone, Username: "harper", Hostname: "box", WorkingDirectory: "/src"},
+		{ID: "1", Timestamp: at(24, 10), Message: "standup", Tags: []string{"work", "meeting"}, Username: "harper", Hostname: "box", WorkingDirectory: "/src"},
+	}
+
+	got := exportMarkdown(entries, time.DateOnly)
+	want := "# 2025-11-24 (Monday)\n\n" +
+		"## 10:00:00 - standup\n- **Tags**: work, meeting\n- **User**: harper@box\n- **Directory**: /src\n\n" +
+		"# 2025-11-25 (Tuesday)\n\n" +
+		"## 09:00:00 - shipped v2\n- **Type**: milestone\n- **User**: harper@box\n- **Directory**: /src\n\n"
+	if got != want {
+		t.Errorf("exportMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := exportMarkdown(entries[1:], "01/02/2006"); !strings.HasPrefix(got, "# 11/24/2025 (Monday)\n") {
+		t.Errorf("exportMarkdown() with us dates = %q", got)
+	}
+}
+
+func TestExportRange(t *testing.T) {
+	now := time.Date(2025, 11, 26, 12, 0, 0, 0, time.Local)
+
+	tf, err := exportRange(ExportMarkdownInput{}, now, time.Monday)
+	if err != nil || tf.Label != "this week" {
+		t.Errorf("default range = %q, %v; want this week", tf.Label, err)
+	}
+
+	tf, err = exportRange(ExportMarkdownInput{Period: "last week", Since: "2025-11-01"}, now, time.Monday)
+	if err != nil || !tf.Since.Equal(time.Date(2025, 11, 1, 0, 0, 0, 0, time.Local)) || !tf.Until.Equal(now) {
+		t.Errorf("since range = %v to %v, %v; want Nov 1 to now", tf.Since, tf.Until, err)
+	}
+
+	for _, input := range []ExportMarkdownInput{
+		{Until: "2025-11-01"},
+		{Since: "not a date"},
+		{Since: "2025-11-10", Until: "2025-11-01"},
+		{Period: "someday"},
+	} {
+		if _, err := exportRange(input, now, time.Monday); err == nil {
+			t.Errorf("exportRange(%+v) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestRelatedEntriesRequiresInput(t *testing.T) {
+	assertHandlersReject(t, []handlerCase{
+		{"no id or text", func(s *Server) error {
+			return handlerErr(s.handleRelatedEntries, RelatedEntriesInput{Text: "  "})
+		}},
+	})
+}
+
+func TestFuzzyMatches(t *testing.T) {
+	similar := []charm.Similar{
+		{Entry: charm.Entry{ID: "1", Message: "rotated certificates on staging"}, Score: 3},
+		{Entry: charm.Entry{ID: "2", Message: "staging certificates renewed"}, Score: 2},
+		{Entry: charm.Entry{ID: "3", Message: "staging deploy"}, Score: 1},
+	}
+
+	entries, strategy := fuzzyMatches(similar, "renewed staging certificates", 10)
+	if strategy != matchAllWords || len(entries) != 1 || entries[0].ID != "2" {
+		t.Errorf("got %v via %s, want entry 2 via all_words", entries, strategy)
+	}
+
+	entries, strategy = fuzzyMatches(similar, "staging outage", 2)
+	if strategy != matchAnyWords || len(entries) != 2 || entries[0].ID != "1" {
+		t.Errorf("got %v via %s, want the 2 best via any_words", entries, strategy)
+	}
+}
+
+func TestFindWhenIRequiresWhat(t *testing.T) {
+	assertHandlersReject(t, []handlerCase{
+		{"blank description", func(s *Server) error {
+			return handlerErr(s.handleFindWhenI, FindWhenIInput{What: " "})
+		}},
+	})
+}
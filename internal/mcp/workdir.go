@@ -0,0 +1,96 @@
+// ABOUTME: Working directory recorded on entries created through MCP
+// ABOUTME: Prefers the assistant's hint or the client's roots over the server process's cwd
+package mcp
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/harper/chronicle/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// rootsTimeout bounds how long a write waits for the client's roots, so a
+// client that ignores the request doesn't stall it.
+const rootsTimeout = time.Second
+
+// entryDir picks the working directory for a new entry. An explicit hint
+// from the assistant wins. Otherwise the server's own directory is used if
+// it is inside one of the client's roots, else the first root, else the
+// server's directory anyway. A project-scoped server keeps entries in its
+// project.
+func (s *Server) entryDir(ctx context.Context, req *mcp.CallToolRequest, hint string) (string, error) {
+	if hint != "" {
+		dir := config.ExpandHome(hint)
+		if !filepath.IsAbs(dir) {
+			return "", invalidInput("working_directory must be an absolute path, got %q", hint)
+		}
+		dir = filepath.Clean(dir)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", invalidInput("working_directory %s is not a directory", dir)
+		}
+		if s.projectRoot != "" && !config.DirWithin(config.CanonicalDir(dir, false), s.projectRoot) {
+			return "", invalidInput("working_directory %s is outside project %s", dir, s.projectRoot)
+		}
+		return dir, nil
+	}
+
+	cwd, _ := os.Getwd()
+	dir := cwd
+	if roots := s.clientRoots(ctx, req); len(roots) > 0 && !withinAny(cwd, roots) {
+		dir = roots[0]
+	}
+	if dir == "" {
+		dir = "unknown"
+	}
+	// A project-scoped server only writes entries it can see again
+	if s.projectRoot != "" && !config.DirWithin(config.CanonicalDir(dir, false), s.projectRoot) {
+		dir = s.projectRoot
+	}
+	return dir, nil
+}
+
+// clientRoots returns the local directories the client says it is working
+// in, or nil if it has none or doesn't support roots.
+func (s *Server) clientRoots(ctx context.Context, req *mcp.CallToolRequest) []string {
+	if req == nil || req.Session == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, rootsTimeout)
+	defer cancel()
+	res, err := req.Session.ListRoots(ctx, nil)
+	if err != nil || res == nil {
+		return nil
+	}
+	return rootDirs(res.Roots)
+}
+
+// rootDirs converts file:// roots to directory paths, skipping any other
+// kind of root.
+func rootDirs(roots []*mcp.Root) []string {
+	var dirs []string
+	for _, root := range roots {
+		u, err := url.Parse(root.URI)
+		if err != nil || u.Scheme != "file" || u.Path == "" {
+			continue
+		}
+		dirs = append(dirs, filepath.Clean(u.Path))
+	}
+	return dirs
+}
+
+// withinAny reports whether dir is one of roots or below one.
+func withinAny(dir string, roots []string) bool {
+	if dir == "" {
+		return false
+	}
+	for _, root := range roots {
+		if config.DirWithin(dir, root) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+// ABOUTME: Tests for the working directory recorded on MCP entries
+// ABOUTME: Covers explicit hints, client roots, and project scoping
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestEntryDirHint(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := &Server{}
+
+	if got, err := s.entryDir(ctx, nil, dir); err != nil || got != dir {
+		t.Errorf("entryDir(%s) = %q, %v", dir, got, err)
+	}
+	for _, hint := range []string{"relative/path", filepath.Join(dir, "missing")} {
+		if _, err := s.entryDir(ctx, nil, hint); err == nil {
+			t.Errorf("entryDir(%q) succeeded, want an error", hint)
+		}
+	}
+
+	s.projectRoot = filepath.Join(dir, "project")
+	if err := os.Mkdir(s.projectRoot, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.entryDir(ctx, nil, dir); err == nil {
+		t.Error("expected an error for a hint outside the project")
+	}
+	// Without a hint, a scoped server falls back to its project
+	if got, err := s.entryDir(ctx, nil, ""); err != nil || got != s.projectRoot {
+		t.Errorf("entryDir() = %q, %v; want the project root", got, err)
+	}
+}
+
+func TestEntryDirUsesClientRoots(t *testing.T) {
+	root := t.TempDir()
+	s := &Server{mcpServer: mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)}
+	type out struct {
+		Dir string `json:"dir"`
+	}
+	mcp.AddTool(s.mcpServer, &mcp.Tool{Name: "where"}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, out, error) {
+		dir, err := s.entryDir(ctx, req, "")
+		return nil, out{Dir: dir}, err
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := s.mcpServer.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	client.AddRoots(&mcp.Root{URI: "https://example.com/ignored"}, &mcp.Root{URI: "file://" + root})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "where", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	got, _ := res.StructuredContent.(map[string]any)["dir"].(string)
+	if got != root {
+		t.Errorf("entry dir = %q, want the client root %q", got, root)
+	}
+}
@@ -0,0 +1,170 @@
+// ABOUTME: Prometheus metrics for chronicle serve and the sync daemon
+// ABOUTME: Collects journal and sync gauges and writes the text exposition format
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+// Metric types in the exposition format.
+const (
+	TypeCounter = "counter"
+	TypeGauge   = "gauge"
+)
+
+// unknownSource labels entries recorded without a source.
+const unknownSource = "unknown"
+
+// Metric is one metric family: a name, its help text and type, and a
+// sample per label set.
+type Metric struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Sample is one value of a metric, with its labels.
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Collector gathers the metrics for one journal.
+type Collector struct {
+	Client *charm.Client
+	// DBPath is the journal's database file, for its size
+	DBPath string
+	// Extra, if set, adds the caller's own metrics, such as the sync
+	// daemon's sync counts
+	Extra func() []Metric
+}
+
+// Collect reads the journal's current metrics.
+func (c *Collector) Collect() ([]Metric, error) {
+	stats, err := c.Client.Stats(nil)
+	if err != nil {
+		return nil, fmt.Errorf("count entries: %w", err)
+	}
+
+	metrics := []Metric{
+		sourceMetric("chronicle_entries_created_total", "Entries stored by this process since it started, by source.", TypeCounter, charm.CreatedBySource()),
+		sourceMetric("chronicle_entries", "Entries in the journal, by source.", TypeGauge, entriesBySource(stats)),
+		gauge("chronicle_sync_pending", "Local writes waiting to be pushed to the sync server.", float64(c.Client.PendingOps())),
+		gauge("chronicle_db_size_bytes", "Size of the database and its WAL file.", float64(charm.DBSize(c.DBPath))),
+	}
+	if last := c.Client.LastSyncTime(); !last.IsZero() {
+		metrics = append(metrics,
+			gauge("chronicle_last_sync_timestamp_seconds", "Unix time of the last successful sync.", float64(last.Unix())),
+			gauge("chronicle_last_sync_age_seconds", "Seconds since the last successful sync.", time.Since(last).Seconds()),
+		)
+	}
+	if c.Extra != nil {
+		metrics = append(metrics, c.Extra()...)
+	}
+	return metrics, nil
+}
+
+// ServeHTTP responds with the current metrics in the Prometheus text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	metrics, err := c.Collect()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, metrics); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Write writes metrics in the Prometheus text exposition format.
+func Write(w io.Writer, metrics []Metric) error {
+	for _, m := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.Name, escapeHelp(m.Help), m.Name, m.Type); err != nil {
+			return err
+		}
+		for _, s := range m.Samples {
+			if _, err := fmt.Fprintf(w, "%s%s %s\n", m.Name, formatLabels(s.Labels), strconv.FormatFloat(s.Value, 'g', -1, 64)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// gauge returns a metric with one unlabeled sample.
+func gauge(name, help string, value float64) Metric {
+	return Metric{Name: name, Help: help, Type: TypeGauge, Samples: []Sample{{Value: value}}}
+}
+
+// sourceMetric returns a metric with a sample per source, sorted by source.
+// Entries without a source are labeled "unknown".
+func sourceMetric(name, help, kind string, counts map[string]int) Metric {
+	m := Metric{Name: name, Help: help, Type: kind}
+	sources := make([]string, 0, len(counts))
+	for source := range counts {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		label := source
+		if label == "" {
+			label = unknownSource
+		}
+		m.Samples = append(m.Samples, Sample{Labels: map[string]string{"source": label}, Value: float64(counts[source])})
+	}
+	return m
+}
+
+// entriesBySource returns stats' per-source counts, with entries that have
+// no source under "".
+func entriesBySource(stats *charm.Stats) map[string]int {
+	counts := make(map[string]int, len(stats.Sources)+1)
+	known := 0
+	for source, n := range stats.Sources {
+		counts[source] = n
+		known += n
+	}
+	if unknown := stats.Total - known; unknown > 0 {
+		counts[""] = unknown
+	}
+	return counts
+}
+
+// formatLabels renders labels as {name="value",...} in name order, or
+// nothing when there are none.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + `="` + labelEscaper.Replace(labels[name]) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeHelp escapes backslashes and newlines in help text.
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
@@ -0,0 +1,50 @@
+// ABOUTME: Tests for Prometheus metrics
+// ABOUTME: Validates the text exposition format and per-source counts
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/harper/chronicle/internal/charm"
+)
+
+func TestWrite(t *testing.T) {
+	metrics := []Metric{
+		gauge("chronicle_sync_pending", "Local writes waiting.", 3),
+		sourceMetric("chronicle_entries", "Entries by source.", TypeGauge, map[string]int{"mcp": 2, "cli": 5, "": 1}),
+		{Name: "odd", Help: "Back\\slash\nnewline", Type: TypeGauge, Samples: []Sample{{Labels: map[string]string{"b": `say "hi"`, "a": "x"}, Value: 0.25}}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, metrics); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP chronicle_sync_pending Local writes waiting.
+# TYPE chronicle_sync_pending gauge
+chronicle_sync_pending 3
+# HELP chronicle_entries Entries by source.
+# TYPE chronicle_entries gauge
+chronicle_entries{source="unknown"} 1
+chronicle_entries{source="cli"} 5
+chronicle_entries{source="mcp"} 2
+# HELP odd Back\\slash\nnewline
+# TYPE odd gauge
+odd{a="x",b="say \"hi\""} 0.25
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEntriesBySource(t *testing.T) {
+	stats := &charm.Stats{Total: 10, Sources: map[string]int{"cli": 6, "api": 1}}
+	got := entriesBySource(stats)
+	if got["cli"] != 6 || got["api"] != 1 || got[""] != 3 || len(got) != 3 {
+		t.Errorf("entriesBySource = %v", got)
+	}
+
+	stats = &charm.Stats{Total: 2, Sources: map[string]int{"cli": 2}}
+	if _, ok := entriesBySource(stats)[""]; ok {
+		t.Error("no unknown count expected when every entry has a source")
+	}
+}
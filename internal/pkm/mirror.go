@@ -0,0 +1,258 @@
+// ABOUTME: Mirrors chronicle entries into Obsidian or Logseq daily notes
+// ABOUTME: Owns one block per note, rewritten on every run, and leaves the rest alone
+package pkm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+// Markers around the block chronicle owns in an Obsidian note.
+const (
+	obsidianBegin = "<!-- chronicle:begin -->"
+	obsidianEnd   = "<!-- chronicle:end -->"
+)
+
+// logseqBlock is the top-level block chronicle owns in a Logseq journal
+// page; its children are the entries.
+const logseqBlock = "- [[chronicle]]"
+
+// Result counts the daily notes a mirror touched.
+type Result struct {
+	Updated   int `json:"updated"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Mirror brings the daily notes for every day from since to now in line
+// with entries: each day's entries go in the block chronicle owns in that
+// day's note, which is created if needed. A day left without entries loses
+// its block, and a note left empty is deleted. Text outside the block is
+// never touched. With dryRun nothing is written.
+func Mirror(cfg *config.Vault, entries []charm.Entry, since, now time.Time, dryRun bool) (Result, error) {
+	format := cfg.FormatName()
+	byDay := make(map[string][]charm.Entry)
+	for _, entry := range entries {
+		day := entry.Timestamp.Local().Format(time.DateOnly)
+		byDay[day] = append(byDay[day], entry)
+	}
+
+	var result Result
+	dir := cfg.NotesDir()
+	for day := startOfDay(since); !day.After(now); day = day.AddDate(0, 0, 1) {
+		dayEntries := byDay[day.Format(time.DateOnly)]
+		sort.SliceStable(dayEntries, func(i, j int) bool { return dayEntries[i].Timestamp.Before(dayEntries[j].Timestamp) })
+
+		path := filepath.Join(dir, noteName(day, format))
+		existing, err := os.ReadFile(path) //nolint:gosec // Path is inside the configured vault
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return result, err
+		}
+		note := string(existing)
+		updated := replaceBlock(note, renderBlock(dayEntries, format), format)
+		switch {
+		case updated == note:
+			if len(dayEntries) > 0 {
+				result.Unchanged++
+			}
+		case strings.TrimSpace(updated) == "":
+			if !dryRun {
+				if err := os.Remove(path); err != nil {
+					return result, err
+				}
+			}
+			result.Removed++
+		default:
+			if !dryRun {
+				if err := writeNote(path, updated); err != nil {
+					return result, err
+				}
+			}
+			result.Updated++
+		}
+	}
+	return result, nil
+}
+
+// MirrorJournal mirrors the client's entries from cfg's window into its
+// vault.
+func MirrorJournal(client *charm.Client, cfg *config.Vault, dryRun bool) (Result, error) {
+	if err := cfg.Validate(); err != nil {
+		return Result{}, fmt.Errorf("invalid vault settings: %w", err)
+	}
+	if info, err := os.Stat(config.ExpandHome(cfg.Path)); err != nil || !info.IsDir() {
+		return Result{}, fmt.Errorf("vault %s is not a directory", cfg.Path)
+	}
+	now := time.Now()
+	since := startOfDay(now.Add(-cfg.Window()))
+	entries, err := client.SearchEntries(&charm.SearchFilter{Tags: cfg.Tags, Since: &since}, 0)
+	if err != nil {
+		return Result{}, fmt.Errorf("list entries: %w", err)
+	}
+	return Mirror(cfg, entries, since, now, dryRun)
+}
+
+// noteName is the daily note file for day: DD-MM-YYYY.md in Obsidian, the
+// same name the obsidian project log format uses, and YYYY_MM_DD.md in
+// Logseq.
+func noteName(day time.Time, format string) string {
+	if format == config.VaultLogseq {
+		return day.Format(config.LogseqJournalLayout) + ".md"
+	}
+	return day.Format(config.ObsidianDailyNoteLayout) + ".md"
+}
+
+// renderBlock renders a day's entries as the block chronicle owns, or ""
+// when there are none. Tags become [[links]] to tag pages.
+func renderBlock(entries []charm.Entry, format string) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	bullet, continuation := "- ", "  "
+	if format == config.VaultLogseq {
+		b.WriteString(logseqBlock + "\n")
+		bullet, continuation = "\t- ", "\t  "
+	} else {
+		b.WriteString(obsidianBegin + "\n## Chronicle\n\n")
+	}
+	for _, entry := range entries {
+		lines := strings.Split(strings.TrimRight(entry.Message, "\n"), "\n")
+		first := entry.Timestamp.Local().Format("15:04") + " "
+		if kind := entry.Kind(); kind != charm.EntryTypeNote {
+			first += "**" + kind + "** "
+		}
+		first += lines[0]
+		for _, tag := range entry.Tags {
+			if link := tagLink(tag); link != "" {
+				first += " " + link
+			}
+		}
+		b.WriteString(bullet + first + "\n")
+		for _, line := range lines[1:] {
+			b.WriteString(continuation + line + "\n")
+		}
+	}
+	if format != config.VaultLogseq {
+		b.WriteString(obsidianEnd + "\n")
+	}
+	return b.String()
+}
+
+// tagLink links to tag's page, replacing characters wikilinks can't hold.
+func tagLink(tag string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '[', ']', '|', '#', '^', '\n':
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(tag))
+	if name == "" {
+		return ""
+	}
+	return "[[" + name + "]]"
+}
+
+// replaceBlock puts block in place of the one chronicle owns in note,
+// appending it if the note has none. An empty block removes chronicle's.
+func replaceBlock(note, block, format string) string {
+	start, end := findBlock(note, format)
+	if start < 0 {
+		if block == "" {
+			return note
+		}
+		if note == "" {
+			return block
+		}
+		return strings.TrimRight(note, "\n") + "\n\n" + block
+	}
+	before, after := note[:start], note[end:]
+	if block == "" {
+		// Drop the blank line that separated the block too
+		before = strings.TrimRight(before, "\n")
+		after = strings.TrimLeft(after, "\n")
+		if before != "" && after != "" {
+			return before + "\n\n" + after
+		}
+		if before != "" {
+			return before + "\n"
+		}
+		return after
+	}
+	return before + block + after
+}
+
+// findBlock returns the byte range of chronicle's block in note, or -1, -1.
+func findBlock(note, format string) (int, int) {
+	if format != config.VaultLogseq {
+		start := strings.Index(note, obsidianBegin)
+		if start < 0 {
+			return -1, -1
+		}
+		end := strings.Index(note[start:], obsidianEnd)
+		if end < 0 {
+			return -1, -1
+		}
+		end += start + len(obsidianEnd)
+		if end < len(note) && note[end] == '\n' {
+			end++
+		}
+		return start, end
+	}
+
+	offset := 0
+	start := -1
+	for _, line := range strings.SplitAfter(note, "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		switch {
+		case start < 0 && trimmed == logseqBlock:
+			start = offset
+		case start >= 0 && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, " "):
+			return start, offset
+		}
+		offset += len(line)
+	}
+	if start < 0 {
+		return -1, -1
+	}
+	return start, len(note)
+}
+
+// writeNote replaces the note at path through a temp file, so the app
+// never sees a half-written note.
+func writeNote(path, note string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".chronicle-*.md")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(note); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil { //nolint:gosec // Notes are ordinary vault files
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// startOfDay returns midnight local time on t's day.
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,147 @@
+// ABOUTME: Tests for mirroring entries into vault daily notes
+// ABOUTME: Checks both note formats, edits around the owned block, and cleanup
+package pkm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+func readNote(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path) //nolint:gosec // Test file in a temp dir
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestMirrorObsidian(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Vault{Path: dir}
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local)
+	entries := []charm.Entry{
+		{ID: "b", Timestamp: day.Add(14 * time.Hour), Message: "shipped it", Tags: []string{"work", "a|b"}},
+		{ID: "a", Timestamp: day.Add(9*time.Hour + 5*time.Minute), Message: "standup\nwent long", Type: charm.EntryTypeTodo},
+	}
+
+	result, err := Mirror(cfg, entries, day, day.Add(20*time.Hour), false)
+	if err != nil {
+		t.Fatalf("Mirror: %v", err)
+	}
+	if result != (Result{Updated: 1}) {
+		t.Errorf("result = %+v, want one update", result)
+	}
+	path := filepath.Join(dir, "04-03-2026.md")
+	want := obsidianBegin + "\n## Chronicle\n\n" +
+		"- 09:05 **todo** standup\n  went long\n" +
+		"- 14:00 shipped it [[work]] [[a-b]]\n" +
+		obsidianEnd + "\n"
+	if got := readNote(t, path); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+
+	// Text the user wrote around the block survives a rewrite
+	note := "# Wednesday\n\nMy own notes.\n\n" + want + "\nAfterthoughts.\n"
+	if err := os.WriteFile(path, []byte(note), 0600); err != nil {
+		t.Fatal(err)
+	}
+	entries[1].Message = "standup"
+	if _, err := Mirror(cfg, entries, day, day.Add(20*time.Hour), false); err != nil {
+		t.Fatalf("Mirror: %v", err)
+	}
+	got := readNote(t, path)
+	if !strings.HasPrefix(got, "# Wednesday\n\nMy own notes.\n\n") || !strings.HasSuffix(got, "\nAfterthoughts.\n") {
+		t.Errorf("user text changed:\n%s", got)
+	}
+	if strings.Contains(got, "went long") {
+		t.Errorf("edited entry not updated:\n%s", got)
+	}
+
+	// Running again changes nothing
+	result, err = Mirror(cfg, entries, day, day.Add(20*time.Hour), false)
+	if err != nil {
+		t.Fatalf("Mirror: %v", err)
+	}
+	if result != (Result{Unchanged: 1}) {
+		t.Errorf("result = %+v, want one unchanged", result)
+	}
+
+	// With the entries gone the block goes, leaving the user's text
+	if _, err := Mirror(cfg, nil, day, day.Add(20*time.Hour), false); err != nil {
+		t.Fatalf("Mirror: %v", err)
+	}
+	if got, want := readNote(t, path), "# Wednesday\n\nMy own notes.\n\nAfterthoughts.\n"; got != want {
+		t.Errorf("note = %q, want %q", got, want)
+	}
+}
+
+func TestMirrorRemovesEmptyNote(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Vault{Path: dir}
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local)
+	entries := []charm.Entry{{ID: "a", Timestamp: day.Add(time.Hour), Message: "hi"}}
+	if _, err := Mirror(cfg, entries, day, day.Add(2*time.Hour), false); err != nil {
+		t.Fatalf("Mirror: %v", err)
+	}
+
+	result, err := Mirror(cfg, nil, day, day.Add(2*time.Hour), false)
+	if err != nil {
+		t.Fatalf("Mirror: %v", err)
+	}
+	if result != (Result{Removed: 1}) {
+		t.Errorf("result = %+v, want one removal", result)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "04-03-2026.md")); !os.IsNotExist(err) {
+		t.Errorf("empty note not removed: %v", err)
+	}
+}
+
+func TestMirrorLogseq(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Vault{Path: dir, Format: config.VaultLogseq}
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local)
+	path := filepath.Join(dir, "journals", "2026_03_04.md")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	before := "- morning pages\n\t- nested thought\n"
+	stale := logseqBlock + "\n\t- 08:00 old entry\n"
+	after := "- evening review\n"
+	if err := os.WriteFile(path, []byte(before+stale+after), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []charm.Entry{{ID: "a", Timestamp: day.Add(10 * time.Hour), Message: "pairing\nwith sam", Tags: []string{"work"}}}
+	if _, err := Mirror(cfg, entries, day, day.Add(20*time.Hour), false); err != nil {
+		t.Fatalf("Mirror: %v", err)
+	}
+	want := before + logseqBlock + "\n\t- 10:00 pairing [[work]]\n\t  with sam\n" + after
+	if got := readNote(t, path); got != want {
+		t.Errorf("note =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestMirrorDryRun(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Vault{Path: dir}
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local)
+	entries := []charm.Entry{{ID: "a", Timestamp: day.Add(time.Hour), Message: "hi"}}
+
+	result, err := Mirror(cfg, entries, day, day.Add(2*time.Hour), true)
+	if err != nil {
+		t.Fatalf("Mirror: %v", err)
+	}
+	if result != (Result{Updated: 1}) {
+		t.Errorf("result = %+v, want one update", result)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("dry run wrote %d files", len(files))
+	}
+}
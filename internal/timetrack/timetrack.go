@@ -0,0 +1,142 @@
+// ABOUTME: Turns entries with a duration into tracked time for Toggl Track and Timewarrior
+// ABOUTME: Writes Toggl's CSV import format and the JSON timew export and import use
+package timetrack
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+// timewTime is Timewarrior's timestamp format, always in UTC.
+const timewTime = "20060102T150405Z"
+
+// Interval is an entry with a duration as a span of tracked time. Entries
+// are logged when the work ends, so the span ends at the entry's
+// timestamp.
+type Interval struct {
+	EntryID     string
+	Start       time.Time
+	End         time.Time
+	Description string
+	Project     string
+	Tags        []string
+}
+
+// Duration returns how long the interval lasts.
+func (iv Interval) Duration() time.Duration {
+	return iv.End.Sub(iv.Start)
+}
+
+// EntryDuration returns how long entry's work took, from its duration
+// metadata, or 0 if it has none.
+func EntryDuration(entry *charm.Entry) time.Duration {
+	d, err := time.ParseDuration(entry.Metadata["duration"])
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Intervals returns the entries with a duration as intervals, oldest
+// first, with projects from cfg (nil for none). The description is the
+// message's first line.
+func Intervals(entries []charm.Entry, cfg *config.TimeTracking) []Interval {
+	var intervals []Interval
+	for i := range entries {
+		entry := &entries[i]
+		d := EntryDuration(entry)
+		if d < time.Second {
+			continue
+		}
+		description, _, _ := strings.Cut(strings.TrimSpace(entry.Message), "\n")
+		intervals = append(intervals, Interval{
+			EntryID:     entry.ID,
+			Start:       entry.Timestamp.Add(-d),
+			End:         entry.Timestamp,
+			Description: strings.TrimSpace(description),
+			Project:     cfg.Project(entry.Tags),
+			Tags:        entry.Tags,
+		})
+	}
+	sort.SliceStable(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+	return intervals
+}
+
+// WriteTogglCSV writes intervals in Toggl Track's CSV import format, in
+// local time. Intervals with a project are marked billable when billable
+// is set.
+func WriteTogglCSV(w io.Writer, intervals []Interval, email string, billable bool) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"Email", "Project", "Description", "Billable", "Start date", "Start time", "Duration", "Tags"}); err != nil {
+		return err
+	}
+	for _, iv := range intervals {
+		isBillable := "No"
+		if billable && iv.Project != "" {
+			isBillable = "Yes"
+		}
+		start := iv.Start.Local()
+		if err := out.Write([]string{
+			email,
+			iv.Project,
+			iv.Description,
+			isBillable,
+			start.Format(time.DateOnly),
+			start.Format(time.TimeOnly),
+			clockDuration(iv.Duration()),
+			strings.Join(iv.Tags, ", "),
+		}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// clockDuration formats d as HH:MM:SS, hours unbounded.
+func clockDuration(d time.Duration) string {
+	s := int64(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", s/3600, s/60%60, s%60)
+}
+
+// timewInterval is one interval as timew export prints it.
+type timewInterval struct {
+	Start      string   `json:"start"`
+	End        string   `json:"end"`
+	Tags       []string `json:"tags,omitempty"`
+	Annotation string   `json:"annotation,omitempty"`
+}
+
+// WriteTimew writes intervals as the JSON array timew export prints and
+// timew import reads. Timewarrior has no projects, so the project becomes
+// the first tag.
+func WriteTimew(w io.Writer, intervals []Interval) error {
+	out := make([]timewInterval, 0, len(intervals))
+	for _, iv := range intervals {
+		var tags []string
+		if iv.Project != "" {
+			tags = append(tags, iv.Project)
+		}
+		tags = charm.MergeTags(tags, iv.Tags)
+		out = append(out, timewInterval{
+			Start:      iv.Start.UTC().Format(timewTime),
+			End:        iv.End.UTC().Format(timewTime),
+			Tags:       tags,
+			Annotation: iv.Description,
+		})
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,153 @@
+// ABOUTME: Tests for exporting entries as tracked time
+// ABOUTME: Checks interval building, Toggl CSV, Timewarrior JSON, and pushing to a fake Toggl API
+package timetrack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harper/chronicle/internal/charm"
+	"github.com/harper/chronicle/internal/config"
+)
+
+func testIntervals() []Interval {
+	end := time.Date(2025, 3, 4, 10, 30, 0, 0, time.UTC)
+	entries := []charm.Entry{
+		{ID: "later", Timestamp: end.Add(3 * time.Hour), Message: "internal sync", Tags: []string{"meeting"}, Metadata: map[string]string{"duration": "30m0s"}},
+		{ID: "acme", Timestamp: end, Message: "ACME API migration\nmoved the auth service", Tags: []string{"backend", "acme"}, Metadata: map[string]string{"duration": "1h30m0s"}},
+		{ID: "none", Timestamp: end, Message: "no duration"},
+		{ID: "bad", Timestamp: end, Message: "bad duration", Metadata: map[string]string{"duration": "soon"}},
+	}
+	cfg := &config.TimeTracking{Projects: map[string]string{"acme": "ACME Corp", "meeting": "Internal"}}
+	return Intervals(entries, cfg)
+}
+
+func TestIntervals(t *testing.T) {
+	got := testIntervals()
+	if len(got) != 2 {
+		t.Fatalf("intervals = %+v, want two", got)
+	}
+	first := got[0]
+	if first.EntryID != "acme" || first.Project != "ACME Corp" || first.Description != "ACME API migration" {
+		t.Errorf("first interval = %+v", first)
+	}
+	if want := time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC); !first.Start.Equal(want) || first.Duration() != 90*time.Minute {
+		t.Errorf("first interval runs from %s for %s", first.Start, first.Duration())
+	}
+	if got[1].Project != "Internal" {
+		t.Errorf("second project = %q", got[1].Project)
+	}
+	tagged := []charm.Entry{{ID: "x", Timestamp: time.Now(), Message: "x", Tags: []string{"acme"}, Metadata: map[string]string{"duration": "5m"}}}
+	if iv := Intervals(tagged, nil); len(iv) != 1 || iv[0].Project != "" {
+		t.Errorf("without config = %+v, want one interval without a project", iv)
+	}
+}
+
+func TestWriteTogglCSV(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+	var buf bytes.Buffer
+	if err := WriteTogglCSV(&buf, testIntervals(), "me@example.com", true); err != nil {
+		t.Fatal(err)
+	}
+	want := "Email,Project,Description,Billable,Start date,Start time,Duration,Tags\n" +
+		"me@example.com,ACME Corp,ACME API migration,Yes,2025-03-04,09:00:00,01:30:00,\"backend, acme\"\n" +
+		"me@example.com,Internal,internal sync,Yes,2025-03-04,13:00:00,00:30:00,meeting\n"
+	if buf.String() != want {
+		t.Errorf("WriteTogglCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+	if got := clockDuration(26*time.Hour + 5*time.Second); got != "26:00:05" {
+		t.Errorf("clockDuration = %s", got)
+	}
+}
+
+func TestWriteTimew(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTimew(&buf, testIntervals()[:1]); err != nil {
+		t.Fatal(err)
+	}
+	var got []timewInterval
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []timewInterval{{Start: "20250304T090000Z", End: "20250304T103000Z", Tags: []string{"ACME Corp", "backend", "acme"}, Annotation: "ACME API migration"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteTimew() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPush(t *testing.T) {
+	var created []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "tok" || pass != "api_token" {
+			http.Error(w, "unauthorized", http.StatusForbidden)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/workspaces/42/projects":
+			if r.URL.Query().Get("page") != "1" {
+				_, _ = fmt.Fprint(w, "[]")
+				return
+			}
+			_, _ = fmt.Fprint(w, `[{"id": 7, "name": "ACME Corp"}, {"id": 8, "name": "Internal"}]`)
+		case r.Method == http.MethodPost && r.URL.Path == "/workspaces/42/time_entries":
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body)
+			_, _ = fmt.Fprintf(w, `{"id": %d}`, 100+len(created))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	toggl := NewToggl("tok", 42)
+	toggl.base = srv.URL
+	statePath := filepath.Join(t.TempDir(), "toggl.json")
+	state, err := LoadPushState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result, err := Push(context.Background(), toggl, testIntervals(), true, state, true); err != nil || result.Created != 2 || len(created) != 0 {
+		t.Fatalf("dry run = %+v, %v with %d created", result, err, len(created))
+	}
+	result, err := Push(context.Background(), toggl, testIntervals(), true, state, false)
+	if err != nil || result.Created != 2 {
+		t.Fatalf("Push() = %+v, %v", result, err)
+	}
+	first := created[0]
+	if first["project_id"] != float64(7) || first["duration"] != float64(5400) || first["billable"] != true || first["start"] != "2025-03-04T09:00:00Z" {
+		t.Errorf("first time entry = %v", first)
+	}
+	if err := state.Save(statePath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pushing again sends nothing new
+	state, err = LoadPushState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := Push(context.Background(), toggl, testIntervals(), true, state, false); err != nil || result.Created != 0 || result.Skipped != 2 {
+		t.Errorf("second push = %+v, %v", result, err)
+	}
+
+	// A project Toggl doesn't have stops the push before anything is sent
+	missing := []Interval{{EntryID: "new", Project: "Globex", Start: time.Now().Add(-time.Hour), End: time.Now()}}
+	if _, err := Push(context.Background(), toggl, missing, false, state, false); err == nil || !strings.Contains(err.Error(), "Globex") {
+		t.Errorf("err = %v, want the missing project named", err)
+	}
+	if len(created) != 2 {
+		t.Errorf("%d time entries created, want 2", len(created))
+	}
+}
@@ -0,0 +1,222 @@
+// ABOUTME: Pushes intervals to Toggl Track through its v9 API
+// ABOUTME: Remembers which entries it created so pushing again never bills twice
+package timetrack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// togglAPI is Toggl Track's API base.
+const togglAPI = "https://api.track.toggl.com/api/v9"
+
+// togglTimeout bounds each Toggl API request.
+const togglTimeout = 30 * time.Second
+
+// togglPageSize is how many projects are listed per request, Toggl's most.
+const togglPageSize = 200
+
+// Toggl is a Toggl Track workspace reached with an API token.
+type Toggl struct {
+	base      string
+	token     string
+	workspace int64
+	http      *http.Client
+}
+
+// NewToggl returns a client for workspace using token.
+func NewToggl(token string, workspace int64) *Toggl {
+	return &Toggl{base: togglAPI, token: token, workspace: workspace, http: &http.Client{Timeout: togglTimeout}}
+}
+
+// PushState records the Toggl time entry created for each entry ID.
+type PushState struct {
+	Entries map[string]int64 `json:"entries"`
+}
+
+// PushResult counts what a push did.
+type PushResult struct {
+	Created int `json:"created"`
+	Skipped int `json:"skipped"`
+}
+
+// LoadPushState reads the state file at path; a missing file is an empty
+// state.
+func LoadPushState(path string) (*PushState, error) {
+	state := &PushState{Entries: make(map[string]int64)}
+	data, err := os.ReadFile(path) //nolint:gosec // Path is chronicle's own state file
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read toggl state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parse toggl state %s: %w", path, err)
+	}
+	if state.Entries == nil {
+		state.Entries = make(map[string]int64)
+	}
+	return state, nil
+}
+
+// Save atomically replaces the state file at path.
+func (s *PushState) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write toggl state: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Push creates a Toggl time entry for each interval not pushed before.
+// Every project must exist in the workspace; if one doesn't, nothing is
+// created. With dryRun nothing is created and state is unchanged. On
+// error, state still records what was created before it.
+func Push(ctx context.Context, t *Toggl, intervals []Interval, billable bool, state *PushState, dryRun bool) (PushResult, error) {
+	var result PushResult
+	var pending []Interval
+	for _, iv := range intervals {
+		if _, ok := state.Entries[iv.EntryID]; ok {
+			result.Skipped++
+			continue
+		}
+		pending = append(pending, iv)
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	projects, err := t.projectIDs(ctx)
+	if err != nil {
+		return result, err
+	}
+	var missing []string
+	for _, iv := range pending {
+		if _, ok := projects[iv.Project]; iv.Project != "" && !ok && !contains(missing, iv.Project) {
+			missing = append(missing, iv.Project)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return result, fmt.Errorf("no Toggl project named %s in workspace %d", strings.Join(missing, ", "), t.workspace)
+	}
+
+	for _, iv := range pending {
+		if !dryRun {
+			id, err := t.create(ctx, iv, projects[iv.Project], billable && iv.Project != "")
+			if err != nil {
+				return result, err
+			}
+			state.Entries[iv.EntryID] = id
+		}
+		result.Created++
+	}
+	return result, nil
+}
+
+// projectIDs returns the workspace's project IDs by name.
+func (t *Toggl) projectIDs(ctx context.Context) (map[string]int64, error) {
+	ids := make(map[string]int64)
+	for page := 1; ; page++ {
+		var projects []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		}
+		path := fmt.Sprintf("/workspaces/%d/projects?per_page=%d&page=%d", t.workspace, togglPageSize, page)
+		if err := t.do(ctx, http.MethodGet, path, nil, &projects); err != nil {
+			return nil, fmt.Errorf("list Toggl projects: %w", err)
+		}
+		for _, p := range projects {
+			ids[p.Name] = p.ID
+		}
+		if len(projects) < togglPageSize {
+			return ids, nil
+		}
+	}
+}
+
+// create adds iv as a time entry and returns its Toggl ID. A zero
+// projectID leaves it without a project.
+func (t *Toggl) create(ctx context.Context, iv Interval, projectID int64, billable bool) (int64, error) {
+	body := map[string]any{
+		"created_with": "chronicle",
+		"workspace_id": t.workspace,
+		"description":  iv.Description,
+		"start":        iv.Start.UTC().Format(time.RFC3339),
+		"stop":         iv.End.UTC().Format(time.RFC3339),
+		"duration":     int64(iv.Duration() / time.Second),
+		"tags":         iv.Tags,
+		"billable":     billable,
+	}
+	if projectID != 0 {
+		body["project_id"] = projectID
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := t.do(ctx, http.MethodPost, fmt.Sprintf("/workspaces/%d/time_entries", t.workspace), body, &created); err != nil {
+		return 0, fmt.Errorf("create Toggl time entry for %s: %w", iv.EntryID, err)
+	}
+	return created.ID, nil
+}
+
+// do sends a request to the Toggl API and decodes the JSON reply into out.
+func (t *Toggl) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.base+path, reader)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.token, "api_token")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, out)
+}
+
+// contains reports whether list holds s.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}